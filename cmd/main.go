@@ -1,21 +1,38 @@
 package main
 
 import (
-	"log"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
 	"os"
+	"os/signal"
 	"pvz/internal/app"
+	"pvz/internal/auth"
 	"pvz/internal/config"
+	"pvz/internal/database"
+	"pvz/internal/logger"
+	"pvz/internal/repository"
+	"pvz/internal/services"
+	"pvz/internal/tracing"
 	"pvz/internal/validation"
+	"syscall"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
 const ConfigDefault = "config/config.yml"
 
-func main() {
+const usage = `usage:
+  pvz                          start the server
+  pvz migrate up|down|status   manage database schema
+  pvz import-pvz [-dry-run] file.csv
+                               create pvz from a csv with city, address, latitude, longitude`
 
-	router := echo.New()
-	router.Validator = validation.NewValidator()
+func main() {
 
 	filepath := os.Getenv("CFG_FILEPATH")
 	if filepath == "" {
@@ -24,14 +41,113 @@ func main() {
 
 	cfg, err := config.LoadConfig(filepath)
 	if err != nil {
-		log.Fatal(err)
+		slog.Error("failed to load config", "error", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(logger.New(cfg.Logger.Level, os.Stdout))
+
+	if len(os.Args) > 1 {
+		if err := runCommand(*cfg, os.Args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	ap, err := app.NewApp(router, *cfg)
+	if err := serve(*cfg); err != nil {
+		slog.Error("server stopped", "error", err)
+		os.Exit(1)
+	}
+}
+
+// serve runs the server until SIGINT or SIGTERM and flushes buffered spans on the way out.
+func serve(cfg config.Config) (err error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdown, err := tracing.Init(ctx, cfg.Tracing)
 	if err != nil {
-		log.Fatal(err)
+		return fmt.Errorf("failed to init tracing: %w", err)
 	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if shutdownErr := shutdown(flushCtx); shutdownErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to flush traces: %w", shutdownErr))
+		}
+	}()
+
+	router := echo.New()
+	ap, err := app.NewApp(router, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to init app: %w", err)
+	}
+	router.Validator = validation.NewValidator(ap.Roles.Names()...)
 	ap.RegisterRoutes()
 	ap.RegisterMiddlewares()
-	ap.Start()
+	return ap.Start(ctx)
+}
+
+func runCommand(cfg config.Config, args []string) error {
+	switch args[0] {
+	case "migrate":
+		if len(args) != 2 {
+			return fmt.Errorf("%s", usage)
+		}
+		db, err := database.InitDB(cfg.DB.GetDsn())
+		if err != nil {
+			return err
+		}
+		defer db.DB.Close()
+		return database.Migrate(context.Background(), db.DB, args[1], os.Stdout)
+	case "import-pvz":
+		return importPVZ(cfg, args[1:])
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], usage)
+	}
+}
+
+// importPVZ runs the same checks as POST /pvz/import and prints the report as JSON.
+func importPVZ(cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("import-pvz", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "validate the file without creating anything")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		return fmt.Errorf("%s", usage)
+	}
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// the validator sets the tags of govalidator for the whole process, with the roles the server knows
+	roles, err := auth.NewRoles(cfg.Roles)
+	if err != nil {
+		return fmt.Errorf("failed to load roles: %w", err)
+	}
+	rows, rowErrs, err := validation.ParsePVZCSV(f, validation.NewValidator(roles.Names()...).Validate)
+	if err != nil {
+		return err
+	}
+	db, err := database.InitDB(cfg.DB.GetDsn())
+	if err != nil {
+		return err
+	}
+	defer db.DB.Close()
+
+	svc := services.NewService(repository.NewRepository(db.DB))
+	svc.Roles = roles
+	report, err := svc.ImportPVZs(context.Background(), rows, rowErrs, *dryRun)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	if len(report.Errors) > 0 {
+		return fmt.Errorf("%d of %d rows are invalid, nothing was imported", len(report.Errors), report.Rows)
+	}
+	return nil
 }
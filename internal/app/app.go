@@ -1,117 +1,599 @@
 package app
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"pvz/internal/auth"
 	"pvz/internal/config"
 	"pvz/internal/database"
 	"pvz/internal/handlers"
+	"pvz/internal/logger"
+	"pvz/internal/metrics"
 	"pvz/internal/models"
+	"pvz/internal/notify"
+	"pvz/internal/outbox"
+	"pvz/internal/password"
+	"pvz/internal/ratelimit"
 	"pvz/internal/repository"
 	"pvz/internal/services"
+	"pvz/internal/webhooks"
+	"pvz/pkg/utils"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	echojwt "github.com/labstack/echo-jwt/v4"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
+	"golang.org/x/time/rate"
 )
 
 type PVZHandlers interface {
 	DummyLogin(c echo.Context) error
 	RegisterUser(c echo.Context) error
 	LoginUser(c echo.Context) error
+	OIDCLogin(c echo.Context) error
+	ChangePassword(c echo.Context) error
+	RequestPasswordReset(c echo.Context) error
+	ResetPassword(c echo.Context) error
+
+	CreateUser(c echo.Context) error
+	ListUsers(c echo.Context) error
+	GetUser(c echo.Context) error
+	UpdateUser(c echo.Context) error
+	DeleteUser(c echo.Context) error
+	AssignPVZ(c echo.Context) error
+	UnassignPVZ(c echo.Context) error
+	GetMe(c echo.Context) error
+	CreateAPIKey(c echo.Context) error
+	ListAPIKeys(c echo.Context) error
+	DeleteAPIKey(c echo.Context) error
+	CreateWebhook(c echo.Context) error
+	ListWebhooks(c echo.Context) error
+	DeleteWebhook(c echo.Context) error
+	ListWebhookDeliveries(c echo.Context) error
 	CreatePVZ(c echo.Context) error
+	ImportPVZs(c echo.Context) error
+	UpdatePVZ(c echo.Context) error
 
 	CreateReception(c echo.Context) error
 	CloseLastReception(c echo.Context) error
+	ReopenLastReception(c echo.Context) error
 	DeleteLastProduct(c echo.Context) error
 	CreateProduct(c echo.Context) error
 
 	GetPVZ(c echo.Context) error
+	PVZEvents(c echo.Context) error
+	IntakeReport(c echo.Context) error
+	ReceptionStats(c echo.Context) error
+	ExportReceptions(c echo.Context) error
+	ExportProducts(c echo.Context) error
+}
+
+// UserChecker looks up the current state of the token owner.
+type UserChecker interface {
+	GetUser(ctx context.Context, userID string) (models.User, error)
+}
+
+// APIKeyAuthenticator resolves the key from an "Authorization: ApiKey" header.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, raw string) (models.APIKey, error)
 }
 
 type App struct {
-	Router  *echo.Echo
-	Handler PVZHandlers
-	Config  config.Config
+	Router      *echo.Echo
+	Handler     PVZHandlers
+	Idempotency IdempotencyStore
+	Users       UserChecker
+	APIKeys     APIKeyAuthenticator
+	Keys        *auth.KeySet
+	Roles       *auth.Roles
+	// Relays publish stored events, one per destination, and Webhooks sends queued
+	// deliveries while the server runs
+	Relays   []*outbox.Relay
+	Webhooks *webhooks.Worker
+	Config   config.Config
 }
 
 func NewApp(router *echo.Echo, config config.Config) (*App, error) {
+	extractor, err := NewIPExtractor(config.App.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	router.IPExtractor = extractor
+
 	db, err := database.InitDB(config.DB.GetDsn())
 	if err != nil {
 		return nil, err
 	}
+	if config.DB.AutoMigrate {
+		if err := database.Migrate(context.Background(), db.DB, database.MigrateUp, io.Discard); err != nil {
+			return nil, err
+		}
+		slog.Info("database schema is up to date")
+	}
 
 	repo := repository.NewRepository(db.DB)
 	service := services.NewService(repo)
+	service.Passwords = password.Policy{
+		MinLength:     config.Password.MinLength,
+		MaxLength:     password.DefaultPolicy().MaxLength,
+		RequireUpper:  config.Password.RequireUpper,
+		RequireLower:  config.Password.RequireLower,
+		RequireDigit:  config.Password.RequireDigit,
+		RequireSymbol: config.Password.RequireSymbol,
+		RejectCommon:  config.Password.RejectCommon,
+	}
+	service.ResetTTL = config.Password.ResetTTL
+	service.AllowPrivateWebhooks = config.Webhooks.AllowPrivate
+	roles, err := auth.NewRoles(config.Roles)
+	if err != nil {
+		return nil, err
+	}
+	service.Roles = roles
+	keys, err := auth.LoadKeySet(config.Auth, []byte(os.Getenv("SECRET_KEY")))
+	if err != nil {
+		return nil, err
+	}
+	service.Tokens = utils.TokenOptions{
+		Signer:   keys,
+		Issuer:   config.Auth.Issuer,
+		Audience: config.Auth.Audience,
+		TTL:      config.Auth.TokenTTL,
+	}
+	if config.Auth.OIDC.Enabled {
+		verifier, err := auth.NewOIDCVerifier(config.Auth.OIDC, roles, nil)
+		if err != nil {
+			return nil, err
+		}
+		service.Identities = verifier
+	}
+	service.Notifier, err = newNotifier(config.Notifier, config.App.Env)
+	if err != nil {
+		return nil, err
+	}
+	publisher, err := newPublisher(config.Outbox)
+	if err != nil {
+		return nil, err
+	}
+	relayCfg := outbox.Config{
+		PollInterval: config.Outbox.PollInterval,
+		BatchSize:    config.Outbox.BatchSize,
+		Lease:        outbox.DefaultConfig().Lease,
+	}
+	relays := []*outbox.Relay{
+		outbox.NewRelay(repo, models.DestWebhooks, webhooks.NewDispatcher(repo), relayCfg),
+		outbox.NewRelay(repo, models.DestPublisher, publisher, relayCfg),
+	}
+	worker := webhooks.NewWorker(repo, webhooks.Config{
+		PollInterval: config.Webhooks.PollInterval,
+		BatchSize:    config.Webhooks.BatchSize,
+		MaxAttempts:  config.Webhooks.MaxAttempts,
+		BaseBackoff:  config.Webhooks.BaseBackoff,
+		MaxBackoff:   config.Webhooks.MaxBackoff,
+		Timeout:      config.Webhooks.Timeout,
+		AllowPrivate: config.Webhooks.AllowPrivate,
+	})
 	handler := handlers.NewHandler(service)
+	handler.Events.PollInterval = config.EventStream.PollInterval
+	handler.Events.Heartbeat = config.EventStream.Heartbeat
+	handler.Events.AccessCheck = config.EventStream.AccessCheck
+	handler.Guard = ratelimit.NewLoginGuard(ratelimit.NewMemoryStore(), ratelimit.Config{
+		MaxFailuresPerIP:    config.RateLimit.MaxFailuresPerIP,
+		MaxFailuresPerEmail: config.RateLimit.MaxFailuresPerEmail,
+		Window:              config.RateLimit.Window,
+		Lockout:             config.RateLimit.Lockout,
+	})
 
-	return &App{Router: router, Handler: handler, Config: config}, nil
+	return &App{Router: router, Handler: handler, Idempotency: repo, Users: repo, APIKeys: service, Keys: keys, Roles: roles, Relays: relays, Webhooks: worker, Config: config}, nil
 }
 
-func (a *App) Start() {
-	log.Printf("Starting server at %s", a.Config.GetAddress())
-	if err := a.Router.Start(":" + a.Config.GetPort()); err != nil {
-		log.Fatalf("Could not start server: %v", err)
+// shutdownTimeout bounds waiting for in-flight requests, event streams still open then are cut.
+const shutdownTimeout = 10 * time.Second
+
+// Start serves until ctx is cancelled, then shuts the server down and waits for the background workers.
+func (a *App) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var wg sync.WaitGroup
+	for _, relay := range a.Relays {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			relay.Run(ctx)
+		}()
+	}
+	if a.Webhooks != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.Webhooks.Run(ctx)
+		}()
+	}
+	if a.Idempotency != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			PurgeIdempotencyKeys(ctx, a.Idempotency, a.Config.Idempotency.TTL, a.Config.Idempotency.PurgeInterval)
+		}()
+	}
+
+	slog.Info("starting server", "address", a.Config.GetAddress())
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- a.Router.Start(":" + a.Config.GetPort()) }()
+
+	var err error
+	select {
+	case err = <-serveErr:
+		err = fmt.Errorf("could not start server: %w", err)
+	case <-ctx.Done():
+		slog.Info("shutting down server")
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancelShutdown()
+		if err = a.Router.Shutdown(shutdownCtx); err != nil {
+			err = errors.Join(err, a.Router.Close())
+		}
 	}
+	cancel()
+	wg.Wait()
+	return err
 }
 
 func (a *App) RegisterMiddlewares() {
 	a.Router.Use(middleware.Recover())
-	a.Router.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
-		Format: "time=${time_rfc3339}, method=${method}, uri=${uri}, status=${status}\n",
-		Output: os.Stdout,
+	a.Router.Use(otelecho.Middleware(a.Config.Tracing.ServiceName))
+	a.Router.Use(middleware.RequestIDWithConfig(middleware.RequestIDConfig{
+		RequestIDHandler: func(c echo.Context, id string) {
+			ctx := logger.With(c.Request().Context(), "request_id", id)
+			c.SetRequest(c.Request().WithContext(ctx))
+		},
+	}))
+	a.Router.Use(middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
+		LogMethod:  true,
+		LogURI:     true,
+		LogStatus:  true,
+		LogLatency: true,
+		LogValuesFunc: func(c echo.Context, v middleware.RequestLoggerValues) error {
+			logger.FromContext(c.Request().Context()).LogAttrs(context.Background(), slog.LevelInfo, "request",
+				slog.String("method", v.Method),
+				slog.String("uri", v.URI),
+				slog.Int("status", v.Status),
+				slog.Duration("latency", v.Latency),
+			)
+			return nil
+		},
 	}))
 }
 
 func (a *App) RegisterRoutes() {
-	a.Router.POST("/dummyLogin", a.Handler.DummyLogin)
-	a.Router.POST("/register", a.Handler.RegisterUser)
-	a.Router.POST("/login", a.Handler.LoginUser)
+	if a.Config.DummyLoginEnabled() {
+		slog.Warn("/dummyLogin is enabled", "env", a.Config.App.Env)
+		a.Router.POST("/dummyLogin", a.Handler.DummyLogin)
+	}
+	authLimitMW := AuthRateLimiterMW(a.Config.RateLimit)
+	a.Router.POST("/register", a.Handler.RegisterUser, authLimitMW)
+	a.Router.POST("/login", a.Handler.LoginUser, authLimitMW)
+	if a.Config.Auth.OIDC.Enabled {
+		a.Router.POST("/login/oidc", a.Handler.OIDCLogin, authLimitMW)
+	}
+	a.Router.POST("/password/reset", a.Handler.RequestPasswordReset, authLimitMW)
+	a.Router.POST("/password/reset/confirm", a.Handler.ResetPassword, authLimitMW)
+	a.Router.GET("/metrics", echo.WrapHandler(promhttp.Handler()))
+
+	a.Router.GET("/.well-known/jwks.json", func(c echo.Context) error {
+		c.Response().Header().Set(echo.HeaderCacheControl, "public, max-age=300")
+		return c.JSON(http.StatusOK, a.Keys.JWKS())
+	})
+
+	jwtMW := JWTMW(a.Config.Auth, a.Keys)
+
+	idempotencyMW := IdempotencyMW(a.Idempotency, a.Config.Idempotency.TTL)
 
-	jwtMW := echojwt.WithConfig(echojwt.Config{
-		SigningKey: []byte(os.Getenv("SECRET_KEY")),
+	// the permission is checked before a stored idempotent response is replayed
+	can := func(perm string) []echo.MiddlewareFunc {
+		return []echo.MiddlewareFunc{PermissionMW(a.Roles, perm), idempotencyMW}
+	}
+
+	api := a.Router.Group("", APIKeyMW(a.APIKeys), jwtMW, CurrentUserMW(a.Users))
+	api.GET("/me", a.Handler.GetMe)
+	api.POST("/me/password", a.Handler.ChangePassword)
+
+	api.GET("/pvz", a.Handler.GetPVZ, can(models.PermPVZRead)...)
+	api.POST("/pvz", a.Handler.CreatePVZ, can(models.PermPVZCreate)...)
+	// the csv is limited before the idempotency middleware reads it
+	api.POST("/pvz/import", a.Handler.ImportPVZs,
+		append([]echo.MiddlewareFunc{BodyLimitMW(handlers.MaxPVZImportSize)}, can(models.PermPVZCreate)...)...)
+	api.PATCH("/pvz/:pvzId", a.Handler.UpdatePVZ, can(models.PermPVZUpdate)...)
+	// a stream is never replayed, so it goes without the idempotency middleware
+	api.GET("/pvz/:pvzId/events", a.Handler.PVZEvents, PermissionMW(a.Roles, models.PermPVZRead))
+
+	api.POST("/receptions", a.Handler.CreateReception, can(models.PermReceptionOpen)...)
+	api.POST("/pvz/:pvzId/close_last_reception", a.Handler.CloseLastReception, can(models.PermReceptionClose)...)
+	api.POST("/pvz/:pvzId/reopen_last_reception", a.Handler.ReopenLastReception, can(models.PermReceptionReopen)...)
+
+	api.POST("/products", a.Handler.CreateProduct, can(models.PermProductCreate)...)
+	api.POST("/pvz/:pvzId/delete_last_product", a.Handler.DeleteLastProduct, can(models.PermProductDelete)...)
+
+	api.POST("/users", a.Handler.CreateUser, can(models.PermUserManage)...)
+	api.GET("/users", a.Handler.ListUsers, can(models.PermUserManage)...)
+	api.GET("/users/:id", a.Handler.GetUser, can(models.PermUserManage)...)
+	api.PATCH("/users/:id", a.Handler.UpdateUser, can(models.PermUserManage)...)
+	api.DELETE("/users/:id", a.Handler.DeleteUser, can(models.PermUserManage)...)
+	api.PUT("/users/:id/pvz/:pvzId", a.Handler.AssignPVZ, can(models.PermUserManage)...)
+	api.DELETE("/users/:id/pvz/:pvzId", a.Handler.UnassignPVZ, can(models.PermUserManage)...)
+
+	// the response holds the raw key, the idempotency middleware would store and replay it
+	api.POST("/api-keys", a.Handler.CreateAPIKey, PermissionMW(a.Roles, models.PermUserManage))
+	api.GET("/api-keys", a.Handler.ListAPIKeys, can(models.PermUserManage)...)
+	api.DELETE("/api-keys/:id", a.Handler.DeleteAPIKey, can(models.PermUserManage)...)
+
+	// the response holds the signing secret, like the api key it must not be stored
+	api.POST("/webhooks", a.Handler.CreateWebhook, PermissionMW(a.Roles, models.PermWebhookManage))
+	api.GET("/webhooks", a.Handler.ListWebhooks, can(models.PermWebhookManage)...)
+	api.DELETE("/webhooks/:id", a.Handler.DeleteWebhook, can(models.PermWebhookManage)...)
+	api.GET("/webhooks/:id/deliveries", a.Handler.ListWebhookDeliveries, can(models.PermWebhookManage)...)
+
+	api.GET("/reports/intake", a.Handler.IntakeReport, can(models.PermReportRead)...)
+	api.GET("/reports/receptions", a.Handler.ReceptionStats, can(models.PermReportRead)...)
+	api.GET("/export/receptions", a.Handler.ExportReceptions, can(models.PermPVZRead)...)
+	api.GET("/export/products", a.Handler.ExportProducts, can(models.PermPVZRead)...)
+}
+
+// NewIPExtractor decides what c.RealIP returns, rate limits and login lockouts are keyed
+// by it. Without trusted proxies it is the peer address, a client can't pick it by
+// sending X-Forwarded-For. With them the header is read up to the first untrusted hop.
+func NewIPExtractor(trustedProxies []string) (echo.IPExtractor, error) {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+	opts := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, cidr := range trustedProxies {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q: %w", cidr, err)
+		}
+		opts = append(opts, echo.TrustIPRange(ipNet))
+	}
+	return echo.ExtractIPFromXFFHeader(opts...), nil
+}
+
+const apiKeyScheme = "ApiKey"
+
+// newNotifier returns nil for "none", password reset is unavailable then. The log
+// notifier writes reset tokens to the log, anyone reading it could take over accounts,
+// so it is refused in prod.
+func newNotifier(cfg config.NotifierCfg, env string) (notify.Notifier, error) {
+	switch cfg.Kind {
+	case "", "none":
+		return nil, nil
+	case "log":
+		if env == config.EnvProd {
+			return nil, fmt.Errorf("notifier kind log writes reset tokens to the log, it is only allowed in dev and test")
+		}
+		return notify.LogNotifier{}, nil
+	case "file":
+		return notify.NewFileNotifier(cfg.FilePath), nil
+	default:
+		return nil, fmt.Errorf("unknown notifier kind %q, expected none, log or file", cfg.Kind)
+	}
+}
+
+func newPublisher(cfg config.OutboxCfg) (outbox.Publisher, error) {
+	switch cfg.Publisher {
+	case "", "none":
+		return outbox.Multi{}, nil
+	case "stdout":
+		return outbox.NewStdoutPublisher(), nil
+	case "file":
+		return outbox.NewFilePublisher(cfg.FilePath), nil
+	case "nats":
+		return newNATSPublisher(cfg.NATS)
+	default:
+		return nil, fmt.Errorf("unknown outbox publisher %q, expected none, stdout, file or nats", cfg.Publisher)
+	}
+}
+
+func newNATSPublisher(cfg config.NATSCfg) (*outbox.NATSPublisher, error) {
+	// the client reconnects on its own, the relay retries events published while disconnected
+	conn, err := nats.Connect(cfg.URL, nats.Name("pvz"), nats.MaxReconnects(-1), nats.Timeout(cfg.Timeout))
+	if err != nil {
+		return nil, fmt.Errorf("connect to nats: %w", err)
+	}
+	pub, err := outbox.NewNATSPublisher(conn, cfg.SubjectPrefix, cfg.JetStream)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	pub.Timeout = cfg.Timeout
+	if cfg.JetStream && cfg.Stream != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+		defer cancel()
+		if err := pub.EnsureStream(ctx, cfg.Stream); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return pub, nil
+}
+
+// JWTMW accepts tokens signed with one of the keys for the configured issuer and audience.
+// Requests already authenticated with an API key are skipped.
+func JWTMW(cfg config.AuthCfg, keys *auth.KeySet) echo.MiddlewareFunc {
+	parser := jwt.NewParser(
+		jwt.WithValidMethods(keys.Methods()),
+		jwt.WithIssuer(cfg.Issuer),
+		jwt.WithAudience(cfg.Audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	return echojwt.WithConfig(echojwt.Config{
+		Skipper: func(c echo.Context) bool {
+			return c.Get(handlers.ContextPrincipal) != nil
+		},
+		ParseTokenFunc: func(c echo.Context, raw string) (interface{}, error) {
+			return parser.ParseWithClaims(raw, new(utils.Claims), keys.Keyfunc)
+		},
 		ErrorHandler: func(c echo.Context, err error) error {
 			return echo.NewHTTPError(http.StatusForbidden, models.Err("access is denied"))
 		},
 	})
+}
 
-	moderMW := RoleCheckerMW(models.Moderator)
-	moderatorsGroup := a.Router.Group("", jwtMW, moderMW)
-	moderatorsGroup.POST("/pvz", a.Handler.CreatePVZ)
+// AuthRateLimiterMW limits requests per client ip with a token bucket.
+func AuthRateLimiterMW(cfg config.RateLimitCfg) echo.MiddlewareFunc {
+	store := middleware.NewRateLimiterMemoryStoreWithConfig(middleware.RateLimiterMemoryStoreConfig{
+		Rate:      rate.Limit(cfg.RequestsPerSecond),
+		Burst:     cfg.Burst,
+		ExpiresIn: 3 * time.Minute,
+	})
+	return middleware.RateLimiterWithConfig(middleware.RateLimiterConfig{
+		Store: store,
+		IdentifierExtractor: func(c echo.Context) (string, error) {
+			return c.RealIP(), nil
+		},
+		ErrorHandler: func(c echo.Context, err error) error {
+			return c.JSON(http.StatusForbidden, models.Err("access is denied"))
+		},
+		DenyHandler: func(c echo.Context, identifier string, err error) error {
+			metrics.AuthRejected.WithLabelValues(c.Path()).Inc()
+			logger.FromContext(c.Request().Context()).Warn("rate limit exceeded", "ip", identifier, "route", c.Path())
+			c.Response().Header().Set(echo.HeaderRetryAfter, "1")
+			return c.JSON(http.StatusTooManyRequests, models.Err("too many requests"))
+		},
+	})
+}
 
-	employeeMW := RoleCheckerMW(models.Employee)
-	employeesGroup := a.Router.Group("", jwtMW, employeeMW)
-	employeesGroup.POST("/receptions", a.Handler.CreateReception)
-	employeesGroup.POST("/products", a.Handler.CreateProduct)
-	employeesGroup.POST("/pvz/:pvzId/close_last_reception", a.Handler.CloseLastReception)
-	employeesGroup.POST("/pvz/:pvzId/delete_last_product", a.Handler.DeleteLastProduct)
+// APIKeyMW authenticates "Authorization: ApiKey <key>" requests, other requests are left to JWTMW.
+func APIKeyMW(keys APIKeyAuthenticator) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			scheme, raw, _ := strings.Cut(c.Request().Header.Get(echo.HeaderAuthorization), " ")
+			if !strings.EqualFold(scheme, apiKeyScheme) {
+				return next(c)
+			}
 
-	moderEmploeeMW := RoleCheckerMW(models.Employee, models.Moderator)
-	a.Router.GET("/pvz", a.Handler.GetPVZ, jwtMW, moderEmploeeMW)
-}
+			ctx := c.Request().Context()
+			key, err := keys.AuthenticateAPIKey(ctx, strings.TrimSpace(raw))
+			switch {
+			case errors.Is(err, repository.ErrAPIKeyNotFound):
+				metrics.AuthFailures.WithLabelValues("invalid_api_key").Inc()
+				logger.FromContext(ctx).Warn("access denied, unknown or expired api key", "ip", c.RealIP())
+				return c.JSON(http.StatusForbidden, models.Err("access is denied"))
+			case errors.Is(err, services.ErrKeyRevoked):
+				metrics.AuthFailures.WithLabelValues("revoked_api_key").Inc()
+				logger.FromContext(ctx).Warn("access denied, api key creator lost its scopes", "ip", c.RealIP())
+				return c.JSON(http.StatusForbidden, models.Err("access is denied"))
+			case err != nil:
+				logger.FromContext(ctx).Error("authenticate api key", "error", err)
+				return c.JSON(http.StatusInternalServerError, models.Err("internal error"))
+			}
 
-func RoleCheckerMW(allowedRoles ...models.Role) echo.MiddlewareFunc {
-	rolesMap := map[string]struct{}{}
-	for _, val := range allowedRoles {
-		rolesMap[string(val)] = struct{}{}
+			ctx = logger.With(ctx, "api_key_id", key.ID)
+			c.SetRequest(c.Request().WithContext(ctx))
+			principal := &auth.Principal{APIKeyID: key.ID, Scopes: key.Scopes, KeyCreator: key.CreatedBy}
+			if key.ExpiresAt != nil {
+				principal.ExpiresAt = *key.ExpiresAt
+			}
+			c.Set(handlers.ContextPrincipal, principal)
+			return next(c)
+		}
 	}
+}
+
+// CurrentUserMW takes the user from the token. When users is set, the user must exist,
+// not be blocked and still have the role from the token. API key principals pass as is.
+func CurrentUserMW(users UserChecker) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
+			if _, ok := c.Get(handlers.ContextPrincipal).(*auth.Principal); ok {
+				return next(c)
+			}
 			token, ok := c.Get("user").(*jwt.Token)
 			if !ok {
 				return c.JSON(http.StatusForbidden, models.Err("access is denied"))
 			}
-			claims, ok := token.Claims.(jwt.MapClaims)
+			claims, ok := token.Claims.(*utils.Claims)
 			if !ok {
 				return c.JSON(http.StatusForbidden, models.Err("access is denied"))
 			}
 
-			role, _ := claims["role"].(string)
-			if _, ok := rolesMap[role]; !ok {
+			role := string(claims.Role)
+			userID := claims.Subject
+			ctx := logger.With(c.Request().Context(), "user_id", userID, "role", role)
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			if users != nil {
+				if _, err := uuid.Parse(userID); err != nil {
+					logger.FromContext(ctx).Warn("access denied, token without user id")
+					return c.JSON(http.StatusForbidden, models.Err("access is denied"))
+				}
+				user, err := users.GetUser(ctx, userID)
+				switch {
+				case errors.Is(err, repository.ErrUserNotFound):
+					logger.FromContext(ctx).Warn("access denied, user not found")
+					return c.JSON(http.StatusForbidden, models.Err("access is denied"))
+				case err != nil:
+					logger.FromContext(ctx).Error("check token owner", "error", err)
+					return c.JSON(http.StatusInternalServerError, models.Err("internal error"))
+				case user.Blocked:
+					logger.FromContext(ctx).Warn("access denied, user is blocked")
+					return c.JSON(http.StatusForbidden, models.Err("user is blocked"))
+				case string(user.Role) != role:
+					logger.FromContext(ctx).Warn("access denied, role changed")
+					return c.JSON(http.StatusForbidden, models.Err("token is outdated, log in again"))
+				}
+			}
+			c.Set(handlers.ContextUserID, userID)
+			principal := &auth.Principal{UserID: userID, Role: claims.Role}
+			if claims.ExpiresAt != nil {
+				principal.ExpiresAt = claims.ExpiresAt.Time
+			}
+			c.Set(handlers.ContextPrincipal, principal)
+			return next(c)
+		}
+	}
+}
+
+// BodyLimitMW answers 413 when the body is larger than limit. It must run before
+// anything reading the body, the idempotency middleware reads all of it.
+func BodyLimitMW(limit int64) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if req.ContentLength > limit {
+				return c.JSON(http.StatusRequestEntityTooLarge, models.Err("request body is too large"))
+			}
+			req.Body = http.MaxBytesReader(c.Response(), req.Body, limit)
+			return next(c)
+		}
+	}
+}
+
+// PermissionMW lets through principals with all the permissions. It runs after CurrentUserMW.
+func PermissionMW(roles *auth.Roles, perms ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, ok := c.Get(handlers.ContextPrincipal).(*auth.Principal)
+			if !ok {
 				return c.JSON(http.StatusForbidden, models.Err("access is denied"))
 			}
+			for _, perm := range perms {
+				if !principal.Can(roles, perm) {
+					logger.FromContext(c.Request().Context()).Warn("access denied", "permission", perm)
+					return c.JSON(http.StatusForbidden, models.Err("access is denied"))
+				}
+			}
 			return next(c)
 		}
 	}
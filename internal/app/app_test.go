@@ -0,0 +1,357 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pvz/internal/auth"
+	"pvz/internal/config"
+	"pvz/internal/handlers"
+	"pvz/internal/handlers/mocks"
+	"pvz/internal/models"
+	"pvz/internal/repository"
+	"pvz/internal/services"
+	"pvz/internal/validation"
+	"pvz/pkg/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthRateLimiterMW(t *testing.T) {
+	e := echo.New()
+	e.POST("/login", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, AuthRateLimiterMW(config.RateLimitCfg{RequestsPerSecond: 0.001, Burst: 2}))
+
+	send := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/login", nil)
+		req.Header.Set(echo.HeaderXRealIP, ip)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	require.Equal(t, http.StatusOK, send("10.0.0.1").Code)
+	require.Equal(t, http.StatusOK, send("10.0.0.1").Code)
+	rec := send("10.0.0.1")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.NotEmpty(t, rec.Header().Get(echo.HeaderRetryAfter))
+
+	require.Equal(t, http.StatusOK, send("10.0.0.2").Code)
+}
+
+func TestDummyLoginRoute(t *testing.T) {
+	cases := []struct {
+		env        string
+		registered bool
+	}{
+		{config.EnvProd, false},
+		{config.EnvDev, true},
+		{config.EnvTest, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.env, func(t *testing.T) {
+			e := echo.New()
+			a := &App{
+				Router:  e,
+				Handler: handlers.NewHandler(nil),
+				Keys:    auth.NewHMACKeySet("test", []byte("secret")),
+				Config:  config.Config{App: config.AppCfg{Env: tc.env}},
+			}
+			a.RegisterRoutes()
+
+			registered := false
+			for _, r := range e.Routes() {
+				if r.Method == http.MethodPost && r.Path == "/dummyLogin" {
+					registered = true
+				}
+			}
+			require.Equal(t, tc.registered, registered)
+		})
+	}
+}
+
+type fakeUsers map[string]models.User
+
+func (f fakeUsers) GetUser(_ context.Context, userID string) (models.User, error) {
+	if user, ok := f[userID]; ok {
+		return user, nil
+	}
+	return models.User{}, repository.ErrUserNotFound
+}
+
+func TestCurrentUserMW(t *testing.T) {
+	const (
+		activeID  = "00000000-0000-4000-8000-0000000000a1"
+		blockedID = "00000000-0000-4000-8000-0000000000b1"
+		movedID   = "00000000-0000-4000-8000-0000000000c1"
+		missingID = "00000000-0000-4000-8000-0000000000d1"
+	)
+	users := fakeUsers{
+		activeID:  {ID: activeID, Role: models.Moderator},
+		blockedID: {ID: blockedID, Role: models.Moderator, Blocked: true},
+		movedID:   {ID: movedID, Role: models.Employee},
+	}
+	expires := time.Now().Add(time.Hour).Truncate(time.Second)
+	handler := CurrentUserMW(users)(func(c echo.Context) error {
+		require.Equal(t, activeID, c.Get(handlers.ContextUserID))
+		require.Equal(t, &auth.Principal{UserID: activeID, Role: models.Moderator, ExpiresAt: expires}, c.Get(handlers.ContextPrincipal))
+		return c.NoContent(http.StatusOK)
+	})
+
+	cases := []struct {
+		name   string
+		userID string
+		role   models.Role
+		status int
+	}{
+		{"active moderator", activeID, models.Moderator, http.StatusOK},
+		{"token role differs", activeID, models.Employee, http.StatusForbidden},
+		{"blocked", blockedID, models.Moderator, http.StatusForbidden},
+		{"role changed", movedID, models.Moderator, http.StatusForbidden},
+		{"deleted", missingID, models.Moderator, http.StatusForbidden},
+		{"no user id", "", models.Moderator, http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			rec := httptest.NewRecorder()
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/users", nil), rec)
+			c.Set("user", jwt.NewWithClaims(jwt.SigningMethodHS256, &utils.Claims{
+				Role:             tc.role,
+				RegisteredClaims: jwt.RegisteredClaims{Subject: tc.userID, ExpiresAt: jwt.NewNumericDate(expires)},
+			}))
+
+			require.NoError(t, handler(c))
+			require.Equal(t, tc.status, rec.Code)
+		})
+	}
+}
+
+func TestPermissionMW(t *testing.T) {
+	roles, err := auth.NewRoles(map[models.Role][]string{
+		"auditor":    {models.PermPVZRead},
+		"shift_lead": {models.PermReceptionOpen, models.PermReceptionReopen},
+	})
+	require.NoError(t, err)
+	handler := PermissionMW(roles, models.PermReceptionReopen)(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	cases := []struct {
+		name      string
+		principal *auth.Principal
+		status    int
+	}{
+		{"shift lead", &auth.Principal{UserID: "u1", Role: "shift_lead"}, http.StatusOK},
+		{"auditor", &auth.Principal{UserID: "u1", Role: "auditor"}, http.StatusForbidden},
+		{"unknown role", &auth.Principal{UserID: "u1", Role: models.Moderator}, http.StatusForbidden},
+		{"api key with scope", &auth.Principal{APIKeyID: "k1", Scopes: []string{models.PermReceptionReopen}}, http.StatusOK},
+		// an api key has only its scopes, whatever the role
+		{"api key without scope", &auth.Principal{APIKeyID: "k1", Role: "shift_lead", Scopes: []string{models.PermPVZRead}}, http.StatusForbidden},
+		{"no principal", nil, http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/", nil), rec)
+			if tc.principal != nil {
+				c.Set(handlers.ContextPrincipal, tc.principal)
+			}
+			require.NoError(t, handler(c))
+			require.Equal(t, tc.status, rec.Code)
+		})
+	}
+}
+
+type fakeAPIKeys map[string]models.APIKey
+
+func (f fakeAPIKeys) AuthenticateAPIKey(_ context.Context, raw string) (models.APIKey, error) {
+	if raw == "pvz_revoked" {
+		return models.APIKey{}, services.ErrKeyRevoked
+	}
+	key, ok := f[raw]
+	if !ok {
+		return models.APIKey{}, repository.ErrAPIKeyNotFound
+	}
+	return key, nil
+}
+
+func TestAPIKeyMW(t *testing.T) {
+	keys := fakeAPIKeys{"pvz_good": {ID: "k1", Scopes: []string{models.PermPVZRead}}}
+	roles := auth.DefaultRoles()
+	e := echo.New()
+	e.GET("/pvz", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, APIKeyMW(keys), JWTMW(config.AuthCfg{}, auth.NewHMACKeySet("test", []byte("secret"))),
+		CurrentUserMW(fakeUsers{}), PermissionMW(roles, models.PermPVZRead))
+	e.POST("/pvz", func(c echo.Context) error {
+		return c.NoContent(http.StatusCreated)
+	}, APIKeyMW(keys), CurrentUserMW(fakeUsers{}), PermissionMW(roles, models.PermPVZCreate))
+
+	cases := []struct {
+		method, auth string
+		status       int
+	}{
+		{http.MethodGet, "ApiKey pvz_good", http.StatusOK},
+		{http.MethodGet, "apikey pvz_good", http.StatusOK},
+		{http.MethodGet, "ApiKey pvz_bad", http.StatusForbidden},
+		{http.MethodGet, "ApiKey pvz_revoked", http.StatusForbidden},
+		{http.MethodPost, "ApiKey pvz_good", http.StatusForbidden},
+		{http.MethodGet, "Bearer pvz_good", http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		t.Run(tc.method+" "+tc.auth, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/pvz", nil)
+			req.Header.Set(echo.HeaderAuthorization, tc.auth)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			require.Equal(t, tc.status, rec.Code)
+		})
+	}
+}
+
+func TestJWTMW(t *testing.T) {
+	cfg := config.AuthCfg{Issuer: "pvz", Audience: "pvz", TokenTTL: time.Hour}
+	keys := auth.NewHMACKeySet("test", []byte("test-secret"))
+	e := echo.New()
+	e.GET("/me", func(c echo.Context) error {
+		claims := c.Get("user").(*jwt.Token).Claims.(*utils.Claims)
+		return c.String(http.StatusOK, claims.Subject)
+	}, JWTMW(cfg, keys))
+
+	send := func(token models.Token) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+string(token))
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	valid, err := utils.GetJWToken(utils.TokenOptions{Signer: keys, Issuer: "pvz", Audience: "pvz", TTL: time.Hour}, models.Employee, "u1")
+	require.NoError(t, err)
+	rec := send(valid)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "u1", rec.Body.String())
+
+	otherKeys := auth.NewHMACKeySet("other", []byte("other-secret"))
+	cases := map[string]utils.TokenOptions{
+		"wrong issuer":   {Signer: keys, Issuer: "other", Audience: "pvz", TTL: time.Hour},
+		"wrong audience": {Signer: keys, Issuer: "pvz", Audience: "other", TTL: time.Hour},
+		"expired":        {Signer: keys, Issuer: "pvz", Audience: "pvz", TTL: -time.Minute},
+		"unknown key":    {Signer: otherKeys, Issuer: "pvz", Audience: "pvz", TTL: time.Hour},
+	}
+	for name, opts := range cases {
+		t.Run(name, func(t *testing.T) {
+			token, err := utils.GetJWToken(opts, models.Employee, "u1")
+			require.NoError(t, err)
+			require.Equal(t, http.StatusForbidden, send(token).Code)
+		})
+	}
+}
+
+func TestCredentialResponsesAreNotStored(t *testing.T) {
+	svc := new(mocks.PvzUserService)
+	store := newMemIdempotencyStore()
+	e := echo.New()
+	e.Validator = validation.NewValidator()
+	a := &App{
+		Router:      e,
+		Handler:     handlers.NewHandler(svc),
+		Idempotency: store,
+		APIKeys: fakeAPIKeys{"pvz_admin": {ID: "k0",
+			Scopes: []string{models.PermUserManage, models.PermWebhookManage, models.PermPVZRead}}},
+		Keys:   auth.NewHMACKeySet("test", []byte("secret")),
+		Roles:  auth.DefaultRoles(),
+		Config: config.Config{App: config.AppCfg{Env: config.EnvProd}},
+	}
+	a.RegisterRoutes()
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, "ApiKey pvz_admin")
+		req.Header.Set(HeaderIdempotencyKey, "k1")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	svc.EXPECT().CreateAPIKey(mock.Anything, mock.Anything, "wms", []string{models.PermPVZRead}, (*time.Time)(nil)).
+		Return(models.NewAPIKey{APIKey: models.APIKey{ID: "k1"}, Key: "pvz_secret"}, nil).Once()
+	rec := post("/api-keys", `{"name":"wms","scopes":["pvz:read"]}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Contains(t, rec.Body.String(), "pvz_secret")
+
+	svc.EXPECT().CreateWebhook(mock.Anything, "", "https://wms.example.com/hook", []string{models.EventProductAdded}, "").
+		Return(models.Webhook{ID: "h1", Secret: "whsec"}, nil).Once()
+	rec = post("/webhooks", `{"url":"https://wms.example.com/hook","events":["product.added"]}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Contains(t, rec.Body.String(), "whsec")
+
+	require.Empty(t, store.records)
+	svc.AssertExpectations(t)
+}
+
+func TestRateLimitIgnoresSpoofedForwardedFor(t *testing.T) {
+	e := echo.New()
+	extractor, err := NewIPExtractor(nil)
+	require.NoError(t, err)
+	e.IPExtractor = extractor
+	e.POST("/login", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, AuthRateLimiterMW(config.RateLimitCfg{RequestsPerSecond: 0.001, Burst: 2}))
+
+	send := func(forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodPost, "/login", nil)
+		req.RemoteAddr = "203.0.113.7:41000"
+		req.Header.Set(echo.HeaderXForwardedFor, forwardedFor)
+		req.Header.Set(echo.HeaderXRealIP, forwardedFor)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	require.Equal(t, http.StatusOK, send("10.0.0.1"))
+	require.Equal(t, http.StatusOK, send("10.0.0.2"))
+	require.Equal(t, http.StatusTooManyRequests, send("10.0.0.3"))
+}
+
+func TestIPExtractorTrustedProxy(t *testing.T) {
+	_, err := NewIPExtractor([]string{"10.0.0.0/33"})
+	require.Error(t, err)
+
+	extractor, err := NewIPExtractor([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+	ip := func(remote, forwardedFor string) string {
+		req := httptest.NewRequest(http.MethodPost, "/login", nil)
+		req.RemoteAddr = remote
+		req.Header.Set(echo.HeaderXForwardedFor, forwardedFor)
+		return extractor(req)
+	}
+	// the proxy appends the peer it saw, what the client sent before it is ignored
+	require.Equal(t, "203.0.113.7", ip("10.1.2.3:80", "198.51.100.1, 203.0.113.7"))
+	require.Equal(t, "203.0.113.9", ip("203.0.113.9:80", "198.51.100.1"))
+}
+
+func TestNewNotifier(t *testing.T) {
+	n, err := newNotifier(config.NotifierCfg{Kind: "none"}, config.EnvProd)
+	require.NoError(t, err)
+	require.Nil(t, n)
+
+	// reset tokens in the log would let anyone reading it take over accounts
+	_, err = newNotifier(config.NotifierCfg{Kind: "log"}, config.EnvProd)
+	require.Error(t, err)
+	n, err = newNotifier(config.NotifierCfg{Kind: "log"}, config.EnvDev)
+	require.NoError(t, err)
+	require.NotNil(t, n)
+
+	_, err = newNotifier(config.NotifierCfg{Kind: "sms"}, config.EnvDev)
+	require.Error(t, err)
+}
@@ -0,0 +1,198 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"pvz/internal/auth"
+	"pvz/internal/handlers"
+	"pvz/internal/logger"
+	"pvz/internal/models"
+	"pvz/pkg/utils"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	HeaderIdempotencyKey    = "Idempotency-Key"
+	HeaderIdempotentReplay  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength = 255
+)
+
+type IdempotencyStore interface {
+	ReserveIdempotencyKey(ctx context.Context, userID, key, requestHash string,
+		expireBefore time.Time) (models.IdempotencyRecord, bool, error)
+	SaveIdempotentResponse(ctx context.Context, userID, key string, rec models.IdempotencyRecord) error
+	ReleaseIdempotencyKey(ctx context.Context, userID, key string) error
+	PurgeIdempotencyKeys(ctx context.Context, expireBefore time.Time) (int64, error)
+}
+
+// replayedHeaders are repeated with a stored response: the ETag a client needs for
+// If-Match and the Location of a created resource.
+var replayedHeaders = []string{echo.HeaderLocation, "ETag"}
+
+// IdempotencyMW replays the stored response when a request is retried with the same
+// Idempotency-Key header. It must run after the jwt middleware, keys are scoped per user.
+func IdempotencyMW(store IdempotencyStore, ttl time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			key := req.Header.Get(HeaderIdempotencyKey)
+			if key == "" || req.Method == http.MethodGet || req.Method == http.MethodHead {
+				return next(c)
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				return c.JSON(http.StatusBadRequest, models.Err("idempotency key is too long"))
+			}
+
+			body, err := io.ReadAll(req.Body)
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return c.JSON(http.StatusRequestEntityTooLarge, models.Err("request body is too large"))
+			}
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, models.Err("failed to read request body"))
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			ctx := req.Context()
+			log := logger.FromContext(ctx).With("idempotency_key", key)
+			userID := idempotencyScope(c)
+			hash := requestHash(req.Method, req.URL.RequestURI(), body)
+
+			stored, reserved, err := store.ReserveIdempotencyKey(ctx, userID, key, hash, time.Now().UTC().Add(-ttl))
+			if err != nil {
+				log.Error("reserve idempotency key", "error", err)
+				return c.JSON(http.StatusInternalServerError, models.Err("internal error"))
+			}
+			if !reserved {
+				if stored.RequestHash != hash {
+					return c.JSON(http.StatusUnprocessableEntity,
+						models.Err("idempotency key was already used with a different request"))
+				}
+				if !stored.Completed {
+					return c.JSON(http.StatusConflict,
+						models.Err("request with this idempotency key is still in progress"))
+				}
+				log.Info("replaying idempotent response")
+				for name, value := range stored.Headers {
+					c.Response().Header().Set(name, value)
+				}
+				c.Response().Header().Set(HeaderIdempotentReplay, "true")
+				return c.Blob(stored.StatusCode, stored.ContentType, stored.Body)
+			}
+
+			// server errors are not final, the key is released so the client may retry
+			release := func() {
+				if err := store.ReleaseIdempotencyKey(context.WithoutCancel(ctx), userID, key); err != nil {
+					log.Error("release idempotency key", "error", err)
+				}
+			}
+			capture := &captureWriter{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = capture
+			handlerErr := func() error {
+				// a panic is recovered further out, the key must not stay reserved until the ttl
+				defer func() {
+					if r := recover(); r != nil {
+						release()
+						panic(r)
+					}
+				}()
+				return next(c)
+			}()
+
+			status := c.Response().Status
+			if handlerErr != nil || status >= http.StatusInternalServerError {
+				release()
+				return handlerErr
+			}
+
+			rec := models.IdempotencyRecord{
+				RequestHash: hash,
+				StatusCode:  status,
+				ContentType: c.Response().Header().Get(echo.HeaderContentType),
+				Body:        capture.body.Bytes(),
+				Completed:   true,
+			}
+			for _, name := range replayedHeaders {
+				if value := c.Response().Header().Get(name); value != "" {
+					if rec.Headers == nil {
+						rec.Headers = make(map[string]string)
+					}
+					rec.Headers[name] = value
+				}
+			}
+			if err := store.SaveIdempotentResponse(context.WithoutCancel(ctx), userID, key, rec); err != nil {
+				log.Error("save idempotent response", "error", err)
+			}
+			return nil
+		}
+	}
+}
+
+// PurgeIdempotencyKeys deletes expired keys every interval until ctx is canceled. Expired
+// keys are already ignored on reserve, this only keeps the table small.
+func PurgeIdempotencyKeys(ctx context.Context, store IdempotencyStore, ttl, interval time.Duration) {
+	log := logger.FromContext(ctx)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		n, err := store.PurgeIdempotencyKeys(ctx, time.Now().UTC().Add(-ttl))
+		if err != nil && ctx.Err() == nil {
+			log.Error("purge idempotency keys", "error", err)
+		}
+		if n > 0 {
+			log.Info("purged idempotency keys", "count", n)
+		}
+	}
+}
+
+// idempotencyScope returns the user id from the token. Tokens without one share a scope per role.
+func idempotencyScope(c echo.Context) string {
+	if p, ok := c.Get(handlers.ContextPrincipal).(*auth.Principal); ok && p.APIKeyID != "" {
+		return "apikey:" + p.APIKeyID
+	}
+	token, ok := c.Get("user").(*jwt.Token)
+	if !ok {
+		return ""
+	}
+	claims, ok := token.Claims.(*utils.Claims)
+	if !ok {
+		return ""
+	}
+	if claims.Subject != "" {
+		return claims.Subject
+	}
+	return "role:" + string(claims.Role)
+}
+
+// requestHash covers the query too, POST /pvz/import?dryRun=true is not the same request
+// as without it.
+func requestHash(method, uri string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + uri + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type captureWriter struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
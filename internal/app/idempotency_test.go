@@ -0,0 +1,250 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"pvz/internal/models"
+	"pvz/pkg/utils"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/require"
+)
+
+type memIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]models.IdempotencyRecord
+}
+
+func newMemIdempotencyStore() *memIdempotencyStore {
+	return &memIdempotencyStore{records: map[string]models.IdempotencyRecord{}}
+}
+
+func (s *memIdempotencyStore) ReserveIdempotencyKey(_ context.Context, userID, key, hash string,
+	_ time.Time) (models.IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec, ok := s.records[userID+"/"+key]; ok {
+		return rec, false, nil
+	}
+	s.records[userID+"/"+key] = models.IdempotencyRecord{RequestHash: hash}
+	return models.IdempotencyRecord{RequestHash: hash}, true, nil
+}
+
+func (s *memIdempotencyStore) SaveIdempotentResponse(_ context.Context, userID, key string, rec models.IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[userID+"/"+key] = rec
+	return nil
+}
+
+func (s *memIdempotencyStore) ReleaseIdempotencyKey(_ context.Context, userID, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, userID+"/"+key)
+	return nil
+}
+
+func (s *memIdempotencyStore) PurgeIdempotencyKeys(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func newIdempotentRouter(store IdempotencyStore, status *int, calls *int) *echo.Echo {
+	e := echo.New()
+	setUser := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user", &jwt.Token{Claims: &utils.Claims{
+				Role:             models.Employee,
+				RegisteredClaims: jwt.RegisteredClaims{Subject: c.Request().Header.Get("X-User")},
+			}})
+			return next(c)
+		}
+	}
+	e.POST("/receptions", func(c echo.Context) error {
+		*calls++
+		return c.JSON(*status, map[string]int{"call": *calls})
+	}, setUser, IdempotencyMW(store, time.Hour))
+	return e
+}
+
+func doPost(e *echo.Echo, user, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/receptions", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("X-User", user)
+	if key != "" {
+		req.Header.Set(HeaderIdempotencyKey, key)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	status, calls := http.StatusCreated, 0
+	e := newIdempotentRouter(newMemIdempotencyStore(), &status, &calls)
+
+	first := doPost(e, "u1", "k1", `{"pvzId":"1"}`)
+	require.Equal(t, http.StatusCreated, first.Code)
+
+	second := doPost(e, "u1", "k1", `{"pvzId":"1"}`)
+	require.Equal(t, http.StatusCreated, second.Code)
+	require.Equal(t, "true", second.Header().Get(HeaderIdempotentReplay))
+	require.Equal(t, first.Body.String(), second.Body.String())
+	require.Equal(t, 1, calls)
+
+	other := doPost(e, "u2", "k1", `{"pvzId":"1"}`)
+	require.Equal(t, http.StatusCreated, other.Code)
+	require.Empty(t, other.Header().Get(HeaderIdempotentReplay))
+	require.Equal(t, 2, calls)
+}
+
+func TestIdempotencyDifferentBody(t *testing.T) {
+	status, calls := http.StatusCreated, 0
+	e := newIdempotentRouter(newMemIdempotencyStore(), &status, &calls)
+
+	require.Equal(t, http.StatusCreated, doPost(e, "u1", "k1", `{"pvzId":"1"}`).Code)
+	require.Equal(t, http.StatusUnprocessableEntity, doPost(e, "u1", "k1", `{"pvzId":"2"}`).Code)
+	require.Equal(t, 1, calls)
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	store := newMemIdempotencyStore()
+	hash := requestHash(http.MethodPost, "/receptions", []byte(`{}`))
+	_, _, _ = store.ReserveIdempotencyKey(context.Background(), "u1", "k1", hash, time.Time{})
+
+	status, calls := http.StatusCreated, 0
+	e := newIdempotentRouter(store, &status, &calls)
+	require.Equal(t, http.StatusConflict, doPost(e, "u1", "k1", `{}`).Code)
+	require.Zero(t, calls)
+}
+
+func TestIdempotencyServerErrorReleasesKey(t *testing.T) {
+	status, calls := http.StatusInternalServerError, 0
+	e := newIdempotentRouter(newMemIdempotencyStore(), &status, &calls)
+
+	require.Equal(t, http.StatusInternalServerError, doPost(e, "u1", "k1", `{}`).Code)
+	status = http.StatusCreated
+	rec := doPost(e, "u1", "k1", `{}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Empty(t, rec.Header().Get(HeaderIdempotentReplay))
+	require.Equal(t, 2, calls)
+}
+
+func TestIdempotencyWithoutKey(t *testing.T) {
+	status, calls := http.StatusCreated, 0
+	e := newIdempotentRouter(newMemIdempotencyStore(), &status, &calls)
+
+	doPost(e, "u1", "", `{}`)
+	doPost(e, "u1", "", `{}`)
+	require.Equal(t, 2, calls)
+}
+
+func TestIdempotencyBodyLimit(t *testing.T) {
+	store := newMemIdempotencyStore()
+	calls := 0
+	e := echo.New()
+	e.POST("/pvz/import", func(c echo.Context) error {
+		calls++
+		return c.NoContent(http.StatusCreated)
+	}, BodyLimitMW(16), IdempotencyMW(store, time.Hour))
+
+	send := func(body string, chunked bool) int {
+		req := httptest.NewRequest(http.MethodPost, "/pvz/import", strings.NewReader(body))
+		if chunked {
+			// the size is only found out while reading
+			req.ContentLength = -1
+		}
+		req.Header.Set(HeaderIdempotencyKey, "k1")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	large := strings.Repeat("x", 17)
+	require.Equal(t, http.StatusRequestEntityTooLarge, send(large, false))
+	require.Equal(t, http.StatusRequestEntityTooLarge, send(large, true))
+	require.Zero(t, calls)
+	require.Empty(t, store.records)
+
+	require.Equal(t, http.StatusCreated, send("city,address", true))
+	require.Equal(t, 1, calls)
+}
+
+func TestIdempotencyQueryIsPartOfRequest(t *testing.T) {
+	store := newMemIdempotencyStore()
+	calls := 0
+	e := echo.New()
+	e.POST("/pvz/import", func(c echo.Context) error {
+		calls++
+		return c.NoContent(http.StatusCreated)
+	}, IdempotencyMW(store, time.Hour))
+
+	send := func(target string) int {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader("city,address"))
+		req.Header.Set(HeaderIdempotencyKey, "k1")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	require.Equal(t, http.StatusCreated, send("/pvz/import?dryRun=true"))
+	require.Equal(t, http.StatusUnprocessableEntity, send("/pvz/import"))
+	require.Equal(t, 1, calls)
+}
+
+func TestIdempotencyReplaysHeaders(t *testing.T) {
+	calls := 0
+	e := echo.New()
+	e.POST("/receptions", func(c echo.Context) error {
+		calls++
+		c.Response().Header().Set("ETag", `"3"`)
+		c.Response().Header().Set(echo.HeaderLocation, "/receptions/1")
+		c.Response().Header().Set("X-Other", "1")
+		return c.NoContent(http.StatusCreated)
+	}, IdempotencyMW(newMemIdempotencyStore(), time.Hour))
+
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/receptions", strings.NewReader(`{}`))
+		req.Header.Set(HeaderIdempotencyKey, "k1")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	send()
+	replay := send()
+	require.Equal(t, 1, calls)
+	require.Equal(t, "true", replay.Header().Get(HeaderIdempotentReplay))
+	require.Equal(t, `"3"`, replay.Header().Get("ETag"))
+	require.Equal(t, "/receptions/1", replay.Header().Get(echo.HeaderLocation))
+	require.Empty(t, replay.Header().Get("X-Other"))
+}
+
+func TestIdempotencyPanicReleasesKey(t *testing.T) {
+	store := newMemIdempotencyStore()
+	calls := 0
+	e := echo.New()
+	e.Use(middleware.Recover())
+	e.POST("/receptions", func(c echo.Context) error {
+		calls++
+		if calls == 1 {
+			panic("boom")
+		}
+		return c.NoContent(http.StatusCreated)
+	}, IdempotencyMW(store, time.Hour))
+
+	send := func() int {
+		req := httptest.NewRequest(http.MethodPost, "/receptions", strings.NewReader(`{}`))
+		req.Header.Set(HeaderIdempotencyKey, "k1")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	require.Equal(t, http.StatusInternalServerError, send())
+	require.Empty(t, store.records)
+	require.Equal(t, http.StatusCreated, send())
+	require.Equal(t, 2, calls)
+}
@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"maps"
+	"math/big"
+	"os"
+	"pvz/internal/config"
+	"slices"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+	AlgHS256 = "HS256"
+)
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+type Key struct {
+	ID     string
+	Method jwt.SigningMethod
+	// signing material, nil for verify-only keys
+	private crypto.PrivateKey
+	// verification material: *rsa.PublicKey, ed25519.PublicKey or []byte for HS256
+	public any
+}
+
+// KeySet signs tokens with one key and verifies them with any of the configured keys,
+// so a new key can be introduced while tokens signed with the previous one are still valid.
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+}
+
+// NewHMACKeySet is the legacy setup: a single HS256 shared secret.
+func NewHMACKeySet(id string, secret []byte) *KeySet {
+	key := &Key{ID: id, Method: jwt.SigningMethodHS256, private: secret, public: secret}
+	return &KeySet{signing: key, keys: map[string]*Key{id: key}}
+}
+
+// LoadKeySet reads the keys from PEM files. Without configured keys it falls back
+// to HS256 with the given secret.
+func LoadKeySet(cfg config.AuthCfg, secret []byte) (*KeySet, error) {
+	if len(cfg.Keys) == 0 {
+		return NewHMACKeySet("default", secret), nil
+	}
+
+	ks := &KeySet{keys: map[string]*Key{}}
+	for _, kc := range cfg.Keys {
+		if kc.ID == "" {
+			return nil, errors.New("jwt key without id")
+		}
+		if _, ok := ks.keys[kc.ID]; ok {
+			return nil, fmt.Errorf("duplicate jwt key id %q", kc.ID)
+		}
+		key, err := loadKey(kc, secret)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %q: %w", kc.ID, err)
+		}
+		ks.keys[kc.ID] = key
+	}
+
+	signing, ok := ks.keys[cfg.SigningKeyID]
+	if !ok {
+		return nil, fmt.Errorf("signing key %q is not among the configured keys", cfg.SigningKeyID)
+	}
+	if signing.private == nil {
+		return nil, fmt.Errorf("signing key %q has no private key", cfg.SigningKeyID)
+	}
+	ks.signing = signing
+	return ks, nil
+}
+
+func loadKey(kc config.JWTKeyCfg, secret []byte) (*Key, error) {
+	key := &Key{ID: kc.ID}
+	switch kc.Algorithm {
+	case AlgHS256:
+		key.Method = jwt.SigningMethodHS256
+		key.private, key.public = secret, secret
+		return key, nil
+	case AlgRS256:
+		key.Method = jwt.SigningMethodRS256
+	case AlgEdDSA:
+		key.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q, expected RS256, EdDSA or HS256", kc.Algorithm)
+	}
+
+	if kc.PrivateKeyFile != "" {
+		pem, err := os.ReadFile(kc.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if key.Method == jwt.SigningMethodRS256 {
+			private, err := jwt.ParseRSAPrivateKeyFromPEM(pem)
+			if err != nil {
+				return nil, err
+			}
+			key.private, key.public = private, &private.PublicKey
+		} else {
+			private, err := jwt.ParseEdPrivateKeyFromPEM(pem)
+			if err != nil {
+				return nil, err
+			}
+			key.private, key.public = private, private.(ed25519.PrivateKey).Public()
+		}
+		return key, nil
+	}
+
+	if kc.PublicKeyFile == "" {
+		return nil, errors.New("private_key_file or public_key_file is required")
+	}
+	pem, err := os.ReadFile(kc.PublicKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if key.Method == jwt.SigningMethodRS256 {
+		key.public, err = jwt.ParseRSAPublicKeyFromPEM(pem)
+	} else {
+		key.public, err = jwt.ParseEdPublicKeyFromPEM(pem)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Sign signs the claims with the signing key and sets the kid header.
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signing.Method, claims)
+	token.Header["kid"] = ks.signing.ID
+	return token.SignedString(ks.signing.private)
+}
+
+// Keyfunc picks the verification key by the kid header. The token algorithm must match the key.
+func (ks *KeySet) Keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("key %q expects %s, token is signed with %s", kid, key.Method.Alg(), token.Method.Alg())
+	}
+	return key.public, nil
+}
+
+// Methods lists the algorithms of the configured keys.
+func (ks *KeySet) Methods() []string {
+	var methods []string
+	for _, id := range slices.Sorted(maps.Keys(ks.keys)) {
+		if alg := ks.keys[id].Method.Alg(); !slices.Contains(methods, alg) {
+			methods = append(methods, alg)
+		}
+	}
+	return methods
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// OKP and EC
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys. HS256 secrets are never published.
+func (ks *KeySet) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	for _, id := range slices.Sorted(maps.Keys(ks.keys)) {
+		key := ks.keys[id]
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "RSA", Kid: key.ID, Alg: key.Method.Alg(), Use: "sig",
+				N: base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "OKP", Kid: key.ID, Alg: key.Method.Alg(), Use: "sig",
+				Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	return set
+}
@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+
+	"pvz/internal/config"
+)
+
+func writePEM(t *testing.T, dir, name, typ string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600))
+	return path
+}
+
+func genRSA(t *testing.T, dir, name string) (private, public string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	pub, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	return writePEM(t, dir, name+".pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key)),
+		writePEM(t, dir, name+".pub.pem", "PUBLIC KEY", pub)
+}
+
+func genEd25519(t *testing.T, dir, name string) (private, public string) {
+	t.Helper()
+	pubKey, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	pub, err := x509.MarshalPKIXPublicKey(pubKey)
+	require.NoError(t, err)
+	return writePEM(t, dir, name+".pem", "PRIVATE KEY", der),
+		writePEM(t, dir, name+".pub.pem", "PUBLIC KEY", pub)
+}
+
+func claims() jwt.Claims {
+	return jwt.RegisteredClaims{Subject: "u1", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))}
+}
+
+func verify(ks *KeySet, token string) error {
+	_, err := jwt.NewParser(jwt.WithValidMethods(ks.Methods())).ParseWithClaims(token, &jwt.RegisteredClaims{}, ks.Keyfunc)
+	return err
+}
+
+func TestKeySetSignVerify(t *testing.T) {
+	dir := t.TempDir()
+	rsaPriv, _ := genRSA(t, dir, "rsa")
+	edPriv, _ := genEd25519(t, dir, "ed")
+
+	for _, kc := range []config.JWTKeyCfg{
+		{ID: "rsa", Algorithm: AlgRS256, PrivateKeyFile: rsaPriv},
+		{ID: "ed", Algorithm: AlgEdDSA, PrivateKeyFile: edPriv},
+		{ID: "hs", Algorithm: AlgHS256},
+	} {
+		t.Run(kc.Algorithm, func(t *testing.T) {
+			ks, err := LoadKeySet(config.AuthCfg{SigningKeyID: kc.ID, Keys: []config.JWTKeyCfg{kc}}, []byte("secret"))
+			require.NoError(t, err)
+
+			token, err := ks.Sign(claims())
+			require.NoError(t, err)
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &jwt.RegisteredClaims{})
+			require.NoError(t, err)
+			require.Equal(t, kc.ID, parsed.Header["kid"])
+			require.Equal(t, kc.Algorithm, parsed.Method.Alg())
+			require.NoError(t, verify(ks, token))
+		})
+	}
+}
+
+func TestKeySetRotation(t *testing.T) {
+	dir := t.TempDir()
+	oldPriv, oldPub := genRSA(t, dir, "old")
+	newPriv, _ := genEd25519(t, dir, "new")
+
+	before, err := LoadKeySet(config.AuthCfg{
+		SigningKeyID: "old",
+		Keys:         []config.JWTKeyCfg{{ID: "old", Algorithm: AlgRS256, PrivateKeyFile: oldPriv}},
+	}, nil)
+	require.NoError(t, err)
+	oldToken, err := before.Sign(claims())
+	require.NoError(t, err)
+
+	// the old key stays as verify-only until its tokens expire
+	after, err := LoadKeySet(config.AuthCfg{
+		SigningKeyID: "new",
+		Keys: []config.JWTKeyCfg{
+			{ID: "old", Algorithm: AlgRS256, PublicKeyFile: oldPub},
+			{ID: "new", Algorithm: AlgEdDSA, PrivateKeyFile: newPriv},
+		},
+	}, nil)
+	require.NoError(t, err)
+	require.NoError(t, verify(after, oldToken))
+
+	newToken, err := after.Sign(claims())
+	require.NoError(t, err)
+	require.NoError(t, verify(after, newToken))
+	require.Error(t, verify(before, newToken))
+
+	_, err = LoadKeySet(config.AuthCfg{
+		SigningKeyID: "old",
+		Keys:         []config.JWTKeyCfg{{ID: "old", Algorithm: AlgRS256, PublicKeyFile: oldPub}},
+	}, nil)
+	require.ErrorContains(t, err, "has no private key")
+}
+
+func TestKeySetRejects(t *testing.T) {
+	ks := NewHMACKeySet("a", []byte("secret"))
+
+	other, err := NewHMACKeySet("b", []byte("secret")).Sign(claims())
+	require.NoError(t, err)
+	require.ErrorIs(t, verify(ks, other), ErrUnknownKey)
+
+	// same kid, different algorithm
+	dir := t.TempDir()
+	rsaPriv, _ := genRSA(t, dir, "a")
+	rsaKeys, err := LoadKeySet(config.AuthCfg{
+		SigningKeyID: "a",
+		Keys:         []config.JWTKeyCfg{{ID: "a", Algorithm: AlgRS256, PrivateKeyFile: rsaPriv}},
+	}, nil)
+	require.NoError(t, err)
+	token, err := rsaKeys.Sign(claims())
+	require.NoError(t, err)
+	require.Error(t, verify(ks, token))
+
+	_, err = LoadKeySet(config.AuthCfg{
+		SigningKeyID: "a",
+		Keys:         []config.JWTKeyCfg{{ID: "a", Algorithm: "none"}},
+	}, nil)
+	require.Error(t, err)
+}
+
+func TestKeySetJWKS(t *testing.T) {
+	dir := t.TempDir()
+	rsaPriv, _ := genRSA(t, dir, "rsa")
+	_, edPub := genEd25519(t, dir, "ed")
+
+	ks, err := LoadKeySet(config.AuthCfg{
+		SigningKeyID: "rsa",
+		Keys: []config.JWTKeyCfg{
+			{ID: "rsa", Algorithm: AlgRS256, PrivateKeyFile: rsaPriv},
+			{ID: "ed", Algorithm: AlgEdDSA, PublicKeyFile: edPub},
+			{ID: "hs", Algorithm: AlgHS256},
+		},
+	}, []byte("secret"))
+	require.NoError(t, err)
+
+	set := ks.JWKS()
+	require.Len(t, set.Keys, 2)
+	require.Equal(t, "ed", set.Keys[0].Kid)
+	require.Equal(t, "OKP", set.Keys[0].Kty)
+	require.Equal(t, "Ed25519", set.Keys[0].Crv)
+	require.NotEmpty(t, set.Keys[0].X)
+	require.Equal(t, "rsa", set.Keys[1].Kid)
+	require.Equal(t, "RSA", set.Keys[1].Kty)
+	require.Equal(t, "AQAB", set.Keys[1].E)
+	require.NotEmpty(t, set.Keys[1].N)
+
+	require.Empty(t, NewHMACKeySet("default", []byte("secret")).JWKS().Keys)
+}
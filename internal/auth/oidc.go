@@ -0,0 +1,334 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"pvz/internal/config"
+	"pvz/internal/logger"
+	"pvz/internal/models"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid identity token")
+	ErrNoRole       = errors.New("identity has no role in the service")
+	// ErrProviderUnavailable means the keys couldn't be fetched, the token itself may be fine
+	ErrProviderUnavailable = errors.New("identity provider unavailable")
+)
+
+// minRefreshInterval limits how often a token with an unknown kid triggers a JWKS fetch.
+const minRefreshInterval = time.Minute
+
+// OIDCVerifier checks tokens of an external OpenID Connect provider. The provider keys are
+// found through discovery and cached, an unknown kid refreshes them to follow key rotation.
+type OIDCVerifier struct {
+	cfg    config.OIDCCfg
+	roles  *Roles
+	client *http.Client
+	parser *jwt.Parser
+	now    func() time.Time
+
+	mu          sync.Mutex
+	jwksURI     string
+	keys        map[string]any
+	fetchedAt   time.Time
+	lastAttempt time.Time
+	// inflight is closed when the running fetch ends, nil when there is none
+	inflight chan struct{}
+	fetchErr error
+}
+
+func NewOIDCVerifier(cfg config.OIDCCfg, roles *Roles, client *http.Client) (*OIDCVerifier, error) {
+	for value, role := range cfg.RoleMap {
+		if !roles.Exists(role) {
+			return nil, fmt.Errorf("oidc role_map %q: unknown role %q", value, role)
+		}
+	}
+	if cfg.DefaultRole != "" && !roles.Exists(cfg.DefaultRole) {
+		return nil, fmt.Errorf("oidc default_role: unknown role %q", cfg.DefaultRole)
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &OIDCVerifier{
+		cfg:    cfg,
+		roles:  roles,
+		client: client,
+		parser: jwt.NewParser(
+			jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "ES256", "ES384", AlgEdDSA}),
+			jwt.WithIssuer(cfg.Issuer),
+			jwt.WithAudience(cfg.Audience),
+			jwt.WithExpirationRequired(),
+			jwt.WithLeeway(30*time.Second),
+		),
+		now: time.Now,
+	}, nil
+}
+
+// Verify checks the signature and the standard claims and maps the token to an identity.
+func (v *OIDCVerifier) Verify(ctx context.Context, raw string) (models.ExternalIdentity, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(raw, claims, func(token *jwt.Token) (any, error) {
+		return v.key(ctx, token)
+	})
+	if errors.Is(err, ErrProviderUnavailable) {
+		return models.ExternalIdentity{}, err
+	}
+	if err != nil {
+		return models.ExternalIdentity{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	sub, _ := claims["sub"].(string)
+	email, _ := claims["email"].(string)
+	if sub == "" || email == "" {
+		return models.ExternalIdentity{}, fmt.Errorf("%w: sub and email claims are required", ErrInvalidToken)
+	}
+	verified, _ := claims["email_verified"].(bool)
+
+	role, err := v.role(claims)
+	if err != nil {
+		return models.ExternalIdentity{}, err
+	}
+	return models.ExternalIdentity{
+		Issuer:        v.cfg.Issuer,
+		Subject:       sub,
+		Email:         strings.ToLower(email),
+		EmailVerified: verified,
+		Role:          role,
+	}, nil
+}
+
+func (v *OIDCVerifier) role(claims jwt.MapClaims) (models.Role, error) {
+	var value any = map[string]any(claims)
+	for _, part := range strings.Split(v.cfg.RoleClaim, ".") {
+		obj, ok := value.(map[string]any)
+		if !ok {
+			value = nil
+			break
+		}
+		value = obj[part]
+	}
+
+	var values []string
+	switch val := value.(type) {
+	case string:
+		values = []string{val}
+	case []any:
+		for _, item := range val {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	// several matching values pick the role with the most permissions
+	var role models.Role
+	for _, val := range values {
+		mapped, ok := v.cfg.RoleMap[val]
+		if ok && (role == "" || len(v.roles.Permissions(mapped)) > len(v.roles.Permissions(role))) {
+			role = mapped
+		}
+	}
+	if role == "" {
+		role = v.cfg.DefaultRole
+	}
+	if role == "" {
+		return "", ErrNoRole
+	}
+	return role, nil
+}
+
+// key finds the signing key of the token. The provider is fetched outside of the lock, tokens
+// with known keys are verified meanwhile and tokens needing the fetch wait for it.
+func (v *OIDCVerifier) key(ctx context.Context, token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	v.mu.Lock()
+	now := v.now()
+	key, ok := v.lookup(kid)
+	stale := now.Sub(v.fetchedAt) > v.cfg.JWKSCacheTTL
+	wait := v.inflight
+	var fetchErr error
+	switch {
+	case wait == nil && (!ok || stale) && now.Sub(v.lastAttempt) >= minRefreshInterval:
+		v.lastAttempt = now
+		done := make(chan struct{})
+		v.inflight = done
+		jwksURI := v.jwksURI
+		v.mu.Unlock()
+
+		// a client going away mustn't fail the fetch for the tokens waiting on it
+		jwksURI, keys, err := v.fetch(context.WithoutCancel(ctx), jwksURI)
+
+		v.mu.Lock()
+		if err == nil {
+			v.jwksURI, v.keys, v.fetchedAt = jwksURI, keys, now
+			key, ok = v.lookup(kid)
+		}
+		v.fetchErr, fetchErr = err, err
+		v.inflight = nil
+		close(done)
+		v.mu.Unlock()
+	case wait != nil && !ok:
+		v.mu.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		v.mu.Lock()
+		key, ok = v.lookup(kid)
+		fetchErr = v.fetchErr
+		v.mu.Unlock()
+	default:
+		// within minRefreshInterval of a failed fetch an unknown key is still the provider's fault
+		if !ok {
+			fetchErr = v.fetchErr
+		}
+		v.mu.Unlock()
+	}
+
+	if fetchErr != nil {
+		if !ok {
+			return nil, fetchErr
+		}
+		logger.FromContext(ctx).Warn("refresh oidc keys, using cached keys", "error", fetchErr)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+	return key, nil
+}
+
+// lookup finds the key by kid. A token without kid is accepted when the provider has a single key.
+func (v *OIDCVerifier) lookup(kid string) (any, bool) {
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, true
+		}
+	}
+	key, ok := v.keys[kid]
+	return key, ok
+}
+
+// fetch runs discovery unless jwksURI is known yet and downloads the keys.
+func (v *OIDCVerifier) fetch(ctx context.Context, jwksURI string) (string, map[string]any, error) {
+	if jwksURI == "" {
+		var doc struct {
+			Issuer  string `json:"issuer"`
+			JWKSURI string `json:"jwks_uri"`
+		}
+		url := strings.TrimSuffix(v.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+		if err := v.getJSON(ctx, url, &doc); err != nil {
+			return "", nil, fmt.Errorf("%w: oidc discovery: %w", ErrProviderUnavailable, err)
+		}
+		if doc.Issuer != v.cfg.Issuer {
+			return "", nil, fmt.Errorf("%w: oidc discovery: issuer %q doesn't match %q", ErrProviderUnavailable, doc.Issuer, v.cfg.Issuer)
+		}
+		if doc.JWKSURI == "" {
+			return "", nil, fmt.Errorf("%w: oidc discovery: no jwks_uri", ErrProviderUnavailable)
+		}
+		jwksURI = doc.JWKSURI
+	}
+
+	var set JWKS
+	if err := v.getJSON(ctx, jwksURI, &set); err != nil {
+		return "", nil, fmt.Errorf("%w: oidc jwks: %w", ErrProviderUnavailable, err)
+	}
+	keys := make(map[string]any, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := parseJWK(jwk)
+		if err != nil {
+			logger.FromContext(ctx).Warn("skip oidc key", "kid", jwk.Kid, "error", err)
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	return jwksURI, keys, nil
+}
+
+func (v *OIDCVerifier) getJSON(ctx context.Context, url string, dst any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(dst)
+}
+
+func parseJWK(jwk JWK) (any, error) {
+	decode := base64.RawURLEncoding.DecodeString
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decode(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := decode(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if _, err := key.ECDH(); err != nil {
+			return nil, err
+		}
+		return key, nil
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := decode(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+	}
+}
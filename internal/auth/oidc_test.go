@@ -0,0 +1,250 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+
+	"pvz/internal/config"
+	"pvz/internal/models"
+)
+
+// stubIssuer is a minimal OIDC provider: discovery, JWKS and token signing.
+type stubIssuer struct {
+	*httptest.Server
+	keys      map[string]*rsa.PrivateKey
+	jwksCalls atomic.Int32
+	// block holds JWKS responses until it is closed
+	block chan struct{}
+}
+
+func newStubIssuer(t *testing.T) *stubIssuer {
+	t.Helper()
+	s := &stubIssuer{keys: map[string]*rsa.PrivateKey{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"issuer": s.URL, "jwks_uri": s.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		s.jwksCalls.Add(1)
+		if s.block != nil {
+			<-s.block
+		}
+		set := JWKS{Keys: []JWK{}}
+		for kid, key := range s.keys {
+			set.Keys = append(set.Keys, JWK{
+				Kty: "RSA", Kid: kid, Alg: AlgRS256, Use: "sig",
+				N: base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		json.NewEncoder(w).Encode(set)
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	s.addKey(t, "k1")
+	return s
+}
+
+func (s *stubIssuer) addKey(t *testing.T, kid string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	s.keys[kid] = key
+}
+
+func (s *stubIssuer) sign(t *testing.T, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	base := jwt.MapClaims{
+		"iss":            s.URL,
+		"aud":            "pvz-client",
+		"sub":            "ext-1",
+		"email":          "Worker@Corp.example",
+		"email_verified": true,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"realm_access":   map[string]any{"roles": []string{"staff", "pvz-moderators"}},
+	}
+	for k, v := range claims {
+		base[k] = v
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, base)
+	token.Header["kid"] = kid
+	raw, err := token.SignedString(s.keys[kid])
+	require.NoError(t, err)
+	return raw
+}
+
+func newVerifier(t *testing.T, s *stubIssuer, modify ...func(*config.OIDCCfg)) *OIDCVerifier {
+	t.Helper()
+	cfg := config.OIDCCfg{
+		Enabled:      true,
+		Issuer:       s.URL,
+		Audience:     "pvz-client",
+		RoleClaim:    "realm_access.roles",
+		RoleMap:      map[string]models.Role{"pvz-employees": models.Employee, "pvz-moderators": models.Moderator},
+		JWKSCacheTTL: time.Hour,
+	}
+	for _, m := range modify {
+		m(&cfg)
+	}
+	v, err := NewOIDCVerifier(cfg, DefaultRoles(), s.Client())
+	require.NoError(t, err)
+	return v
+}
+
+func TestOIDCVerify(t *testing.T) {
+	s := newStubIssuer(t)
+	v := newVerifier(t, s)
+
+	ident, err := v.Verify(context.Background(), s.sign(t, "k1", nil))
+	require.NoError(t, err)
+	require.Equal(t, models.ExternalIdentity{
+		Issuer:        s.URL,
+		Subject:       "ext-1",
+		Email:         "worker@corp.example",
+		EmailVerified: true,
+		Role:          models.Moderator,
+	}, ident)
+
+	// keys are cached
+	_, err = v.Verify(context.Background(), s.sign(t, "k1", jwt.MapClaims{"sub": "ext-2"}))
+	require.NoError(t, err)
+	require.Equal(t, int32(1), s.jwksCalls.Load())
+}
+
+func TestOIDCVerifyRejects(t *testing.T) {
+	s := newStubIssuer(t)
+	v := newVerifier(t, s)
+
+	cases := map[string]jwt.MapClaims{
+		"wrong issuer":   {"iss": "https://other.example"},
+		"wrong audience": {"aud": "other-client"},
+		"expired":        {"exp": time.Now().Add(-time.Hour).Unix()},
+		"no email":       {"email": nil},
+	}
+	for name, claims := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := v.Verify(context.Background(), s.sign(t, "k1", claims))
+			require.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+
+	// same kid, signed by another provider's key
+	other := newStubIssuer(t)
+	forged := other.sign(t, "k1", jwt.MapClaims{"iss": s.URL})
+	_, err := v.Verify(context.Background(), forged)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = v.Verify(context.Background(), s.sign(t, "k1", jwt.MapClaims{"realm_access": map[string]any{"roles": []string{"staff"}}}))
+	require.ErrorIs(t, err, ErrNoRole)
+}
+
+func TestOIDCRoleMapping(t *testing.T) {
+	s := newStubIssuer(t)
+	v := newVerifier(t, s, func(cfg *config.OIDCCfg) {
+		cfg.RoleClaim = "pvz_role"
+		cfg.DefaultRole = models.Employee
+	})
+
+	ident, err := v.Verify(context.Background(), s.sign(t, "k1", jwt.MapClaims{"pvz_role": "pvz-moderators"}))
+	require.NoError(t, err)
+	require.Equal(t, models.Moderator, ident.Role)
+
+	ident, err = v.Verify(context.Background(), s.sign(t, "k1", nil))
+	require.NoError(t, err)
+	require.Equal(t, models.Employee, ident.Role)
+
+	_, err = NewOIDCVerifier(config.OIDCCfg{RoleMap: map[string]models.Role{"x": "admin"}}, DefaultRoles(), nil)
+	require.Error(t, err)
+}
+
+func TestOIDCKeyRotation(t *testing.T) {
+	s := newStubIssuer(t)
+	v := newVerifier(t, s)
+	now := time.Now()
+	v.now = func() time.Time { return now }
+
+	_, err := v.Verify(context.Background(), s.sign(t, "k1", nil))
+	require.NoError(t, err)
+
+	// an unknown kid refreshes the keys, but not more often than minRefreshInterval
+	s.addKey(t, "k2")
+	_, err = v.Verify(context.Background(), s.sign(t, "k2", nil))
+	require.ErrorIs(t, err, ErrInvalidToken)
+	require.Equal(t, int32(1), s.jwksCalls.Load())
+
+	now = now.Add(minRefreshInterval)
+	_, err = v.Verify(context.Background(), s.sign(t, "k2", nil))
+	require.NoError(t, err)
+	require.Equal(t, int32(2), s.jwksCalls.Load())
+
+	// cached keys stay in use when the provider is down
+	now = now.Add(2 * time.Hour)
+	s.Close()
+	_, err = v.Verify(context.Background(), s.sign(t, "k1", nil))
+	require.NoError(t, err)
+}
+
+func TestOIDCDiscoveryIssuerMismatch(t *testing.T) {
+	s := newStubIssuer(t)
+	v := newVerifier(t, s, func(cfg *config.OIDCCfg) { cfg.Issuer = s.URL + "/" })
+
+	_, err := v.Verify(context.Background(), s.sign(t, "k1", jwt.MapClaims{"iss": s.URL + "/"}))
+	require.ErrorIs(t, err, ErrProviderUnavailable)
+	require.NotErrorIs(t, err, ErrInvalidToken)
+	require.ErrorContains(t, err, "doesn't match")
+}
+
+func TestOIDCProviderDown(t *testing.T) {
+	s := newStubIssuer(t)
+	v := newVerifier(t, s)
+	token := s.sign(t, "k1", nil)
+	s.Close()
+
+	_, err := v.Verify(context.Background(), token)
+	require.ErrorIs(t, err, ErrProviderUnavailable)
+	require.NotErrorIs(t, err, ErrInvalidToken)
+
+	// the next token is not refetched yet, it fails the same way rather than as an unknown key
+	_, err = v.Verify(context.Background(), token)
+	require.ErrorIs(t, err, ErrProviderUnavailable)
+	require.NotErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestOIDCRefreshDoesNotBlock(t *testing.T) {
+	s := newStubIssuer(t)
+	v := newVerifier(t, s)
+	now := time.Now()
+	v.now = func() time.Time { return now }
+	_, err := v.Verify(context.Background(), s.sign(t, "k1", nil))
+	require.NoError(t, err)
+
+	// the cache goes stale, the next token starts a refresh that hangs on the provider
+	now = now.Add(2 * time.Hour)
+	s.block = make(chan struct{})
+	refreshed := make(chan error)
+	go func() {
+		_, err := v.Verify(context.Background(), s.sign(t, "k1", nil))
+		refreshed <- err
+	}()
+	require.Eventually(t, func() bool { return s.jwksCalls.Load() == 2 }, time.Second, time.Millisecond)
+
+	// meanwhile tokens with cached keys are verified without waiting
+	_, err = v.Verify(context.Background(), s.sign(t, "k1", jwt.MapClaims{"sub": "ext-2"}))
+	require.NoError(t, err)
+
+	close(s.block)
+	require.NoError(t, <-refreshed)
+}
@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"pvz/internal/models"
+	"slices"
+	"time"
+)
+
+// Principal is the caller of a request: a user or an API key.
+type Principal struct {
+	UserID string
+	Role   models.Role
+	// APIKeyID is set for API keys, their permissions are the Scopes instead of a role
+	APIKeyID string
+	Scopes   []string
+	// KeyCreator is the user an API key acts for, keys it creates are theirs too
+	KeyCreator string
+	// ExpiresAt is when the token or the key expires, zero when it doesn't
+	ExpiresAt time.Time
+}
+
+// Can reports whether the principal has the permission.
+func (p *Principal) Can(roles *Roles, perm string) bool {
+	if p.APIKeyID != "" {
+		return slices.Contains(p.Scopes, perm)
+	}
+	return roles.Has(p.Role, perm)
+}
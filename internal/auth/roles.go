@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"fmt"
+	"maps"
+	"pvz/internal/models"
+	"slices"
+)
+
+// Roles maps role names to permission sets.
+type Roles struct {
+	perms map[models.Role][]string
+}
+
+// NewRoles checks that every role only uses known permissions. Without definitions
+// the default employee and moderator roles are used.
+func NewRoles(defs map[models.Role][]string) (*Roles, error) {
+	if len(defs) == 0 {
+		defs = models.DefaultRolePermissions
+	}
+	r := &Roles{perms: make(map[models.Role][]string, len(defs))}
+	for role, perms := range defs {
+		if role == "" {
+			return nil, fmt.Errorf("role without name")
+		}
+		for _, perm := range perms {
+			if !slices.Contains(models.Permissions, perm) {
+				return nil, fmt.Errorf("role %q: unknown permission %q", role, perm)
+			}
+		}
+		r.perms[role] = slices.Compact(slices.Sorted(slices.Values(perms)))
+	}
+	return r, nil
+}
+
+// DefaultRoles returns the built-in employee and moderator roles.
+func DefaultRoles() *Roles {
+	r, _ := NewRoles(nil)
+	return r
+}
+
+func (r *Roles) Exists(role models.Role) bool {
+	_, ok := r.perms[role]
+	return ok
+}
+
+func (r *Roles) Has(role models.Role, perm string) bool {
+	return slices.Contains(r.perms[role], perm)
+}
+
+// Permissions returns the sorted permissions of the role, nil for an unknown role.
+func (r *Roles) Permissions(role models.Role) []string {
+	return slices.Clone(r.perms[role])
+}
+
+// Names returns the sorted role names.
+func (r *Roles) Names() []models.Role {
+	return slices.Sorted(maps.Keys(r.perms))
+}
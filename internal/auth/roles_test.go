@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"pvz/internal/models"
+)
+
+func TestRoles(t *testing.T) {
+	roles, err := NewRoles(map[models.Role][]string{
+		"auditor":        {models.PermPVZRead},
+		models.Moderator: {models.PermUserManage, models.PermPVZRead, models.PermUserManage},
+	})
+	require.NoError(t, err)
+
+	require.True(t, roles.Exists("auditor"))
+	require.False(t, roles.Exists(models.Employee))
+	require.True(t, roles.Has("auditor", models.PermPVZRead))
+	require.False(t, roles.Has("auditor", models.PermPVZCreate))
+	require.False(t, roles.Has("unknown", models.PermPVZRead))
+	require.Equal(t, []string{models.PermPVZRead, models.PermUserManage}, roles.Permissions(models.Moderator))
+	require.Equal(t, []models.Role{"auditor", models.Moderator}, roles.Names())
+
+	_, err = NewRoles(map[models.Role][]string{"auditor": {"pvz:raed"}})
+	require.ErrorContains(t, err, "unknown permission")
+
+	defaults := DefaultRoles()
+	require.Equal(t, []models.Role{models.Employee, models.Moderator}, defaults.Names())
+	require.True(t, defaults.Has(models.Employee, models.PermReceptionOpen))
+	require.False(t, defaults.Has(models.Employee, models.PermUserManage))
+}
@@ -2,13 +2,27 @@ package config
 
 import (
 	"fmt"
+	"pvz/internal/models"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
 type Config struct {
-	DB  DataBaseCfg `yaml:"database"`
-	App AppCfg      `yaml:"server"`
+	DB          DataBaseCfg    `yaml:"database"`
+	App         AppCfg         `yaml:"server"`
+	Logger      LoggerCfg      `yaml:"logger"`
+	Tracing     TracingCfg     `yaml:"tracing"`
+	Idempotency IdempotencyCfg `yaml:"idempotency"`
+	RateLimit   RateLimitCfg   `yaml:"rate_limit"`
+	Password    PasswordCfg    `yaml:"password"`
+	Notifier    NotifierCfg    `yaml:"notifier"`
+	Auth        AuthCfg        `yaml:"auth"`
+	Webhooks    WebhooksCfg    `yaml:"webhooks"`
+	Outbox      OutboxCfg      `yaml:"outbox"`
+	EventStream EventStreamCfg `yaml:"event_stream"`
+	// Roles maps role names to permissions, the built-in employee and moderator are used when empty
+	Roles map[models.Role][]string `yaml:"roles"`
 }
 
 type DataBaseCfg struct {
@@ -17,11 +31,154 @@ type DataBaseCfg struct {
 	User     string `yaml:"user" env:"DB_USER" env-default:"postgres"`
 	Password string `yaml:"password" env:"DB_PASSWORD" env-default:"123456"`
 	DBName   string `yaml:"dbname" env:"DB_NAME" env-default:"postgres"`
+	// AutoMigrate applies pending migrations on startup
+	AutoMigrate bool `yaml:"auto_migrate" env:"DB_AUTO_MIGRATE" env-default:"false"`
 }
 
+const (
+	EnvDev  = "dev"
+	EnvTest = "test"
+	EnvProd = "prod"
+)
+
 type AppCfg struct {
 	Port    string `yaml:"port"`
 	Address string `yaml:"address"`
+	// Env is one of dev, test, prod. Development helpers like /dummyLogin are disabled in prod
+	Env string `yaml:"env" env:"APP_ENV" env-default:"prod"`
+	// TrustedProxies are the CIDRs of reverse proxies whose X-Forwarded-For is believed,
+	// without them the client ip is the peer address and forwarding headers are ignored
+	TrustedProxies []string `yaml:"trusted_proxies" env:"TRUSTED_PROXIES" env-separator:","`
+}
+
+type LoggerCfg struct {
+	Level string `yaml:"level" env:"LOG_LEVEL" env-default:"info"`
+}
+
+type TracingCfg struct {
+	Enabled     bool    `yaml:"enabled" env:"TRACING_ENABLED" env-default:"false"`
+	ServiceName string  `yaml:"service_name" env-default:"pvz"`
+	Endpoint    string  `yaml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	Insecure    bool    `yaml:"insecure" env-default:"true"`
+	SampleRatio float64 `yaml:"sample_ratio" env-default:"1"`
+}
+
+type IdempotencyCfg struct {
+	// TTL is how long a stored response is replayed for a repeated Idempotency-Key
+	TTL time.Duration `yaml:"ttl" env:"IDEMPOTENCY_TTL" env-default:"24h"`
+	// PurgeInterval is how often keys older than the TTL are deleted
+	PurgeInterval time.Duration `yaml:"purge_interval" env:"IDEMPOTENCY_PURGE_INTERVAL" env-default:"1h"`
+}
+
+type RateLimitCfg struct {
+	// RequestsPerSecond and Burst limit /login and /register per client ip
+	RequestsPerSecond   float64       `yaml:"requests_per_second" env:"RATE_LIMIT_RPS" env-default:"5"`
+	Burst               int           `yaml:"burst" env:"RATE_LIMIT_BURST" env-default:"10"`
+	MaxFailuresPerIP    int           `yaml:"max_failures_per_ip" env:"LOGIN_MAX_FAILURES_PER_IP" env-default:"20"`
+	MaxFailuresPerEmail int           `yaml:"max_failures_per_email" env:"LOGIN_MAX_FAILURES_PER_EMAIL" env-default:"5"`
+	Window              time.Duration `yaml:"window" env:"LOGIN_FAILURE_WINDOW" env-default:"15m"`
+	Lockout             time.Duration `yaml:"lockout" env:"LOGIN_LOCKOUT" env-default:"15m"`
+}
+
+type AuthCfg struct {
+	// Issuer and Audience are set in issued tokens and required in incoming ones
+	Issuer   string        `yaml:"issuer" env:"JWT_ISSUER" env-default:"pvz"`
+	Audience string        `yaml:"audience" env:"JWT_AUDIENCE" env-default:"pvz"`
+	TokenTTL time.Duration `yaml:"token_ttl" env:"JWT_TOKEN_TTL" env-default:"24h"`
+	// SigningKeyID selects the key new tokens are signed with, the other keys only verify.
+	// Without keys tokens are signed with HS256 and SECRET_KEY
+	SigningKeyID string      `yaml:"signing_key_id" env:"JWT_SIGNING_KEY_ID"`
+	Keys         []JWTKeyCfg `yaml:"keys"`
+	OIDC         OIDCCfg     `yaml:"oidc"`
+}
+
+// OIDCCfg enables POST /login/oidc, which exchanges a token of an external identity
+// provider for a token of the service.
+type OIDCCfg struct {
+	Enabled bool `yaml:"enabled" env:"OIDC_ENABLED" env-default:"false"`
+	// Issuer is the provider url, the discovery document is read from Issuer/.well-known/openid-configuration
+	Issuer string `yaml:"issuer" env:"OIDC_ISSUER"`
+	// Audience is the client id for ID tokens or the api audience for access tokens
+	Audience string `yaml:"audience" env:"OIDC_AUDIENCE"`
+	// RoleClaim is the claim the role is read from, nested claims are separated by dots (realm_access.roles).
+	// The claim can be a string or a list of strings
+	RoleClaim string `yaml:"role_claim" env:"OIDC_ROLE_CLAIM" env-default:"roles"`
+	// RoleMap maps claim values to roles. When several values match, the role with the most permissions wins
+	RoleMap map[string]models.Role `yaml:"role_map"`
+	// DefaultRole is used when no claim value matches, empty rejects the login
+	DefaultRole models.Role `yaml:"default_role" env:"OIDC_DEFAULT_ROLE"`
+	// JWKSCacheTTL is how long the provider keys are used before they are fetched again
+	JWKSCacheTTL time.Duration `yaml:"jwks_cache_ttl" env:"OIDC_JWKS_CACHE_TTL" env-default:"1h"`
+}
+
+type JWTKeyCfg struct {
+	ID string `yaml:"id"`
+	// Algorithm is RS256, EdDSA or HS256 (SECRET_KEY)
+	Algorithm      string `yaml:"algorithm"`
+	PrivateKeyFile string `yaml:"private_key_file"`
+	PublicKeyFile  string `yaml:"public_key_file"`
+}
+
+type PasswordCfg struct {
+	MinLength     int  `yaml:"min_length" env:"PASSWORD_MIN_LENGTH" env-default:"8"`
+	RequireUpper  bool `yaml:"require_upper" env:"PASSWORD_REQUIRE_UPPER" env-default:"true"`
+	RequireLower  bool `yaml:"require_lower" env:"PASSWORD_REQUIRE_LOWER" env-default:"true"`
+	RequireDigit  bool `yaml:"require_digit" env:"PASSWORD_REQUIRE_DIGIT" env-default:"true"`
+	RequireSymbol bool `yaml:"require_symbol" env:"PASSWORD_REQUIRE_SYMBOL" env-default:"false"`
+	RejectCommon  bool `yaml:"reject_common" env:"PASSWORD_REJECT_COMMON" env-default:"true"`
+	// ResetTTL is how long a password reset token stays valid
+	ResetTTL time.Duration `yaml:"reset_ttl" env:"PASSWORD_RESET_TTL" env-default:"30m"`
+}
+
+type NotifierCfg struct {
+	// Kind is "none", "log" (dev and test only) or "file"; without a notifier password reset answers 501
+	Kind     string `yaml:"kind" env:"NOTIFIER_KIND" env-default:"none"`
+	FilePath string `yaml:"file_path" env:"NOTIFIER_FILE_PATH" env-default:"notifications.jsonl"`
+}
+
+type WebhooksCfg struct {
+	PollInterval time.Duration `yaml:"poll_interval" env:"WEBHOOKS_POLL_INTERVAL" env-default:"1s"`
+	BatchSize    int           `yaml:"batch_size" env:"WEBHOOKS_BATCH_SIZE" env-default:"20"`
+	// MaxAttempts is how many times a delivery is sent before it is marked failed
+	MaxAttempts int           `yaml:"max_attempts" env:"WEBHOOKS_MAX_ATTEMPTS" env-default:"8"`
+	BaseBackoff time.Duration `yaml:"base_backoff" env:"WEBHOOKS_BASE_BACKOFF" env-default:"10s"`
+	MaxBackoff  time.Duration `yaml:"max_backoff" env:"WEBHOOKS_MAX_BACKOFF" env-default:"1h"`
+	// Timeout limits one delivery request
+	Timeout time.Duration `yaml:"timeout" env:"WEBHOOKS_TIMEOUT" env-default:"10s"`
+	// AllowPrivate lets webhooks target loopback and private addresses, only for local setups
+	AllowPrivate bool `yaml:"allow_private" env:"WEBHOOKS_ALLOW_PRIVATE" env-default:"false"`
+}
+
+// OutboxCfg configures the relay that publishes stored domain events.
+// Webhooks always receive them, Publisher adds another destination.
+type OutboxCfg struct {
+	PollInterval time.Duration `yaml:"poll_interval" env:"OUTBOX_POLL_INTERVAL" env-default:"1s"`
+	BatchSize    int           `yaml:"batch_size" env:"OUTBOX_BATCH_SIZE" env-default:"100"`
+	// Publisher is "none", "stdout", "file" or "nats"
+	Publisher string  `yaml:"publisher" env:"OUTBOX_PUBLISHER" env-default:"none"`
+	FilePath  string  `yaml:"file_path" env:"OUTBOX_FILE_PATH" env-default:"events.jsonl"`
+	NATS      NATSCfg `yaml:"nats"`
+}
+
+// EventStreamCfg configures GET /pvz/:pvzId/events, the live feed reads new events from the outbox.
+type EventStreamCfg struct {
+	PollInterval time.Duration `yaml:"poll_interval" env:"EVENT_STREAM_POLL_INTERVAL" env-default:"1s"`
+	// Heartbeat is the longest silence on an open stream
+	Heartbeat time.Duration `yaml:"heartbeat" env:"EVENT_STREAM_HEARTBEAT" env-default:"15s"`
+	// AccessCheck is how often an open stream checks that the caller may still watch the pvz
+	AccessCheck time.Duration `yaml:"access_check" env:"EVENT_STREAM_ACCESS_CHECK" env-default:"30s"`
+}
+
+// NATSCfg configures the broker publisher, events are protobuf messages (api/proto/events.proto)
+// on subjects <subject_prefix>.<event type>.
+type NATSCfg struct {
+	URL           string `yaml:"url" env:"NATS_URL" env-default:"nats://localhost:4222"`
+	SubjectPrefix string `yaml:"subject_prefix" env:"NATS_SUBJECT_PREFIX" env-default:"pvz.events"`
+	// JetStream waits for the stream to store each event, core NATS only for the server to receive it
+	JetStream bool `yaml:"jetstream" env:"NATS_JETSTREAM" env-default:"true"`
+	// Stream is created on startup for all event subjects, empty when it is managed elsewhere
+	Stream  string        `yaml:"stream" env:"NATS_STREAM" env-default:"PVZ_EVENTS"`
+	Timeout time.Duration `yaml:"timeout" env:"NATS_TIMEOUT" env-default:"5s"`
 }
 
 func LoadConfig(configPath string) (*Config, error) {
@@ -32,9 +189,22 @@ func LoadConfig(configPath string) (*Config, error) {
 	if err := cleanenv.ReadEnv(&cfg); err != nil {
 		return nil, err
 	}
+	switch cfg.App.Env {
+	case EnvDev, EnvTest, EnvProd:
+	default:
+		return nil, fmt.Errorf("unknown env %q, expected one of: dev, test, prod", cfg.App.Env)
+	}
+	if cfg.Auth.OIDC.Enabled && (cfg.Auth.OIDC.Issuer == "" || cfg.Auth.OIDC.Audience == "") {
+		return nil, fmt.Errorf("oidc: issuer and audience are required")
+	}
 	return &cfg, nil
 }
 
+// DummyLoginEnabled reports whether /dummyLogin is registered.
+func (c *Config) DummyLoginEnabled() bool {
+	return c.App.Env == EnvDev || c.App.Env == EnvTest
+}
+
 func (c *Config) GetPort() string {
 	return c.App.Port
 }
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"text/tabwriter"
+
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+//go:embed migrations/*.sql
+var embedMigrations embed.FS
+
+const (
+	MigrateUp     = "up"
+	MigrateDown   = "down"
+	MigrateStatus = "status"
+)
+
+func newMigrator(db *sql.DB) (*goose.Provider, error) {
+	migrations, err := fs.Sub(embedMigrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	// the session lock keeps several instances with auto-migrate from applying the same version
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, err
+	}
+	return goose.NewProvider(goose.DialectPostgres, db, migrations,
+		goose.WithSessionLocker(locker),
+		goose.WithDisableGlobalRegistry(true),
+	)
+}
+
+// Migrate runs one of the migrate subcommands (up, down, status) and writes
+// a human readable report to w.
+func Migrate(ctx context.Context, db *sql.DB, command string, w io.Writer) error {
+	migrator, err := newMigrator(db)
+	if err != nil {
+		return fmt.Errorf("failed to init migrator: %w", err)
+	}
+
+	switch command {
+	case MigrateUp:
+		results, err := migrator.Up(ctx)
+		for _, res := range results {
+			fmt.Fprintf(w, "OK   %s (%s)\n", res.Source.Path, res.Duration)
+		}
+		if err != nil {
+			return fmt.Errorf("migrate up: %w", err)
+		}
+		if len(results) == 0 {
+			fmt.Fprintln(w, "no migrations to apply")
+		}
+	case MigrateDown:
+		res, err := migrator.Down(ctx)
+		if err != nil {
+			return fmt.Errorf("migrate down: %w", err)
+		}
+		fmt.Fprintf(w, "OK   %s (%s)\n", res.Source.Path, res.Duration)
+	case MigrateStatus:
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return fmt.Errorf("migrate status: %w", err)
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tSTATE\tAPPLIED AT\tFILE")
+		for _, st := range statuses {
+			appliedAt := "-"
+			if !st.AppliedAt.IsZero() {
+				appliedAt = st.AppliedAt.UTC().Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", st.Source.Version, st.State, appliedAt, st.Source.Path)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected one of: up, down, status", command)
+	}
+	return nil
+}
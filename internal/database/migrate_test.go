@@ -0,0 +1,48 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrationsAreSequential(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	migrator, err := newMigrator(db)
+	require.NoError(t, err)
+
+	sources := migrator.ListSources()
+	require.NotEmpty(t, sources)
+	for i, src := range sources {
+		require.Equal(t, int64(i+1), src.Version, src.Path)
+	}
+}
+
+func TestMigrationsHaveDown(t *testing.T) {
+	files, err := fs.Glob(embedMigrations, "migrations/*.sql")
+	require.NoError(t, err)
+	for _, name := range files {
+		body, err := fs.ReadFile(embedMigrations, name)
+		require.NoError(t, err)
+		require.True(t, strings.Contains(string(body), "-- +goose Up"), name)
+		require.True(t, strings.Contains(string(body), "-- +goose Down"), name)
+	}
+}
+
+func TestMigrateUnknownCommand(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	var out bytes.Buffer
+	err = Migrate(context.Background(), db, "sideways", &out)
+	require.ErrorContains(t, err, "unknown migrate command")
+}
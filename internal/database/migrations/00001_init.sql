@@ -1,3 +1,4 @@
+-- +goose Up
 CREATE TABLE IF NOT EXISTS users (
     id TEXT PRIMARY KEY,
     email TEXT NOT NULL UNIQUE,
@@ -29,4 +30,11 @@ CREATE TABLE IF NOT EXISTS products (
 );
 
 CREATE INDEX IF NOT EXISTS idx_receptions_pvz_date
-    ON receptions(pvz_id, create_date);
\ No newline at end of file
+    ON receptions(pvz_id, create_date);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_receptions_pvz_date;
+DROP TABLE IF EXISTS products;
+DROP TABLE IF EXISTS receptions;
+DROP TABLE IF EXISTS pvz;
+DROP TABLE IF EXISTS users;
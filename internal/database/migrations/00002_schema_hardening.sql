@@ -0,0 +1,68 @@
+-- +goose Up
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_reception_id_fkey;
+ALTER TABLE receptions DROP CONSTRAINT IF EXISTS receptions_pvz_id_fkey;
+
+ALTER TABLE users ALTER COLUMN id TYPE uuid USING id::uuid;
+ALTER TABLE pvz ALTER COLUMN id TYPE uuid USING id::uuid;
+ALTER TABLE receptions
+    ALTER COLUMN id TYPE uuid USING id::uuid,
+    ALTER COLUMN pvz_id TYPE uuid USING pvz_id::uuid;
+ALTER TABLE products
+    ALTER COLUMN id TYPE uuid USING id::uuid,
+    ALTER COLUMN reception_id TYPE uuid USING reception_id::uuid;
+
+ALTER TABLE receptions ADD CONSTRAINT receptions_pvz_id_fkey
+    FOREIGN KEY (pvz_id) REFERENCES pvz(id) ON DELETE CASCADE;
+ALTER TABLE products ADD CONSTRAINT products_reception_id_fkey
+    FOREIGN KEY (reception_id) REFERENCES receptions(id) ON DELETE CASCADE;
+
+ALTER TABLE users ADD CONSTRAINT users_role_check
+    CHECK (role IN ('employee', 'moderator'));
+ALTER TABLE pvz ADD CONSTRAINT pvz_city_check
+    CHECK (city IN ('Москва', 'Санкт-Петербург', 'Казань'));
+ALTER TABLE receptions ADD CONSTRAINT receptions_status_check
+    CHECK (status IN ('in_progress', 'close'));
+ALTER TABLE products ADD CONSTRAINT products_type_check
+    CHECK (type IN ('электроника', 'одежда', 'обувь'));
+
+-- concurrent openings may have left several receptions of a pvz in progress,
+-- all but the newest are closed so the index below can be built
+UPDATE receptions r SET status = 'close'
+WHERE r.status = 'in_progress' AND EXISTS (
+    SELECT 1 FROM receptions newer
+    WHERE newer.pvz_id = r.pvz_id AND newer.status = 'in_progress'
+      AND (newer.create_date, newer.id) > (r.create_date, r.id)
+);
+
+-- only one reception per pvz can be in progress
+CREATE UNIQUE INDEX IF NOT EXISTS receptions_one_in_progress_idx
+    ON receptions(pvz_id) WHERE status = 'in_progress';
+
+CREATE INDEX IF NOT EXISTS idx_products_reception_date
+    ON products(reception_id, create_date);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_products_reception_date;
+DROP INDEX IF EXISTS receptions_one_in_progress_idx;
+
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_type_check;
+ALTER TABLE receptions DROP CONSTRAINT IF EXISTS receptions_status_check;
+ALTER TABLE pvz DROP CONSTRAINT IF EXISTS pvz_city_check;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_reception_id_fkey;
+ALTER TABLE receptions DROP CONSTRAINT IF EXISTS receptions_pvz_id_fkey;
+
+ALTER TABLE products
+    ALTER COLUMN id TYPE TEXT,
+    ALTER COLUMN reception_id TYPE TEXT;
+ALTER TABLE receptions
+    ALTER COLUMN id TYPE TEXT,
+    ALTER COLUMN pvz_id TYPE TEXT;
+ALTER TABLE pvz ALTER COLUMN id TYPE TEXT;
+ALTER TABLE users ALTER COLUMN id TYPE TEXT;
+
+ALTER TABLE receptions ADD CONSTRAINT receptions_pvz_id_fkey
+    FOREIGN KEY (pvz_id) REFERENCES pvz(id) ON DELETE CASCADE;
+ALTER TABLE products ADD CONSTRAINT products_reception_id_fkey
+    FOREIGN KEY (reception_id) REFERENCES receptions(id) ON DELETE CASCADE;
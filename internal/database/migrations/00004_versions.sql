@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE pvz ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE receptions ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+-- +goose Down
+ALTER TABLE receptions DROP COLUMN IF EXISTS version;
+ALTER TABLE pvz DROP COLUMN IF EXISTS version;
@@ -0,0 +1,10 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN blocked BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN create_date TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS idx_users_create_date ON users(create_date);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_users_create_date;
+ALTER TABLE users DROP COLUMN IF EXISTS create_date;
+ALTER TABLE users DROP COLUMN IF EXISTS blocked;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- tokens are issued at login and expire, they are no longer stored
+ALTER TABLE users DROP COLUMN IF EXISTS token;
+
+CREATE TABLE IF NOT EXISTS user_pvz (
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    pvz_id uuid NOT NULL REFERENCES pvz(id) ON DELETE CASCADE,
+    create_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (user_id, pvz_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_pvz_pvz_id ON user_pvz(pvz_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS user_pvz;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS token TEXT;
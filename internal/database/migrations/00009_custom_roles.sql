@@ -0,0 +1,8 @@
+-- +goose Up
+-- roles are defined in the config, the database no longer limits them
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+
+-- +goose Down
+-- NOT VALID keeps users with custom roles, new rows are checked
+ALTER TABLE users ADD CONSTRAINT users_role_check
+    CHECK (role IN ('employee', 'moderator')) NOT VALID;
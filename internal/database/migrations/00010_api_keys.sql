@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS api_keys (
+    id uuid PRIMARY KEY,
+    name TEXT NOT NULL,
+    -- prefix is the start of the key, shown to tell keys apart
+    prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    created_by uuid REFERENCES users(id) ON DELETE SET NULL,
+    create_date TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- events are written here in the transaction of the change they describe,
+-- the relay publishes unsent rows in seq order
+CREATE TABLE IF NOT EXISTS outbox (
+    seq BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    id uuid NOT NULL UNIQUE,
+    event_type TEXT NOT NULL,
+    pvz_id uuid NOT NULL,
+    payload JSONB NOT NULL,
+    create_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    sent_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_unsent ON outbox(seq) WHERE sent_at IS NULL;
+
+-- the relay may publish an event twice, a webhook gets one delivery per event anyway
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_event ON webhook_deliveries(webhook_id, event_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_webhook_deliveries_event;
+DROP TABLE IF EXISTS outbox;
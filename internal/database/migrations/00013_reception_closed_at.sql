@@ -0,0 +1,16 @@
+-- +goose Up
+-- set when a reception is closed and cleared when it is reopened
+ALTER TABLE receptions ADD COLUMN IF NOT EXISTS closed_at TIMESTAMP WITH TIME ZONE;
+
+-- receptions closed before the column existed get the time of their last reception.closed event,
+-- its payload is the reception or, since the events carry products, {"reception": ..., "products": ...}
+UPDATE receptions r SET closed_at = e.closed_at
+FROM (
+    SELECT COALESCE(payload->'reception'->>'id', payload->>'id')::uuid AS id, MAX(create_date) AS closed_at
+    FROM outbox WHERE event_type = 'reception.closed'
+    GROUP BY 1
+) e
+WHERE r.id = e.id AND r.status = 'close';
+
+-- +goose Down
+ALTER TABLE receptions DROP COLUMN IF EXISTS closed_at;
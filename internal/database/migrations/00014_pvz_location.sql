@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE pvz
+    ADD COLUMN IF NOT EXISTS address TEXT,
+    ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION;
+
+-- an import run twice must not create the same pvz again
+CREATE UNIQUE INDEX IF NOT EXISTS pvz_city_address_idx ON pvz(city, address) WHERE address IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS pvz_city_address_idx;
+ALTER TABLE pvz
+    DROP COLUMN IF EXISTS longitude,
+    DROP COLUMN IF EXISTS latitude,
+    DROP COLUMN IF EXISTS address;
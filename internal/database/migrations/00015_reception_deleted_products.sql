@@ -0,0 +1,15 @@
+-- +goose Up
+-- counts products deleted from a reception, for the share of receptions with corrections
+ALTER TABLE receptions ADD COLUMN IF NOT EXISTS deleted_products INT NOT NULL DEFAULT 0;
+
+-- deletions made before the column existed are known from product.deleted events
+UPDATE receptions r SET deleted_products = e.deleted
+FROM (
+    SELECT (payload->>'receptionId')::uuid AS id, COUNT(*) AS deleted
+    FROM outbox WHERE event_type = 'product.deleted'
+    GROUP BY 1
+) e
+WHERE r.id = e.id;
+
+-- +goose Down
+ALTER TABLE receptions DROP COLUMN IF EXISTS deleted_products;
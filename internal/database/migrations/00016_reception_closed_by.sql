@@ -0,0 +1,11 @@
+-- +goose Up
+-- the user who closed the reception, NULL for receptions closed with an API key or before the column existed
+ALTER TABLE receptions ADD COLUMN IF NOT EXISTS closed_by uuid REFERENCES users(id) ON DELETE SET NULL;
+
+-- GET /pvz may filter receptions by close time
+CREATE INDEX IF NOT EXISTS idx_receptions_pvz_closed_at
+    ON receptions(pvz_id, closed_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_receptions_pvz_closed_at;
+ALTER TABLE receptions DROP COLUMN IF EXISTS closed_by;
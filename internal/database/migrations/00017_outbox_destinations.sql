@@ -0,0 +1,16 @@
+-- +goose Up
+-- the publisher keeps its own sent marker, sent_at and locked_until stay with webhooks,
+-- so a broker outage doesn't hold back webhook deliveries or make them resend
+ALTER TABLE outbox
+    ADD COLUMN IF NOT EXISTS publish_locked_until TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS published_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE outbox SET published_at = sent_at WHERE sent_at IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_unpublished ON outbox(seq) WHERE published_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_outbox_unpublished;
+ALTER TABLE outbox
+    DROP COLUMN IF EXISTS published_at,
+    DROP COLUMN IF EXISTS publish_locked_until;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- the event stream reads the events of one pvz in seq order: its head and what follows a seq
+CREATE INDEX IF NOT EXISTS idx_outbox_pvz_seq ON outbox(pvz_id, seq);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_outbox_pvz_seq;
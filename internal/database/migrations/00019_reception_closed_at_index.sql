@@ -0,0 +1,9 @@
+-- +goose Up
+-- GET /pvz?dateBy=close looks receptions up by a closed_at range across all pvz,
+-- an index led by pvz_id can't serve it
+DROP INDEX IF EXISTS idx_receptions_pvz_closed_at;
+CREATE INDEX IF NOT EXISTS idx_receptions_closed_at ON receptions(closed_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_receptions_closed_at;
+CREATE INDEX IF NOT EXISTS idx_receptions_pvz_closed_at ON receptions(pvz_id, closed_at);
@@ -0,0 +1,6 @@
+-- +goose Up
+-- headers a replay must repeat, e.g. the ETag the If-Match flow relies on
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS response_headers JSONB;
+
+-- +goose Down
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS response_headers;
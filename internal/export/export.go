@@ -0,0 +1,198 @@
+// Package export writes tables as CSV or XLSX row by row, so a large export
+// never has to be held in memory as a whole.
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"pvz/internal/models"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+
+	LangRU = "ru"
+	LangEN = "en"
+)
+
+// Writer writes rows of a table. Flush finishes the file, Close releases the writer
+// whether the file was finished or not.
+type Writer interface {
+	WriteRow(cells []any) error
+	Flush() error
+	Close() error
+}
+
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// NewWriter returns a writer of the format, sheet names the XLSX worksheet.
+func NewWriter(format string, w io.Writer, sheet string) (Writer, error) {
+	switch format {
+	case "", FormatCSV:
+		return newCSVWriter(w)
+	case FormatXLSX:
+		return newXLSXWriter(w, sheet)
+	default:
+		return nil, fmt.Errorf("unknown export format %q, expected csv or xlsx", format)
+	}
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+// newCSVWriter buffers the output, nothing reaches w before the first few kilobytes are written.
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	buf := bufio.NewWriter(w)
+	// the byte order mark makes Excel read the file as UTF-8
+	if _, err := buf.WriteString("\ufeff"); err != nil {
+		return nil, err
+	}
+	// csv.NewWriter keeps using buf, it doesn't wrap a bufio.Writer twice
+	return &csvWriter{w: csv.NewWriter(buf)}, nil
+}
+
+func (cw *csvWriter) WriteRow(cells []any) error {
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		switch v := cell.(type) {
+		case time.Time:
+			record[i] = v.UTC().Format(time.RFC3339)
+		case *time.Time:
+			if v != nil {
+				record[i] = v.UTC().Format(time.RFC3339)
+			}
+		default:
+			record[i] = fmt.Sprint(v)
+		}
+	}
+	return cw.w.Write(record)
+}
+
+func (cw *csvWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func (cw *csvWriter) Close() error { return nil }
+
+// xlsxWriter uses the excelize stream writer, rows beyond its memory limit go to a temporary file
+// until the workbook is written out on Flush.
+type xlsxWriter struct {
+	out       io.Writer
+	file      *excelize.File
+	stream    *excelize.StreamWriter
+	timeStyle int
+	row       int
+}
+
+func newXLSXWriter(w io.Writer, sheet string) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+	if err := file.SetSheetName("Sheet1", sheet); err != nil {
+		file.Close()
+		return nil, err
+	}
+	stream, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	format := "yyyy-mm-dd hh:mm:ss"
+	timeStyle, err := file.NewStyle(&excelize.Style{CustomNumFmt: &format})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &xlsxWriter{out: w, file: file, stream: stream, timeStyle: timeStyle}, nil
+}
+
+func (xw *xlsxWriter) WriteRow(cells []any) error {
+	values := make([]any, len(cells))
+	for i, cell := range cells {
+		switch v := cell.(type) {
+		case time.Time:
+			values[i] = excelize.Cell{StyleID: xw.timeStyle, Value: v.UTC()}
+		case *time.Time:
+			if v != nil {
+				values[i] = excelize.Cell{StyleID: xw.timeStyle, Value: v.UTC()}
+			}
+		case models.City, models.ProductType, models.ReceptionStatus:
+			values[i] = fmt.Sprint(v)
+		default:
+			values[i] = v
+		}
+	}
+	xw.row++
+	cell, err := excelize.CoordinatesToCellName(1, xw.row)
+	if err != nil {
+		return err
+	}
+	return xw.stream.SetRow(cell, values)
+}
+
+func (xw *xlsxWriter) Flush() error {
+	if err := xw.stream.Flush(); err != nil {
+		return err
+	}
+	_, err := xw.file.WriteTo(xw.out)
+	return err
+}
+
+// Close removes the temporary files.
+func (xw *xlsxWriter) Close() error {
+	return xw.file.Close()
+}
+
+var (
+	receptionHeaders = map[string][]string{
+		LangRU: {"ID приемки", "ID ПВЗ", "Город", "Открыта", "Закрыта", "Статус", "Товаров"},
+		LangEN: {"Reception ID", "PVZ ID", "City", "Opened at", "Closed at", "Status", "Products"},
+	}
+	productHeaders = map[string][]string{
+		LangRU: {"ID товара", "Принят", "Тип", "ID приемки", "ID ПВЗ", "Город"},
+		LangEN: {"Product ID", "Received at", "Type", "Reception ID", "PVZ ID", "City"},
+	}
+)
+
+// Lang picks the language of the column headers, Russian unless English is asked for.
+func Lang(lang string) string {
+	if lang == LangEN {
+		return LangEN
+	}
+	return LangRU
+}
+
+func ReceptionHeader(lang string) []any {
+	return header(receptionHeaders[Lang(lang)])
+}
+
+func ReceptionRow(r models.ExportReception) []any {
+	return []any{r.ID, r.PvzID, r.City, r.DateTime, r.ClosedAt, r.Status, r.Products}
+}
+
+func ProductHeader(lang string) []any {
+	return header(productHeaders[Lang(lang)])
+}
+
+func ProductRow(p models.ExportProduct) []any {
+	return []any{p.ID, p.DateTime, p.Type, p.ReceptionID, p.PvzID, p.City}
+}
+
+func header(names []string) []any {
+	cells := make([]any, len(names))
+	for i, name := range names {
+		cells[i] = name
+	}
+	return cells
+}
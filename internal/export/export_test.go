@@ -0,0 +1,66 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+
+	"pvz/internal/models"
+)
+
+var (
+	opened   = time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)
+	closedAt = opened.Add(2 * time.Hour)
+	rows     = []models.ExportReception{
+		{Reception: models.Reception{ID: "r1", PvzID: "p1", DateTime: opened, Status: models.StatusClose, ClosedAt: &closedAt}, City: models.Kazan, Products: 3},
+		{Reception: models.Reception{ID: "r2", PvzID: "p1", DateTime: opened, Status: models.StatusInProgress}, City: models.Kazan},
+	}
+)
+
+func TestCSV(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatCSV, &buf, "receptions")
+	require.NoError(t, err)
+	require.NoError(t, w.WriteRow(ReceptionHeader(LangEN)))
+	require.Zero(t, buf.Len(), "small files are buffered")
+	for _, r := range rows {
+		require.NoError(t, w.WriteRow(ReceptionRow(r)))
+	}
+	require.NoError(t, w.Flush())
+	require.NoError(t, w.Close())
+
+	require.Equal(t, "\ufeff"+
+		"Reception ID,PVZ ID,City,Opened at,Closed at,Status,Products\n"+
+		"r1,p1,Казань,2025-03-01T09:30:00Z,2025-03-01T11:30:00Z,close,3\n"+
+		"r2,p1,Казань,2025-03-01T09:30:00Z,,in_progress,0\n", buf.String())
+}
+
+func TestXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatXLSX, &buf, "receptions")
+	require.NoError(t, err)
+	require.NoError(t, w.WriteRow(ReceptionHeader("")))
+	for _, r := range rows {
+		require.NoError(t, w.WriteRow(ReceptionRow(r)))
+	}
+	require.NoError(t, w.Flush())
+	require.NoError(t, w.Close())
+
+	f, err := excelize.OpenReader(&buf)
+	require.NoError(t, err)
+	defer f.Close()
+	got, err := f.GetRows("receptions")
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	require.Equal(t, "ID приемки", got[0][0])
+	require.Equal(t, []string{"r1", "p1", "Казань", "2025-03-01 09:30:00", "2025-03-01 11:30:00", "close", "3"}, got[1])
+	require.Equal(t, "", got[2][4])
+}
+
+func TestUnknownFormat(t *testing.T) {
+	_, err := NewWriter("ods", &bytes.Buffer{}, "receptions")
+	require.Error(t, err)
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"pvz/internal/auth"
+	"pvz/internal/models"
+	"pvz/internal/repository"
+	"pvz/internal/services"
+	"pvz/internal/validation"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/labstack/echo/v4"
+)
+
+// CreateAPIKey answers with the key itself, it can't be read again later.
+func (h *Handler) CreateAPIKey(c echo.Context) error {
+	var req validation.CreateAPIKeyRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Err("invalid JSON: "+err.Error()))
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
+	}
+
+	principal, ok := c.Get(ContextPrincipal).(*auth.Principal)
+	if !ok {
+		return c.JSON(http.StatusForbidden, models.Err("access is denied"))
+	}
+	key, err := h.Service.CreateAPIKey(c.Request().Context(), principal, req.Name, req.Scopes, req.ExpiresAt)
+	switch {
+	case err == nil:
+		return c.JSON(http.StatusCreated, key)
+	case errors.Is(err, services.ErrInvalidScopes), errors.Is(err, services.ErrKeyExpired):
+		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
+	case errors.Is(err, services.ErrScopeNotHeld):
+		return c.JSON(http.StatusForbidden, models.Err(err.Error()))
+	default:
+		logError(c, "create api key", err)
+		return c.JSON(http.StatusInternalServerError, models.Err("internal error"))
+	}
+}
+
+func (h *Handler) ListAPIKeys(c echo.Context) error {
+	keys, err := h.Service.ListAPIKeys(c.Request().Context())
+	if err != nil {
+		logError(c, "list api keys", err)
+		return c.JSON(http.StatusInternalServerError, models.Err("internal error"))
+	}
+	return c.JSON(http.StatusOK, keys)
+}
+
+func (h *Handler) DeleteAPIKey(c echo.Context) error {
+	keyID := c.Param("id")
+	if !govalidator.IsUUID(keyID) {
+		return c.JSON(http.StatusBadRequest, models.Err("invalid id, uuid expected"))
+	}
+
+	err := h.Service.DeleteAPIKey(c.Request().Context(), keyID)
+	switch {
+	case err == nil:
+		return c.NoContent(http.StatusNoContent)
+	case errors.Is(err, repository.ErrAPIKeyNotFound):
+		return c.JSON(http.StatusNotFound, models.Err(err.Error()))
+	default:
+		logError(c, "delete api key", err, "api_key_id", keyID)
+		return c.JSON(http.StatusInternalServerError, models.Err("internal error"))
+	}
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pvz/internal/auth"
+	"pvz/internal/models"
+	"pvz/internal/repository"
+	"pvz/internal/services"
+	storemocks "pvz/internal/services/mocks"
+	"pvz/internal/validation"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAPIKey(t *testing.T) {
+	e, svc := setup()
+	h := NewHandler(svc)
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	body := validation.CreateAPIKeyRequest{Name: "wms", Scopes: []string{models.PermPVZRead}, ExpiresAt: &expires}
+
+	moderator := &auth.Principal{UserID: "m1", Role: models.Moderator}
+
+	svc.EXPECT().CreateAPIKey(mock.Anything, moderator, "wms", body.Scopes, &expires).
+		Return(models.NewAPIKey{APIKey: models.APIKey{ID: "k1"}, Key: "pvz_secret"}, nil).Once()
+	c, rec := jsonContext(e, "/api-keys", body)
+	c.Set(ContextPrincipal, moderator)
+	require.NoError(t, h.CreateAPIKey(c))
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Contains(t, rec.Body.String(), `"key":"pvz_secret"`)
+
+	for err, code := range map[error]int{
+		services.ErrInvalidScopes: http.StatusBadRequest,
+		services.ErrKeyExpired:    http.StatusBadRequest,
+		services.ErrScopeNotHeld:  http.StatusForbidden,
+		errors.New("db down"):     http.StatusInternalServerError,
+	} {
+		svc.EXPECT().CreateAPIKey(mock.Anything, moderator, "wms", body.Scopes, &expires).
+			Return(models.NewAPIKey{}, err).Once()
+		c, rec := jsonContext(e, "/api-keys", body)
+		c.Set(ContextPrincipal, moderator)
+		require.NoError(t, h.CreateAPIKey(c))
+		require.Equal(t, code, rec.Code, err.Error())
+	}
+
+	c, rec = jsonContext(e, "/api-keys", body)
+	require.NoError(t, h.CreateAPIKey(c))
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestCreateAPIKeyBeyondRole(t *testing.T) {
+	e, _ := setup()
+	store := new(storemocks.PvzUserStore)
+	h := NewHandler(services.NewService(store))
+
+	body := validation.CreateAPIKeyRequest{Name: "wms", Scopes: []string{models.PermPVZRead, models.PermProductCreate}}
+	c, rec := jsonContext(e, "/api-keys", body)
+	c.Set(ContextPrincipal, &auth.Principal{UserID: "m1", Role: models.Moderator})
+	require.NoError(t, h.CreateAPIKey(c))
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	store.AssertNotCalled(t, "CreateAPIKey", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDeleteAPIKey(t *testing.T) {
+	e, svc := setup()
+	h := NewHandler(svc)
+	const id = "00000000-0000-4000-8000-0000000000a1"
+
+	send := func(keyID string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodDelete, "/api-keys/"+keyID, nil), rec)
+		c.SetParamNames("id")
+		c.SetParamValues(keyID)
+		require.NoError(t, h.DeleteAPIKey(c))
+		return rec
+	}
+
+	require.Equal(t, http.StatusBadRequest, send("bad").Code)
+
+	svc.EXPECT().DeleteAPIKey(mock.Anything, id).Return(repository.ErrAPIKeyNotFound).Once()
+	require.Equal(t, http.StatusNotFound, send(id).Code)
+
+	svc.EXPECT().DeleteAPIKey(mock.Anything, id).Return(nil).Once()
+	require.Equal(t, http.StatusNoContent, send(id).Code)
+	svc.AssertExpectations(t)
+}
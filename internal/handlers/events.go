@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"pvz/internal/auth"
+	"pvz/internal/logger"
+	"pvz/internal/models"
+	"pvz/internal/repository"
+	"pvz/internal/services"
+	"strconv"
+	"time"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/labstack/echo/v4"
+)
+
+const HeaderLastEventID = "Last-Event-ID"
+
+// EventStreamConfig paces the server-sent events of GET /pvz/:pvzId/events.
+type EventStreamConfig struct {
+	// PollInterval is how often new events are looked up, it is also the client's reconnect delay
+	PollInterval time.Duration
+	// Heartbeat is the longest silence on a stream, proxies close idle connections
+	Heartbeat time.Duration
+	// AccessCheck is how often the caller's access is checked again on an open stream
+	AccessCheck time.Duration
+	BatchSize   int
+}
+
+func DefaultEventStreamConfig() EventStreamConfig {
+	return EventStreamConfig{PollInterval: time.Second, Heartbeat: 15 * time.Second, AccessCheck: 30 * time.Second, BatchSize: 100}
+}
+
+// PVZEvents streams events of the pvz as server-sent events, the id of each is its seq.
+// A client reconnecting with Last-Event-ID (or ?lastEventId=) gets the events it missed,
+// a new one only events after it connected. The stream ends when the caller loses
+// access to the pvz or its token expires.
+func (h *Handler) PVZEvents(c echo.Context) error {
+	pvzID := c.Param("pvzId")
+	if !govalidator.IsUUID(pvzID) {
+		return c.JSON(http.StatusBadRequest, models.Err("invalid pvzId, uuid expected"))
+	}
+	principal, ok := c.Get(ContextPrincipal).(*auth.Principal)
+	if !ok {
+		return c.JSON(http.StatusForbidden, models.Err("access is denied"))
+	}
+	lastID := c.Request().Header.Get(HeaderLastEventID)
+	if lastID == "" {
+		lastID = c.QueryParam("lastEventId")
+	}
+	var after int64
+	if lastID != "" {
+		var err error
+		if after, err = strconv.ParseInt(lastID, 10, 64); err != nil || after < 0 {
+			return c.JSON(http.StatusBadRequest, models.Err("invalid Last-Event-ID, event seq expected"))
+		}
+	}
+
+	ctx := c.Request().Context()
+	head, err := h.Service.OpenPVZEvents(ctx, principal, pvzID)
+	switch {
+	case err == nil:
+	case errors.Is(err, repository.ErrPvzNotFound):
+		return c.JSON(http.StatusNotFound, models.Err(err.Error()))
+	case errors.Is(err, services.ErrPVZAccessDenied):
+		return c.JSON(http.StatusForbidden, models.Err("access is denied"))
+	default:
+		logError(c, "open pvz events", err, "pvz_id", pvzID)
+		return c.JSON(http.StatusInternalServerError, models.Err("internal error"))
+	}
+	if lastID == "" {
+		after = head
+	}
+
+	cfg := h.Events
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprintf(res, "retry: %d\n\n", cfg.PollInterval.Milliseconds()); err != nil {
+		return nil
+	}
+	res.Flush()
+
+	poll := time.NewTicker(cfg.PollInterval)
+	defer poll.Stop()
+	heartbeat := time.NewTicker(cfg.Heartbeat)
+	defer heartbeat.Stop()
+	accessCheck := time.NewTicker(cfg.AccessCheck)
+	defer accessCheck.Stop()
+	var expired <-chan time.Time
+	if !principal.ExpiresAt.IsZero() {
+		expiry := time.NewTimer(time.Until(principal.ExpiresAt))
+		defer expiry.Stop()
+		expired = expiry.C
+	}
+	for {
+		events, err := h.Service.PVZEvents(ctx, pvzID, after, cfg.BatchSize)
+		if err != nil {
+			// the response has started, the client reconnects with the last id it got
+			if ctx.Err() == nil {
+				logError(c, "list pvz events", err, "pvz_id", pvzID)
+			}
+			return nil
+		}
+		for _, event := range events {
+			if err := writeEvent(res, event); err != nil {
+				return nil
+			}
+			after = event.Seq
+		}
+		if len(events) > 0 {
+			res.Flush()
+			heartbeat.Reset(cfg.Heartbeat)
+		}
+		if len(events) == cfg.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-poll.C:
+		case <-heartbeat.C:
+			if _, err := io.WriteString(res, ": ping\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		case <-accessCheck.C:
+			if err := h.Service.CheckPVZEvents(ctx, principal, pvzID); err != nil {
+				switch {
+				case errors.Is(err, services.ErrPVZAccessDenied), errors.Is(err, services.ErrAccessRevoked),
+					errors.Is(err, repository.ErrUserNotFound), errors.Is(err, repository.ErrPvzNotFound):
+					logger.FromContext(ctx).Warn("event stream closed, access is lost", "pvz_id", pvzID, "reason", err.Error())
+				case ctx.Err() == nil:
+					logError(c, "check pvz events access", err, "pvz_id", pvzID)
+				}
+				return nil
+			}
+		case <-expired:
+			return nil
+		}
+	}
+}
+
+func writeEvent(w io.Writer, event models.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Seq, event.Type, data)
+	return err
+}
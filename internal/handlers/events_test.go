@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pvz/internal/auth"
+	"pvz/internal/models"
+	"pvz/internal/repository"
+	"pvz/internal/services"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const eventsPVZ = "00000000-0000-4000-8000-0000000000e1"
+
+func eventsContext(h *Handler, lastEventID string) (*httptest.ResponseRecorder, func() error, context.CancelFunc) {
+	return eventsContextAs(h, lastEventID, &auth.Principal{UserID: "e1", Role: models.Employee})
+}
+
+func eventsContextAs(h *Handler, lastEventID string, p *auth.Principal) (*httptest.ResponseRecorder, func() error, context.CancelFunc) {
+	e, _ := setup()
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/pvz/"+eventsPVZ+"/events", nil).WithContext(ctx)
+	if lastEventID != "" {
+		req.Header.Set(HeaderLastEventID, lastEventID)
+	}
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("pvzId")
+	c.SetParamValues(eventsPVZ)
+	c.Set(ContextPrincipal, p)
+	return rec, func() error { return h.PVZEvents(c) }, cancel
+}
+
+func TestPVZEventsResume(t *testing.T) {
+	_, svc := setup()
+	h := NewHandler(svc)
+	h.Events.PollInterval = time.Millisecond
+	rec, run, cancel := eventsContext(h, "3")
+	defer cancel()
+
+	svc.EXPECT().OpenPVZEvents(mock.Anything, mock.Anything, eventsPVZ).Return(9, nil).Once()
+	svc.EXPECT().PVZEvents(mock.Anything, eventsPVZ, int64(3), 100).Return([]models.Event{
+		{Seq: 4, Type: models.EventProductAdded, PvzID: eventsPVZ, Data: []byte(`{}`)},
+		{Seq: 6, Type: models.EventReceptionClosed, PvzID: eventsPVZ, Data: []byte(`{}`)},
+	}, nil).Once()
+	svc.EXPECT().PVZEvents(mock.Anything, eventsPVZ, int64(6), 100).
+		RunAndReturn(func(context.Context, string, int64, int) ([]models.Event, error) {
+			cancel()
+			return nil, context.Canceled
+		}).Once()
+
+	require.NoError(t, run())
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	body := rec.Body.String()
+	require.Contains(t, body, "retry: 1\n\n")
+	require.Contains(t, body, "id: 4\nevent: product.added\ndata: {")
+	require.Contains(t, body, "id: 6\nevent: reception.closed\n")
+	svc.AssertExpectations(t)
+}
+
+func TestPVZEventsStartsAtHead(t *testing.T) {
+	_, svc := setup()
+	h := NewHandler(svc)
+	h.Events.PollInterval = time.Millisecond
+	h.Events.Heartbeat = time.Millisecond
+	rec, run, cancel := eventsContext(h, "")
+	defer cancel()
+
+	calls := 0
+	svc.EXPECT().OpenPVZEvents(mock.Anything, mock.Anything, eventsPVZ).Return(9, nil).Once()
+	svc.EXPECT().PVZEvents(mock.Anything, eventsPVZ, int64(9), 100).
+		RunAndReturn(func(context.Context, string, int64, int) ([]models.Event, error) {
+			if calls++; calls == 5 {
+				cancel()
+			}
+			return nil, nil
+		})
+
+	require.NoError(t, run())
+	require.Contains(t, rec.Body.String(), ": ping\n\n")
+	svc.AssertExpectations(t)
+}
+
+func TestPVZEventsErrors(t *testing.T) {
+	_, svc := setup()
+	h := NewHandler(svc)
+
+	rec, run, cancel := eventsContext(h, "abc")
+	defer cancel()
+	require.NoError(t, run())
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	for err, code := range map[error]int{
+		repository.ErrPvzNotFound:   http.StatusNotFound,
+		services.ErrPVZAccessDenied: http.StatusForbidden,
+		errors.New("db down"):       http.StatusInternalServerError,
+	} {
+		svc.EXPECT().OpenPVZEvents(mock.Anything, mock.Anything, eventsPVZ).Return(0, err).Once()
+		rec, run, cancel := eventsContext(h, "")
+		require.NoError(t, run())
+		require.Equal(t, code, rec.Code, err.Error())
+		cancel()
+	}
+	svc.AssertExpectations(t)
+}
+
+func TestPVZEventsAccessLost(t *testing.T) {
+	for _, err := range []error{services.ErrPVZAccessDenied, services.ErrAccessRevoked, errors.New("db down")} {
+		_, svc := setup()
+		h := NewHandler(svc)
+		h.Events.PollInterval = time.Millisecond
+		h.Events.AccessCheck = 5 * time.Millisecond
+		rec, run, cancel := eventsContext(h, "")
+
+		svc.EXPECT().OpenPVZEvents(mock.Anything, mock.Anything, eventsPVZ).Return(9, nil).Once()
+		svc.EXPECT().PVZEvents(mock.Anything, eventsPVZ, int64(9), 100).Return(nil, nil)
+		svc.EXPECT().CheckPVZEvents(mock.Anything, mock.Anything, eventsPVZ).Return(nil).Once()
+		svc.EXPECT().CheckPVZEvents(mock.Anything, mock.Anything, eventsPVZ).Return(err).Once()
+
+		// the stream ends on its own, the request is never canceled
+		require.NoError(t, run())
+		require.Equal(t, http.StatusOK, rec.Code)
+		svc.AssertExpectations(t)
+		cancel()
+	}
+}
+
+func TestPVZEventsTokenExpires(t *testing.T) {
+	_, svc := setup()
+	h := NewHandler(svc)
+	h.Events.PollInterval = time.Millisecond
+	p := &auth.Principal{UserID: "e1", Role: models.Employee, ExpiresAt: time.Now().Add(20 * time.Millisecond)}
+	rec, run, cancel := eventsContextAs(h, "", p)
+	defer cancel()
+
+	svc.EXPECT().OpenPVZEvents(mock.Anything, p, eventsPVZ).Return(9, nil).Once()
+	svc.EXPECT().PVZEvents(mock.Anything, eventsPVZ, int64(9), 100).Return(nil, nil)
+
+	done := make(chan error, 1)
+	go func() { done <- run() }()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream outlived the token")
+	}
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.False(t, time.Now().Before(p.ExpiresAt))
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"pvz/internal/export"
+	"pvz/internal/models"
+	"pvz/internal/services"
+	"pvz/internal/validation"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h *Handler) ExportReceptions(c echo.Context) error {
+	return h.export(c, "receptions", export.ReceptionHeader, func(ctx context.Context, q validation.ExportQuery, write func([]any) error) error {
+		return h.Service.ExportReceptions(ctx, q.StartDate, q.EndDate, func(r models.ExportReception) error {
+			return write(export.ReceptionRow(r))
+		})
+	})
+}
+
+func (h *Handler) ExportProducts(c echo.Context) error {
+	return h.export(c, "products", export.ProductHeader, func(ctx context.Context, q validation.ExportQuery, write func([]any) error) error {
+		return h.Service.ExportProducts(ctx, q.StartDate, q.EndDate, func(p models.ExportProduct) error {
+			return write(export.ProductRow(p))
+		})
+	})
+}
+
+// export streams a table as the file name.csv or name.xlsx. Errors before anything was sent
+// are answered as usual, later ones cut the connection, so a client can't take a partial
+// file for a complete one.
+func (h *Handler) export(c echo.Context, name string, header func(lang string) []any,
+	stream func(ctx context.Context, q validation.ExportQuery, write func([]any) error) error) error {
+	var req validation.ExportQuery
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Err("invalid query: "+err.Error()))
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
+	}
+	if req.Format == "" {
+		req.Format = export.FormatCSV
+	}
+	if req.Lang == "" && strings.HasPrefix(strings.ToLower(c.Request().Header.Get("Accept-Language")), export.LangEN) {
+		req.Lang = export.LangEN
+	}
+
+	res := c.Response()
+	w, err := export.NewWriter(req.Format, res, name)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
+	}
+	defer w.Close()
+	res.Header().Set(echo.HeaderContentType, export.ContentType(req.Format))
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.%s"`, name, req.Format))
+
+	err = w.WriteRow(header(req.Lang))
+	if err == nil {
+		err = stream(c.Request().Context(), req, w.WriteRow)
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		return nil
+	}
+	if !res.Committed {
+		res.Header().Del(echo.HeaderContentType)
+		res.Header().Del(echo.HeaderContentDisposition)
+		if errors.Is(err, services.ErrInvalidDate) {
+			return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
+		}
+		logError(c, "export "+name, err)
+		return c.JSON(http.StatusInternalServerError, models.Err("internal error"))
+	}
+	logError(c, "export "+name, err)
+	panic(http.ErrAbortHandler)
+}
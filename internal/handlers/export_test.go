@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pvz/internal/models"
+	"pvz/internal/services"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportReceptions(t *testing.T) {
+	e, svc := setup()
+	h := NewHandler(svc)
+	opened := time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)
+
+	send := func(query, acceptLanguage string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/export/receptions?"+query, nil)
+		req.Header.Set("Accept-Language", acceptLanguage)
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.ExportReceptions(e.NewContext(req, rec)))
+		return rec
+	}
+
+	svc.EXPECT().ExportReceptions(mock.Anything, "2025-03-01T00:00:00Z", "", mock.Anything).
+		RunAndReturn(func(_ context.Context, _, _ string, fn func(models.ExportReception) error) error {
+			return fn(models.ExportReception{
+				Reception: models.Reception{ID: "r1", PvzID: "p1", DateTime: opened, Status: models.StatusInProgress},
+				City:      models.Moscow,
+			})
+		}).Twice()
+	rec := send("startDate=2025-03-01T00:00:00Z", "en-US,en;q=0.9")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	require.Equal(t, `attachment; filename="receptions.csv"`, rec.Header().Get("Content-Disposition"))
+	lines := strings.Split(strings.TrimPrefix(rec.Body.String(), "\ufeff"), "\n")
+	require.Equal(t, "Reception ID,PVZ ID,City,Opened at,Closed at,Status,Products", lines[0])
+	require.Equal(t, "r1,p1,Москва,2025-03-01T09:30:00Z,,in_progress,0", lines[1])
+
+	rec = send("startDate=2025-03-01T00:00:00Z&format=xlsx&lang=ru", "en")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, `attachment; filename="receptions.xlsx"`, rec.Header().Get("Content-Disposition"))
+	require.True(t, strings.HasPrefix(rec.Body.String(), "PK"), "xlsx is a zip archive")
+
+	for err, code := range map[error]int{
+		models.Wrap("invalid endDate", services.ErrInvalidDate): http.StatusBadRequest,
+		errors.New("db down"): http.StatusInternalServerError,
+	} {
+		svc.EXPECT().ExportReceptions(mock.Anything, "", "", mock.Anything).Return(err).Once()
+		rec := send("", "")
+		require.Equal(t, code, rec.Code, err.Error())
+		require.Empty(t, rec.Header().Get("Content-Disposition"))
+	}
+	svc.AssertExpectations(t)
+}
+
+func TestExportProductsCutOnLateError(t *testing.T) {
+	e, svc := setup()
+	h := NewHandler(svc)
+
+	svc.EXPECT().ExportProducts(mock.Anything, "", "", mock.Anything).
+		RunAndReturn(func(_ context.Context, _, _ string, fn func(models.ExportProduct) error) error {
+			for i := 0; i < 1000; i++ {
+				if err := fn(models.ExportProduct{Product: models.Product{ID: "00000000-0000-4000-8000-000000000001", Type: models.Shoes}}); err != nil {
+					return err
+				}
+			}
+			return errors.New("connection reset")
+		}).Once()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/export/products", nil), rec)
+	require.PanicsWithValue(t, http.ErrAbortHandler, func() { _ = h.ExportProducts(c) })
+	require.Equal(t, http.StatusOK, rec.Code)
+	svc.AssertExpectations(t)
+}
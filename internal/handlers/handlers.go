@@ -1,42 +1,158 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"pvz/internal/auth"
+	"pvz/internal/logger"
+	"pvz/internal/metrics"
 	"pvz/internal/models"
+	"pvz/internal/ratelimit"
+	"pvz/internal/repository"
 	"pvz/internal/validation"
-	"pvz/pkg/utils"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/asaskevich/govalidator"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
+// ContextUserID and ContextPrincipal are the echo context keys the auth middleware
+// stores the caller under. ContextUserID is empty for API keys.
+const (
+	ContextUserID    = "userID"
+	ContextPrincipal = "principal"
+)
+
+const (
+	HeaderETag        = "ETag"
+	HeaderIfMatch     = "If-Match"
+	HeaderIfNoneMatch = "If-None-Match"
+)
+
+// errInvalidCredentials is the only login failure a client sees, so that
+// a missing user and a wrong password can't be told apart.
+const errInvalidCredentials = "invalid email or password"
+
 type Handler struct {
 	Service PvzUserService
+	Guard   LoginGuard
+	Events  EventStreamConfig
+}
+
+// LoginGuard limits failed login attempts per ip and per email.
+type LoginGuard interface {
+	Allow(ctx context.Context, ip, email string) (time.Duration, error)
+	Failed(ctx context.Context, ip, email string) error
+	Succeeded(ctx context.Context, ip, email string) error
 }
 
+type noopGuard struct{}
+
+func (noopGuard) Allow(context.Context, string, string) (time.Duration, error) { return 0, nil }
+func (noopGuard) Failed(context.Context, string, string) error                 { return nil }
+func (noopGuard) Succeeded(context.Context, string, string) error              { return nil }
+
 //go:generate mockery --name=PvzUserService --dir=. --output=./mocks --outpkg=mocks --with-expecter
 type PvzUserService interface {
 	PvzService
 	UserService
+	ReportService
 }
 
 func NewHandler(service PvzUserService) *Handler {
-	return &Handler{Service: service}
+	return &Handler{Service: service, Guard: noopGuard{}, Events: DefaultEventStreamConfig()}
 }
 
 type PvzService interface {
-	CreatePVZ(city models.City) (models.PVZ, error)
-	GetPVZInfo(start, end string, page, limit int) ([]models.PVZInfo, error)
+	CreatePVZ(ctx context.Context, city models.City) (models.PVZ, error)
+	ImportPVZs(ctx context.Context, rows []models.PVZImportRow, rowErrs []models.PVZImportError, dryRun bool) (models.PVZImportReport, error)
+	GetPVZInfo(ctx context.Context, start, end string, by models.ReceptionDate, page, limit int) ([]models.PVZInfo, error)
+	PVZInfoETag(ctx context.Context, start, end string, by models.ReceptionDate, page, limit int) (string, error)
+
+	CreateReception(ctx context.Context, pvzID string) (models.Reception, error)
+	UpdatePVZ(ctx context.Context, pvzID string, city models.City, ifMatch models.IfMatch) (models.PVZ, error)
+	CloseLastReception(ctx context.Context, actorID, pvzID string, ifMatch models.IfMatch) (models.Reception, error)
+	ReopenLastReception(ctx context.Context, pvzID string, ifMatch models.IfMatch) (models.Reception, error)
+
+	CreateProduct(ctx context.Context, pvzID string, prType models.ProductType) (models.Product, error)
+	DeleteLastProduct(ctx context.Context, pvzID string) error
+
+	OpenPVZEvents(ctx context.Context, p *auth.Principal, pvzID string) (int64, error)
+	CheckPVZEvents(ctx context.Context, p *auth.Principal, pvzID string) error
+	PVZEvents(ctx context.Context, pvzID string, afterSeq int64, limit int) ([]models.Event, error)
+}
+type UserService interface {
+	DummyLogin(ctx context.Context, role models.Role) (models.Token, error)
+	RegisterUser(ctx context.Context, email, password string, role models.Role) (models.User, error)
+	LoginUser(ctx context.Context, email, password string) (models.Token, error)
+	OIDCLogin(ctx context.Context, rawToken string) (models.Token, error)
+	ChangePassword(ctx context.Context, userID, oldPassword, newPassword string) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+
+	ListUsers(ctx context.Context, role models.Role, page, limit int) ([]models.User, error)
+	GetUser(ctx context.Context, userID string) (models.User, error)
+	UpdateUser(ctx context.Context, actorID, userID string, upd models.UserUpdate) (models.User, error)
+	DeleteUser(ctx context.Context, actorID, userID string) error
+	Me(ctx context.Context, userID string) (models.Me, error)
+	AssignPVZ(ctx context.Context, userID, pvzID string) error
+	UnassignPVZ(ctx context.Context, userID, pvzID string) error
+
+	CreateAPIKey(ctx context.Context, p *auth.Principal, name string, scopes []string, expiresAt *time.Time) (models.NewAPIKey, error)
+	ListAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	DeleteAPIKey(ctx context.Context, keyID string) error
+
+	CreateWebhook(ctx context.Context, actorID, url string, events []string, secret string) (models.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]models.Webhook, error)
+	DeleteWebhook(ctx context.Context, webhookID string) error
+	ListWebhookDeliveries(ctx context.Context, webhookID string, page, limit int) ([]models.WebhookDelivery, error)
+}
 
-	CreateReception(pvzID string) (models.Reception, error)
-	CloseLastReception(pvzID string) (models.Reception, error)
+type ReportService interface {
+	IntakeReport(ctx context.Context, start, end string, groupBy []string) (models.IntakeReport, error)
+	ReceptionStats(ctx context.Context, start, end, pvzID string) (models.ReceptionStatsReport, error)
+	ExportReceptions(ctx context.Context, start, end string, fn func(models.ExportReception) error) error
+	ExportProducts(ctx context.Context, start, end string, fn func(models.ExportProduct) error) error
+}
 
-	CreateProduct(pvzID string, prType models.ProductType) (models.Product, error)
-	DeleteLastProduct(pvzID string) error
+func logError(c echo.Context, msg string, err error, attrs ...any) {
+	logger.FromContext(c.Request().Context()).Error(msg, append(attrs, "error", err)...)
 }
-type UserService interface {
-	RegisterUser(email, password string, role models.Role) (models.User, error)
-	LoginUser(email, password string) (models.Token, error)
+
+func parseIfMatch(c echo.Context) models.IfMatch {
+	header := c.Request().Header.Get(HeaderIfMatch)
+	if header == "" {
+		return nil
+	}
+	var tags models.IfMatch
+	for _, tag := range strings.Split(header, ",") {
+		tags = append(tags, strings.TrimSpace(tag))
+	}
+	return tags
+}
+
+// noneMatch reports whether If-None-Match contains etag, weak tags are compared by value.
+func noneMatch(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func errorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, repository.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
+	default:
+		return fallback
+	}
 }
 
 func (h *Handler) DummyLogin(c echo.Context) error {
@@ -49,8 +165,9 @@ func (h *Handler) DummyLogin(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
 	}
 
-	token, err := utils.GetJWToken(req.Role, "")
+	token, err := h.Service.DummyLogin(c.Request().Context(), req.Role)
 	if err != nil {
+		logError(c, "dummy login", err)
 		return c.JSON(http.StatusBadRequest, models.Err("Invalid request"))
 	}
 	return c.JSON(http.StatusOK, token)
@@ -67,9 +184,18 @@ func (h *Handler) RegisterUser(c echo.Context) error {
 	if err := c.Validate(req); err != nil {
 		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
 	}
+	if req.Role != models.Employee {
+		return c.JSON(http.StatusForbidden, models.Err("only employees can register, other roles are granted by a moderator"))
+	}
 
-	user, err := h.Service.RegisterUser(req.Email, req.Password, req.Role)
+	user, err := h.Service.RegisterUser(c.Request().Context(), req.Email, req.Password, req.Role)
+	if errors.Is(err, repository.ErrUserExists) {
+		// answered as a registration, so /register can't tell which emails have an account
+		logger.FromContext(c.Request().Context()).Warn("registration with a taken email")
+		return c.JSON(http.StatusCreated, models.User{ID: uuid.NewString(), Email: req.Email, Role: req.Role})
+	}
 	if err != nil {
+		logError(c, "register user", err)
 		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
 	}
 
@@ -87,9 +213,43 @@ func (h *Handler) LoginUser(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, models.Err(err.Error()))
 	}
 
-	token, err := h.Service.LoginUser(req.Email, req.Password)
+	ctx := c.Request().Context()
+	ip := c.RealIP()
+	if wait, err := h.Guard.Allow(ctx, ip, req.Email); err != nil {
+		if errors.Is(err, ratelimit.ErrLocked) {
+			metrics.AuthRejected.WithLabelValues("/login").Inc()
+			logger.FromContext(ctx).Warn("login rejected, locked out", "ip", ip)
+			c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(int(wait.Seconds())+1))
+			return c.JSON(http.StatusTooManyRequests, models.Err(err.Error()))
+		}
+		logError(c, "check login guard", err)
+		return c.JSON(http.StatusInternalServerError, models.Err("internal error"))
+	}
+
+	token, err := h.Service.LoginUser(ctx, req.Email, req.Password)
 	if err != nil {
-		return c.JSON(http.StatusUnauthorized, models.Err(err.Error()))
+		reason := ""
+		switch {
+		case errors.Is(err, repository.ErrUserNotFound):
+			reason = "user_not_found"
+		case errors.Is(err, repository.ErrInvalidPassword):
+			reason = "invalid_password"
+		case errors.Is(err, repository.ErrUserBlocked):
+			logger.FromContext(ctx).Warn("blocked user tried to log in", "ip", ip)
+			return c.JSON(http.StatusForbidden, models.Err(err.Error()))
+		default:
+			logError(c, "login user", err)
+			return c.JSON(http.StatusInternalServerError, models.Err("internal error"))
+		}
+		metrics.AuthFailures.WithLabelValues(reason).Inc()
+		logger.FromContext(ctx).Warn("login failed", "reason", reason, "ip", ip)
+		if err := h.Guard.Failed(ctx, ip, req.Email); err != nil {
+			logError(c, "record login failure", err)
+		}
+		return c.JSON(http.StatusUnauthorized, models.Err(errInvalidCredentials))
+	}
+	if err := h.Guard.Succeeded(ctx, ip, req.Email); err != nil {
+		logError(c, "reset login failures", err)
 	}
 
 	return c.JSON(http.StatusOK, token)
@@ -106,14 +266,43 @@ func (h *Handler) CreatePVZ(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
 	}
 
-	reqPVZ, err := h.Service.CreatePVZ(pvz.City)
+	reqPVZ, err := h.Service.CreatePVZ(c.Request().Context(), pvz.City)
 	if err != nil {
+		logError(c, "create pvz", err)
 		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
 	}
 
+	c.Response().Header().Set(HeaderETag, reqPVZ.ETag())
 	return c.JSON(http.StatusCreated, reqPVZ)
 }
 
+func (h *Handler) UpdatePVZ(c echo.Context) error {
+	pvzID := c.Param("pvzId")
+	if !govalidator.IsUUID(pvzID) {
+		return c.JSON(http.StatusBadRequest, models.Err("invalid pvzId, uuid expected"))
+	}
+
+	var req validation.UpdatePVZRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Err("invalid JSON: "+err.Error()))
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
+	}
+
+	res, err := h.Service.UpdatePVZ(c.Request().Context(), pvzID, req.City, parseIfMatch(c))
+	if err != nil {
+		logError(c, "update pvz", err, "pvz_id", pvzID)
+		if errors.Is(err, repository.ErrPvzNotFound) {
+			return c.JSON(http.StatusNotFound, models.Err(err.Error()))
+		}
+		return c.JSON(errorStatus(err, http.StatusBadRequest), models.Err(err.Error()))
+	}
+
+	c.Response().Header().Set(HeaderETag, res.ETag())
+	return c.JSON(http.StatusOK, res)
+}
+
 func (h *Handler) CreateReception(c echo.Context) error {
 
 	var req validation.CreateReceptionRequest
@@ -123,10 +312,12 @@ func (h *Handler) CreateReception(c echo.Context) error {
 	if err := c.Validate(req); err != nil {
 		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
 	}
-	res, err := h.Service.CreateReception(req.PvzID)
+	res, err := h.Service.CreateReception(c.Request().Context(), req.PvzID)
 	if err != nil {
+		logError(c, "create reception", err, "pvz_id", req.PvzID)
 		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
 	}
+	c.Response().Header().Set(HeaderETag, res.ETag())
 	return c.JSON(http.StatusCreated, res)
 }
 
@@ -140,8 +331,9 @@ func (h *Handler) CreateProduct(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
 	}
 
-	res, err := h.Service.CreateProduct(req.PvzID, req.Type)
+	res, err := h.Service.CreateProduct(c.Request().Context(), req.PvzID, req.Type)
 	if err != nil {
+		logError(c, "create product", err, "pvz_id", req.PvzID)
 		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
 	}
 	return c.JSON(http.StatusCreated, res)
@@ -152,10 +344,27 @@ func (h *Handler) CloseLastReception(c echo.Context) error {
 	if !govalidator.IsUUID(pvzID) {
 		return c.JSON(http.StatusBadRequest, models.Err("invalid pvzId, uuid expected"))
 	}
-	res, err := h.Service.CloseLastReception(pvzID)
+	actorID, _ := c.Get(ContextUserID).(string)
+	res, err := h.Service.CloseLastReception(c.Request().Context(), actorID, pvzID, parseIfMatch(c))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
+		logError(c, "close last reception", err, "pvz_id", pvzID)
+		return c.JSON(errorStatus(err, http.StatusBadRequest), models.Err(err.Error()))
 	}
+	c.Response().Header().Set(HeaderETag, res.ETag())
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) ReopenLastReception(c echo.Context) error {
+	pvzID := c.Param("pvzId")
+	if !govalidator.IsUUID(pvzID) {
+		return c.JSON(http.StatusBadRequest, models.Err("invalid pvzId, uuid expected"))
+	}
+	res, err := h.Service.ReopenLastReception(c.Request().Context(), pvzID, parseIfMatch(c))
+	if err != nil {
+		logError(c, "reopen last reception", err, "pvz_id", pvzID)
+		return c.JSON(errorStatus(err, http.StatusBadRequest), models.Err(err.Error()))
+	}
+	c.Response().Header().Set(HeaderETag, res.ETag())
 	return c.JSON(http.StatusOK, res)
 }
 
@@ -165,8 +374,9 @@ func (h *Handler) DeleteLastProduct(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, models.Err("invalid pvzId, uuid expected"))
 	}
 
-	err := h.Service.DeleteLastProduct(pvzID)
+	err := h.Service.DeleteLastProduct(c.Request().Context(), pvzID)
 	if err != nil {
+		logError(c, "delete last product", err, "pvz_id", pvzID)
 		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
 	}
 	return c.NoContent(http.StatusOK)
@@ -182,10 +392,24 @@ func (h *Handler) GetPVZ(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
 	}
 
-	res, err := h.Service.GetPVZInfo(req.StartDate, req.EndDate, req.Page, req.Limit)
+	// the tag is checked before the page is loaded, dashboards polling with If-None-Match get 304
+	// without reading receptions and products. A change in between only makes the next poll load it again.
+	ctx := c.Request().Context()
+	by := models.ReceptionDate(req.DateBy)
+	etag, err := h.Service.PVZInfoETag(ctx, req.StartDate, req.EndDate, by, req.Page, req.Limit)
 	if err != nil {
+		logError(c, "get pvz info etag", err)
 		return c.JSON(http.StatusInternalServerError, models.Err(err.Error()))
 	}
+	c.Response().Header().Set(HeaderETag, etag)
+	if noneMatch(c.Request().Header.Get(HeaderIfNoneMatch), etag) {
+		return c.NoContent(http.StatusNotModified)
+	}
 
+	res, err := h.Service.GetPVZInfo(ctx, req.StartDate, req.EndDate, by, req.Page, req.Limit)
+	if err != nil {
+		logError(c, "get pvz info", err)
+		return c.JSON(http.StatusInternalServerError, models.Err(err.Error()))
+	}
 	return c.JSON(http.StatusOK, res)
 }
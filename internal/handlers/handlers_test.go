@@ -4,15 +4,20 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"pvz/internal/handlers/mocks"
 	"pvz/internal/models"
+	"pvz/internal/ratelimit"
+	"pvz/internal/repository"
 	"pvz/internal/validation"
 
 	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -28,8 +33,8 @@ func setup() (*echo.Echo, *mocks.PvzUserService) {
 }
 
 func TestDummyLogin(t *testing.T) {
-	e, _ := setup()
-	h := NewHandler(nil)
+	e, svc := setup()
+	h := NewHandler(svc)
 
 	t.Run("invalid JSON", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodPost, "/dummyLogin", bytes.NewBufferString("{bad}"))
@@ -42,7 +47,20 @@ func TestDummyLogin(t *testing.T) {
 		require.Equal(t, http.StatusBadRequest, rec.Code)
 	})
 
+	t.Run("service error", func(t *testing.T) {
+		svc.EXPECT().DummyLogin(mock.Anything, models.Employee).Return(models.Token(""), errors.New("db down")).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/dummyLogin", bytes.NewBufferString(`{"role":"employee"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		require.NoError(t, h.DummyLogin(c))
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
 	t.Run("success", func(t *testing.T) {
+		svc.EXPECT().DummyLogin(mock.Anything, models.Employee).Return(models.Token("tok"), nil).Once()
 		body := `{"role":"employee"}`
 		req := httptest.NewRequest(http.MethodPost, "/dummyLogin", bytes.NewBufferString(body))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
@@ -55,7 +73,7 @@ func TestDummyLogin(t *testing.T) {
 
 		var tok string
 		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tok))
-		require.NotEmpty(t, tok)
+		require.Equal(t, "tok", tok)
 	})
 }
 
@@ -76,7 +94,7 @@ func TestRegisterUser(t *testing.T) {
 
 	t.Run("service error", func(t *testing.T) {
 		svc.EXPECT().
-			RegisterUser("a@b.com", "pass", models.Employee).
+			RegisterUser(mock.Anything, "a@b.com", "pass", models.Employee).
 			Return(models.User{}, errors.New("fail")).
 			Once()
 
@@ -94,7 +112,7 @@ func TestRegisterUser(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		user := models.User{ID: "u1", Email: "a@b.com", Role: models.Employee}
 		svc.EXPECT().
-			RegisterUser("a@b.com", "pass", models.Employee).
+			RegisterUser(mock.Anything, "a@b.com", "pass", models.Employee).
 			Return(user, nil).
 			Once()
 
@@ -112,6 +130,35 @@ func TestRegisterUser(t *testing.T) {
 		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
 		require.Equal(t, user, got)
 	})
+	t.Run("taken email", func(t *testing.T) {
+		svc.EXPECT().
+			RegisterUser(mock.Anything, "a@b.com", "pass", models.Employee).
+			Return(models.User{}, fmt.Errorf("failed to register user: %w", repository.ErrUserExists)).
+			Once()
+
+		reqBody, _ := json.Marshal(validation.RegisterRequest{Email: "a@b.com", Password: "pass", Role: models.Employee})
+		req := httptest.NewRequest(http.MethodPost, "/register", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		require.NoError(t, h.RegisterUser(e.NewContext(req, rec)))
+		require.Equal(t, http.StatusCreated, rec.Code)
+		var got models.User
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		require.Equal(t, "a@b.com", got.Email)
+		require.NotEmpty(t, got.ID)
+	})
+	t.Run("moderator self registration", func(t *testing.T) {
+		reqBody, _ := json.Marshal(validation.RegisterRequest{Email: "a@b.com", Password: "pass", Role: models.Moderator})
+		req := httptest.NewRequest(http.MethodPost, "/register", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		require.NoError(t, h.RegisterUser(c))
+		require.Equal(t, http.StatusForbidden, rec.Code)
+		svc.AssertNotCalled(t, "RegisterUser", mock.Anything, "a@b.com", "pass", models.Moderator)
+	})
 }
 
 func TestLoginUser(t *testing.T) {
@@ -129,10 +176,46 @@ func TestLoginUser(t *testing.T) {
 		require.Equal(t, http.StatusUnauthorized, rec.Code)
 	})
 
+	for _, svcErr := range []error{repository.ErrInvalidPassword, repository.ErrUserNotFound} {
+		t.Run("invalid credentials: "+svcErr.Error(), func(t *testing.T) {
+			svc.EXPECT().
+				LoginUser(mock.Anything, "a@b.com", "pass").
+				Return(models.Token(""), svcErr).
+				Once()
+
+			reqBody, _ := json.Marshal(validation.LoginRequest{Email: "a@b.com", Password: "pass"})
+			req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := h.LoginUser(c)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusUnauthorized, rec.Code)
+			require.Contains(t, rec.Body.String(), errInvalidCredentials)
+		})
+	}
+
+	t.Run("blocked user", func(t *testing.T) {
+		svc.EXPECT().
+			LoginUser(mock.Anything, "a@b.com", "pass").
+			Return(models.Token(""), repository.ErrUserBlocked).
+			Once()
+
+		reqBody, _ := json.Marshal(validation.LoginRequest{Email: "a@b.com", Password: "pass"})
+		req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		require.NoError(t, h.LoginUser(c))
+		require.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
 	t.Run("service error", func(t *testing.T) {
 		svc.EXPECT().
-			LoginUser("a@b.com", "pass").
-			Return(models.Token(""), errors.New("denied")).
+			LoginUser(mock.Anything, "a@b.com", "pass").
+			Return(models.Token(""), errors.New("db down")).
 			Once()
 
 		reqBody, _ := json.Marshal(validation.LoginRequest{Email: "a@b.com", Password: "pass"})
@@ -143,13 +226,42 @@ func TestLoginUser(t *testing.T) {
 
 		err := h.LoginUser(c)
 		require.NoError(t, err)
-		require.Equal(t, http.StatusUnauthorized, rec.Code)
+		require.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+
+	t.Run("locked out", func(t *testing.T) {
+		h := NewHandler(svc)
+		h.Guard = ratelimit.NewLoginGuard(ratelimit.NewMemoryStore(), ratelimit.Config{
+			MaxFailuresPerEmail: 2,
+			Window:              time.Minute,
+			Lockout:             time.Minute,
+		})
+		svc.EXPECT().
+			LoginUser(mock.Anything, "a@b.com", "wrong").
+			Return(models.Token(""), repository.ErrInvalidPassword).
+			Twice()
+
+		codes := make([]int, 0, 3)
+		for range 3 {
+			reqBody, _ := json.Marshal(validation.LoginRequest{Email: "a@b.com", Password: "wrong"})
+			req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			require.NoError(t, h.LoginUser(c))
+			codes = append(codes, rec.Code)
+			if rec.Code == http.StatusTooManyRequests {
+				require.NotEmpty(t, rec.Header().Get(echo.HeaderRetryAfter))
+			}
+		}
+		require.Equal(t, []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests}, codes)
 	})
 
 	t.Run("success", func(t *testing.T) {
 		token := models.Token("tok123")
 		svc.EXPECT().
-			LoginUser("a@b.com", "pass").
+			LoginUser(mock.Anything, "a@b.com", "pass").
 			Return(token, nil).
 			Once()
 
@@ -186,7 +298,7 @@ func TestCreatePVZ(t *testing.T) {
 
 	t.Run("service error", func(t *testing.T) {
 		svc.EXPECT().
-			CreatePVZ(models.Moscow).
+			CreatePVZ(mock.Anything, models.Moscow).
 			Return(models.PVZ{}, errors.New("nope")).
 			Once()
 
@@ -204,7 +316,7 @@ func TestCreatePVZ(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		pvz := models.PVZ{ID: "p1", City: models.Kazan}
 		svc.EXPECT().
-			CreatePVZ(models.Kazan).
+			CreatePVZ(mock.Anything, models.Kazan).
 			Return(pvz, nil).
 			Once()
 
@@ -230,7 +342,7 @@ func TestCreateReception(t *testing.T) {
 
 	t.Run("service error", func(t *testing.T) {
 		svc.EXPECT().
-			CreateReception("pvz1").
+			CreateReception(mock.Anything, "pvz1").
 			Return(models.Reception{}, errors.New("err")).
 			Once()
 
@@ -248,7 +360,7 @@ func TestCreateReception(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		recp := models.Reception{ID: "r1", PvzID: "pvz1", Status: models.StatusInProgress}
 		svc.EXPECT().
-			CreateReception("pvz1").
+			CreateReception(mock.Anything, "pvz1").
 			Return(recp, nil).
 			Once()
 
@@ -274,7 +386,7 @@ func TestCreateProduct(t *testing.T) {
 
 	t.Run("service error", func(t *testing.T) {
 		svc.EXPECT().
-			CreateProduct("pvz1", models.Electronic).
+			CreateProduct(mock.Anything, "pvz1", models.Electronic).
 			Return(models.Product{}, errors.New("fail")).
 			Once()
 
@@ -292,7 +404,7 @@ func TestCreateProduct(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		prod := models.Product{ID: "p1", ReceptionID: "r1", Type: models.Clothes}
 		svc.EXPECT().
-			CreateProduct("pvz1", models.Clothes).
+			CreateProduct(mock.Anything, "pvz1", models.Clothes).
 			Return(prod, nil).
 			Once()
 
@@ -331,7 +443,7 @@ func TestCloseLastReception(t *testing.T) {
 	t.Run("service error", func(t *testing.T) {
 		valid := "123e4567-e89b-12d3-a456-426655440000"
 		svc.EXPECT().
-			CloseLastReception(valid).
+			CloseLastReception(mock.Anything, "", valid, models.IfMatch(nil)).
 			Return(models.Reception{}, errors.New("err")).
 			Once()
 
@@ -348,9 +460,10 @@ func TestCloseLastReception(t *testing.T) {
 
 	t.Run("success", func(t *testing.T) {
 		valid := "123e4567-e89b-12d3-a456-426655440000"
-		rc := models.Reception{ID: "r2", PvzID: valid, Status: models.StatusClose}
+		closedAt := time.Date(2025, 4, 20, 12, 0, 0, 0, time.UTC)
+		rc := models.Reception{ID: "r2", PvzID: valid, Status: models.StatusClose, ClosedAt: &closedAt, ClosedBy: "u1"}
 		svc.EXPECT().
-			CloseLastReception(valid).
+			CloseLastReception(mock.Anything, "u1", valid, models.IfMatch(nil)).
 			Return(rc, nil).
 			Once()
 
@@ -359,15 +472,128 @@ func TestCloseLastReception(t *testing.T) {
 		c := e.NewContext(req, rec)
 		c.SetParamNames("pvzId")
 		c.SetParamValues(valid)
+		c.Set(ContextUserID, "u1")
 
 		err := h.CloseLastReception(c)
 		require.NoError(t, err)
 		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, rc.ETag(), rec.Header().Get(HeaderETag))
 
 		var got models.Reception
 		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
 		require.Equal(t, rc, got)
 	})
+
+	t.Run("precondition failed", func(t *testing.T) {
+		valid := "123e4567-e89b-12d3-a456-426655440000"
+		svc.EXPECT().
+			CloseLastReception(mock.Anything, "", valid, models.IfMatch{`"r2-1"`, `"r2-2"`}).
+			Return(models.Reception{}, repository.ErrPreconditionFailed).
+			Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/pvz/"+valid+"/close_last_reception", nil)
+		req.Header.Set(HeaderIfMatch, `"r2-1", "r2-2"`)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("pvzId")
+		c.SetParamValues(valid)
+
+		err := h.CloseLastReception(c)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	})
+}
+
+func TestReopenLastReception(t *testing.T) {
+	e, svc := setup()
+	h := NewHandler(svc)
+	valid := "123e4567-e89b-12d3-a456-426655440000"
+
+	t.Run("in progress", func(t *testing.T) {
+		svc.EXPECT().
+			ReopenLastReception(mock.Anything, valid, models.IfMatch(nil)).
+			Return(models.Reception{}, repository.ErrReceptionInProgress).
+			Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/pvz/"+valid+"/reopen_last_reception", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("pvzId")
+		c.SetParamValues(valid)
+
+		require.NoError(t, h.ReopenLastReception(c))
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		rc := models.Reception{ID: "r1", PvzID: valid, Status: models.StatusInProgress, Version: 3}
+		svc.EXPECT().
+			ReopenLastReception(mock.Anything, valid, models.IfMatch{`"r1-2"`}).
+			Return(rc, nil).
+			Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/pvz/"+valid+"/reopen_last_reception", nil)
+		req.Header.Set(HeaderIfMatch, `"r1-2"`)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("pvzId")
+		c.SetParamValues(valid)
+
+		require.NoError(t, h.ReopenLastReception(c))
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, `"r1-3"`, rec.Header().Get(HeaderETag))
+	})
+}
+
+func TestUpdatePVZ(t *testing.T) {
+	e, svc := setup()
+	h := NewHandler(svc)
+	valid := "123e4567-e89b-12d3-a456-426655440000"
+
+	newRequest := func(ifMatch string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPatch, "/pvz/"+valid, bytes.NewBufferString(`{"city":"Казань"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if ifMatch != "" {
+			req.Header.Set(HeaderIfMatch, ifMatch)
+		}
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("pvzId")
+		c.SetParamValues(valid)
+		return c, rec
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		svc.EXPECT().
+			UpdatePVZ(mock.Anything, valid, models.Kazan, models.IfMatch(nil)).
+			Return(models.PVZ{}, repository.ErrPvzNotFound).
+			Once()
+		c, rec := newRequest("")
+		require.NoError(t, h.UpdatePVZ(c))
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("precondition failed", func(t *testing.T) {
+		svc.EXPECT().
+			UpdatePVZ(mock.Anything, valid, models.Kazan, models.IfMatch{`"old"`}).
+			Return(models.PVZ{}, repository.ErrPreconditionFailed).
+			Once()
+		c, rec := newRequest(`"old"`)
+		require.NoError(t, h.UpdatePVZ(c))
+		require.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		pvz := models.PVZ{ID: valid, City: models.Kazan, Version: 2}
+		svc.EXPECT().
+			UpdatePVZ(mock.Anything, valid, models.Kazan, models.IfMatch{"*"}).
+			Return(pvz, nil).
+			Once()
+		c, rec := newRequest("*")
+		require.NoError(t, h.UpdatePVZ(c))
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, pvz.ETag(), rec.Header().Get(HeaderETag))
+	})
 }
 
 func TestDeleteLastProduct(t *testing.T) {
@@ -389,7 +615,7 @@ func TestDeleteLastProduct(t *testing.T) {
 	t.Run("service error", func(t *testing.T) {
 		valid := "123e4567-e89b-12d3-a456-426655440000"
 		svc.EXPECT().
-			DeleteLastProduct(valid).
+			DeleteLastProduct(mock.Anything, valid).
 			Return(errors.New("err")).
 			Once()
 
@@ -407,7 +633,7 @@ func TestDeleteLastProduct(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		valid := "123e4567-e89b-12d3-a456-426655440000"
 		svc.EXPECT().
-			DeleteLastProduct(valid).
+			DeleteLastProduct(mock.Anything, valid).
 			Return(nil).
 			Once()
 
@@ -430,7 +656,11 @@ func TestGetPVZ(t *testing.T) {
 
 	t.Run("service error", func(t *testing.T) {
 		svc.EXPECT().
-			GetPVZInfo("2025-04-01", "2025-04-20", 1, 10).
+			PVZInfoETag(mock.Anything, "2025-04-01", "2025-04-20", models.ReceptionDate(""), 1, 10).
+			Return(`"tag"`, nil).
+			Once()
+		svc.EXPECT().
+			GetPVZInfo(mock.Anything, "2025-04-01", "2025-04-20", models.ReceptionDate(""), 1, 10).
 			Return(nil, errors.New("oops")).
 			Once()
 
@@ -443,13 +673,29 @@ func TestGetPVZ(t *testing.T) {
 		require.Equal(t, http.StatusInternalServerError, rec.Code)
 	})
 
+	t.Run("etag error", func(t *testing.T) {
+		svc.EXPECT().
+			PVZInfoETag(mock.Anything, "", "", models.ReceptionDate(""), 0, 0).
+			Return("", errors.New("oops")).
+			Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/pvz", nil)
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.GetPVZ(e.NewContext(req, rec)))
+		require.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+
 	t.Run("success empty", func(t *testing.T) {
 		svc.EXPECT().
-			GetPVZInfo("2025-04-01", "2025-04-20", 1, 10).
+			PVZInfoETag(mock.Anything, "2025-04-01", "2025-04-20", models.ByCloseDate, 1, 10).
+			Return(`"tag"`, nil).
+			Once()
+		svc.EXPECT().
+			GetPVZInfo(mock.Anything, "2025-04-01", "2025-04-20", models.ByCloseDate, 1, 10).
 			Return([]models.PVZInfo{}, nil).
 			Once()
 
-		req := httptest.NewRequest(http.MethodGet, "/pvz?startDate=2025-04-01&endDate=2025-04-20&page=1&limit=10", nil)
+		req := httptest.NewRequest(http.MethodGet, "/pvz?startDate=2025-04-01&endDate=2025-04-20&dateBy=close&page=1&limit=10", nil)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
 
@@ -457,5 +703,33 @@ func TestGetPVZ(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, http.StatusOK, rec.Code)
 		require.JSONEq(t, "[]", rec.Body.String())
+		require.Equal(t, `"tag"`, rec.Header().Get(HeaderETag))
+	})
+
+	t.Run("not modified", func(t *testing.T) {
+		list := []models.PVZInfo{{Pvz: models.PVZ{ID: "p1", City: models.Moscow, Version: 1}}}
+		svc.EXPECT().
+			PVZInfoETag(mock.Anything, "", "", models.ReceptionDate(""), 0, 0).
+			Return(`"tag"`, nil).
+			Twice()
+		// the page is only loaded for the first request
+		svc.EXPECT().
+			GetPVZInfo(mock.Anything, "", "", models.ReceptionDate(""), 0, 0).
+			Return(list, nil).
+			Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/pvz", nil)
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.GetPVZ(e.NewContext(req, rec)))
+		require.Equal(t, http.StatusOK, rec.Code)
+		etag := rec.Header().Get(HeaderETag)
+		require.NotEmpty(t, etag)
+
+		req = httptest.NewRequest(http.MethodGet, "/pvz", nil)
+		req.Header.Set(HeaderIfNoneMatch, "W/"+etag)
+		rec = httptest.NewRecorder()
+		require.NoError(t, h.GetPVZ(e.NewContext(req, rec)))
+		require.Equal(t, http.StatusNotModified, rec.Code)
+		require.Empty(t, rec.Body.String())
 	})
 }
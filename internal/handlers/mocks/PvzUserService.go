@@ -3,9 +3,14 @@
 package mocks
 
 import (
-	models "pvz/internal/models"
+	context "context"
+	auth "pvz/internal/auth"
 
 	mock "github.com/stretchr/testify/mock"
+
+	models "pvz/internal/models"
+
+	time "time"
 )
 
 // PvzUserService is an autogenerated mock type for the PvzUserService type
@@ -21,9 +26,154 @@ func (_m *PvzUserService) EXPECT() *PvzUserService_Expecter {
 	return &PvzUserService_Expecter{mock: &_m.Mock}
 }
 
-// CloseLastReception provides a mock function with given fields: pvzID
-func (_m *PvzUserService) CloseLastReception(pvzID string) (models.Reception, error) {
-	ret := _m.Called(pvzID)
+// AssignPVZ provides a mock function with given fields: ctx, userID, pvzID
+func (_m *PvzUserService) AssignPVZ(ctx context.Context, userID string, pvzID string) error {
+	ret := _m.Called(ctx, userID, pvzID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AssignPVZ")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, userID, pvzID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PvzUserService_AssignPVZ_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AssignPVZ'
+type PvzUserService_AssignPVZ_Call struct {
+	*mock.Call
+}
+
+// AssignPVZ is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - pvzID string
+func (_e *PvzUserService_Expecter) AssignPVZ(ctx interface{}, userID interface{}, pvzID interface{}) *PvzUserService_AssignPVZ_Call {
+	return &PvzUserService_AssignPVZ_Call{Call: _e.mock.On("AssignPVZ", ctx, userID, pvzID)}
+}
+
+func (_c *PvzUserService_AssignPVZ_Call) Run(run func(ctx context.Context, userID string, pvzID string)) *PvzUserService_AssignPVZ_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_AssignPVZ_Call) Return(_a0 error) *PvzUserService_AssignPVZ_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *PvzUserService_AssignPVZ_Call) RunAndReturn(run func(context.Context, string, string) error) *PvzUserService_AssignPVZ_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ChangePassword provides a mock function with given fields: ctx, userID, oldPassword, newPassword
+func (_m *PvzUserService) ChangePassword(ctx context.Context, userID string, oldPassword string, newPassword string) error {
+	ret := _m.Called(ctx, userID, oldPassword, newPassword)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ChangePassword")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, userID, oldPassword, newPassword)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PvzUserService_ChangePassword_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ChangePassword'
+type PvzUserService_ChangePassword_Call struct {
+	*mock.Call
+}
+
+// ChangePassword is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - oldPassword string
+//   - newPassword string
+func (_e *PvzUserService_Expecter) ChangePassword(ctx interface{}, userID interface{}, oldPassword interface{}, newPassword interface{}) *PvzUserService_ChangePassword_Call {
+	return &PvzUserService_ChangePassword_Call{Call: _e.mock.On("ChangePassword", ctx, userID, oldPassword, newPassword)}
+}
+
+func (_c *PvzUserService_ChangePassword_Call) Run(run func(ctx context.Context, userID string, oldPassword string, newPassword string)) *PvzUserService_ChangePassword_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_ChangePassword_Call) Return(_a0 error) *PvzUserService_ChangePassword_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *PvzUserService_ChangePassword_Call) RunAndReturn(run func(context.Context, string, string, string) error) *PvzUserService_ChangePassword_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CheckPVZEvents provides a mock function with given fields: ctx, p, pvzID
+func (_m *PvzUserService) CheckPVZEvents(ctx context.Context, p *auth.Principal, pvzID string) error {
+	ret := _m.Called(ctx, p, pvzID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckPVZEvents")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *auth.Principal, string) error); ok {
+		r0 = rf(ctx, p, pvzID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PvzUserService_CheckPVZEvents_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CheckPVZEvents'
+type PvzUserService_CheckPVZEvents_Call struct {
+	*mock.Call
+}
+
+// CheckPVZEvents is a helper method to define mock.On call
+//   - ctx context.Context
+//   - p *auth.Principal
+//   - pvzID string
+func (_e *PvzUserService_Expecter) CheckPVZEvents(ctx interface{}, p interface{}, pvzID interface{}) *PvzUserService_CheckPVZEvents_Call {
+	return &PvzUserService_CheckPVZEvents_Call{Call: _e.mock.On("CheckPVZEvents", ctx, p, pvzID)}
+}
+
+func (_c *PvzUserService_CheckPVZEvents_Call) Run(run func(ctx context.Context, p *auth.Principal, pvzID string)) *PvzUserService_CheckPVZEvents_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*auth.Principal), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_CheckPVZEvents_Call) Return(_a0 error) *PvzUserService_CheckPVZEvents_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *PvzUserService_CheckPVZEvents_Call) RunAndReturn(run func(context.Context, *auth.Principal, string) error) *PvzUserService_CheckPVZEvents_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CloseLastReception provides a mock function with given fields: ctx, actorID, pvzID, ifMatch
+func (_m *PvzUserService) CloseLastReception(ctx context.Context, actorID string, pvzID string, ifMatch models.IfMatch) (models.Reception, error) {
+	ret := _m.Called(ctx, actorID, pvzID, ifMatch)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CloseLastReception")
@@ -31,17 +181,17 @@ func (_m *PvzUserService) CloseLastReception(pvzID string) (models.Reception, er
 
 	var r0 models.Reception
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (models.Reception, error)); ok {
-		return rf(pvzID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, models.IfMatch) (models.Reception, error)); ok {
+		return rf(ctx, actorID, pvzID, ifMatch)
 	}
-	if rf, ok := ret.Get(0).(func(string) models.Reception); ok {
-		r0 = rf(pvzID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, models.IfMatch) models.Reception); ok {
+		r0 = rf(ctx, actorID, pvzID, ifMatch)
 	} else {
 		r0 = ret.Get(0).(models.Reception)
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(pvzID)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, models.IfMatch) error); ok {
+		r1 = rf(ctx, actorID, pvzID, ifMatch)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -55,14 +205,17 @@ type PvzUserService_CloseLastReception_Call struct {
 }
 
 // CloseLastReception is a helper method to define mock.On call
+//   - ctx context.Context
+//   - actorID string
 //   - pvzID string
-func (_e *PvzUserService_Expecter) CloseLastReception(pvzID interface{}) *PvzUserService_CloseLastReception_Call {
-	return &PvzUserService_CloseLastReception_Call{Call: _e.mock.On("CloseLastReception", pvzID)}
+//   - ifMatch models.IfMatch
+func (_e *PvzUserService_Expecter) CloseLastReception(ctx interface{}, actorID interface{}, pvzID interface{}, ifMatch interface{}) *PvzUserService_CloseLastReception_Call {
+	return &PvzUserService_CloseLastReception_Call{Call: _e.mock.On("CloseLastReception", ctx, actorID, pvzID, ifMatch)}
 }
 
-func (_c *PvzUserService_CloseLastReception_Call) Run(run func(pvzID string)) *PvzUserService_CloseLastReception_Call {
+func (_c *PvzUserService_CloseLastReception_Call) Run(run func(ctx context.Context, actorID string, pvzID string, ifMatch models.IfMatch)) *PvzUserService_CloseLastReception_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(models.IfMatch))
 	})
 	return _c
 }
@@ -72,14 +225,74 @@ func (_c *PvzUserService_CloseLastReception_Call) Return(_a0 models.Reception, _
 	return _c
 }
 
-func (_c *PvzUserService_CloseLastReception_Call) RunAndReturn(run func(string) (models.Reception, error)) *PvzUserService_CloseLastReception_Call {
+func (_c *PvzUserService_CloseLastReception_Call) RunAndReturn(run func(context.Context, string, string, models.IfMatch) (models.Reception, error)) *PvzUserService_CloseLastReception_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateAPIKey provides a mock function with given fields: ctx, p, name, scopes, expiresAt
+func (_m *PvzUserService) CreateAPIKey(ctx context.Context, p *auth.Principal, name string, scopes []string, expiresAt *time.Time) (models.NewAPIKey, error) {
+	ret := _m.Called(ctx, p, name, scopes, expiresAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAPIKey")
+	}
+
+	var r0 models.NewAPIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *auth.Principal, string, []string, *time.Time) (models.NewAPIKey, error)); ok {
+		return rf(ctx, p, name, scopes, expiresAt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *auth.Principal, string, []string, *time.Time) models.NewAPIKey); ok {
+		r0 = rf(ctx, p, name, scopes, expiresAt)
+	} else {
+		r0 = ret.Get(0).(models.NewAPIKey)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *auth.Principal, string, []string, *time.Time) error); ok {
+		r1 = rf(ctx, p, name, scopes, expiresAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserService_CreateAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateAPIKey'
+type PvzUserService_CreateAPIKey_Call struct {
+	*mock.Call
+}
+
+// CreateAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - p *auth.Principal
+//   - name string
+//   - scopes []string
+//   - expiresAt *time.Time
+func (_e *PvzUserService_Expecter) CreateAPIKey(ctx interface{}, p interface{}, name interface{}, scopes interface{}, expiresAt interface{}) *PvzUserService_CreateAPIKey_Call {
+	return &PvzUserService_CreateAPIKey_Call{Call: _e.mock.On("CreateAPIKey", ctx, p, name, scopes, expiresAt)}
+}
+
+func (_c *PvzUserService_CreateAPIKey_Call) Run(run func(ctx context.Context, p *auth.Principal, name string, scopes []string, expiresAt *time.Time)) *PvzUserService_CreateAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*auth.Principal), args[2].(string), args[3].([]string), args[4].(*time.Time))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_CreateAPIKey_Call) Return(_a0 models.NewAPIKey, _a1 error) *PvzUserService_CreateAPIKey_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserService_CreateAPIKey_Call) RunAndReturn(run func(context.Context, *auth.Principal, string, []string, *time.Time) (models.NewAPIKey, error)) *PvzUserService_CreateAPIKey_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// CreatePVZ provides a mock function with given fields: city
-func (_m *PvzUserService) CreatePVZ(city models.City) (models.PVZ, error) {
-	ret := _m.Called(city)
+// CreatePVZ provides a mock function with given fields: ctx, city
+func (_m *PvzUserService) CreatePVZ(ctx context.Context, city models.City) (models.PVZ, error) {
+	ret := _m.Called(ctx, city)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreatePVZ")
@@ -87,17 +300,17 @@ func (_m *PvzUserService) CreatePVZ(city models.City) (models.PVZ, error) {
 
 	var r0 models.PVZ
 	var r1 error
-	if rf, ok := ret.Get(0).(func(models.City) (models.PVZ, error)); ok {
-		return rf(city)
+	if rf, ok := ret.Get(0).(func(context.Context, models.City) (models.PVZ, error)); ok {
+		return rf(ctx, city)
 	}
-	if rf, ok := ret.Get(0).(func(models.City) models.PVZ); ok {
-		r0 = rf(city)
+	if rf, ok := ret.Get(0).(func(context.Context, models.City) models.PVZ); ok {
+		r0 = rf(ctx, city)
 	} else {
 		r0 = ret.Get(0).(models.PVZ)
 	}
 
-	if rf, ok := ret.Get(1).(func(models.City) error); ok {
-		r1 = rf(city)
+	if rf, ok := ret.Get(1).(func(context.Context, models.City) error); ok {
+		r1 = rf(ctx, city)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -111,14 +324,15 @@ type PvzUserService_CreatePVZ_Call struct {
 }
 
 // CreatePVZ is a helper method to define mock.On call
+//   - ctx context.Context
 //   - city models.City
-func (_e *PvzUserService_Expecter) CreatePVZ(city interface{}) *PvzUserService_CreatePVZ_Call {
-	return &PvzUserService_CreatePVZ_Call{Call: _e.mock.On("CreatePVZ", city)}
+func (_e *PvzUserService_Expecter) CreatePVZ(ctx interface{}, city interface{}) *PvzUserService_CreatePVZ_Call {
+	return &PvzUserService_CreatePVZ_Call{Call: _e.mock.On("CreatePVZ", ctx, city)}
 }
 
-func (_c *PvzUserService_CreatePVZ_Call) Run(run func(city models.City)) *PvzUserService_CreatePVZ_Call {
+func (_c *PvzUserService_CreatePVZ_Call) Run(run func(ctx context.Context, city models.City)) *PvzUserService_CreatePVZ_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(models.City))
+		run(args[0].(context.Context), args[1].(models.City))
 	})
 	return _c
 }
@@ -128,14 +342,14 @@ func (_c *PvzUserService_CreatePVZ_Call) Return(_a0 models.PVZ, _a1 error) *PvzU
 	return _c
 }
 
-func (_c *PvzUserService_CreatePVZ_Call) RunAndReturn(run func(models.City) (models.PVZ, error)) *PvzUserService_CreatePVZ_Call {
+func (_c *PvzUserService_CreatePVZ_Call) RunAndReturn(run func(context.Context, models.City) (models.PVZ, error)) *PvzUserService_CreatePVZ_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// CreateProduct provides a mock function with given fields: pvzID, prType
-func (_m *PvzUserService) CreateProduct(pvzID string, prType models.ProductType) (models.Product, error) {
-	ret := _m.Called(pvzID, prType)
+// CreateProduct provides a mock function with given fields: ctx, pvzID, prType
+func (_m *PvzUserService) CreateProduct(ctx context.Context, pvzID string, prType models.ProductType) (models.Product, error) {
+	ret := _m.Called(ctx, pvzID, prType)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateProduct")
@@ -143,17 +357,17 @@ func (_m *PvzUserService) CreateProduct(pvzID string, prType models.ProductType)
 
 	var r0 models.Product
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, models.ProductType) (models.Product, error)); ok {
-		return rf(pvzID, prType)
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.ProductType) (models.Product, error)); ok {
+		return rf(ctx, pvzID, prType)
 	}
-	if rf, ok := ret.Get(0).(func(string, models.ProductType) models.Product); ok {
-		r0 = rf(pvzID, prType)
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.ProductType) models.Product); ok {
+		r0 = rf(ctx, pvzID, prType)
 	} else {
 		r0 = ret.Get(0).(models.Product)
 	}
 
-	if rf, ok := ret.Get(1).(func(string, models.ProductType) error); ok {
-		r1 = rf(pvzID, prType)
+	if rf, ok := ret.Get(1).(func(context.Context, string, models.ProductType) error); ok {
+		r1 = rf(ctx, pvzID, prType)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -167,15 +381,16 @@ type PvzUserService_CreateProduct_Call struct {
 }
 
 // CreateProduct is a helper method to define mock.On call
+//   - ctx context.Context
 //   - pvzID string
 //   - prType models.ProductType
-func (_e *PvzUserService_Expecter) CreateProduct(pvzID interface{}, prType interface{}) *PvzUserService_CreateProduct_Call {
-	return &PvzUserService_CreateProduct_Call{Call: _e.mock.On("CreateProduct", pvzID, prType)}
+func (_e *PvzUserService_Expecter) CreateProduct(ctx interface{}, pvzID interface{}, prType interface{}) *PvzUserService_CreateProduct_Call {
+	return &PvzUserService_CreateProduct_Call{Call: _e.mock.On("CreateProduct", ctx, pvzID, prType)}
 }
 
-func (_c *PvzUserService_CreateProduct_Call) Run(run func(pvzID string, prType models.ProductType)) *PvzUserService_CreateProduct_Call {
+func (_c *PvzUserService_CreateProduct_Call) Run(run func(ctx context.Context, pvzID string, prType models.ProductType)) *PvzUserService_CreateProduct_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(models.ProductType))
+		run(args[0].(context.Context), args[1].(string), args[2].(models.ProductType))
 	})
 	return _c
 }
@@ -185,14 +400,14 @@ func (_c *PvzUserService_CreateProduct_Call) Return(_a0 models.Product, _a1 erro
 	return _c
 }
 
-func (_c *PvzUserService_CreateProduct_Call) RunAndReturn(run func(string, models.ProductType) (models.Product, error)) *PvzUserService_CreateProduct_Call {
+func (_c *PvzUserService_CreateProduct_Call) RunAndReturn(run func(context.Context, string, models.ProductType) (models.Product, error)) *PvzUserService_CreateProduct_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// CreateReception provides a mock function with given fields: pvzID
-func (_m *PvzUserService) CreateReception(pvzID string) (models.Reception, error) {
-	ret := _m.Called(pvzID)
+// CreateReception provides a mock function with given fields: ctx, pvzID
+func (_m *PvzUserService) CreateReception(ctx context.Context, pvzID string) (models.Reception, error) {
+	ret := _m.Called(ctx, pvzID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateReception")
@@ -200,17 +415,17 @@ func (_m *PvzUserService) CreateReception(pvzID string) (models.Reception, error
 
 	var r0 models.Reception
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (models.Reception, error)); ok {
-		return rf(pvzID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.Reception, error)); ok {
+		return rf(ctx, pvzID)
 	}
-	if rf, ok := ret.Get(0).(func(string) models.Reception); ok {
-		r0 = rf(pvzID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.Reception); ok {
+		r0 = rf(ctx, pvzID)
 	} else {
 		r0 = ret.Get(0).(models.Reception)
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(pvzID)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, pvzID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -224,14 +439,15 @@ type PvzUserService_CreateReception_Call struct {
 }
 
 // CreateReception is a helper method to define mock.On call
+//   - ctx context.Context
 //   - pvzID string
-func (_e *PvzUserService_Expecter) CreateReception(pvzID interface{}) *PvzUserService_CreateReception_Call {
-	return &PvzUserService_CreateReception_Call{Call: _e.mock.On("CreateReception", pvzID)}
+func (_e *PvzUserService_Expecter) CreateReception(ctx interface{}, pvzID interface{}) *PvzUserService_CreateReception_Call {
+	return &PvzUserService_CreateReception_Call{Call: _e.mock.On("CreateReception", ctx, pvzID)}
 }
 
-func (_c *PvzUserService_CreateReception_Call) Run(run func(pvzID string)) *PvzUserService_CreateReception_Call {
+func (_c *PvzUserService_CreateReception_Call) Run(run func(ctx context.Context, pvzID string)) *PvzUserService_CreateReception_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
@@ -241,22 +457,129 @@ func (_c *PvzUserService_CreateReception_Call) Return(_a0 models.Reception, _a1
 	return _c
 }
 
-func (_c *PvzUserService_CreateReception_Call) RunAndReturn(run func(string) (models.Reception, error)) *PvzUserService_CreateReception_Call {
+func (_c *PvzUserService_CreateReception_Call) RunAndReturn(run func(context.Context, string) (models.Reception, error)) *PvzUserService_CreateReception_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateWebhook provides a mock function with given fields: ctx, actorID, url, events, secret
+func (_m *PvzUserService) CreateWebhook(ctx context.Context, actorID string, url string, events []string, secret string) (models.Webhook, error) {
+	ret := _m.Called(ctx, actorID, url, events, secret)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateWebhook")
+	}
+
+	var r0 models.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []string, string) (models.Webhook, error)); ok {
+		return rf(ctx, actorID, url, events, secret)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []string, string) models.Webhook); ok {
+		r0 = rf(ctx, actorID, url, events, secret)
+	} else {
+		r0 = ret.Get(0).(models.Webhook)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, []string, string) error); ok {
+		r1 = rf(ctx, actorID, url, events, secret)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserService_CreateWebhook_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateWebhook'
+type PvzUserService_CreateWebhook_Call struct {
+	*mock.Call
+}
+
+// CreateWebhook is a helper method to define mock.On call
+//   - ctx context.Context
+//   - actorID string
+//   - url string
+//   - events []string
+//   - secret string
+func (_e *PvzUserService_Expecter) CreateWebhook(ctx interface{}, actorID interface{}, url interface{}, events interface{}, secret interface{}) *PvzUserService_CreateWebhook_Call {
+	return &PvzUserService_CreateWebhook_Call{Call: _e.mock.On("CreateWebhook", ctx, actorID, url, events, secret)}
+}
+
+func (_c *PvzUserService_CreateWebhook_Call) Run(run func(ctx context.Context, actorID string, url string, events []string, secret string)) *PvzUserService_CreateWebhook_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].([]string), args[4].(string))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_CreateWebhook_Call) Return(_a0 models.Webhook, _a1 error) *PvzUserService_CreateWebhook_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserService_CreateWebhook_Call) RunAndReturn(run func(context.Context, string, string, []string, string) (models.Webhook, error)) *PvzUserService_CreateWebhook_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteAPIKey provides a mock function with given fields: ctx, keyID
+func (_m *PvzUserService) DeleteAPIKey(ctx context.Context, keyID string) error {
+	ret := _m.Called(ctx, keyID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteAPIKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, keyID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PvzUserService_DeleteAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteAPIKey'
+type PvzUserService_DeleteAPIKey_Call struct {
+	*mock.Call
+}
+
+// DeleteAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - keyID string
+func (_e *PvzUserService_Expecter) DeleteAPIKey(ctx interface{}, keyID interface{}) *PvzUserService_DeleteAPIKey_Call {
+	return &PvzUserService_DeleteAPIKey_Call{Call: _e.mock.On("DeleteAPIKey", ctx, keyID)}
+}
+
+func (_c *PvzUserService_DeleteAPIKey_Call) Run(run func(ctx context.Context, keyID string)) *PvzUserService_DeleteAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_DeleteAPIKey_Call) Return(_a0 error) *PvzUserService_DeleteAPIKey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *PvzUserService_DeleteAPIKey_Call) RunAndReturn(run func(context.Context, string) error) *PvzUserService_DeleteAPIKey_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DeleteLastProduct provides a mock function with given fields: pvzID
-func (_m *PvzUserService) DeleteLastProduct(pvzID string) error {
-	ret := _m.Called(pvzID)
+// DeleteLastProduct provides a mock function with given fields: ctx, pvzID
+func (_m *PvzUserService) DeleteLastProduct(ctx context.Context, pvzID string) error {
+	ret := _m.Called(ctx, pvzID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteLastProduct")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(pvzID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, pvzID)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -270,14 +593,15 @@ type PvzUserService_DeleteLastProduct_Call struct {
 }
 
 // DeleteLastProduct is a helper method to define mock.On call
+//   - ctx context.Context
 //   - pvzID string
-func (_e *PvzUserService_Expecter) DeleteLastProduct(pvzID interface{}) *PvzUserService_DeleteLastProduct_Call {
-	return &PvzUserService_DeleteLastProduct_Call{Call: _e.mock.On("DeleteLastProduct", pvzID)}
+func (_e *PvzUserService_Expecter) DeleteLastProduct(ctx interface{}, pvzID interface{}) *PvzUserService_DeleteLastProduct_Call {
+	return &PvzUserService_DeleteLastProduct_Call{Call: _e.mock.On("DeleteLastProduct", ctx, pvzID)}
 }
 
-func (_c *PvzUserService_DeleteLastProduct_Call) Run(run func(pvzID string)) *PvzUserService_DeleteLastProduct_Call {
+func (_c *PvzUserService_DeleteLastProduct_Call) Run(run func(ctx context.Context, pvzID string)) *PvzUserService_DeleteLastProduct_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
@@ -287,150 +611,127 @@ func (_c *PvzUserService_DeleteLastProduct_Call) Return(_a0 error) *PvzUserServi
 	return _c
 }
 
-func (_c *PvzUserService_DeleteLastProduct_Call) RunAndReturn(run func(string) error) *PvzUserService_DeleteLastProduct_Call {
+func (_c *PvzUserService_DeleteLastProduct_Call) RunAndReturn(run func(context.Context, string) error) *PvzUserService_DeleteLastProduct_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetPVZInfo provides a mock function with given fields: start, end, page, limit
-func (_m *PvzUserService) GetPVZInfo(start string, end string, page int, limit int) ([]models.PVZInfo, error) {
-	ret := _m.Called(start, end, page, limit)
+// DeleteUser provides a mock function with given fields: ctx, actorID, userID
+func (_m *PvzUserService) DeleteUser(ctx context.Context, actorID string, userID string) error {
+	ret := _m.Called(ctx, actorID, userID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetPVZInfo")
-	}
-
-	var r0 []models.PVZInfo
-	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string, int, int) ([]models.PVZInfo, error)); ok {
-		return rf(start, end, page, limit)
-	}
-	if rf, ok := ret.Get(0).(func(string, string, int, int) []models.PVZInfo); ok {
-		r0 = rf(start, end, page, limit)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]models.PVZInfo)
-		}
+		panic("no return value specified for DeleteUser")
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string, int, int) error); ok {
-		r1 = rf(start, end, page, limit)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, actorID, userID)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// PvzUserService_GetPVZInfo_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPVZInfo'
-type PvzUserService_GetPVZInfo_Call struct {
+// PvzUserService_DeleteUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteUser'
+type PvzUserService_DeleteUser_Call struct {
 	*mock.Call
 }
 
-// GetPVZInfo is a helper method to define mock.On call
-//   - start string
-//   - end string
-//   - page int
-//   - limit int
-func (_e *PvzUserService_Expecter) GetPVZInfo(start interface{}, end interface{}, page interface{}, limit interface{}) *PvzUserService_GetPVZInfo_Call {
-	return &PvzUserService_GetPVZInfo_Call{Call: _e.mock.On("GetPVZInfo", start, end, page, limit)}
+// DeleteUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - actorID string
+//   - userID string
+func (_e *PvzUserService_Expecter) DeleteUser(ctx interface{}, actorID interface{}, userID interface{}) *PvzUserService_DeleteUser_Call {
+	return &PvzUserService_DeleteUser_Call{Call: _e.mock.On("DeleteUser", ctx, actorID, userID)}
 }
 
-func (_c *PvzUserService_GetPVZInfo_Call) Run(run func(start string, end string, page int, limit int)) *PvzUserService_GetPVZInfo_Call {
+func (_c *PvzUserService_DeleteUser_Call) Run(run func(ctx context.Context, actorID string, userID string)) *PvzUserService_DeleteUser_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(string), args[2].(int), args[3].(int))
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
 	})
 	return _c
 }
 
-func (_c *PvzUserService_GetPVZInfo_Call) Return(_a0 []models.PVZInfo, _a1 error) *PvzUserService_GetPVZInfo_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *PvzUserService_DeleteUser_Call) Return(_a0 error) *PvzUserService_DeleteUser_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *PvzUserService_GetPVZInfo_Call) RunAndReturn(run func(string, string, int, int) ([]models.PVZInfo, error)) *PvzUserService_GetPVZInfo_Call {
+func (_c *PvzUserService_DeleteUser_Call) RunAndReturn(run func(context.Context, string, string) error) *PvzUserService_DeleteUser_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// LoginUser provides a mock function with given fields: email, password
-func (_m *PvzUserService) LoginUser(email string, password string) (models.Token, error) {
-	ret := _m.Called(email, password)
+// DeleteWebhook provides a mock function with given fields: ctx, webhookID
+func (_m *PvzUserService) DeleteWebhook(ctx context.Context, webhookID string) error {
+	ret := _m.Called(ctx, webhookID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for LoginUser")
-	}
-
-	var r0 models.Token
-	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string) (models.Token, error)); ok {
-		return rf(email, password)
-	}
-	if rf, ok := ret.Get(0).(func(string, string) models.Token); ok {
-		r0 = rf(email, password)
-	} else {
-		r0 = ret.Get(0).(models.Token)
+		panic("no return value specified for DeleteWebhook")
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(email, password)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, webhookID)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// PvzUserService_LoginUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LoginUser'
-type PvzUserService_LoginUser_Call struct {
+// PvzUserService_DeleteWebhook_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteWebhook'
+type PvzUserService_DeleteWebhook_Call struct {
 	*mock.Call
 }
 
-// LoginUser is a helper method to define mock.On call
-//   - email string
-//   - password string
-func (_e *PvzUserService_Expecter) LoginUser(email interface{}, password interface{}) *PvzUserService_LoginUser_Call {
-	return &PvzUserService_LoginUser_Call{Call: _e.mock.On("LoginUser", email, password)}
+// DeleteWebhook is a helper method to define mock.On call
+//   - ctx context.Context
+//   - webhookID string
+func (_e *PvzUserService_Expecter) DeleteWebhook(ctx interface{}, webhookID interface{}) *PvzUserService_DeleteWebhook_Call {
+	return &PvzUserService_DeleteWebhook_Call{Call: _e.mock.On("DeleteWebhook", ctx, webhookID)}
 }
 
-func (_c *PvzUserService_LoginUser_Call) Run(run func(email string, password string)) *PvzUserService_LoginUser_Call {
+func (_c *PvzUserService_DeleteWebhook_Call) Run(run func(ctx context.Context, webhookID string)) *PvzUserService_DeleteWebhook_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *PvzUserService_LoginUser_Call) Return(_a0 models.Token, _a1 error) *PvzUserService_LoginUser_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *PvzUserService_DeleteWebhook_Call) Return(_a0 error) *PvzUserService_DeleteWebhook_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *PvzUserService_LoginUser_Call) RunAndReturn(run func(string, string) (models.Token, error)) *PvzUserService_LoginUser_Call {
+func (_c *PvzUserService_DeleteWebhook_Call) RunAndReturn(run func(context.Context, string) error) *PvzUserService_DeleteWebhook_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// RegisterUser provides a mock function with given fields: email, password, role
-func (_m *PvzUserService) RegisterUser(email string, password string, role models.Role) (models.User, error) {
-	ret := _m.Called(email, password, role)
+// DummyLogin provides a mock function with given fields: ctx, role
+func (_m *PvzUserService) DummyLogin(ctx context.Context, role models.Role) (models.Token, error) {
+	ret := _m.Called(ctx, role)
 
 	if len(ret) == 0 {
-		panic("no return value specified for RegisterUser")
+		panic("no return value specified for DummyLogin")
 	}
 
-	var r0 models.User
+	var r0 models.Token
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string, models.Role) (models.User, error)); ok {
-		return rf(email, password, role)
+	if rf, ok := ret.Get(0).(func(context.Context, models.Role) (models.Token, error)); ok {
+		return rf(ctx, role)
 	}
-	if rf, ok := ret.Get(0).(func(string, string, models.Role) models.User); ok {
-		r0 = rf(email, password, role)
+	if rf, ok := ret.Get(0).(func(context.Context, models.Role) models.Token); ok {
+		r0 = rf(ctx, role)
 	} else {
-		r0 = ret.Get(0).(models.User)
+		r0 = ret.Get(0).(models.Token)
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string, models.Role) error); ok {
-		r1 = rf(email, password, role)
+	if rf, ok := ret.Get(1).(func(context.Context, models.Role) error); ok {
+		r1 = rf(ctx, role)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -438,32 +739,1394 @@ func (_m *PvzUserService) RegisterUser(email string, password string, role model
 	return r0, r1
 }
 
-// PvzUserService_RegisterUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RegisterUser'
-type PvzUserService_RegisterUser_Call struct {
+// PvzUserService_DummyLogin_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DummyLogin'
+type PvzUserService_DummyLogin_Call struct {
 	*mock.Call
 }
 
-// RegisterUser is a helper method to define mock.On call
-//   - email string
-//   - password string
+// DummyLogin is a helper method to define mock.On call
+//   - ctx context.Context
 //   - role models.Role
-func (_e *PvzUserService_Expecter) RegisterUser(email interface{}, password interface{}, role interface{}) *PvzUserService_RegisterUser_Call {
-	return &PvzUserService_RegisterUser_Call{Call: _e.mock.On("RegisterUser", email, password, role)}
+func (_e *PvzUserService_Expecter) DummyLogin(ctx interface{}, role interface{}) *PvzUserService_DummyLogin_Call {
+	return &PvzUserService_DummyLogin_Call{Call: _e.mock.On("DummyLogin", ctx, role)}
 }
 
-func (_c *PvzUserService_RegisterUser_Call) Run(run func(email string, password string, role models.Role)) *PvzUserService_RegisterUser_Call {
+func (_c *PvzUserService_DummyLogin_Call) Run(run func(ctx context.Context, role models.Role)) *PvzUserService_DummyLogin_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(string), args[2].(models.Role))
+		run(args[0].(context.Context), args[1].(models.Role))
 	})
 	return _c
 }
 
-func (_c *PvzUserService_RegisterUser_Call) Return(_a0 models.User, _a1 error) *PvzUserService_RegisterUser_Call {
+func (_c *PvzUserService_DummyLogin_Call) Return(_a0 models.Token, _a1 error) *PvzUserService_DummyLogin_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserService_DummyLogin_Call) RunAndReturn(run func(context.Context, models.Role) (models.Token, error)) *PvzUserService_DummyLogin_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ExportProducts provides a mock function with given fields: ctx, start, end, fn
+func (_m *PvzUserService) ExportProducts(ctx context.Context, start string, end string, fn func(models.ExportProduct) error) error {
+	ret := _m.Called(ctx, start, end, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportProducts")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, func(models.ExportProduct) error) error); ok {
+		r0 = rf(ctx, start, end, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PvzUserService_ExportProducts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExportProducts'
+type PvzUserService_ExportProducts_Call struct {
+	*mock.Call
+}
+
+// ExportProducts is a helper method to define mock.On call
+//   - ctx context.Context
+//   - start string
+//   - end string
+//   - fn func(models.ExportProduct) error
+func (_e *PvzUserService_Expecter) ExportProducts(ctx interface{}, start interface{}, end interface{}, fn interface{}) *PvzUserService_ExportProducts_Call {
+	return &PvzUserService_ExportProducts_Call{Call: _e.mock.On("ExportProducts", ctx, start, end, fn)}
+}
+
+func (_c *PvzUserService_ExportProducts_Call) Run(run func(ctx context.Context, start string, end string, fn func(models.ExportProduct) error)) *PvzUserService_ExportProducts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(func(models.ExportProduct) error))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_ExportProducts_Call) Return(_a0 error) *PvzUserService_ExportProducts_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *PvzUserService_ExportProducts_Call) RunAndReturn(run func(context.Context, string, string, func(models.ExportProduct) error) error) *PvzUserService_ExportProducts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ExportReceptions provides a mock function with given fields: ctx, start, end, fn
+func (_m *PvzUserService) ExportReceptions(ctx context.Context, start string, end string, fn func(models.ExportReception) error) error {
+	ret := _m.Called(ctx, start, end, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportReceptions")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, func(models.ExportReception) error) error); ok {
+		r0 = rf(ctx, start, end, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PvzUserService_ExportReceptions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExportReceptions'
+type PvzUserService_ExportReceptions_Call struct {
+	*mock.Call
+}
+
+// ExportReceptions is a helper method to define mock.On call
+//   - ctx context.Context
+//   - start string
+//   - end string
+//   - fn func(models.ExportReception) error
+func (_e *PvzUserService_Expecter) ExportReceptions(ctx interface{}, start interface{}, end interface{}, fn interface{}) *PvzUserService_ExportReceptions_Call {
+	return &PvzUserService_ExportReceptions_Call{Call: _e.mock.On("ExportReceptions", ctx, start, end, fn)}
+}
+
+func (_c *PvzUserService_ExportReceptions_Call) Run(run func(ctx context.Context, start string, end string, fn func(models.ExportReception) error)) *PvzUserService_ExportReceptions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(func(models.ExportReception) error))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_ExportReceptions_Call) Return(_a0 error) *PvzUserService_ExportReceptions_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *PvzUserService_ExportReceptions_Call) RunAndReturn(run func(context.Context, string, string, func(models.ExportReception) error) error) *PvzUserService_ExportReceptions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetPVZInfo provides a mock function with given fields: ctx, start, end, by, page, limit
+func (_m *PvzUserService) GetPVZInfo(ctx context.Context, start string, end string, by models.ReceptionDate, page int, limit int) ([]models.PVZInfo, error) {
+	ret := _m.Called(ctx, start, end, by, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPVZInfo")
+	}
+
+	var r0 []models.PVZInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, models.ReceptionDate, int, int) ([]models.PVZInfo, error)); ok {
+		return rf(ctx, start, end, by, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, models.ReceptionDate, int, int) []models.PVZInfo); ok {
+		r0 = rf(ctx, start, end, by, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.PVZInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, models.ReceptionDate, int, int) error); ok {
+		r1 = rf(ctx, start, end, by, page, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserService_GetPVZInfo_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPVZInfo'
+type PvzUserService_GetPVZInfo_Call struct {
+	*mock.Call
+}
+
+// GetPVZInfo is a helper method to define mock.On call
+//   - ctx context.Context
+//   - start string
+//   - end string
+//   - by models.ReceptionDate
+//   - page int
+//   - limit int
+func (_e *PvzUserService_Expecter) GetPVZInfo(ctx interface{}, start interface{}, end interface{}, by interface{}, page interface{}, limit interface{}) *PvzUserService_GetPVZInfo_Call {
+	return &PvzUserService_GetPVZInfo_Call{Call: _e.mock.On("GetPVZInfo", ctx, start, end, by, page, limit)}
+}
+
+func (_c *PvzUserService_GetPVZInfo_Call) Run(run func(ctx context.Context, start string, end string, by models.ReceptionDate, page int, limit int)) *PvzUserService_GetPVZInfo_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(models.ReceptionDate), args[4].(int), args[5].(int))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_GetPVZInfo_Call) Return(_a0 []models.PVZInfo, _a1 error) *PvzUserService_GetPVZInfo_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserService_GetPVZInfo_Call) RunAndReturn(run func(context.Context, string, string, models.ReceptionDate, int, int) ([]models.PVZInfo, error)) *PvzUserService_GetPVZInfo_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUser provides a mock function with given fields: ctx, userID
+func (_m *PvzUserService) GetUser(ctx context.Context, userID string) (models.User, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUser")
+	}
+
+	var r0 models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.User, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.User); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(models.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserService_GetUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUser'
+type PvzUserService_GetUser_Call struct {
+	*mock.Call
+}
+
+// GetUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *PvzUserService_Expecter) GetUser(ctx interface{}, userID interface{}) *PvzUserService_GetUser_Call {
+	return &PvzUserService_GetUser_Call{Call: _e.mock.On("GetUser", ctx, userID)}
+}
+
+func (_c *PvzUserService_GetUser_Call) Run(run func(ctx context.Context, userID string)) *PvzUserService_GetUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_GetUser_Call) Return(_a0 models.User, _a1 error) *PvzUserService_GetUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserService_GetUser_Call) RunAndReturn(run func(context.Context, string) (models.User, error)) *PvzUserService_GetUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ImportPVZs provides a mock function with given fields: ctx, rows, rowErrs, dryRun
+func (_m *PvzUserService) ImportPVZs(ctx context.Context, rows []models.PVZImportRow, rowErrs []models.PVZImportError, dryRun bool) (models.PVZImportReport, error) {
+	ret := _m.Called(ctx, rows, rowErrs, dryRun)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportPVZs")
+	}
+
+	var r0 models.PVZImportReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []models.PVZImportRow, []models.PVZImportError, bool) (models.PVZImportReport, error)); ok {
+		return rf(ctx, rows, rowErrs, dryRun)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []models.PVZImportRow, []models.PVZImportError, bool) models.PVZImportReport); ok {
+		r0 = rf(ctx, rows, rowErrs, dryRun)
+	} else {
+		r0 = ret.Get(0).(models.PVZImportReport)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []models.PVZImportRow, []models.PVZImportError, bool) error); ok {
+		r1 = rf(ctx, rows, rowErrs, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserService_ImportPVZs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ImportPVZs'
+type PvzUserService_ImportPVZs_Call struct {
+	*mock.Call
+}
+
+// ImportPVZs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - rows []models.PVZImportRow
+//   - rowErrs []models.PVZImportError
+//   - dryRun bool
+func (_e *PvzUserService_Expecter) ImportPVZs(ctx interface{}, rows interface{}, rowErrs interface{}, dryRun interface{}) *PvzUserService_ImportPVZs_Call {
+	return &PvzUserService_ImportPVZs_Call{Call: _e.mock.On("ImportPVZs", ctx, rows, rowErrs, dryRun)}
+}
+
+func (_c *PvzUserService_ImportPVZs_Call) Run(run func(ctx context.Context, rows []models.PVZImportRow, rowErrs []models.PVZImportError, dryRun bool)) *PvzUserService_ImportPVZs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]models.PVZImportRow), args[2].([]models.PVZImportError), args[3].(bool))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_ImportPVZs_Call) Return(_a0 models.PVZImportReport, _a1 error) *PvzUserService_ImportPVZs_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserService_ImportPVZs_Call) RunAndReturn(run func(context.Context, []models.PVZImportRow, []models.PVZImportError, bool) (models.PVZImportReport, error)) *PvzUserService_ImportPVZs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IntakeReport provides a mock function with given fields: ctx, start, end, groupBy
+func (_m *PvzUserService) IntakeReport(ctx context.Context, start string, end string, groupBy []string) (models.IntakeReport, error) {
+	ret := _m.Called(ctx, start, end, groupBy)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IntakeReport")
+	}
+
+	var r0 models.IntakeReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []string) (models.IntakeReport, error)); ok {
+		return rf(ctx, start, end, groupBy)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []string) models.IntakeReport); ok {
+		r0 = rf(ctx, start, end, groupBy)
+	} else {
+		r0 = ret.Get(0).(models.IntakeReport)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, []string) error); ok {
+		r1 = rf(ctx, start, end, groupBy)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserService_IntakeReport_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IntakeReport'
+type PvzUserService_IntakeReport_Call struct {
+	*mock.Call
+}
+
+// IntakeReport is a helper method to define mock.On call
+//   - ctx context.Context
+//   - start string
+//   - end string
+//   - groupBy []string
+func (_e *PvzUserService_Expecter) IntakeReport(ctx interface{}, start interface{}, end interface{}, groupBy interface{}) *PvzUserService_IntakeReport_Call {
+	return &PvzUserService_IntakeReport_Call{Call: _e.mock.On("IntakeReport", ctx, start, end, groupBy)}
+}
+
+func (_c *PvzUserService_IntakeReport_Call) Run(run func(ctx context.Context, start string, end string, groupBy []string)) *PvzUserService_IntakeReport_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].([]string))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_IntakeReport_Call) Return(_a0 models.IntakeReport, _a1 error) *PvzUserService_IntakeReport_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserService_IntakeReport_Call) RunAndReturn(run func(context.Context, string, string, []string) (models.IntakeReport, error)) *PvzUserService_IntakeReport_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListAPIKeys provides a mock function with given fields: ctx
+func (_m *PvzUserService) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAPIKeys")
+	}
+
+	var r0 []models.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]models.APIKey, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []models.APIKey); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserService_ListAPIKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListAPIKeys'
+type PvzUserService_ListAPIKeys_Call struct {
+	*mock.Call
+}
+
+// ListAPIKeys is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *PvzUserService_Expecter) ListAPIKeys(ctx interface{}) *PvzUserService_ListAPIKeys_Call {
+	return &PvzUserService_ListAPIKeys_Call{Call: _e.mock.On("ListAPIKeys", ctx)}
+}
+
+func (_c *PvzUserService_ListAPIKeys_Call) Run(run func(ctx context.Context)) *PvzUserService_ListAPIKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_ListAPIKeys_Call) Return(_a0 []models.APIKey, _a1 error) *PvzUserService_ListAPIKeys_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserService_ListAPIKeys_Call) RunAndReturn(run func(context.Context) ([]models.APIKey, error)) *PvzUserService_ListAPIKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListUsers provides a mock function with given fields: ctx, role, page, limit
+func (_m *PvzUserService) ListUsers(ctx context.Context, role models.Role, page int, limit int) ([]models.User, error) {
+	ret := _m.Called(ctx, role, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListUsers")
+	}
+
+	var r0 []models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.Role, int, int) ([]models.User, error)); ok {
+		return rf(ctx, role, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.Role, int, int) []models.User); ok {
+		r0 = rf(ctx, role, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.Role, int, int) error); ok {
+		r1 = rf(ctx, role, page, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserService_ListUsers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListUsers'
+type PvzUserService_ListUsers_Call struct {
+	*mock.Call
+}
+
+// ListUsers is a helper method to define mock.On call
+//   - ctx context.Context
+//   - role models.Role
+//   - page int
+//   - limit int
+func (_e *PvzUserService_Expecter) ListUsers(ctx interface{}, role interface{}, page interface{}, limit interface{}) *PvzUserService_ListUsers_Call {
+	return &PvzUserService_ListUsers_Call{Call: _e.mock.On("ListUsers", ctx, role, page, limit)}
+}
+
+func (_c *PvzUserService_ListUsers_Call) Run(run func(ctx context.Context, role models.Role, page int, limit int)) *PvzUserService_ListUsers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.Role), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_ListUsers_Call) Return(_a0 []models.User, _a1 error) *PvzUserService_ListUsers_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserService_ListUsers_Call) RunAndReturn(run func(context.Context, models.Role, int, int) ([]models.User, error)) *PvzUserService_ListUsers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListWebhookDeliveries provides a mock function with given fields: ctx, webhookID, page, limit
+func (_m *PvzUserService) ListWebhookDeliveries(ctx context.Context, webhookID string, page int, limit int) ([]models.WebhookDelivery, error) {
+	ret := _m.Called(ctx, webhookID, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListWebhookDeliveries")
+	}
+
+	var r0 []models.WebhookDelivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) ([]models.WebhookDelivery, error)); ok {
+		return rf(ctx, webhookID, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) []models.WebhookDelivery); ok {
+		r0 = rf(ctx, webhookID, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.WebhookDelivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, int) error); ok {
+		r1 = rf(ctx, webhookID, page, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserService_ListWebhookDeliveries_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListWebhookDeliveries'
+type PvzUserService_ListWebhookDeliveries_Call struct {
+	*mock.Call
+}
+
+// ListWebhookDeliveries is a helper method to define mock.On call
+//   - ctx context.Context
+//   - webhookID string
+//   - page int
+//   - limit int
+func (_e *PvzUserService_Expecter) ListWebhookDeliveries(ctx interface{}, webhookID interface{}, page interface{}, limit interface{}) *PvzUserService_ListWebhookDeliveries_Call {
+	return &PvzUserService_ListWebhookDeliveries_Call{Call: _e.mock.On("ListWebhookDeliveries", ctx, webhookID, page, limit)}
+}
+
+func (_c *PvzUserService_ListWebhookDeliveries_Call) Run(run func(ctx context.Context, webhookID string, page int, limit int)) *PvzUserService_ListWebhookDeliveries_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_ListWebhookDeliveries_Call) Return(_a0 []models.WebhookDelivery, _a1 error) *PvzUserService_ListWebhookDeliveries_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserService_ListWebhookDeliveries_Call) RunAndReturn(run func(context.Context, string, int, int) ([]models.WebhookDelivery, error)) *PvzUserService_ListWebhookDeliveries_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListWebhooks provides a mock function with given fields: ctx
+func (_m *PvzUserService) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListWebhooks")
+	}
+
+	var r0 []models.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]models.Webhook, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []models.Webhook); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Webhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserService_ListWebhooks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListWebhooks'
+type PvzUserService_ListWebhooks_Call struct {
+	*mock.Call
+}
+
+// ListWebhooks is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *PvzUserService_Expecter) ListWebhooks(ctx interface{}) *PvzUserService_ListWebhooks_Call {
+	return &PvzUserService_ListWebhooks_Call{Call: _e.mock.On("ListWebhooks", ctx)}
+}
+
+func (_c *PvzUserService_ListWebhooks_Call) Run(run func(ctx context.Context)) *PvzUserService_ListWebhooks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_ListWebhooks_Call) Return(_a0 []models.Webhook, _a1 error) *PvzUserService_ListWebhooks_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserService_ListWebhooks_Call) RunAndReturn(run func(context.Context) ([]models.Webhook, error)) *PvzUserService_ListWebhooks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LoginUser provides a mock function with given fields: ctx, email, password
+func (_m *PvzUserService) LoginUser(ctx context.Context, email string, password string) (models.Token, error) {
+	ret := _m.Called(ctx, email, password)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LoginUser")
+	}
+
+	var r0 models.Token
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (models.Token, error)); ok {
+		return rf(ctx, email, password)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) models.Token); ok {
+		r0 = rf(ctx, email, password)
+	} else {
+		r0 = ret.Get(0).(models.Token)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, email, password)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserService_LoginUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LoginUser'
+type PvzUserService_LoginUser_Call struct {
+	*mock.Call
+}
+
+// LoginUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+//   - password string
+func (_e *PvzUserService_Expecter) LoginUser(ctx interface{}, email interface{}, password interface{}) *PvzUserService_LoginUser_Call {
+	return &PvzUserService_LoginUser_Call{Call: _e.mock.On("LoginUser", ctx, email, password)}
+}
+
+func (_c *PvzUserService_LoginUser_Call) Run(run func(ctx context.Context, email string, password string)) *PvzUserService_LoginUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_LoginUser_Call) Return(_a0 models.Token, _a1 error) *PvzUserService_LoginUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserService_LoginUser_Call) RunAndReturn(run func(context.Context, string, string) (models.Token, error)) *PvzUserService_LoginUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Me provides a mock function with given fields: ctx, userID
+func (_m *PvzUserService) Me(ctx context.Context, userID string) (models.Me, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Me")
+	}
+
+	var r0 models.Me
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.Me, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.Me); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(models.Me)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserService_Me_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Me'
+type PvzUserService_Me_Call struct {
+	*mock.Call
+}
+
+// Me is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *PvzUserService_Expecter) Me(ctx interface{}, userID interface{}) *PvzUserService_Me_Call {
+	return &PvzUserService_Me_Call{Call: _e.mock.On("Me", ctx, userID)}
+}
+
+func (_c *PvzUserService_Me_Call) Run(run func(ctx context.Context, userID string)) *PvzUserService_Me_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_Me_Call) Return(_a0 models.Me, _a1 error) *PvzUserService_Me_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserService_Me_Call) RunAndReturn(run func(context.Context, string) (models.Me, error)) *PvzUserService_Me_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// OIDCLogin provides a mock function with given fields: ctx, rawToken
+func (_m *PvzUserService) OIDCLogin(ctx context.Context, rawToken string) (models.Token, error) {
+	ret := _m.Called(ctx, rawToken)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OIDCLogin")
+	}
+
+	var r0 models.Token
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.Token, error)); ok {
+		return rf(ctx, rawToken)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.Token); ok {
+		r0 = rf(ctx, rawToken)
+	} else {
+		r0 = ret.Get(0).(models.Token)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, rawToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserService_OIDCLogin_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'OIDCLogin'
+type PvzUserService_OIDCLogin_Call struct {
+	*mock.Call
+}
+
+// OIDCLogin is a helper method to define mock.On call
+//   - ctx context.Context
+//   - rawToken string
+func (_e *PvzUserService_Expecter) OIDCLogin(ctx interface{}, rawToken interface{}) *PvzUserService_OIDCLogin_Call {
+	return &PvzUserService_OIDCLogin_Call{Call: _e.mock.On("OIDCLogin", ctx, rawToken)}
+}
+
+func (_c *PvzUserService_OIDCLogin_Call) Run(run func(ctx context.Context, rawToken string)) *PvzUserService_OIDCLogin_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_OIDCLogin_Call) Return(_a0 models.Token, _a1 error) *PvzUserService_OIDCLogin_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserService_OIDCLogin_Call) RunAndReturn(run func(context.Context, string) (models.Token, error)) *PvzUserService_OIDCLogin_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// OpenPVZEvents provides a mock function with given fields: ctx, p, pvzID
+func (_m *PvzUserService) OpenPVZEvents(ctx context.Context, p *auth.Principal, pvzID string) (int64, error) {
+	ret := _m.Called(ctx, p, pvzID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OpenPVZEvents")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *auth.Principal, string) (int64, error)); ok {
+		return rf(ctx, p, pvzID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *auth.Principal, string) int64); ok {
+		r0 = rf(ctx, p, pvzID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *auth.Principal, string) error); ok {
+		r1 = rf(ctx, p, pvzID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserService_OpenPVZEvents_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'OpenPVZEvents'
+type PvzUserService_OpenPVZEvents_Call struct {
+	*mock.Call
+}
+
+// OpenPVZEvents is a helper method to define mock.On call
+//   - ctx context.Context
+//   - p *auth.Principal
+//   - pvzID string
+func (_e *PvzUserService_Expecter) OpenPVZEvents(ctx interface{}, p interface{}, pvzID interface{}) *PvzUserService_OpenPVZEvents_Call {
+	return &PvzUserService_OpenPVZEvents_Call{Call: _e.mock.On("OpenPVZEvents", ctx, p, pvzID)}
+}
+
+func (_c *PvzUserService_OpenPVZEvents_Call) Run(run func(ctx context.Context, p *auth.Principal, pvzID string)) *PvzUserService_OpenPVZEvents_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*auth.Principal), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_OpenPVZEvents_Call) Return(_a0 int64, _a1 error) *PvzUserService_OpenPVZEvents_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserService_OpenPVZEvents_Call) RunAndReturn(run func(context.Context, *auth.Principal, string) (int64, error)) *PvzUserService_OpenPVZEvents_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PVZEvents provides a mock function with given fields: ctx, pvzID, afterSeq, limit
+func (_m *PvzUserService) PVZEvents(ctx context.Context, pvzID string, afterSeq int64, limit int) ([]models.Event, error) {
+	ret := _m.Called(ctx, pvzID, afterSeq, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PVZEvents")
+	}
+
+	var r0 []models.Event
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, int) ([]models.Event, error)); ok {
+		return rf(ctx, pvzID, afterSeq, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, int) []models.Event); ok {
+		r0 = rf(ctx, pvzID, afterSeq, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Event)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64, int) error); ok {
+		r1 = rf(ctx, pvzID, afterSeq, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserService_PVZEvents_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PVZEvents'
+type PvzUserService_PVZEvents_Call struct {
+	*mock.Call
+}
+
+// PVZEvents is a helper method to define mock.On call
+//   - ctx context.Context
+//   - pvzID string
+//   - afterSeq int64
+//   - limit int
+func (_e *PvzUserService_Expecter) PVZEvents(ctx interface{}, pvzID interface{}, afterSeq interface{}, limit interface{}) *PvzUserService_PVZEvents_Call {
+	return &PvzUserService_PVZEvents_Call{Call: _e.mock.On("PVZEvents", ctx, pvzID, afterSeq, limit)}
+}
+
+func (_c *PvzUserService_PVZEvents_Call) Run(run func(ctx context.Context, pvzID string, afterSeq int64, limit int)) *PvzUserService_PVZEvents_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int64), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_PVZEvents_Call) Return(_a0 []models.Event, _a1 error) *PvzUserService_PVZEvents_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserService_PVZEvents_Call) RunAndReturn(run func(context.Context, string, int64, int) ([]models.Event, error)) *PvzUserService_PVZEvents_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PVZInfoETag provides a mock function with given fields: ctx, start, end, by, page, limit
+func (_m *PvzUserService) PVZInfoETag(ctx context.Context, start string, end string, by models.ReceptionDate, page int, limit int) (string, error) {
+	ret := _m.Called(ctx, start, end, by, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PVZInfoETag")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, models.ReceptionDate, int, int) (string, error)); ok {
+		return rf(ctx, start, end, by, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, models.ReceptionDate, int, int) string); ok {
+		r0 = rf(ctx, start, end, by, page, limit)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, models.ReceptionDate, int, int) error); ok {
+		r1 = rf(ctx, start, end, by, page, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserService_PVZInfoETag_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PVZInfoETag'
+type PvzUserService_PVZInfoETag_Call struct {
+	*mock.Call
+}
+
+// PVZInfoETag is a helper method to define mock.On call
+//   - ctx context.Context
+//   - start string
+//   - end string
+//   - by models.ReceptionDate
+//   - page int
+//   - limit int
+func (_e *PvzUserService_Expecter) PVZInfoETag(ctx interface{}, start interface{}, end interface{}, by interface{}, page interface{}, limit interface{}) *PvzUserService_PVZInfoETag_Call {
+	return &PvzUserService_PVZInfoETag_Call{Call: _e.mock.On("PVZInfoETag", ctx, start, end, by, page, limit)}
+}
+
+func (_c *PvzUserService_PVZInfoETag_Call) Run(run func(ctx context.Context, start string, end string, by models.ReceptionDate, page int, limit int)) *PvzUserService_PVZInfoETag_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(models.ReceptionDate), args[4].(int), args[5].(int))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_PVZInfoETag_Call) Return(_a0 string, _a1 error) *PvzUserService_PVZInfoETag_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserService_PVZInfoETag_Call) RunAndReturn(run func(context.Context, string, string, models.ReceptionDate, int, int) (string, error)) *PvzUserService_PVZInfoETag_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReceptionStats provides a mock function with given fields: ctx, start, end, pvzID
+func (_m *PvzUserService) ReceptionStats(ctx context.Context, start string, end string, pvzID string) (models.ReceptionStatsReport, error) {
+	ret := _m.Called(ctx, start, end, pvzID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReceptionStats")
+	}
+
+	var r0 models.ReceptionStatsReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (models.ReceptionStatsReport, error)); ok {
+		return rf(ctx, start, end, pvzID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) models.ReceptionStatsReport); ok {
+		r0 = rf(ctx, start, end, pvzID)
+	} else {
+		r0 = ret.Get(0).(models.ReceptionStatsReport)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, start, end, pvzID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserService_ReceptionStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReceptionStats'
+type PvzUserService_ReceptionStats_Call struct {
+	*mock.Call
+}
+
+// ReceptionStats is a helper method to define mock.On call
+//   - ctx context.Context
+//   - start string
+//   - end string
+//   - pvzID string
+func (_e *PvzUserService_Expecter) ReceptionStats(ctx interface{}, start interface{}, end interface{}, pvzID interface{}) *PvzUserService_ReceptionStats_Call {
+	return &PvzUserService_ReceptionStats_Call{Call: _e.mock.On("ReceptionStats", ctx, start, end, pvzID)}
+}
+
+func (_c *PvzUserService_ReceptionStats_Call) Run(run func(ctx context.Context, start string, end string, pvzID string)) *PvzUserService_ReceptionStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_ReceptionStats_Call) Return(_a0 models.ReceptionStatsReport, _a1 error) *PvzUserService_ReceptionStats_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserService_ReceptionStats_Call) RunAndReturn(run func(context.Context, string, string, string) (models.ReceptionStatsReport, error)) *PvzUserService_ReceptionStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RegisterUser provides a mock function with given fields: ctx, email, password, role
+func (_m *PvzUserService) RegisterUser(ctx context.Context, email string, password string, role models.Role) (models.User, error) {
+	ret := _m.Called(ctx, email, password, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RegisterUser")
+	}
+
+	var r0 models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, models.Role) (models.User, error)); ok {
+		return rf(ctx, email, password, role)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, models.Role) models.User); ok {
+		r0 = rf(ctx, email, password, role)
+	} else {
+		r0 = ret.Get(0).(models.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, models.Role) error); ok {
+		r1 = rf(ctx, email, password, role)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserService_RegisterUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RegisterUser'
+type PvzUserService_RegisterUser_Call struct {
+	*mock.Call
+}
+
+// RegisterUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+//   - password string
+//   - role models.Role
+func (_e *PvzUserService_Expecter) RegisterUser(ctx interface{}, email interface{}, password interface{}, role interface{}) *PvzUserService_RegisterUser_Call {
+	return &PvzUserService_RegisterUser_Call{Call: _e.mock.On("RegisterUser", ctx, email, password, role)}
+}
+
+func (_c *PvzUserService_RegisterUser_Call) Run(run func(ctx context.Context, email string, password string, role models.Role)) *PvzUserService_RegisterUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(models.Role))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_RegisterUser_Call) Return(_a0 models.User, _a1 error) *PvzUserService_RegisterUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserService_RegisterUser_Call) RunAndReturn(run func(context.Context, string, string, models.Role) (models.User, error)) *PvzUserService_RegisterUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReopenLastReception provides a mock function with given fields: ctx, pvzID, ifMatch
+func (_m *PvzUserService) ReopenLastReception(ctx context.Context, pvzID string, ifMatch models.IfMatch) (models.Reception, error) {
+	ret := _m.Called(ctx, pvzID, ifMatch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReopenLastReception")
+	}
+
+	var r0 models.Reception
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.IfMatch) (models.Reception, error)); ok {
+		return rf(ctx, pvzID, ifMatch)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.IfMatch) models.Reception); ok {
+		r0 = rf(ctx, pvzID, ifMatch)
+	} else {
+		r0 = ret.Get(0).(models.Reception)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, models.IfMatch) error); ok {
+		r1 = rf(ctx, pvzID, ifMatch)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserService_ReopenLastReception_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReopenLastReception'
+type PvzUserService_ReopenLastReception_Call struct {
+	*mock.Call
+}
+
+// ReopenLastReception is a helper method to define mock.On call
+//   - ctx context.Context
+//   - pvzID string
+//   - ifMatch models.IfMatch
+func (_e *PvzUserService_Expecter) ReopenLastReception(ctx interface{}, pvzID interface{}, ifMatch interface{}) *PvzUserService_ReopenLastReception_Call {
+	return &PvzUserService_ReopenLastReception_Call{Call: _e.mock.On("ReopenLastReception", ctx, pvzID, ifMatch)}
+}
+
+func (_c *PvzUserService_ReopenLastReception_Call) Run(run func(ctx context.Context, pvzID string, ifMatch models.IfMatch)) *PvzUserService_ReopenLastReception_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(models.IfMatch))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_ReopenLastReception_Call) Return(_a0 models.Reception, _a1 error) *PvzUserService_ReopenLastReception_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserService_ReopenLastReception_Call) RunAndReturn(run func(context.Context, string, models.IfMatch) (models.Reception, error)) *PvzUserService_ReopenLastReception_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RequestPasswordReset provides a mock function with given fields: ctx, email
+func (_m *PvzUserService) RequestPasswordReset(ctx context.Context, email string) error {
+	ret := _m.Called(ctx, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RequestPasswordReset")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, email)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PvzUserService_RequestPasswordReset_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RequestPasswordReset'
+type PvzUserService_RequestPasswordReset_Call struct {
+	*mock.Call
+}
+
+// RequestPasswordReset is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+func (_e *PvzUserService_Expecter) RequestPasswordReset(ctx interface{}, email interface{}) *PvzUserService_RequestPasswordReset_Call {
+	return &PvzUserService_RequestPasswordReset_Call{Call: _e.mock.On("RequestPasswordReset", ctx, email)}
+}
+
+func (_c *PvzUserService_RequestPasswordReset_Call) Run(run func(ctx context.Context, email string)) *PvzUserService_RequestPasswordReset_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_RequestPasswordReset_Call) Return(_a0 error) *PvzUserService_RequestPasswordReset_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *PvzUserService_RequestPasswordReset_Call) RunAndReturn(run func(context.Context, string) error) *PvzUserService_RequestPasswordReset_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResetPassword provides a mock function with given fields: ctx, token, newPassword
+func (_m *PvzUserService) ResetPassword(ctx context.Context, token string, newPassword string) error {
+	ret := _m.Called(ctx, token, newPassword)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResetPassword")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, token, newPassword)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PvzUserService_ResetPassword_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResetPassword'
+type PvzUserService_ResetPassword_Call struct {
+	*mock.Call
+}
+
+// ResetPassword is a helper method to define mock.On call
+//   - ctx context.Context
+//   - token string
+//   - newPassword string
+func (_e *PvzUserService_Expecter) ResetPassword(ctx interface{}, token interface{}, newPassword interface{}) *PvzUserService_ResetPassword_Call {
+	return &PvzUserService_ResetPassword_Call{Call: _e.mock.On("ResetPassword", ctx, token, newPassword)}
+}
+
+func (_c *PvzUserService_ResetPassword_Call) Run(run func(ctx context.Context, token string, newPassword string)) *PvzUserService_ResetPassword_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_ResetPassword_Call) Return(_a0 error) *PvzUserService_ResetPassword_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *PvzUserService_ResetPassword_Call) RunAndReturn(run func(context.Context, string, string) error) *PvzUserService_ResetPassword_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UnassignPVZ provides a mock function with given fields: ctx, userID, pvzID
+func (_m *PvzUserService) UnassignPVZ(ctx context.Context, userID string, pvzID string) error {
+	ret := _m.Called(ctx, userID, pvzID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UnassignPVZ")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, userID, pvzID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PvzUserService_UnassignPVZ_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UnassignPVZ'
+type PvzUserService_UnassignPVZ_Call struct {
+	*mock.Call
+}
+
+// UnassignPVZ is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - pvzID string
+func (_e *PvzUserService_Expecter) UnassignPVZ(ctx interface{}, userID interface{}, pvzID interface{}) *PvzUserService_UnassignPVZ_Call {
+	return &PvzUserService_UnassignPVZ_Call{Call: _e.mock.On("UnassignPVZ", ctx, userID, pvzID)}
+}
+
+func (_c *PvzUserService_UnassignPVZ_Call) Run(run func(ctx context.Context, userID string, pvzID string)) *PvzUserService_UnassignPVZ_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_UnassignPVZ_Call) Return(_a0 error) *PvzUserService_UnassignPVZ_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *PvzUserService_UnassignPVZ_Call) RunAndReturn(run func(context.Context, string, string) error) *PvzUserService_UnassignPVZ_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdatePVZ provides a mock function with given fields: ctx, pvzID, city, ifMatch
+func (_m *PvzUserService) UpdatePVZ(ctx context.Context, pvzID string, city models.City, ifMatch models.IfMatch) (models.PVZ, error) {
+	ret := _m.Called(ctx, pvzID, city, ifMatch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePVZ")
+	}
+
+	var r0 models.PVZ
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.City, models.IfMatch) (models.PVZ, error)); ok {
+		return rf(ctx, pvzID, city, ifMatch)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.City, models.IfMatch) models.PVZ); ok {
+		r0 = rf(ctx, pvzID, city, ifMatch)
+	} else {
+		r0 = ret.Get(0).(models.PVZ)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, models.City, models.IfMatch) error); ok {
+		r1 = rf(ctx, pvzID, city, ifMatch)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserService_UpdatePVZ_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdatePVZ'
+type PvzUserService_UpdatePVZ_Call struct {
+	*mock.Call
+}
+
+// UpdatePVZ is a helper method to define mock.On call
+//   - ctx context.Context
+//   - pvzID string
+//   - city models.City
+//   - ifMatch models.IfMatch
+func (_e *PvzUserService_Expecter) UpdatePVZ(ctx interface{}, pvzID interface{}, city interface{}, ifMatch interface{}) *PvzUserService_UpdatePVZ_Call {
+	return &PvzUserService_UpdatePVZ_Call{Call: _e.mock.On("UpdatePVZ", ctx, pvzID, city, ifMatch)}
+}
+
+func (_c *PvzUserService_UpdatePVZ_Call) Run(run func(ctx context.Context, pvzID string, city models.City, ifMatch models.IfMatch)) *PvzUserService_UpdatePVZ_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(models.City), args[3].(models.IfMatch))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_UpdatePVZ_Call) Return(_a0 models.PVZ, _a1 error) *PvzUserService_UpdatePVZ_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserService_UpdatePVZ_Call) RunAndReturn(run func(context.Context, string, models.City, models.IfMatch) (models.PVZ, error)) *PvzUserService_UpdatePVZ_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateUser provides a mock function with given fields: ctx, actorID, userID, upd
+func (_m *PvzUserService) UpdateUser(ctx context.Context, actorID string, userID string, upd models.UserUpdate) (models.User, error) {
+	ret := _m.Called(ctx, actorID, userID, upd)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateUser")
+	}
+
+	var r0 models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, models.UserUpdate) (models.User, error)); ok {
+		return rf(ctx, actorID, userID, upd)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, models.UserUpdate) models.User); ok {
+		r0 = rf(ctx, actorID, userID, upd)
+	} else {
+		r0 = ret.Get(0).(models.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, models.UserUpdate) error); ok {
+		r1 = rf(ctx, actorID, userID, upd)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserService_UpdateUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateUser'
+type PvzUserService_UpdateUser_Call struct {
+	*mock.Call
+}
+
+// UpdateUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - actorID string
+//   - userID string
+//   - upd models.UserUpdate
+func (_e *PvzUserService_Expecter) UpdateUser(ctx interface{}, actorID interface{}, userID interface{}, upd interface{}) *PvzUserService_UpdateUser_Call {
+	return &PvzUserService_UpdateUser_Call{Call: _e.mock.On("UpdateUser", ctx, actorID, userID, upd)}
+}
+
+func (_c *PvzUserService_UpdateUser_Call) Run(run func(ctx context.Context, actorID string, userID string, upd models.UserUpdate)) *PvzUserService_UpdateUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(models.UserUpdate))
+	})
+	return _c
+}
+
+func (_c *PvzUserService_UpdateUser_Call) Return(_a0 models.User, _a1 error) *PvzUserService_UpdateUser_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *PvzUserService_RegisterUser_Call) RunAndReturn(run func(string, string, models.Role) (models.User, error)) *PvzUserService_RegisterUser_Call {
+func (_c *PvzUserService_UpdateUser_Call) RunAndReturn(run func(context.Context, string, string, models.UserUpdate) (models.User, error)) *PvzUserService_UpdateUser_Call {
 	_c.Call.Return(run)
 	return _c
 }
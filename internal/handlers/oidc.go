@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"pvz/internal/auth"
+	"pvz/internal/logger"
+	"pvz/internal/metrics"
+	"pvz/internal/models"
+	"pvz/internal/repository"
+	"pvz/internal/services"
+	"pvz/internal/validation"
+
+	"github.com/labstack/echo/v4"
+)
+
+// OIDCLogin exchanges an ID or access token of the identity provider for a token of the service.
+func (h *Handler) OIDCLogin(c echo.Context) error {
+	var req validation.OIDCLoginRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusUnauthorized, models.Err("invalid JSON: "+err.Error()))
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusUnauthorized, models.Err(err.Error()))
+	}
+
+	ctx := c.Request().Context()
+	token, err := h.Service.OIDCLogin(ctx, req.Token)
+	switch {
+	case err == nil:
+		return c.JSON(http.StatusOK, token)
+	case errors.Is(err, auth.ErrInvalidToken):
+		metrics.AuthFailures.WithLabelValues("invalid_oidc_token").Inc()
+		logger.FromContext(ctx).Warn("oidc login failed", "error", err, "ip", c.RealIP())
+		return c.JSON(http.StatusUnauthorized, models.Err(auth.ErrInvalidToken.Error()))
+	case errors.Is(err, auth.ErrNoRole), errors.Is(err, repository.ErrUserBlocked):
+		logger.FromContext(ctx).Warn("oidc login rejected", "error", err, "ip", c.RealIP())
+		return c.JSON(http.StatusForbidden, models.Err(err.Error()))
+	case errors.Is(err, repository.ErrIdentityConflict):
+		return c.JSON(http.StatusConflict, models.Err(err.Error()))
+	case errors.Is(err, services.ErrOIDCDisabled):
+		return c.JSON(http.StatusNotFound, models.Err(err.Error()))
+	case errors.Is(err, auth.ErrProviderUnavailable):
+		logError(c, "oidc login", err)
+		return c.JSON(http.StatusServiceUnavailable, models.Err(auth.ErrProviderUnavailable.Error()))
+	default:
+		logError(c, "oidc login", err)
+		return c.JSON(http.StatusInternalServerError, models.Err("internal error"))
+	}
+}
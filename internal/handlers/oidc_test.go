@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"pvz/internal/auth"
+	"pvz/internal/models"
+	"pvz/internal/repository"
+	"pvz/internal/validation"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOIDCLogin(t *testing.T) {
+	e, svc := setup()
+	h := NewHandler(svc)
+	body := validation.OIDCLoginRequest{Token: "raw"}
+
+	svc.EXPECT().OIDCLogin(mock.Anything, "raw").Return(models.Token("token"), nil).Once()
+	c, rec := jsonContext(e, "/login/oidc", body)
+	require.NoError(t, h.OIDCLogin(c))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `"token"`, rec.Body.String())
+
+	cases := []struct {
+		err  error
+		code int
+	}{
+		{auth.ErrInvalidToken, http.StatusUnauthorized},
+		{auth.ErrNoRole, http.StatusForbidden},
+		{repository.ErrUserBlocked, http.StatusForbidden},
+		{repository.ErrIdentityConflict, http.StatusConflict},
+		{auth.ErrProviderUnavailable, http.StatusServiceUnavailable},
+		{errors.New("db down"), http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		t.Run(tc.err.Error(), func(t *testing.T) {
+			svc.EXPECT().OIDCLogin(mock.Anything, "raw").Return(models.Token(""), tc.err).Once()
+			c, rec := jsonContext(e, "/login/oidc", body)
+			require.NoError(t, h.OIDCLogin(c))
+			require.Equal(t, tc.code, rec.Code)
+		})
+	}
+	svc.AssertExpectations(t)
+}
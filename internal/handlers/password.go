@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"pvz/internal/models"
+	"pvz/internal/password"
+	"pvz/internal/repository"
+	"pvz/internal/services"
+	"pvz/internal/validation"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h *Handler) ChangePassword(c echo.Context) error {
+	var req validation.ChangePasswordRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Err("invalid JSON: "+err.Error()))
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
+	}
+	userID, _ := c.Get(ContextUserID).(string)
+	if userID == "" {
+		return c.JSON(http.StatusForbidden, models.Err("access is denied"))
+	}
+
+	err := h.Service.ChangePassword(c.Request().Context(), userID, req.OldPassword, req.NewPassword)
+	switch {
+	case err == nil:
+		return c.NoContent(http.StatusNoContent)
+	case errors.Is(err, password.ErrWeakPassword):
+		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
+	case errors.Is(err, repository.ErrInvalidPassword):
+		return c.JSON(http.StatusForbidden, models.Err("current password is invalid"))
+	case errors.Is(err, repository.ErrUserNotFound):
+		return c.JSON(http.StatusNotFound, models.Err(err.Error()))
+	default:
+		logError(c, "change password", err)
+		return c.JSON(http.StatusInternalServerError, models.Err("internal error"))
+	}
+}
+
+// RequestPasswordReset answers 202 whether the email is registered or not, and 501
+// when no notifier is configured to deliver the token.
+func (h *Handler) RequestPasswordReset(c echo.Context) error {
+	var req validation.PasswordResetRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Err("invalid JSON: "+err.Error()))
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
+	}
+
+	err := h.Service.RequestPasswordReset(c.Request().Context(), req.Email)
+	switch {
+	case err == nil:
+		return c.NoContent(http.StatusAccepted)
+	case errors.Is(err, services.ErrPasswordResetDisabled):
+		return c.JSON(http.StatusNotImplemented, models.Err(err.Error()))
+	default:
+		logError(c, "request password reset", err)
+		return c.JSON(http.StatusInternalServerError, models.Err("internal error"))
+	}
+}
+
+func (h *Handler) ResetPassword(c echo.Context) error {
+	var req validation.PasswordResetConfirmRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Err("invalid JSON: "+err.Error()))
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
+	}
+
+	err := h.Service.ResetPassword(c.Request().Context(), req.Token, req.NewPassword)
+	switch {
+	case err == nil:
+		return c.NoContent(http.StatusNoContent)
+	case errors.Is(err, password.ErrWeakPassword), errors.Is(err, repository.ErrInvalidResetToken):
+		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
+	default:
+		logError(c, "reset password", err)
+		return c.JSON(http.StatusInternalServerError, models.Err("internal error"))
+	}
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pvz/internal/password"
+	"pvz/internal/repository"
+	"pvz/internal/services"
+	"pvz/internal/validation"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func jsonContext(e *echo.Echo, path string, body any) (echo.Context, *httptest.ResponseRecorder) {
+	reqBody, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewBuffer(reqBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	return e.NewContext(req, rec), rec
+}
+
+func TestChangePassword(t *testing.T) {
+	e, svc := setup()
+	h := NewHandler(svc)
+	body := validation.ChangePasswordRequest{OldPassword: "old", NewPassword: "new"}
+
+	t.Run("no user in context", func(t *testing.T) {
+		c, rec := jsonContext(e, "/me/password", body)
+		require.NoError(t, h.ChangePassword(c))
+		require.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	cases := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"success", nil, http.StatusNoContent},
+		{"weak password", fmt.Errorf("%w: too short", password.ErrWeakPassword), http.StatusBadRequest},
+		{"wrong current password", repository.ErrInvalidPassword, http.StatusForbidden},
+		{"user not found", repository.ErrUserNotFound, http.StatusNotFound},
+		{"internal", errors.New("db down"), http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc.EXPECT().ChangePassword(mock.Anything, "u1", "old", "new").Return(tc.err).Once()
+
+			c, rec := jsonContext(e, "/me/password", body)
+			c.Set(ContextUserID, "u1")
+			require.NoError(t, h.ChangePassword(c))
+			require.Equal(t, tc.status, rec.Code)
+		})
+	}
+}
+
+func TestRequestPasswordReset(t *testing.T) {
+	e, svc := setup()
+	h := NewHandler(svc)
+
+	svc.EXPECT().RequestPasswordReset(mock.Anything, "a@b.com").Return(nil).Once()
+	c, rec := jsonContext(e, "/password/reset", validation.PasswordResetRequest{Email: "a@b.com"})
+	require.NoError(t, h.RequestPasswordReset(c))
+	require.Equal(t, http.StatusAccepted, rec.Code)
+
+	svc.EXPECT().RequestPasswordReset(mock.Anything, "a@b.com").Return(errors.New("smtp down")).Once()
+	c, rec = jsonContext(e, "/password/reset", validation.PasswordResetRequest{Email: "a@b.com"})
+	require.NoError(t, h.RequestPasswordReset(c))
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	svc.EXPECT().RequestPasswordReset(mock.Anything, "a@b.com").Return(services.ErrPasswordResetDisabled).Once()
+	c, rec = jsonContext(e, "/password/reset", validation.PasswordResetRequest{Email: "a@b.com"})
+	require.NoError(t, h.RequestPasswordReset(c))
+	require.Equal(t, http.StatusNotImplemented, rec.Code)
+}
+
+func TestResetPassword(t *testing.T) {
+	e, svc := setup()
+	h := NewHandler(svc)
+	body := validation.PasswordResetConfirmRequest{Token: "tok", NewPassword: "new"}
+
+	cases := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"success", nil, http.StatusNoContent},
+		{"invalid token", repository.ErrInvalidResetToken, http.StatusBadRequest},
+		{"weak password", password.ErrWeakPassword, http.StatusBadRequest},
+		{"internal", errors.New("db down"), http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc.EXPECT().ResetPassword(mock.Anything, "tok", "new").Return(tc.err).Once()
+
+			c, rec := jsonContext(e, "/password/reset/confirm", body)
+			require.NoError(t, h.ResetPassword(c))
+			require.Equal(t, tc.status, rec.Code)
+		})
+	}
+}
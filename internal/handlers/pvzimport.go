@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"pvz/internal/models"
+	"pvz/internal/repository"
+	"pvz/internal/services"
+	"pvz/internal/validation"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MaxPVZImportSize limits the csv of POST /pvz/import.
+const MaxPVZImportSize = 5 << 20
+
+// ImportPVZs creates pvz from a csv file sent as the body or as the "file" field of a form.
+// Rows failing validation are answered with 422 and the report, nothing is created then.
+func (h *Handler) ImportPVZs(c echo.Context) error {
+	var dryRun bool
+	if err := echo.QueryParamsBinder(c).Bool("dryRun", &dryRun).BindError(); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Err("invalid dryRun, boolean expected"))
+	}
+
+	req := c.Request()
+	req.Body = http.MaxBytesReader(c.Response(), req.Body, MaxPVZImportSize)
+	var file io.Reader = req.Body
+	if strings.HasPrefix(req.Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return c.JSON(importErrorStatus(err), models.Err("invalid form, csv file expected in the file field"))
+		}
+		f, err := header.Open()
+		if err != nil {
+			logError(c, "open pvz import file", err)
+			return c.JSON(http.StatusInternalServerError, models.Err("internal error"))
+		}
+		defer f.Close()
+		file = f
+	}
+
+	rows, rowErrs, err := validation.ParsePVZCSV(file, c.Validate)
+	if err != nil {
+		return c.JSON(importErrorStatus(err), models.Err(err.Error()))
+	}
+
+	report, err := h.Service.ImportPVZs(req.Context(), rows, rowErrs, dryRun)
+	switch {
+	case err == nil && len(report.Errors) > 0:
+		return c.JSON(http.StatusUnprocessableEntity, report)
+	case err == nil && dryRun:
+		return c.JSON(http.StatusOK, report)
+	case err == nil:
+		return c.JSON(http.StatusCreated, report)
+	case errors.Is(err, services.ErrNoImportRows):
+		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
+	case errors.Is(err, repository.ErrPVZExists):
+		return c.JSON(http.StatusConflict, models.Err(err.Error()))
+	default:
+		logError(c, "import pvz", err)
+		return c.JSON(http.StatusInternalServerError, models.Err("internal error"))
+	}
+}
+
+func importErrorStatus(err error) int {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"pvz/internal/models"
+	"pvz/internal/repository"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const importCSV = "city,address,latitude,longitude\nМосква,\"Тверская, 1\",55.75,37.61\n"
+
+func csvContext(e *echo.Echo, target, body string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, "text/csv")
+	rec := httptest.NewRecorder()
+	return e.NewContext(req, rec), rec
+}
+
+func TestImportPVZs(t *testing.T) {
+	e, svc := setup()
+	h := NewHandler(svc)
+	rows := []models.PVZImportRow{{Line: 2, City: models.Moscow, Address: "Тверская, 1", Latitude: 55.75, Longitude: 37.61}}
+
+	t.Run("created", func(t *testing.T) {
+		report := models.PVZImportReport{Rows: 1, Errors: []models.PVZImportError{}, Created: []models.PVZ{{ID: "p1"}}}
+		svc.EXPECT().ImportPVZs(mock.Anything, rows, []models.PVZImportError{}, false).Return(report, nil).Once()
+		c, rec := csvContext(e, "/pvz/import", importCSV)
+		require.NoError(t, h.ImportPVZs(c))
+		require.Equal(t, http.StatusCreated, rec.Code)
+		require.Contains(t, rec.Body.String(), `"id":"p1"`)
+	})
+
+	t.Run("dry run from a form", func(t *testing.T) {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, _ := form.CreateFormFile("file", "pvz.csv")
+		part.Write([]byte(importCSV))
+		form.Close()
+
+		svc.EXPECT().ImportPVZs(mock.Anything, rows, []models.PVZImportError{}, true).
+			Return(models.PVZImportReport{DryRun: true, Rows: 1}, nil).Once()
+		req := httptest.NewRequest(http.MethodPost, "/pvz/import?dryRun=true", &body)
+		req.Header.Set(echo.HeaderContentType, form.FormDataContentType())
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.ImportPVZs(e.NewContext(req, rec)))
+		require.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("invalid rows", func(t *testing.T) {
+		report := models.PVZImportReport{Rows: 2, Errors: []models.PVZImportError{{Line: 3, Error: "expected 4 fields, got 1"}}}
+		svc.EXPECT().ImportPVZs(mock.Anything, rows, report.Errors, false).Return(report, nil).Once()
+		c, rec := csvContext(e, "/pvz/import", importCSV+"Казань\n")
+		require.NoError(t, h.ImportPVZs(c))
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		require.Contains(t, rec.Body.String(), `"line":3`)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		cases := []struct {
+			name, target, body string
+			err                error
+			code               int
+		}{
+			{"no header", "/pvz/import", "Москва,a,1,2\n", nil, http.StatusBadRequest},
+			{"bad dryRun", "/pvz/import?dryRun=maybe", importCSV, nil, http.StatusBadRequest},
+			{"too large", "/pvz/import", importCSV + strings.Repeat("Москва,a,1,2\n", MaxPVZImportSize/10), nil, http.StatusRequestEntityTooLarge},
+			{"exists", "/pvz/import", importCSV, repository.ErrPVZExists, http.StatusConflict},
+			{"db down", "/pvz/import", importCSV, errors.New("db down"), http.StatusInternalServerError},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				if tc.err != nil {
+					svc.EXPECT().ImportPVZs(mock.Anything, rows, mock.Anything, false).Return(models.PVZImportReport{}, tc.err).Once()
+				}
+				c, rec := csvContext(e, tc.target, tc.body)
+				require.NoError(t, h.ImportPVZs(c))
+				require.Equal(t, tc.code, rec.Code)
+			})
+		}
+	})
+	svc.AssertExpectations(t)
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"pvz/internal/models"
+	"pvz/internal/services"
+	"pvz/internal/validation"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h *Handler) IntakeReport(c echo.Context) error {
+	var req validation.IntakeReportQuery
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Err("invalid query: "+err.Error()))
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
+	}
+	var groupBy []string
+	if req.GroupBy != "" {
+		for _, dim := range strings.Split(req.GroupBy, ",") {
+			groupBy = append(groupBy, strings.TrimSpace(dim))
+		}
+	}
+
+	report, err := h.Service.IntakeReport(c.Request().Context(), req.StartDate, req.EndDate, groupBy)
+	switch {
+	case err == nil:
+		return c.JSON(http.StatusOK, report)
+	case errors.Is(err, services.ErrInvalidDate), errors.Is(err, services.ErrInvalidRange), errors.Is(err, services.ErrInvalidGroupBy):
+		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
+	default:
+		logError(c, "intake report", err)
+		return c.JSON(http.StatusInternalServerError, models.Err("internal error"))
+	}
+}
+
+func (h *Handler) ReceptionStats(c echo.Context) error {
+	var req validation.ReceptionStatsQuery
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Err("invalid query: "+err.Error()))
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
+	}
+
+	report, err := h.Service.ReceptionStats(c.Request().Context(), req.StartDate, req.EndDate, req.PvzID)
+	switch {
+	case err == nil:
+		return c.JSON(http.StatusOK, report)
+	case errors.Is(err, services.ErrInvalidDate), errors.Is(err, services.ErrInvalidRange):
+		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
+	default:
+		logError(c, "reception stats", err)
+		return c.JSON(http.StatusInternalServerError, models.Err("internal error"))
+	}
+}
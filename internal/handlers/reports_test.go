@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pvz/internal/models"
+	"pvz/internal/services"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntakeReport(t *testing.T) {
+	e, svc := setup()
+	h := NewHandler(svc)
+
+	send := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.IntakeReport(e.NewContext(httptest.NewRequest(http.MethodGet, "/reports/intake?"+query, nil), rec)))
+		return rec
+	}
+
+	svc.EXPECT().IntakeReport(mock.Anything, "2025-01-01T00:00:00Z", "", []string{models.GroupByCity, models.GroupByWeek}).
+		Return(models.IntakeReport{Rows: []models.IntakeRow{{City: models.Kazan, Products: 4}}}, nil).Once()
+	rec := send("startDate=2025-01-01T00:00:00Z&groupBy=city,%20week")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), `"city":"Казань","products":4`)
+
+	for err, code := range map[error]int{
+		services.ErrInvalidGroupBy: http.StatusBadRequest,
+		services.ErrInvalidRange:   http.StatusBadRequest,
+		errors.New("db down"):      http.StatusInternalServerError,
+	} {
+		svc.EXPECT().IntakeReport(mock.Anything, "", "", []string(nil)).Return(models.IntakeReport{}, err).Once()
+		require.Equal(t, code, send("").Code, err.Error())
+	}
+	svc.AssertExpectations(t)
+}
+
+func TestReceptionStats(t *testing.T) {
+	e, svc := setup()
+	h := NewHandler(svc)
+	pvzID := "11111111-1111-4111-8111-111111111111"
+
+	send := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.ReceptionStats(e.NewContext(httptest.NewRequest(http.MethodGet, "/reports/receptions?"+query, nil), rec)))
+		return rec
+	}
+
+	rate := 12.5
+	svc.EXPECT().ReceptionStats(mock.Anything, "2025-01-01T00:00:00Z", "", pvzID).
+		Return(models.ReceptionStatsReport{Rows: []models.ReceptionStats{{PvzID: pvzID, ProductsPerHour: &rate, DeletionShare: 0.5}}}, nil).Once()
+	rec := send("startDate=2025-01-01T00:00:00Z&pvzId=" + pvzID)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), `"productsPerHour":12.5,"deletionShare":0.5`)
+
+	for err, code := range map[error]int{
+		services.ErrInvalidRange: http.StatusBadRequest,
+		errors.New("db down"):    http.StatusInternalServerError,
+	} {
+		svc.EXPECT().ReceptionStats(mock.Anything, "", "", "").Return(models.ReceptionStatsReport{}, err).Once()
+		require.Equal(t, code, send("").Code, err.Error())
+	}
+	svc.AssertExpectations(t)
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"pvz/internal/models"
+	"pvz/internal/password"
+	"pvz/internal/repository"
+	"pvz/internal/services"
+	"pvz/internal/validation"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/labstack/echo/v4"
+)
+
+func userErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrUserNotFound), errors.Is(err, repository.ErrPvzNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrSelfModification):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// CreateUser registers a user of any role on behalf of a moderator.
+func (h *Handler) CreateUser(c echo.Context) error {
+	var req validation.RegisterRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Err("invalid JSON: "+err.Error()))
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
+	}
+
+	user, err := h.Service.RegisterUser(c.Request().Context(), req.Email, req.Password, req.Role)
+	if err != nil {
+		if !errors.Is(err, password.ErrWeakPassword) {
+			logError(c, "create user", err)
+		}
+		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
+	}
+	return c.JSON(http.StatusCreated, user)
+}
+
+func (h *Handler) ListUsers(c echo.Context) error {
+	var req validation.ListUsersQuery
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Err("invalid query: "+err.Error()))
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
+	}
+
+	users, err := h.Service.ListUsers(c.Request().Context(), models.Role(req.Role), req.Page, req.Limit)
+	if err != nil {
+		logError(c, "list users", err)
+		return c.JSON(http.StatusInternalServerError, models.Err("internal error"))
+	}
+	return c.JSON(http.StatusOK, users)
+}
+
+func (h *Handler) GetUser(c echo.Context) error {
+	userID := c.Param("id")
+	if !govalidator.IsUUID(userID) {
+		return c.JSON(http.StatusBadRequest, models.Err("invalid id, uuid expected"))
+	}
+
+	user, err := h.Service.GetUser(c.Request().Context(), userID)
+	if err != nil {
+		status := userErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			logError(c, "get user", err, "target_user_id", userID)
+			return c.JSON(status, models.Err("internal error"))
+		}
+		return c.JSON(status, models.Err(err.Error()))
+	}
+	return c.JSON(http.StatusOK, user)
+}
+
+func (h *Handler) UpdateUser(c echo.Context) error {
+	userID := c.Param("id")
+	if !govalidator.IsUUID(userID) {
+		return c.JSON(http.StatusBadRequest, models.Err("invalid id, uuid expected"))
+	}
+
+	var req validation.UpdateUserRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Err("invalid JSON: "+err.Error()))
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
+	}
+	if req.Role == nil && req.Blocked == nil {
+		return c.JSON(http.StatusBadRequest, models.Err("nothing to update, expected role or blocked"))
+	}
+
+	actorID, _ := c.Get(ContextUserID).(string)
+	user, err := h.Service.UpdateUser(c.Request().Context(), actorID, userID,
+		models.UserUpdate{Role: req.Role, Blocked: req.Blocked})
+	if err != nil {
+		status := userErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			logError(c, "update user", err, "target_user_id", userID)
+			return c.JSON(status, models.Err("internal error"))
+		}
+		return c.JSON(status, models.Err(err.Error()))
+	}
+	return c.JSON(http.StatusOK, user)
+}
+
+func (h *Handler) DeleteUser(c echo.Context) error {
+	userID := c.Param("id")
+	if !govalidator.IsUUID(userID) {
+		return c.JSON(http.StatusBadRequest, models.Err("invalid id, uuid expected"))
+	}
+
+	actorID, _ := c.Get(ContextUserID).(string)
+	if err := h.Service.DeleteUser(c.Request().Context(), actorID, userID); err != nil {
+		status := userErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			logError(c, "delete user", err, "target_user_id", userID)
+			return c.JSON(status, models.Err("internal error"))
+		}
+		return c.JSON(status, models.Err(err.Error()))
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) GetMe(c echo.Context) error {
+	userID, _ := c.Get(ContextUserID).(string)
+	if userID == "" {
+		return c.JSON(http.StatusForbidden, models.Err("access is denied"))
+	}
+
+	me, err := h.Service.Me(c.Request().Context(), userID)
+	if err != nil {
+		status := userErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			logError(c, "get me", err)
+			return c.JSON(status, models.Err("internal error"))
+		}
+		return c.JSON(status, models.Err(err.Error()))
+	}
+	return c.JSON(http.StatusOK, me)
+}
+
+func (h *Handler) AssignPVZ(c echo.Context) error {
+	return h.changeAssignment(c, "assign pvz", h.Service.AssignPVZ)
+}
+
+func (h *Handler) UnassignPVZ(c echo.Context) error {
+	return h.changeAssignment(c, "unassign pvz", h.Service.UnassignPVZ)
+}
+
+func (h *Handler) changeAssignment(c echo.Context, op string, change func(ctx context.Context, userID, pvzID string) error) error {
+	userID, pvzID := c.Param("id"), c.Param("pvzId")
+	if !govalidator.IsUUID(userID) || !govalidator.IsUUID(pvzID) {
+		return c.JSON(http.StatusBadRequest, models.Err("invalid id or pvzId, uuid expected"))
+	}
+
+	if err := change(c.Request().Context(), userID, pvzID); err != nil {
+		status := userErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			logError(c, op, err, "target_user_id", userID, "pvz_id", pvzID)
+			return c.JSON(status, models.Err("internal error"))
+		}
+		return c.JSON(status, models.Err(err.Error()))
+	}
+	return c.NoContent(http.StatusNoContent)
+}
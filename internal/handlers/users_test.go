@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pvz/internal/models"
+	"pvz/internal/repository"
+	"pvz/internal/services"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testUserID = "3f0c8b0e-6a63-4a43-9e8d-2c5f1f7a4d11"
+
+func userContext(e *echo.Echo, method, body string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, "/users/"+testUserID, bytes.NewBufferString(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(testUserID)
+	c.Set(ContextUserID, "moderator-1")
+	return c, rec
+}
+
+func TestListUsers(t *testing.T) {
+	e, svc := setup()
+	h := NewHandler(svc)
+
+	users := []models.User{{ID: "u1", Email: "a@b.com", Role: models.Employee}}
+	svc.EXPECT().ListUsers(mock.Anything, models.Employee, 2, 5).Return(users, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/users?role=employee&page=2&limit=5", nil)
+	rec := httptest.NewRecorder()
+	require.NoError(t, h.ListUsers(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var got []models.User
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	require.Equal(t, users, got)
+}
+
+func TestGetUser(t *testing.T) {
+	e, svc := setup()
+	h := NewHandler(svc)
+
+	t.Run("invalid id", func(t *testing.T) {
+		c, rec := userContext(e, http.MethodGet, "")
+		c.SetParamValues("42")
+		require.NoError(t, h.GetUser(c))
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		svc.EXPECT().GetUser(mock.Anything, testUserID).Return(models.User{}, repository.ErrUserNotFound).Once()
+		c, rec := userContext(e, http.MethodGet, "")
+		require.NoError(t, h.GetUser(c))
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		svc.EXPECT().GetUser(mock.Anything, testUserID).Return(models.User{ID: testUserID}, nil).Once()
+		c, rec := userContext(e, http.MethodGet, "")
+		require.NoError(t, h.GetUser(c))
+		require.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func TestUpdateUser(t *testing.T) {
+	e, svc := setup()
+	h := NewHandler(svc)
+
+	t.Run("empty update", func(t *testing.T) {
+		c, rec := userContext(e, http.MethodPatch, `{}`)
+		require.NoError(t, h.UpdateUser(c))
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("block", func(t *testing.T) {
+		blocked := true
+		svc.EXPECT().
+			UpdateUser(mock.Anything, "moderator-1", testUserID, models.UserUpdate{Blocked: &blocked}).
+			Return(models.User{ID: testUserID, Blocked: true}, nil).Once()
+		c, rec := userContext(e, http.MethodPatch, `{"blocked":true}`)
+		require.NoError(t, h.UpdateUser(c))
+		require.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("self modification", func(t *testing.T) {
+		role := models.Employee
+		svc.EXPECT().
+			UpdateUser(mock.Anything, "moderator-1", testUserID, models.UserUpdate{Role: &role}).
+			Return(models.User{}, services.ErrSelfModification).Once()
+		c, rec := userContext(e, http.MethodPatch, `{"role":"employee"}`)
+		require.NoError(t, h.UpdateUser(c))
+		require.Equal(t, http.StatusConflict, rec.Code)
+	})
+}
+
+func TestDeleteUser(t *testing.T) {
+	e, svc := setup()
+	h := NewHandler(svc)
+
+	svc.EXPECT().DeleteUser(mock.Anything, "moderator-1", testUserID).Return(nil).Once()
+	c, rec := userContext(e, http.MethodDelete, "")
+	require.NoError(t, h.DeleteUser(c))
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	svc.EXPECT().DeleteUser(mock.Anything, "moderator-1", testUserID).Return(errors.New("db down")).Once()
+	c, rec = userContext(e, http.MethodDelete, "")
+	require.NoError(t, h.DeleteUser(c))
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestGetMe(t *testing.T) {
+	e, svc := setup()
+	h := NewHandler(svc)
+
+	me := models.Me{
+		User:        models.User{ID: testUserID, Email: "a@b.com", Role: models.Employee},
+		PVZs:        []models.PVZ{{ID: "p1", City: models.Moscow}},
+		Permissions: []string{models.PermReceptionOpen},
+	}
+	svc.EXPECT().Me(mock.Anything, testUserID).Return(me, nil).Once()
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/me", nil), rec)
+	c.Set(ContextUserID, testUserID)
+	require.NoError(t, h.GetMe(c))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var got models.Me
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	require.Equal(t, me.User, got.User)
+	require.Equal(t, me.Permissions, got.Permissions)
+	require.Len(t, got.PVZs, 1)
+}
+
+func TestAssignPVZ(t *testing.T) {
+	e, svc := setup()
+	h := NewHandler(svc)
+	const pvzID = "9d7a3a51-2b1e-4f7e-8f6a-1c1d2e3f4a5b"
+
+	newContext := func() (echo.Context, *httptest.ResponseRecorder) {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodPut, "/", nil), rec)
+		c.SetParamNames("id", "pvzId")
+		c.SetParamValues(testUserID, pvzID)
+		return c, rec
+	}
+
+	svc.EXPECT().AssignPVZ(mock.Anything, testUserID, pvzID).Return(nil).Once()
+	c, rec := newContext()
+	require.NoError(t, h.AssignPVZ(c))
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	svc.EXPECT().AssignPVZ(mock.Anything, testUserID, pvzID).Return(repository.ErrPvzNotFound).Once()
+	c, rec = newContext()
+	require.NoError(t, h.AssignPVZ(c))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	svc.EXPECT().UnassignPVZ(mock.Anything, testUserID, pvzID).Return(nil).Once()
+	c, rec = newContext()
+	require.NoError(t, h.UnassignPVZ(c))
+	require.Equal(t, http.StatusNoContent, rec.Code)
+}
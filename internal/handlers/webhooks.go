@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"pvz/internal/models"
+	"pvz/internal/repository"
+	"pvz/internal/services"
+	"pvz/internal/validation"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/labstack/echo/v4"
+)
+
+// CreateWebhook answers with the secret, list responses omit it.
+func (h *Handler) CreateWebhook(c echo.Context) error {
+	var req validation.CreateWebhookRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Err("invalid JSON: "+err.Error()))
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
+	}
+
+	actorID, _ := c.Get(ContextUserID).(string)
+	hook, err := h.Service.CreateWebhook(c.Request().Context(), actorID, req.URL, req.Events, req.Secret)
+	switch {
+	case err == nil:
+		return c.JSON(http.StatusCreated, hook)
+	case errors.Is(err, services.ErrInvalidWebhookURL), errors.Is(err, services.ErrInvalidEvents):
+		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
+	default:
+		logError(c, "create webhook", err)
+		return c.JSON(http.StatusInternalServerError, models.Err("internal error"))
+	}
+}
+
+func (h *Handler) ListWebhooks(c echo.Context) error {
+	hooks, err := h.Service.ListWebhooks(c.Request().Context())
+	if err != nil {
+		logError(c, "list webhooks", err)
+		return c.JSON(http.StatusInternalServerError, models.Err("internal error"))
+	}
+	return c.JSON(http.StatusOK, hooks)
+}
+
+func (h *Handler) DeleteWebhook(c echo.Context) error {
+	webhookID := c.Param("id")
+	if !govalidator.IsUUID(webhookID) {
+		return c.JSON(http.StatusBadRequest, models.Err("invalid id, uuid expected"))
+	}
+
+	err := h.Service.DeleteWebhook(c.Request().Context(), webhookID)
+	switch {
+	case err == nil:
+		return c.NoContent(http.StatusNoContent)
+	case errors.Is(err, repository.ErrWebhookNotFound):
+		return c.JSON(http.StatusNotFound, models.Err(err.Error()))
+	default:
+		logError(c, "delete webhook", err, "webhook_id", webhookID)
+		return c.JSON(http.StatusInternalServerError, models.Err("internal error"))
+	}
+}
+
+func (h *Handler) ListWebhookDeliveries(c echo.Context) error {
+	webhookID := c.Param("id")
+	if !govalidator.IsUUID(webhookID) {
+		return c.JSON(http.StatusBadRequest, models.Err("invalid id, uuid expected"))
+	}
+	var req validation.ListDeliveriesQuery
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Err("invalid query: "+err.Error()))
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Err(err.Error()))
+	}
+
+	deliveries, err := h.Service.ListWebhookDeliveries(c.Request().Context(), webhookID, req.Page, req.Limit)
+	switch {
+	case err == nil:
+		return c.JSON(http.StatusOK, deliveries)
+	case errors.Is(err, repository.ErrWebhookNotFound):
+		return c.JSON(http.StatusNotFound, models.Err(err.Error()))
+	default:
+		logError(c, "list webhook deliveries", err, "webhook_id", webhookID)
+		return c.JSON(http.StatusInternalServerError, models.Err("internal error"))
+	}
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pvz/internal/models"
+	"pvz/internal/repository"
+	"pvz/internal/services"
+	"pvz/internal/validation"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateWebhook(t *testing.T) {
+	e, svc := setup()
+	h := NewHandler(svc)
+	body := validation.CreateWebhookRequest{URL: "https://wms.local/hook", Events: []string{models.EventReceptionClosed}}
+
+	svc.EXPECT().CreateWebhook(mock.Anything, "m1", body.URL, body.Events, "").
+		Return(models.Webhook{ID: "h1", Secret: "generated"}, nil).Once()
+	c, rec := jsonContext(e, "/webhooks", body)
+	c.Set(ContextUserID, "m1")
+	require.NoError(t, h.CreateWebhook(c))
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Contains(t, rec.Body.String(), `"secret":"generated"`)
+
+	for err, code := range map[error]int{
+		services.ErrInvalidWebhookURL: http.StatusBadRequest,
+		services.ErrInvalidEvents:     http.StatusBadRequest,
+		errors.New("db down"):         http.StatusInternalServerError,
+	} {
+		svc.EXPECT().CreateWebhook(mock.Anything, "", body.URL, body.Events, "").
+			Return(models.Webhook{}, err).Once()
+		c, rec := jsonContext(e, "/webhooks", body)
+		require.NoError(t, h.CreateWebhook(c))
+		require.Equal(t, code, rec.Code, err.Error())
+	}
+	svc.AssertExpectations(t)
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	e, svc := setup()
+	h := NewHandler(svc)
+	const id = "00000000-0000-4000-8000-0000000000b1"
+
+	send := func(hookID string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodDelete, "/webhooks/"+hookID, nil), rec)
+		c.SetParamNames("id")
+		c.SetParamValues(hookID)
+		require.NoError(t, h.DeleteWebhook(c))
+		return rec
+	}
+
+	require.Equal(t, http.StatusBadRequest, send("bad").Code)
+
+	svc.EXPECT().DeleteWebhook(mock.Anything, id).Return(repository.ErrWebhookNotFound).Once()
+	require.Equal(t, http.StatusNotFound, send(id).Code)
+
+	svc.EXPECT().DeleteWebhook(mock.Anything, id).Return(nil).Once()
+	require.Equal(t, http.StatusNoContent, send(id).Code)
+	svc.AssertExpectations(t)
+}
+
+func TestListWebhookDeliveries(t *testing.T) {
+	e, svc := setup()
+	h := NewHandler(svc)
+	const id = "00000000-0000-4000-8000-0000000000b1"
+
+	send := func(hookID, query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/webhooks/"+hookID+"/deliveries"+query, nil), rec)
+		c.SetParamNames("id")
+		c.SetParamValues(hookID)
+		require.NoError(t, h.ListWebhookDeliveries(c))
+		return rec
+	}
+
+	require.Equal(t, http.StatusBadRequest, send("bad", "").Code)
+
+	svc.EXPECT().ListWebhookDeliveries(mock.Anything, id, 2, 5).Return(nil, repository.ErrWebhookNotFound).Once()
+	require.Equal(t, http.StatusNotFound, send(id, "?page=2&limit=5").Code)
+
+	svc.EXPECT().ListWebhookDeliveries(mock.Anything, id, 0, 0).
+		Return([]models.WebhookDelivery{{ID: "d1", Status: models.DeliveryFailed, Attempts: 8}}, nil).Once()
+	rec := send(id, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), `"status":"failed"`)
+	svc.AssertExpectations(t)
+}
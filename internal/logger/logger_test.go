@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLevel(t *testing.T) {
+	cases := []struct {
+		in   string
+		want slog.Level
+	}{
+		{"debug", slog.LevelDebug},
+		{"INFO", slog.LevelInfo},
+		{"warn", slog.LevelWarn},
+		{"error", slog.LevelError},
+		{"", slog.LevelInfo},
+		{"unknown", slog.LevelInfo},
+	}
+	for _, tc := range cases {
+		t.Run(tc.in, func(t *testing.T) {
+			require.Equal(t, tc.want, ParseLevel(tc.in))
+		})
+	}
+}
+
+func TestContextFields(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := WithContext(context.Background(), New("info", &buf))
+	ctx = With(ctx, "request_id", "req-1")
+	ctx = With(ctx, "user_id", "u-1")
+
+	FromContext(ctx).Error("failed", "pvz_id", "p-1")
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	require.Equal(t, "failed", entry["msg"])
+	require.Equal(t, "ERROR", entry["level"])
+	require.Equal(t, "req-1", entry["request_id"])
+	require.Equal(t, "u-1", entry["user_id"])
+	require.Equal(t, "p-1", entry["pvz_id"])
+}
+
+func TestLevelFilter(t *testing.T) {
+	var buf bytes.Buffer
+	l := New("warn", &buf)
+	l.Info("skipped")
+	require.Zero(t, buf.Len())
+	l.Warn("written")
+	require.NotZero(t, buf.Len())
+}
+
+func TestFromContextDefault(t *testing.T) {
+	require.Equal(t, slog.Default(), FromContext(context.Background()))
+}
@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	AuthFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "pvz_auth_failures_total",
+		Help: "Failed login attempts.",
+	}, []string{"reason"})
+
+	AuthLockouts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "pvz_auth_lockouts_total",
+		Help: "Lockouts after too many failed login attempts, by key kind (ip, email).",
+	}, []string{"kind"})
+
+	AuthRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "pvz_auth_rejected_total",
+		Help: "Requests rejected because of rate limits or an active lockout.",
+	}, []string{"route"})
+)
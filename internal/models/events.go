@@ -0,0 +1,100 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EventReceptionOpened   = "reception.opened"
+	EventReceptionClosed   = "reception.closed"
+	EventReceptionReopened = "reception.reopened"
+	EventProductAdded      = "product.added"
+	EventProductDeleted    = "product.deleted"
+)
+
+// EventTypes are all domain event types.
+var EventTypes = []string{EventReceptionOpened, EventReceptionClosed, EventReceptionReopened, EventProductAdded, EventProductDeleted}
+
+// Destination is where a relay sends stored events. Each destination marks what it
+// sent on its own, so one that is down neither holds back nor duplicates the others.
+type Destination string
+
+const (
+	DestWebhooks  Destination = "webhooks"
+	DestPublisher Destination = "publisher"
+)
+
+// Event is a change of a PVZ, Data holds the reception or product it is about.
+// Seq orders stored events, it is set once the event is read back from the outbox.
+type Event struct {
+	ID         string          `json:"id"`
+	Seq        int64           `json:"seq,omitempty"`
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	PvzID      string          `json:"pvzId"`
+	Data       json.RawMessage `json:"data"`
+}
+
+func NewEvent(eventType, pvzID string, data any) (Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, Wrap("marshal event data", err)
+	}
+	return Event{
+		ID:         uuid.NewString(),
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		PvzID:      pvzID,
+		Data:       raw,
+	}, nil
+}
+
+// Webhook is a subscription of an external URL to event types.
+type Webhook struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedBy string    `json:"createdBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookDelivery is one event sent to one webhook, with the outcome of the last attempt.
+type WebhookDelivery struct {
+	ID             string          `json:"id"`
+	WebhookID      string          `json:"webhookId"`
+	EventID        string          `json:"eventId"`
+	EventType      string          `json:"eventType"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	LastStatusCode *int            `json:"lastStatusCode,omitempty"`
+	LastError      *string         `json:"lastError,omitempty"`
+	NextAttemptAt  time.Time       `json:"nextAttemptAt"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+}
+
+// WebhookJob is a due delivery with what is needed to send it.
+type WebhookJob struct {
+	Delivery WebhookDelivery
+	URL      string
+	Secret   string
+}
+
+// DeliveryResult is the outcome of one attempt. NextAttemptAt is nil when no retry follows.
+type DeliveryResult struct {
+	Status        string
+	StatusCode    *int
+	Error         *string
+	NextAttemptAt *time.Time
+}
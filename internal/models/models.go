@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -29,15 +30,121 @@ const (
 )
 
 type User struct {
-	ID    string `json:"id"`
-	Email string `json:"email"`
-	Role  Role   `json:"role"`
+	ID      string `json:"id"`
+	Email   string `json:"email"`
+	Role    Role   `json:"role"`
+	Blocked bool   `json:"blocked"`
+}
+
+const (
+	PermPVZRead         = "pvz:read"
+	PermPVZReadAll      = "pvz:read_all" // live events of any pvz, not only the assigned ones
+	PermPVZCreate       = "pvz:create"
+	PermPVZUpdate       = "pvz:update"
+	PermReceptionOpen   = "reception:open"
+	PermReceptionClose  = "reception:close"
+	PermReceptionReopen = "reception:reopen"
+	PermProductCreate   = "product:create"
+	PermProductDelete   = "product:delete"
+	PermUserManage      = "user:manage"
+	PermWebhookManage   = "webhook:manage"
+	PermReportRead      = "report:read"
+)
+
+// Permissions are all permission names the service checks.
+var Permissions = []string{
+	PermPVZRead, PermPVZReadAll, PermPVZCreate, PermPVZUpdate,
+	PermReceptionOpen, PermReceptionClose, PermReceptionReopen,
+	PermProductCreate, PermProductDelete, PermUserManage, PermWebhookManage, PermReportRead,
+}
+
+// DefaultRolePermissions are the roles used when the config defines none.
+var DefaultRolePermissions = map[Role][]string{
+	Employee:  {PermPVZRead, PermReceptionOpen, PermReceptionClose, PermProductCreate, PermProductDelete},
+	Moderator: {PermPVZRead, PermPVZReadAll, PermPVZCreate, PermPVZUpdate, PermReceptionReopen, PermUserManage, PermWebhookManage, PermReportRead},
+}
+
+// Me describes the current user.
+type Me struct {
+	User
+	PVZs        []PVZ    `json:"pvzs"`
+	Permissions []string `json:"permissions"`
+}
+
+// UserUpdate holds the fields a moderator changes, nil fields are left as is.
+type UserUpdate struct {
+	Role    *Role
+	Blocked *bool
+}
+
+// APIKey lets an integration call the API with the permissions listed in Scopes.
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  string     `json:"createdBy,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+}
+
+// NewAPIKey is returned once on creation, only the hash of Key is stored.
+type NewAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// ExternalIdentity is a verified user of an external identity provider.
+type ExternalIdentity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Role          Role
+}
+
+// DummyUsers are the accounts /dummyLogin issues tokens for in dev and test.
+var DummyUsers = map[Role]User{
+	Employee:  {ID: "00000000-0000-4000-8000-000000000001", Email: "dummy-employee@pvz.local", Role: Employee},
+	Moderator: {ID: "00000000-0000-4000-8000-000000000002", Email: "dummy-moderator@pvz.local", Role: Moderator},
 }
 
 type PVZ struct {
 	ID               string    `json:"id"`
 	RegistrationDate time.Time `json:"registrationDate"`
 	City             City      `json:"city"`
+	// Address and coordinates are set for pvz created by an import
+	Address   string   `json:"address,omitempty"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+	Version   int      `json:"version"`
+}
+
+func (p PVZ) ETag() string {
+	return ETag(p.ID, p.Version)
+}
+
+// PVZImportRow is a validated row of an import file, Line is its line in the file.
+type PVZImportRow struct {
+	Line      int
+	City      City
+	Address   string
+	Latitude  float64
+	Longitude float64
+}
+
+type PVZImportError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// PVZImportReport is the outcome of an import, nothing is created when Errors isn't empty.
+type PVZImportReport struct {
+	DryRun  bool             `json:"dryRun"`
+	Rows    int              `json:"rows"`
+	Errors  []PVZImportError `json:"errors"`
+	Created []PVZ            `json:"created"`
 }
 
 type Reception struct {
@@ -45,6 +152,22 @@ type Reception struct {
 	DateTime time.Time       `json:"dateTime"`
 	PvzID    string          `json:"pvzId"`
 	Status   ReceptionStatus `json:"status"`
+	Version  int             `json:"version"`
+	// ClosedAt and ClosedBy are set while the reception is closed, ClosedBy is empty when an API key closed it
+	ClosedAt *time.Time `json:"closedAt,omitempty"`
+	ClosedBy string     `json:"closedBy,omitempty"`
+}
+
+// ReceptionDate is the reception time a date range applies to.
+type ReceptionDate string
+
+const (
+	ByOpenDate  ReceptionDate = "open"
+	ByCloseDate ReceptionDate = "close"
+)
+
+func (r Reception) ETag() string {
+	return ETag(r.ID, r.Version)
 }
 
 type Product struct {
@@ -59,7 +182,48 @@ type PVZInfo struct {
 	Receptions []ReceptionWithProducts `json:"receptions"`
 }
 
+// PVZRevision identifies the state of a pvz in a PVZInfo page: its version and the seq of
+// its latest event, every change of its receptions and products stores one.
+type PVZRevision struct {
+	ID       string
+	Version  int
+	EventSeq int64
+}
+
 type ReceptionWithProducts struct {
 	Reception Reception `json:"reception"`
 	Products  []Product `json:"products"`
 }
+
+// IdempotencyRecord is the stored outcome of a request sent with an Idempotency-Key header.
+// Completed is false while the first request is still being processed.
+type IdempotencyRecord struct {
+	RequestHash string
+	StatusCode  int
+	ContentType string
+	// Headers are the response headers repeated on replay, such as ETag and Location
+	Headers   map[string]string
+	Body      []byte
+	Completed bool
+}
+
+// ETag is a strong entity tag for a versioned resource. The id is part of the tag,
+// so a tag of one reception never matches another reception of the same pvz.
+func ETag(id string, version int) string {
+	return fmt.Sprintf(`"%s-%d"`, id, version)
+}
+
+// IfMatch holds entity tags from an If-Match header, nil means the request is unconditional.
+type IfMatch []string
+
+func (m IfMatch) Allows(etag string) bool {
+	if m == nil {
+		return true
+	}
+	for _, tag := range m {
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package models
+
+import "time"
+
+// Dimensions of an intake report, at most one of them is a period.
+const (
+	GroupByPVZ         = "pvz"
+	GroupByCity        = "city"
+	GroupByProductType = "productType"
+	GroupByDay         = "day"
+	GroupByWeek        = "week"
+	GroupByMonth       = "month"
+)
+
+var (
+	IntakeDimensions = []string{GroupByPVZ, GroupByCity, GroupByProductType, GroupByDay, GroupByWeek, GroupByMonth}
+	IntakePeriods    = []string{GroupByDay, GroupByWeek, GroupByMonth}
+)
+
+// IntakeReportFilter selects receptions opened between Start and End.
+type IntakeReportFilter struct {
+	Start   time.Time
+	End     time.Time
+	GroupBy []string
+}
+
+type IntakeReport struct {
+	StartDate time.Time   `json:"startDate"`
+	EndDate   time.Time   `json:"endDate"`
+	GroupBy   []string    `json:"groupBy"`
+	Rows      []IntakeRow `json:"rows"`
+}
+
+// IntakeRow is one group of an intake report, only the grouped fields are set.
+// Durations are from opening to closing of the closed receptions, in seconds.
+type IntakeRow struct {
+	PvzID       string      `json:"pvzId,omitempty"`
+	City        City        `json:"city,omitempty"`
+	ProductType ProductType `json:"productType,omitempty"`
+	Period      *time.Time  `json:"period,omitempty"`
+
+	Products         int      `json:"products"`
+	Receptions       int      `json:"receptions"`
+	ClosedReceptions int      `json:"closedReceptions"`
+	AvgDuration      *float64 `json:"avgDurationSeconds,omitempty"`
+	MaxDuration      *float64 `json:"maxDurationSeconds,omitempty"`
+}
+
+// ReceptionStatsFilter selects receptions opened between Start and End, of one pvz when PvzID is set.
+type ReceptionStatsFilter struct {
+	Start time.Time
+	End   time.Time
+	PvzID string
+}
+
+type ReceptionStatsReport struct {
+	StartDate time.Time        `json:"startDate"`
+	EndDate   time.Time        `json:"endDate"`
+	Rows      []ReceptionStats `json:"rows"`
+}
+
+// ReceptionStats are the reception metrics of a pvz. The duration and the intake rate
+// are of closed receptions only, an open one has no end yet.
+type ReceptionStats struct {
+	PvzID            string `json:"pvzId"`
+	City             City   `json:"city"`
+	Receptions       int    `json:"receptions"`
+	ClosedReceptions int    `json:"closedReceptions"`
+	Products         int    `json:"products"`
+
+	ProductsPerReception float64  `json:"productsPerReception"`
+	AvgDuration          *float64 `json:"avgDurationSeconds,omitempty"`
+	ProductsPerHour      *float64 `json:"productsPerHour,omitempty"`
+	// DeletionShare is the share of receptions a product was deleted from, from 0 to 1
+	DeletionShare float64 `json:"deletionShare"`
+}
+
+// ExportReception is a row of the receptions export.
+type ExportReception struct {
+	Reception
+	City     City
+	Products int
+}
+
+// ExportProduct is a row of the products export.
+type ExportProduct struct {
+	Product
+	PvzID string
+	City  City
+}
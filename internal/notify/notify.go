@@ -0,0 +1,66 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"pvz/internal/logger"
+	"sync"
+	"time"
+)
+
+// Notifier delivers account messages to a user. Real delivery (email, sms)
+// is plugged in by implementing this interface.
+type Notifier interface {
+	PasswordReset(ctx context.Context, email, token string, expiresAt time.Time) error
+}
+
+// LogNotifier writes the message to the application log, for local development only.
+type LogNotifier struct{}
+
+func (LogNotifier) PasswordReset(ctx context.Context, email, token string, expiresAt time.Time) error {
+	logger.FromContext(ctx).Info("password reset requested",
+		"email", email, "reset_token", token, "expires_at", expiresAt)
+	return nil
+}
+
+// FileNotifier appends messages as JSON lines to a file.
+type FileNotifier struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{Path: path}
+}
+
+type message struct {
+	Kind      string    `json:"kind"`
+	Email     string    `json:"email"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (n *FileNotifier) PasswordReset(_ context.Context, email, token string, expiresAt time.Time) error {
+	return n.write(message{
+		Kind:      "password_reset",
+		Email:     email,
+		Token:     token,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now().UTC(),
+	})
+}
+
+func (n *FileNotifier) write(msg message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open notification file: %w", err)
+	}
+	defer f.Close()
+	return json.NewEncoder(f).Encode(msg)
+}
@@ -0,0 +1,40 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileNotifierAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	n := NewFileNotifier(path)
+	expires := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, n.PasswordReset(context.Background(), "a@b.com", "tok-1", expires))
+	require.NoError(t, n.PasswordReset(context.Background(), "c@d.com", "tok-2", expires))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var got []message
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var msg message
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &msg))
+		got = append(got, msg)
+	}
+	require.Len(t, got, 2)
+	require.Equal(t, "password_reset", got[0].Kind)
+	require.Equal(t, "a@b.com", got[0].Email)
+	require.Equal(t, "tok-1", got[0].Token)
+	require.True(t, expires.Equal(got[0].ExpiresAt))
+	require.Equal(t, "tok-2", got[1].Token)
+}
@@ -0,0 +1,78 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"pvz/internal/models"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const ContentTypeProtobuf = "application/protobuf"
+
+// NATSPublisher sends protobuf encoded events to <prefix>.<event type>.
+// With JetStream a publish succeeds once the stream stored the message and
+// repeated events are dropped by the stream using the event id as Nats-Msg-Id.
+// Core NATS only guarantees the server received the message.
+type NATSPublisher struct {
+	conn   *nats.Conn
+	js     jetstream.JetStream
+	prefix string
+	// Timeout limits waiting for the server acknowledgement
+	Timeout time.Duration
+}
+
+func NewNATSPublisher(conn *nats.Conn, prefix string, useJetStream bool) (*NATSPublisher, error) {
+	p := &NATSPublisher{conn: conn, prefix: prefix, Timeout: 5 * time.Second}
+	if useJetStream {
+		js, err := jetstream.New(conn)
+		if err != nil {
+			return nil, models.Wrap("jetstream", err)
+		}
+		p.js = js
+	}
+	return p, nil
+}
+
+// EnsureStream creates or updates the stream that stores all event subjects.
+func (p *NATSPublisher) EnsureStream(ctx context.Context, name string) error {
+	if p.js == nil {
+		return errors.New("stream requires jetstream")
+	}
+	_, err := p.js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     name,
+		Subjects: []string{p.prefix + ".>"},
+	})
+	if err != nil {
+		return models.Wrap("create stream "+name, err)
+	}
+	return nil
+}
+
+func (p *NATSPublisher) Subject(eventType string) string {
+	return p.prefix + "." + eventType
+}
+
+func (p *NATSPublisher) Publish(ctx context.Context, event models.Event) error {
+	data, err := MarshalProto(event)
+	if err != nil {
+		return err
+	}
+	msg := nats.NewMsg(p.Subject(event.Type))
+	msg.Data = data
+	msg.Header.Set(nats.MsgIdHdr, event.ID)
+	msg.Header.Set("Content-Type", ContentTypeProtobuf)
+
+	ctx, cancel := context.WithTimeout(ctx, p.Timeout)
+	defer cancel()
+	if p.js != nil {
+		_, err := p.js.PublishMsg(ctx, msg)
+		return err
+	}
+	if err := p.conn.PublishMsg(msg); err != nil {
+		return err
+	}
+	return p.conn.FlushWithContext(ctx)
+}
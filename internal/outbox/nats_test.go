@@ -0,0 +1,131 @@
+package outbox
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"pvz/internal/models"
+	"pvz/pkg/eventspb"
+)
+
+func runNATS(t *testing.T) *nats.Conn {
+	t.Helper()
+	ns, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1, JetStream: true, StoreDir: t.TempDir(), NoLog: true, NoSigs: true})
+	require.NoError(t, err)
+	go ns.Start()
+	t.Cleanup(ns.Shutdown)
+	require.True(t, ns.ReadyForConnections(5*time.Second), "nats server is not ready")
+
+	conn, err := nats.Connect(ns.ClientURL())
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+	return conn
+}
+
+func closedEvent(t *testing.T) models.Event {
+	opened := time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC)
+	event, err := models.NewEvent(models.EventReceptionClosed, "pvz-1", models.ReceptionWithProducts{
+		Reception: models.Reception{ID: "r1", DateTime: opened, PvzID: "pvz-1", Status: models.StatusClose, Version: 2},
+		Products: []models.Product{
+			{ID: "prod-1", DateTime: opened.Add(time.Minute), Type: models.Shoes, ReceptionID: "r1"},
+			{ID: "prod-2", DateTime: opened.Add(2 * time.Minute), Type: models.Electronic, ReceptionID: "r1"},
+		},
+	})
+	require.NoError(t, err)
+	event.Seq = 42
+	return event
+}
+
+func TestNATSPublisherCore(t *testing.T) {
+	conn := runNATS(t)
+	sub, err := conn.SubscribeSync("pvz.events.>")
+	require.NoError(t, err)
+
+	pub, err := NewNATSPublisher(conn, "pvz.events", false)
+	require.NoError(t, err)
+	event := closedEvent(t)
+	require.NoError(t, pub.Publish(context.Background(), event))
+
+	msg, err := sub.NextMsg(5 * time.Second)
+	require.NoError(t, err)
+	require.Equal(t, "pvz.events.reception.closed", msg.Subject)
+	require.Equal(t, ContentTypeProtobuf, msg.Header.Get("Content-Type"))
+	require.Equal(t, event.ID, msg.Header.Get(nats.MsgIdHdr))
+
+	var got eventspb.Event
+	require.NoError(t, proto.Unmarshal(msg.Data, &got))
+	require.Equal(t, event.ID, got.GetId())
+	require.Equal(t, int64(42), got.GetSeq())
+	closed := got.GetReceptionClosed()
+	require.NotNil(t, closed)
+	require.Equal(t, eventspb.ReceptionStatus_RECEPTION_STATUS_CLOSED, closed.GetReception().GetStatus())
+	require.Len(t, closed.GetProducts(), 2)
+	require.Equal(t, string(models.Electronic), closed.GetProducts()[1].GetType())
+	require.True(t, closed.GetProducts()[0].GetDateTime().AsTime().Equal(time.Date(2025, 4, 1, 9, 1, 0, 0, time.UTC)))
+}
+
+func TestNATSPublisherJetStreamDeduplicates(t *testing.T) {
+	conn := runNATS(t)
+	ctx := context.Background()
+
+	pub, err := NewNATSPublisher(conn, "pvz.events", true)
+	require.NoError(t, err)
+	require.NoError(t, pub.EnsureStream(ctx, "PVZ_EVENTS"))
+
+	event := closedEvent(t)
+	require.NoError(t, pub.Publish(ctx, event))
+	// the relay publishes again when marking the event sent failed
+	require.NoError(t, pub.Publish(ctx, event))
+
+	js, err := jetstream.New(conn)
+	require.NoError(t, err)
+	stream, err := js.Stream(ctx, "PVZ_EVENTS")
+	require.NoError(t, err)
+	info, err := stream.Info(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), info.State.Msgs)
+}
+
+func TestNATSPublisherStreamRequiresJetStream(t *testing.T) {
+	pub, err := NewNATSPublisher(runNATS(t), "pvz.events", false)
+	require.NoError(t, err)
+	require.Error(t, pub.EnsureStream(context.Background(), "PVZ_EVENTS"))
+}
+
+func TestProtoEvent(t *testing.T) {
+	product := models.Product{ID: "prod-1", Type: models.Clothes, ReceptionID: "r1"}
+	added, err := models.NewEvent(models.EventProductAdded, "pvz-1", product)
+	require.NoError(t, err)
+	msg, err := ProtoEvent(added)
+	require.NoError(t, err)
+	require.Equal(t, "prod-1", msg.GetProductAdded().GetProduct().GetId())
+
+	deleted, err := models.NewEvent(models.EventProductDeleted, "pvz-1", product)
+	require.NoError(t, err)
+	msg, err = ProtoEvent(deleted)
+	require.NoError(t, err)
+	require.Equal(t, "r1", msg.GetProductDeleted().GetProduct().GetReceptionId())
+
+	opened, err := models.NewEvent(models.EventReceptionOpened, "pvz-1", models.Reception{ID: "r1", Status: models.StatusInProgress})
+	require.NoError(t, err)
+	msg, err = ProtoEvent(opened)
+	require.NoError(t, err)
+	require.Equal(t, eventspb.ReceptionStatus_RECEPTION_STATUS_IN_PROGRESS, msg.GetReceptionOpened().GetReception().GetStatus())
+
+	reopened, err := models.NewEvent(models.EventReceptionReopened, "pvz-1", models.Reception{ID: "r1", Status: models.StatusInProgress})
+	require.NoError(t, err)
+	msg, err = ProtoEvent(reopened)
+	require.NoError(t, err)
+	require.Equal(t, models.EventReceptionReopened, msg.GetType())
+	require.Equal(t, "r1", msg.GetReceptionOpened().GetReception().GetId())
+
+	_, err = ProtoEvent(models.Event{Type: "pvz.burned", Data: []byte(`{}`)})
+	require.Error(t, err)
+}
@@ -0,0 +1,96 @@
+// Package outbox publishes the domain events stored by the repository.
+//
+// Events are written to the outbox table in the transaction of the change they
+// describe. A Relay per destination reads the events that destination hasn't got yet
+// in order, hands them to its Publisher and marks them sent for that destination, so
+// every committed change is published at least once to each of them.
+package outbox
+
+import (
+	"context"
+	"pvz/internal/logger"
+	"pvz/internal/models"
+	"time"
+)
+
+type Publisher interface {
+	Publish(ctx context.Context, event models.Event) error
+}
+
+// Store is the outbox table, it tracks sent events per destination.
+type Store interface {
+	ClaimOutbox(ctx context.Context, dest models.Destination, limit int, lease time.Duration) ([]models.Event, error)
+	MarkOutboxSent(ctx context.Context, dest models.Destination, eventIDs []string) error
+}
+
+type Config struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// Lease is how long claimed events are hidden from other relays
+	Lease time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{PollInterval: time.Second, BatchSize: 100, Lease: time.Minute}
+}
+
+type Relay struct {
+	Store       Store
+	Destination models.Destination
+	Publisher   Publisher
+	Config      Config
+}
+
+func NewRelay(store Store, dest models.Destination, publisher Publisher, cfg Config) *Relay {
+	return &Relay{Store: store, Destination: dest, Publisher: publisher, Config: cfg}
+}
+
+// Run publishes events every poll interval until ctx is canceled.
+func (r *Relay) Run(ctx context.Context) {
+	log := logger.FromContext(ctx).With("destination", r.Destination)
+	ticker := time.NewTicker(r.Config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := r.RunOnce(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Error("outbox relay", "error", err)
+			}
+			if err != nil || n < r.Config.BatchSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce publishes one batch of events and returns how many were published.
+// It stops at the first failure to keep the order, the rest of the batch is
+// claimed again after the lease.
+func (r *Relay) RunOnce(ctx context.Context) (int, error) {
+	events, err := r.Store.ClaimOutbox(ctx, r.Destination, r.Config.BatchSize, r.Config.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := make([]string, 0, len(events))
+	var pubErr error
+	for _, event := range events {
+		if pubErr = r.Publisher.Publish(ctx, event); pubErr != nil {
+			pubErr = models.Wrap("publish event "+event.ID, pubErr)
+			break
+		}
+		sent = append(sent, event.ID)
+	}
+	if len(sent) > 0 {
+		if err := r.Store.MarkOutboxSent(ctx, r.Destination, sent); err != nil {
+			return len(sent), err
+		}
+	}
+	return len(sent), pubErr
+}
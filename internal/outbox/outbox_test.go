@@ -0,0 +1,197 @@
+package outbox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"pvz/internal/models"
+)
+
+type memStore struct {
+	mu     sync.Mutex
+	events []models.Event
+	sent   map[models.Destination]map[string]bool
+}
+
+func newMemStore(n int) *memStore {
+	s := &memStore{sent: map[models.Destination]map[string]bool{}}
+	for i := 1; i <= n; i++ {
+		s.events = append(s.events, models.Event{
+			ID: "e" + strconv.Itoa(i), Seq: int64(i), Type: models.EventProductAdded, PvzID: "p1", Data: []byte(`{}`),
+		})
+	}
+	return s
+}
+
+func (s *memStore) ClaimOutbox(_ context.Context, dest models.Destination, limit int, _ time.Duration) ([]models.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var events []models.Event
+	for _, e := range s.events {
+		if !s.sent[dest][e.ID] && len(events) < limit {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (s *memStore) MarkOutboxSent(_ context.Context, dest models.Destination, ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sent[dest] == nil {
+		s.sent[dest] = map[string]bool{}
+	}
+	for _, id := range ids {
+		s.sent[dest][id] = true
+	}
+	return nil
+}
+
+func eventIDs(events []models.Event) []string {
+	ids := make([]string, 0, len(events))
+	for _, e := range events {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func TestRelayPublishesInOrder(t *testing.T) {
+	store := newMemStore(5)
+	pub := &MemoryPublisher{}
+	relay := NewRelay(store, models.DestPublisher, pub, Config{PollInterval: time.Second, BatchSize: 3})
+
+	n, err := relay.RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	n, err = relay.RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	n, err = relay.RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	require.Equal(t, []string{"e1", "e2", "e3", "e4", "e5"}, eventIDs(pub.Events()))
+}
+
+type failingPublisher struct {
+	MemoryPublisher
+	failOn string
+}
+
+func (p *failingPublisher) Publish(ctx context.Context, event models.Event) error {
+	if event.ID == p.failOn {
+		return errors.New("broker down")
+	}
+	return p.MemoryPublisher.Publish(ctx, event)
+}
+
+func TestRelayStopsAtFailure(t *testing.T) {
+	store := newMemStore(4)
+	pub := &failingPublisher{failOn: "e3"}
+	relay := NewRelay(store, models.DestPublisher, pub, DefaultConfig())
+
+	n, err := relay.RunOnce(context.Background())
+	require.ErrorContains(t, err, "broker down")
+	require.Equal(t, 2, n)
+	require.Equal(t, map[string]bool{"e1": true, "e2": true}, store.sent[models.DestPublisher])
+
+	pub.failOn = ""
+	n, err = relay.RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, []string{"e1", "e2", "e3", "e4"}, eventIDs(pub.Events()))
+}
+
+func TestRelayDestinationsAreIndependent(t *testing.T) {
+	store := newMemStore(3)
+	hooks := &MemoryPublisher{}
+	broker := &MemoryPublisher{Err: errors.New("broker down")}
+	hooksRelay := NewRelay(store, models.DestWebhooks, hooks, DefaultConfig())
+	brokerRelay := NewRelay(store, models.DestPublisher, broker, DefaultConfig())
+
+	_, err := brokerRelay.RunOnce(context.Background())
+	require.ErrorContains(t, err, "broker down")
+	n, err := hooksRelay.RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	require.Empty(t, store.sent[models.DestPublisher])
+
+	// the broker is back: it gets every event, webhooks get nothing twice
+	broker.Err = nil
+	n, err = brokerRelay.RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	n, err = hooksRelay.RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, n)
+	require.Equal(t, []string{"e1", "e2", "e3"}, eventIDs(hooks.Events()))
+	require.Equal(t, []string{"e1", "e2", "e3"}, eventIDs(broker.Events()))
+}
+
+func TestRelayRunStopsOnCancel(t *testing.T) {
+	store := newMemStore(1)
+	pub := &MemoryPublisher{}
+	relay := NewRelay(store, models.DestPublisher, pub, Config{PollInterval: 10 * time.Millisecond, BatchSize: 10})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		relay.Run(ctx)
+		close(done)
+	}()
+	require.Eventually(t, func() bool { return len(pub.Events()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("relay did not stop")
+	}
+}
+
+func TestWriterAndFilePublishers(t *testing.T) {
+	event := models.Event{ID: "e1", Type: models.EventReceptionClosed, PvzID: "p1", Data: []byte(`{"id":"r1"}`)}
+
+	var buf bytes.Buffer
+	require.NoError(t, NewWriterPublisher(&buf).Publish(context.Background(), event))
+	var got models.Event
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	require.Equal(t, event.ID, got.ID)
+
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	p := NewFilePublisher(path)
+	require.NoError(t, p.Publish(context.Background(), event))
+	require.NoError(t, p.Publish(context.Background(), event))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	lines := 0
+	for sc := bufio.NewScanner(f); sc.Scan(); lines++ {
+		require.JSONEq(t, `{"id":"r1"}`, string(mustEvent(t, sc.Bytes()).Data))
+	}
+	require.Equal(t, 2, lines)
+}
+
+func mustEvent(t *testing.T, line []byte) models.Event {
+	var e models.Event
+	require.NoError(t, json.Unmarshal(line, &e))
+	return e
+}
+
+func TestMulti(t *testing.T) {
+	a, b := &MemoryPublisher{}, &MemoryPublisher{Err: errors.New("b down")}
+	err := Multi{a, b}.Publish(context.Background(), models.Event{ID: "e1"})
+	require.ErrorContains(t, err, "b down")
+	require.Len(t, a.Events(), 1)
+}
@@ -0,0 +1,89 @@
+package outbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"pvz/internal/models"
+	"pvz/pkg/eventspb"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// MarshalProto encodes the event as eventspb.Event.
+func MarshalProto(event models.Event) ([]byte, error) {
+	msg, err := ProtoEvent(event)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(msg)
+}
+
+// ProtoEvent converts the event, its JSON data becomes the typed payload.
+func ProtoEvent(event models.Event) (*eventspb.Event, error) {
+	msg := &eventspb.Event{
+		Id:         event.ID,
+		Type:       event.Type,
+		OccurredAt: timestamppb.New(event.OccurredAt),
+		PvzId:      event.PvzID,
+		Seq:        event.Seq,
+	}
+
+	switch event.Type {
+	// a reopened reception is in progress again, the payload is the same as when it was opened
+	case models.EventReceptionOpened, models.EventReceptionReopened:
+		var rec models.Reception
+		if err := json.Unmarshal(event.Data, &rec); err != nil {
+			return nil, models.Wrap("decode reception", err)
+		}
+		msg.Payload = &eventspb.Event_ReceptionOpened{ReceptionOpened: &eventspb.ReceptionOpened{Reception: protoReception(rec)}}
+	case models.EventReceptionClosed:
+		var rec models.ReceptionWithProducts
+		if err := json.Unmarshal(event.Data, &rec); err != nil {
+			return nil, models.Wrap("decode reception", err)
+		}
+		closed := &eventspb.ReceptionClosed{Reception: protoReception(rec.Reception)}
+		for _, p := range rec.Products {
+			closed.Products = append(closed.Products, protoProduct(p))
+		}
+		msg.Payload = &eventspb.Event_ReceptionClosed{ReceptionClosed: closed}
+	case models.EventProductAdded, models.EventProductDeleted:
+		var p models.Product
+		if err := json.Unmarshal(event.Data, &p); err != nil {
+			return nil, models.Wrap("decode product", err)
+		}
+		if event.Type == models.EventProductAdded {
+			msg.Payload = &eventspb.Event_ProductAdded{ProductAdded: &eventspb.ProductAdded{Product: protoProduct(p)}}
+		} else {
+			msg.Payload = &eventspb.Event_ProductDeleted{ProductDeleted: &eventspb.ProductDeleted{Product: protoProduct(p)}}
+		}
+	default:
+		return nil, fmt.Errorf("unknown event type %q", event.Type)
+	}
+	return msg, nil
+}
+
+func protoReception(rec models.Reception) *eventspb.Reception {
+	status := eventspb.ReceptionStatus_RECEPTION_STATUS_UNSPECIFIED
+	switch rec.Status {
+	case models.StatusInProgress:
+		status = eventspb.ReceptionStatus_RECEPTION_STATUS_IN_PROGRESS
+	case models.StatusClose:
+		status = eventspb.ReceptionStatus_RECEPTION_STATUS_CLOSED
+	}
+	return &eventspb.Reception{
+		Id:       rec.ID,
+		DateTime: timestamppb.New(rec.DateTime),
+		PvzId:    rec.PvzID,
+		Status:   status,
+	}
+}
+
+func protoProduct(p models.Product) *eventspb.Product {
+	return &eventspb.Product{
+		Id:          p.ID,
+		DateTime:    timestamppb.New(p.DateTime),
+		Type:        string(p.Type),
+		ReceptionId: p.ReceptionID,
+	}
+}
@@ -0,0 +1,104 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"pvz/internal/models"
+	"slices"
+	"sync"
+)
+
+// WriterPublisher writes events as JSON lines, NewStdoutPublisher is meant for local development.
+type WriterPublisher struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterPublisher(w io.Writer) *WriterPublisher {
+	return &WriterPublisher{w: w}
+}
+
+func NewStdoutPublisher() *WriterPublisher {
+	return NewWriterPublisher(os.Stdout)
+}
+
+func (p *WriterPublisher) Publish(_ context.Context, event models.Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err = p.w.Write(append(line, '\n'))
+	return err
+}
+
+// FilePublisher appends events as JSON lines to a file.
+type FilePublisher struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func NewFilePublisher(path string) *FilePublisher {
+	return &FilePublisher{Path: path}
+}
+
+func (p *FilePublisher) Publish(_ context.Context, event models.Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	f, err := os.OpenFile(p.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// MemoryPublisher keeps published events, for tests.
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []models.Event
+	// Err is returned instead of publishing when set
+	Err error
+}
+
+func (p *MemoryPublisher) Publish(_ context.Context, event models.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.Err != nil {
+		return p.Err
+	}
+	p.events = append(p.events, event)
+	return nil
+}
+
+func (p *MemoryPublisher) Events() []models.Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.events)
+}
+
+// Multi publishes every event to all publishers. When one of them fails the event
+// is published again to all of them, so each must tolerate duplicates.
+type Multi []Publisher
+
+func (m Multi) Publish(ctx context.Context, event models.Event) error {
+	var errs []error
+	for _, p := range m {
+		if err := p.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,101 @@
+package password
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//go:embed common.txt
+var commonList string
+
+var common = func() map[string]struct{} {
+	set := map[string]struct{}{}
+	sc := bufio.NewScanner(strings.NewReader(commonList))
+	for sc.Scan() {
+		if line := strings.TrimSpace(sc.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			set[strings.ToLower(line)] = struct{}{}
+		}
+	}
+	return set
+}()
+
+var ErrWeakPassword = errors.New("password does not satisfy the policy")
+
+type Policy struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	RejectCommon  bool
+}
+
+func DefaultPolicy() Policy {
+	return Policy{
+		MinLength:    8,
+		MaxLength:    72,
+		RequireUpper: true,
+		RequireLower: true,
+		RequireDigit: true,
+		RejectCommon: true,
+	}
+}
+
+// Check returns ErrWeakPassword wrapped with the list of unmet requirements.
+func (p Policy) Check(password string) error {
+	var problems []string
+
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		problems = append(problems, fmt.Sprintf("at least %d characters", p.MinLength))
+	}
+	// bcrypt ignores everything after 72 bytes
+	if p.MaxLength > 0 && len(password) > p.MaxLength {
+		problems = append(problems, fmt.Sprintf("at most %d bytes", p.MaxLength))
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+	if p.RequireUpper && !upper {
+		problems = append(problems, "an uppercase letter")
+	}
+	if p.RequireLower && !lower {
+		problems = append(problems, "a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		problems = append(problems, "a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		problems = append(problems, "a symbol")
+	}
+	if p.RejectCommon && IsCommon(password) {
+		problems = append(problems, "not a commonly used password")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: must contain %s", ErrWeakPassword, strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+func IsCommon(password string) bool {
+	_, ok := common[strings.ToLower(password)]
+	return ok
+}
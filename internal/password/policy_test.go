@@ -0,0 +1,48 @@
+package password
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	p := DefaultPolicy()
+	cases := []struct {
+		name     string
+		password string
+		wantErr  bool
+	}{
+		{"valid", "Rec3ption-Desk", false},
+		{"too short", "Ab1", true},
+		{"no upper", "reception42", true},
+		{"no lower", "RECEPTION42", true},
+		{"no digit", "ReceptionDesk", true},
+		{"common", "Password123", true},
+		{"common other case", "pASSWORD123", true},
+		{"too long", "Aa1" + string(make([]byte, 80)), true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := p.Check(tc.password)
+			if tc.wantErr {
+				require.ErrorIs(t, err, ErrWeakPassword)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPolicyCheckSymbol(t *testing.T) {
+	p := Policy{MinLength: 4, RequireSymbol: true}
+	require.ErrorIs(t, p.Check("abcd"), ErrWeakPassword)
+	require.NoError(t, p.Check("ab-cd"))
+}
+
+func TestPolicyCheckListsProblems(t *testing.T) {
+	err := DefaultPolicy().Check("abc")
+	require.ErrorContains(t, err, "at least 8 characters")
+	require.ErrorContains(t, err, "an uppercase letter")
+	require.ErrorContains(t, err, "a digit")
+}
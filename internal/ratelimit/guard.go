@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"pvz/internal/logger"
+	"pvz/internal/metrics"
+	"strings"
+	"time"
+)
+
+var ErrLocked = errors.New("too many failed login attempts, try again later")
+
+type Config struct {
+	MaxFailuresPerIP    int
+	MaxFailuresPerEmail int
+	Window              time.Duration
+	Lockout             time.Duration
+}
+
+// LoginGuard locks out an ip or an email after too many failed logins in a window.
+type LoginGuard struct {
+	store Store
+	cfg   Config
+	now   func() time.Time
+}
+
+func NewLoginGuard(store Store, cfg Config) *LoginGuard {
+	return &LoginGuard{store: store, cfg: cfg, now: time.Now}
+}
+
+type guardKey struct {
+	kind  string
+	value string
+	max   int
+}
+
+func (g *LoginGuard) keys(ip, email string) []guardKey {
+	return []guardKey{g.ipKey(ip), g.emailKey(email)}
+}
+
+func (g *LoginGuard) ipKey(ip string) guardKey {
+	return guardKey{kind: "ip", value: ip, max: g.cfg.MaxFailuresPerIP}
+}
+
+func (g *LoginGuard) emailKey(email string) guardKey {
+	return guardKey{kind: "email", value: strings.ToLower(strings.TrimSpace(email)), max: g.cfg.MaxFailuresPerEmail}
+}
+
+func (k guardKey) String() string {
+	return "login:" + k.kind + ":" + k.value
+}
+
+// Allow returns ErrLocked and the remaining lockout time if the ip or the email is locked out.
+func (g *LoginGuard) Allow(ctx context.Context, ip, email string) (time.Duration, error) {
+	for _, key := range g.keys(ip, email) {
+		until, err := g.store.LockedUntil(ctx, key.String())
+		if err != nil {
+			return 0, err
+		}
+		if wait := until.Sub(g.now()); wait > 0 {
+			return wait, ErrLocked
+		}
+	}
+	return 0, nil
+}
+
+func (g *LoginGuard) Failed(ctx context.Context, ip, email string) error {
+	for _, key := range g.keys(ip, email) {
+		if key.max <= 0 {
+			continue
+		}
+		failures, err := g.store.Incr(ctx, key.String(), g.cfg.Window)
+		if err != nil {
+			return err
+		}
+		if failures < key.max {
+			continue
+		}
+		until := g.now().Add(g.cfg.Lockout)
+		if err := g.store.Lock(ctx, key.String(), until); err != nil {
+			return err
+		}
+		metrics.AuthLockouts.WithLabelValues(key.kind).Inc()
+		logger.FromContext(ctx).Warn("login locked out",
+			"kind", key.kind, "key", key.value, "failures", failures, "locked_until", until)
+	}
+	return nil
+}
+
+// Succeeded clears the failures of the email. The ip counter is kept, otherwise
+// a single valid account would be enough to keep guessing passwords of others.
+func (g *LoginGuard) Succeeded(ctx context.Context, _, email string) error {
+	return g.store.Reset(ctx, g.emailKey(email).String())
+}
@@ -0,0 +1,89 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time { return c.t }
+
+func newTestGuard() (*LoginGuard, *clock) {
+	c := &clock{t: time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore()
+	store.now = c.now
+	g := NewLoginGuard(store, Config{
+		MaxFailuresPerIP:    5,
+		MaxFailuresPerEmail: 3,
+		Window:              time.Minute,
+		Lockout:             10 * time.Minute,
+	})
+	g.now = c.now
+	return g, c
+}
+
+func TestEmailLockout(t *testing.T) {
+	ctx := context.Background()
+	g, c := newTestGuard()
+
+	for range 2 {
+		require.NoError(t, g.Failed(ctx, "1.1.1.1", "User@Example.com"))
+	}
+	_, err := g.Allow(ctx, "2.2.2.2", "user@example.com")
+	require.NoError(t, err)
+
+	require.NoError(t, g.Failed(ctx, "1.1.1.1", "user@example.com"))
+	wait, err := g.Allow(ctx, "2.2.2.2", "user@example.com")
+	require.ErrorIs(t, err, ErrLocked)
+	require.Equal(t, 10*time.Minute, wait)
+
+	_, err = g.Allow(ctx, "2.2.2.2", "other@example.com")
+	require.NoError(t, err)
+
+	c.t = c.t.Add(11 * time.Minute)
+	_, err = g.Allow(ctx, "2.2.2.2", "user@example.com")
+	require.NoError(t, err)
+}
+
+func TestIPLockout(t *testing.T) {
+	ctx := context.Background()
+	g, _ := newTestGuard()
+
+	for i := range 5 {
+		require.NoError(t, g.Failed(ctx, "1.1.1.1", string(rune('a'+i))+"@example.com"))
+	}
+	_, err := g.Allow(ctx, "1.1.1.1", "new@example.com")
+	require.ErrorIs(t, err, ErrLocked)
+	_, err = g.Allow(ctx, "2.2.2.2", "new@example.com")
+	require.NoError(t, err)
+}
+
+func TestWindowExpiry(t *testing.T) {
+	ctx := context.Background()
+	g, c := newTestGuard()
+
+	require.NoError(t, g.Failed(ctx, "1.1.1.1", "user@example.com"))
+	require.NoError(t, g.Failed(ctx, "1.1.1.1", "user@example.com"))
+	c.t = c.t.Add(2 * time.Minute)
+	require.NoError(t, g.Failed(ctx, "1.1.1.1", "user@example.com"))
+
+	_, err := g.Allow(ctx, "1.1.1.1", "user@example.com")
+	require.NoError(t, err)
+}
+
+func TestSucceededResetsEmail(t *testing.T) {
+	ctx := context.Background()
+	g, _ := newTestGuard()
+
+	require.NoError(t, g.Failed(ctx, "1.1.1.1", "user@example.com"))
+	require.NoError(t, g.Failed(ctx, "1.1.1.1", "user@example.com"))
+	require.NoError(t, g.Succeeded(ctx, "1.1.1.1", "user@example.com"))
+	require.NoError(t, g.Failed(ctx, "1.1.1.1", "user@example.com"))
+
+	_, err := g.Allow(ctx, "1.1.1.1", "user@example.com")
+	require.NoError(t, err)
+}
@@ -0,0 +1,99 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Store keeps failure counters and lockouts. MemoryStore is used by default,
+// a shared store (e.g. redis) is needed when the service runs in several replicas.
+type Store interface {
+	// Incr increments the counter of key and returns the new value.
+	// The counter is reset once window has passed since the first increment.
+	Incr(ctx context.Context, key string, window time.Duration) (int, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	LockedUntil(ctx context.Context, key string) (time.Time, error)
+	Reset(ctx context.Context, key string) error
+}
+
+type entry struct {
+	count       int
+	expiresAt   time.Time
+	lockedUntil time.Time
+}
+
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*entry
+	now     func() time.Time
+	ops     int
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: map[string]*entry{}, now: time.Now}
+}
+
+func (s *MemoryStore) Incr(_ context.Context, key string, window time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep()
+
+	now := s.now()
+	e, ok := s.entries[key]
+	if !ok {
+		e = &entry{}
+		s.entries[key] = e
+	}
+	if e.count == 0 || now.After(e.expiresAt) {
+		e.count = 0
+		e.expiresAt = now.Add(window)
+	}
+	e.count++
+	return e.count, nil
+}
+
+func (s *MemoryStore) Lock(_ context.Context, key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		e = &entry{}
+		s.entries[key] = e
+	}
+	e.lockedUntil = until
+	return nil
+}
+
+func (s *MemoryStore) LockedUntil(_ context.Context, key string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[key]; ok {
+		return e.lockedUntil, nil
+	}
+	return time.Time{}, nil
+}
+
+func (s *MemoryStore) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// sweep drops stale entries from time to time, so the map doesn't grow with every ip seen.
+func (s *MemoryStore) sweep() {
+	s.ops++
+	if s.ops%1024 != 0 {
+		return
+	}
+	now := s.now()
+	for key, e := range s.entries {
+		if now.After(e.expiresAt) && now.After(e.lockedUntil) {
+			delete(s.entries, key)
+		}
+	}
+}
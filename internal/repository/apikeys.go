@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"pvz/internal/models"
+
+	"github.com/lib/pq"
+)
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+const apiKeyColumns = `id, name, prefix, scopes, COALESCE(created_by::text, ''), create_date, expires_at, last_used_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAPIKey(row rowScanner) (models.APIKey, error) {
+	var key models.APIKey
+	err := row.Scan(&key.ID, &key.Name, &key.Prefix, pq.Array(&key.Scopes), &key.CreatedBy,
+		&key.CreatedAt, &key.ExpiresAt, &key.LastUsedAt)
+	return key, err
+}
+
+func (r *Repository) CreateAPIKey(ctx context.Context, key models.APIKey, keyHash string) error {
+	const query = `INSERT INTO api_keys (id, name, prefix, key_hash, scopes, created_by, create_date, expires_at)
+	VALUES ($1, $2, $3, $4, $5, NULLIF($6, '')::uuid, $7, $8);`
+	_, err := r.DB.ExecContext(ctx, query, key.ID, key.Name, key.Prefix, keyHash, pq.Array(key.Scopes),
+		key.CreatedBy, key.CreatedAt, key.ExpiresAt)
+	if err != nil {
+		return models.Wrap("insert api key", err)
+	}
+	return nil
+}
+
+func (r *Repository) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	const query = `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY create_date, id;`
+
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, models.Wrap("select api keys", err)
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, models.Wrap("api keys rows scan", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, models.Wrap("rows err api keys", err)
+	}
+	return keys, nil
+}
+
+func (r *Repository) DeleteAPIKey(ctx context.Context, keyID string) error {
+	const query = `DELETE FROM api_keys WHERE id = $1;`
+	res, err := r.DB.ExecContext(ctx, query, keyID)
+	if err != nil {
+		return models.Wrap("delete api key", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return models.Wrap("rows affected", err)
+	}
+	if n == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// AuthenticateAPIKey finds an unexpired key by its hash and records its use. It returns the
+// role of the creator, keys of deleted or blocked users are not found.
+func (r *Repository) AuthenticateAPIKey(ctx context.Context, keyHash string) (models.APIKey, models.Role, error) {
+	const query = `UPDATE api_keys k SET last_used_at = now()
+	FROM users u
+	WHERE k.key_hash = $1 AND (k.expires_at IS NULL OR k.expires_at > now())
+		AND u.id = k.created_by AND NOT u.blocked
+	RETURNING k.id, k.name, k.prefix, k.scopes, k.created_by::text, k.create_date, k.expires_at, k.last_used_at, u.role;`
+
+	var (
+		key  models.APIKey
+		role models.Role
+	)
+	err := r.DB.QueryRowContext(ctx, query, keyHash).Scan(&key.ID, &key.Name, &key.Prefix, pq.Array(&key.Scopes),
+		&key.CreatedBy, &key.CreatedAt, &key.ExpiresAt, &key.LastUsedAt, &role)
+	if err == sql.ErrNoRows {
+		return models.APIKey{}, "", ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return models.APIKey{}, "", models.Wrap("authenticate api key", err)
+	}
+	return key, role, nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"pvz/internal/models"
+	"time"
+)
+
+// ExportReceptions passes receptions opened in the range to fn in opening order. Rows are
+// read from the connection as fn consumes them, the result is never held in memory.
+func (r *Repository) ExportReceptions(ctx context.Context, start, end time.Time, fn func(models.ExportReception) error) error {
+	const query = `SELECT r.id, r.pvz_id, pvz.city, r.create_date, r.closed_at, r.status,
+	(SELECT COUNT(*) FROM products p WHERE p.reception_id = r.id)
+	FROM receptions r JOIN pvz ON pvz.id = r.pvz_id
+	WHERE r.create_date >= $1 AND r.create_date <= $2
+	ORDER BY r.create_date, r.id;`
+
+	rows, err := r.DB.QueryContext(ctx, query, start, end)
+	if err != nil {
+		return models.Wrap("select export receptions", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rec models.ExportReception
+		if err := rows.Scan(&rec.ID, &rec.PvzID, &rec.City, &rec.DateTime, &rec.ClosedAt, &rec.Status, &rec.Products); err != nil {
+			return models.Wrap("export receptions rows scan", err)
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return models.Wrap("rows err export receptions", err)
+	}
+	return nil
+}
+
+// ExportProducts passes products of receptions opened in the range to fn in the order they were received.
+func (r *Repository) ExportProducts(ctx context.Context, start, end time.Time, fn func(models.ExportProduct) error) error {
+	const query = `SELECT p.id, p.create_date, p.type, p.reception_id, r.pvz_id, pvz.city
+	FROM products p
+	JOIN receptions r ON r.id = p.reception_id
+	JOIN pvz ON pvz.id = r.pvz_id
+	WHERE r.create_date >= $1 AND r.create_date <= $2
+	ORDER BY p.create_date, p.id;`
+
+	rows, err := r.DB.QueryContext(ctx, query, start, end)
+	if err != nil {
+		return models.Wrap("select export products", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p models.ExportProduct
+		if err := rows.Scan(&p.ID, &p.DateTime, &p.Type, &p.ReceptionID, &p.PvzID, &p.City); err != nil {
+			return models.Wrap("export products rows scan", err)
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return models.Wrap("rows err export products", err)
+	}
+	return nil
+}
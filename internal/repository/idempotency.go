@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"pvz/internal/models"
+	"time"
+)
+
+// ReserveIdempotencyKey claims the key for the user. If the key was already used it returns
+// the stored record and false. A record created before expireBefore no longer counts,
+// the key is claimed anew; PurgeIdempotencyKeys removes such records in the background.
+func (r *Repository) ReserveIdempotencyKey(ctx context.Context, userID, key, requestHash string,
+	expireBefore time.Time) (models.IdempotencyRecord, bool, error) {
+
+	const insertQuery = `INSERT INTO idempotency_keys (user_id, idempotency_key, request_hash, create_date)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (user_id, idempotency_key) DO UPDATE
+	SET request_hash = EXCLUDED.request_hash, create_date = EXCLUDED.create_date,
+		status_code = NULL, content_type = NULL, response_headers = NULL, response_body = NULL
+	WHERE idempotency_keys.create_date < $5;`
+	res, err := r.DB.ExecContext(ctx, insertQuery, userID, key, requestHash, time.Now().UTC(), expireBefore)
+	if err != nil {
+		return models.IdempotencyRecord{}, false, models.Wrap("insert idempotency key", err)
+	}
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return models.IdempotencyRecord{}, false, models.Wrap("insert idempotency key", err)
+	}
+	if inserted == 1 {
+		return models.IdempotencyRecord{RequestHash: requestHash}, true, nil
+	}
+
+	const selectQuery = `SELECT request_hash, status_code, content_type, response_headers, response_body
+	FROM idempotency_keys WHERE user_id = $1 AND idempotency_key = $2;`
+	var (
+		rec         models.IdempotencyRecord
+		statusCode  sql.NullInt64
+		contentType sql.NullString
+		headers     []byte
+	)
+	err = r.DB.QueryRowContext(ctx, selectQuery, userID, key).Scan(&rec.RequestHash, &statusCode, &contentType, &headers, &rec.Body)
+	if err != nil {
+		return models.IdempotencyRecord{}, false, models.Wrap("select idempotency key", err)
+	}
+	if len(headers) > 0 {
+		if err := json.Unmarshal(headers, &rec.Headers); err != nil {
+			return models.IdempotencyRecord{}, false, models.Wrap("decode idempotent response headers", err)
+		}
+	}
+	rec.Completed = statusCode.Valid
+	rec.StatusCode = int(statusCode.Int64)
+	rec.ContentType = contentType.String
+	return rec, false, nil
+}
+
+func (r *Repository) SaveIdempotentResponse(ctx context.Context, userID, key string, rec models.IdempotencyRecord) error {
+	headers, err := json.Marshal(rec.Headers)
+	if err != nil {
+		return models.Wrap("encode idempotent response headers", err)
+	}
+	const query = `UPDATE idempotency_keys SET status_code = $1, content_type = $2, response_headers = $3, response_body = $4
+	WHERE user_id = $5 AND idempotency_key = $6;`
+	if _, err := r.DB.ExecContext(ctx, query, rec.StatusCode, rec.ContentType, string(headers), rec.Body, userID, key); err != nil {
+		return models.Wrap("save idempotent response", err)
+	}
+	return nil
+}
+
+// PurgeIdempotencyKeys deletes records created before expireBefore and returns how many.
+func (r *Repository) PurgeIdempotencyKeys(ctx context.Context, expireBefore time.Time) (int64, error) {
+	const query = `DELETE FROM idempotency_keys WHERE create_date < $1;`
+	res, err := r.DB.ExecContext(ctx, query, expireBefore)
+	if err != nil {
+		return 0, models.Wrap("purge idempotency keys", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, models.Wrap("purge idempotency keys", err)
+	}
+	return n, nil
+}
+
+// ReleaseIdempotencyKey removes a reservation whose request failed, so the client can retry it.
+func (r *Repository) ReleaseIdempotencyKey(ctx context.Context, userID, key string) error {
+	const query = `DELETE FROM idempotency_keys WHERE user_id = $1 AND idempotency_key = $2;`
+	if _, err := r.DB.ExecContext(ctx, query, userID, key); err != nil {
+		return models.Wrap("release idempotency key", err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"pvz/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var ErrIdentityConflict = errors.New("email belongs to another account")
+
+// ProvisionUser returns the user linked to the external identity, creating it on first login.
+// An existing account with the same email is linked only when the provider verified the email.
+// The role follows the provider for accounts it created, a linked local account keeps the role
+// given here. Blocked users get ErrUserBlocked.
+func (r *Repository) ProvisionUser(ctx context.Context, ident models.ExternalIdentity) (models.User, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.User{}, ErrBeginTransaction
+	}
+	defer tx.Rollback()
+
+	const linkedQuery = `SELECT u.id, u.email, u.role, u.blocked, u.password = '!' FROM user_identities i
+	JOIN users u ON u.id = i.user_id
+	WHERE i.issuer = $1 AND i.subject = $2
+	FOR UPDATE OF u;`
+	var (
+		user        models.User
+		provisioned bool
+	)
+	err = tx.QueryRowContext(ctx, linkedQuery, ident.Issuer, ident.Subject).
+		Scan(&user.ID, &user.Email, &user.Role, &user.Blocked, &provisioned)
+	switch {
+	case err == nil:
+	case err != sql.ErrNoRows:
+		return models.User{}, models.Wrap("select linked user", err)
+	default:
+		user, provisioned, err = linkUser(ctx, tx, ident)
+		if err != nil {
+			return models.User{}, err
+		}
+	}
+
+	if user.Blocked {
+		return models.User{}, ErrUserBlocked
+	}
+	// a moderator who also signs in through the provider mustn't be demoted by its claims
+	if provisioned && user.Role != ident.Role {
+		const roleQuery = `UPDATE users SET role = $1 WHERE id = $2;`
+		if _, err := tx.ExecContext(ctx, roleQuery, ident.Role, user.ID); err != nil {
+			return models.User{}, models.Wrap("update user role", err)
+		}
+		user.Role = ident.Role
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.User{}, ErrCommitTransaction
+	}
+	return user, nil
+}
+
+// linkUser links the identity to the account with its email or creates one. It reports whether
+// the account was created by the provider.
+func linkUser(ctx context.Context, tx *sql.Tx, ident models.ExternalIdentity) (models.User, bool, error) {
+	const selectQuery = `SELECT id, email, role, blocked, password = '!' FROM users WHERE email = $1 FOR UPDATE;`
+	var (
+		user        models.User
+		provisioned bool
+	)
+	err := tx.QueryRowContext(ctx, selectQuery, ident.Email).Scan(&user.ID, &user.Email, &user.Role, &user.Blocked, &provisioned)
+	switch {
+	case err == nil:
+		if !ident.EmailVerified {
+			return models.User{}, false, ErrIdentityConflict
+		}
+	case err != sql.ErrNoRows:
+		return models.User{}, false, models.Wrap("select user by email", err)
+	default:
+		provisioned = true
+		user = models.User{ID: uuid.NewString(), Email: ident.Email, Role: ident.Role}
+		// the password is not a bcrypt hash, the account can only be used through the provider
+		const insertUserQuery = `INSERT INTO users (id, email, password, role) VALUES ($1, $2, '!', $3);`
+		if _, err := tx.ExecContext(ctx, insertUserQuery, user.ID, user.Email, user.Role); err != nil {
+			if isConstraintViolation(err, pqUniqueViolation, "users_email_key") {
+				return models.User{}, false, ErrIdentityConflict
+			}
+			return models.User{}, false, models.Wrap("insert user", err)
+		}
+	}
+
+	const insertIdentityQuery = `INSERT INTO user_identities (issuer, subject, user_id, create_date)
+	VALUES ($1, $2, $3, now());`
+	if _, err := tx.ExecContext(ctx, insertIdentityQuery, ident.Issuer, ident.Subject, user.ID); err != nil {
+		return models.User{}, false, models.Wrap("insert identity", err)
+	}
+	return user, provisioned, nil
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"pvz/internal/models"
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// insertEvent stores the event in the outbox as part of tx, so it is published
+// if and only if the change it describes is committed.
+func insertEvent(ctx context.Context, tx *sql.Tx, eventType, pvzID string, data any) error {
+	event, err := models.NewEvent(eventType, pvzID, data)
+	if err != nil {
+		return err
+	}
+	const query = `INSERT INTO outbox (id, event_type, pvz_id, payload, create_date)
+	VALUES ($1, $2, $3, $4, $5);`
+	if _, err := tx.ExecContext(ctx, query, event.ID, event.Type, event.PvzID, string(event.Data), event.OccurredAt); err != nil {
+		return models.Wrap("insert outbox event", err)
+	}
+	return nil
+}
+
+// receptionProducts lists the products of the reception for the reception.closed event.
+func receptionProducts(ctx context.Context, tx *sql.Tx, receptionID string) ([]models.Product, error) {
+	const query = `SELECT id, create_date, type FROM products WHERE reception_id = $1 ORDER BY create_date, id;`
+	rows, err := tx.QueryContext(ctx, query, receptionID)
+	if err != nil {
+		return nil, models.Wrap("select reception products", err)
+	}
+	defer rows.Close()
+
+	products := []models.Product{}
+	for rows.Next() {
+		p := models.Product{ReceptionID: receptionID}
+		if err := rows.Scan(&p.ID, &p.DateTime, &p.Type); err != nil {
+			return nil, models.Wrap("reception products rows scan", err)
+		}
+		products = append(products, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, models.Wrap("rows err reception products", err)
+	}
+	return products, nil
+}
+
+// outboxMarkers are the lock and sent columns each destination keeps on outbox rows.
+var outboxMarkers = map[models.Destination]struct{ lock, sent string }{
+	models.DestWebhooks:  {lock: "locked_until", sent: "sent_at"},
+	models.DestPublisher: {lock: "publish_locked_until", sent: "published_at"},
+}
+
+func outboxMarker(dest models.Destination) (lock, sent string, err error) {
+	m, ok := outboxMarkers[dest]
+	if !ok {
+		return "", "", fmt.Errorf("unknown outbox destination %q", dest)
+	}
+	return m.lock, m.sent, nil
+}
+
+// ClaimOutbox locks up to limit events not yet sent to dest for lease and returns them
+// in seq order. Events of a relay that crashed are claimed again once the lease expires.
+func (r *Repository) ClaimOutbox(ctx context.Context, dest models.Destination, limit int, lease time.Duration) ([]models.Event, error) {
+	lock, sent, err := outboxMarker(dest)
+	if err != nil {
+		return nil, err
+	}
+	query := `UPDATE outbox SET ` + lock + ` = now() + $2 * interval '1 millisecond'
+	WHERE seq IN (
+		SELECT seq FROM outbox
+		WHERE ` + sent + ` IS NULL AND (` + lock + ` IS NULL OR ` + lock + ` <= now())
+		ORDER BY seq
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING seq, id, event_type, pvz_id, payload, create_date;`
+
+	rows, err := r.DB.QueryContext(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, models.Wrap("claim outbox", err)
+	}
+	defer rows.Close()
+
+	var events []models.Event
+	for rows.Next() {
+		var e models.Event
+		var payload []byte
+		if err := rows.Scan(&e.Seq, &e.ID, &e.Type, &e.PvzID, &payload, &e.OccurredAt); err != nil {
+			return nil, models.Wrap("outbox rows scan", err)
+		}
+		e.Data = payload
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, models.Wrap("rows err outbox", err)
+	}
+	// RETURNING keeps no order
+	slices.SortFunc(events, func(a, b models.Event) int { return cmp.Compare(a.Seq, b.Seq) })
+	return events, nil
+}
+
+func (r *Repository) MarkOutboxSent(ctx context.Context, dest models.Destination, eventIDs []string) error {
+	lock, sent, err := outboxMarker(dest)
+	if err != nil {
+		return err
+	}
+	query := `UPDATE outbox SET ` + sent + ` = now(), ` + lock + ` = NULL WHERE id = ANY($1);`
+	if _, err := r.DB.ExecContext(ctx, query, pq.Array(eventIDs)); err != nil {
+		return models.Wrap("mark outbox sent", err)
+	}
+	return nil
+}
+
+// PVZEventsHead returns the seq of the last stored event of the pvz, 0 when there is none.
+func (r *Repository) PVZEventsHead(ctx context.Context, pvzID string) (int64, error) {
+	const query = `SELECT (SELECT COALESCE(MAX(seq), 0) FROM outbox WHERE pvz_id = p.id)
+	FROM pvz p WHERE p.id = $1;`
+	var head int64
+	err := r.DB.QueryRowContext(ctx, query, pvzID).Scan(&head)
+	if err == sql.ErrNoRows {
+		return 0, ErrPvzNotFound
+	}
+	if err != nil {
+		return 0, models.Wrap("select pvz events head", err)
+	}
+	return head, nil
+}
+
+// ListPVZEvents returns events of the pvz stored after afterSeq, sent or not.
+// Changes of one pvz are serialized by row locks, so their seq follows the commit order.
+func (r *Repository) ListPVZEvents(ctx context.Context, pvzID string, afterSeq int64, limit int) ([]models.Event, error) {
+	const query = `SELECT seq, id, event_type, pvz_id, payload, create_date FROM outbox
+	WHERE pvz_id = $1 AND seq > $2
+	ORDER BY seq
+	LIMIT $3;`
+
+	rows, err := r.DB.QueryContext(ctx, query, pvzID, afterSeq, limit)
+	if err != nil {
+		return nil, models.Wrap("select pvz events", err)
+	}
+	defer rows.Close()
+
+	events := []models.Event{}
+	for rows.Next() {
+		var e models.Event
+		var payload []byte
+		if err := rows.Scan(&e.Seq, &e.ID, &e.Type, &e.PvzID, &payload, &e.OccurredAt); err != nil {
+			return nil, models.Wrap("pvz events rows scan", err)
+		}
+		e.Data = payload
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, models.Wrap("rows err pvz events", err)
+	}
+	return events, nil
+}
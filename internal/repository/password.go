@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"pvz/internal/models"
+	"pvz/pkg/utils"
+	"time"
+)
+
+var ErrInvalidResetToken = errors.New("reset token is invalid or expired")
+
+// ChangePassword replaces the password hash after checking the current password.
+func (r *Repository) ChangePassword(ctx context.Context, userID, oldPassword, newPasswordHash string) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return ErrBeginTransaction
+	}
+	defer tx.Rollback()
+
+	var hash string
+	err = tx.QueryRowContext(ctx, `SELECT password FROM users WHERE id = $1 FOR UPDATE;`, userID).Scan(&hash)
+	if err == sql.ErrNoRows {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return models.Wrap("select user", err)
+	}
+	if !utils.CheckHashPassword(hash, oldPassword) {
+		return ErrInvalidPassword
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE users SET password = $1 WHERE id = $2;`, newPasswordHash, userID); err != nil {
+		return models.Wrap("update password", err)
+	}
+	// a changed password invalidates reset links that were sent before
+	const revokeQuery = `UPDATE password_resets SET used_at = $1 WHERE user_id = $2 AND used_at IS NULL;`
+	if _, err := tx.ExecContext(ctx, revokeQuery, time.Now().UTC(), userID); err != nil {
+		return models.Wrap("revoke password resets", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return ErrCommitTransaction
+	}
+	return nil
+}
+
+// CreatePasswordReset stores a reset token hash for the user with the email.
+// It returns false if there is no such user or the user has no local password:
+// accounts of an identity provider must not get one through a reset.
+func (r *Repository) CreatePasswordReset(ctx context.Context, email, tokenHash string, expiresAt time.Time) (bool, error) {
+	const query = `INSERT INTO password_resets (token_hash, user_id, expires_at, create_date)
+	SELECT $1, id, $2, $3 FROM users WHERE email = $4 AND password <> '!';`
+	res, err := r.DB.ExecContext(ctx, query, tokenHash, expiresAt, time.Now().UTC(), email)
+	if err != nil {
+		return false, models.Wrap("insert password reset", err)
+	}
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return false, models.Wrap("insert password reset", err)
+	}
+	return inserted == 1, nil
+}
+
+// ResetPassword sets a new password hash by an unused, unexpired reset token.
+// The token and all other pending tokens of the user are marked as used.
+func (r *Repository) ResetPassword(ctx context.Context, tokenHash, newPasswordHash string) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return ErrBeginTransaction
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	const selectQuery = `SELECT user_id FROM password_resets
+	WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $2 FOR UPDATE;`
+	var userID string
+	err = tx.QueryRowContext(ctx, selectQuery, tokenHash, now).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return ErrInvalidResetToken
+	}
+	if err != nil {
+		return models.Wrap("select password reset", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE users SET password = $1 WHERE id = $2;`, newPasswordHash, userID); err != nil {
+		return models.Wrap("update password", err)
+	}
+	const revokeQuery = `UPDATE password_resets SET used_at = $1 WHERE user_id = $2 AND used_at IS NULL;`
+	if _, err := tx.ExecContext(ctx, revokeQuery, now, userID); err != nil {
+		return models.Wrap("revoke password resets", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return ErrCommitTransaction
+	}
+	return nil
+}
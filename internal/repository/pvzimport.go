@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pvz/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var ErrPVZExists = errors.New("pvz with this city and address already exists")
+
+const pvzAddressIndex = "pvz_city_address_idx"
+
+// FindPVZsByAddress returns pvz with one of the addresses, in any city.
+func (r *Repository) FindPVZsByAddress(ctx context.Context, addresses []string) ([]models.PVZ, error) {
+	const query = `SELECT ` + pvzColumns + ` FROM pvz WHERE address = ANY($1);`
+	rows, err := r.DB.QueryContext(ctx, query, pq.Array(addresses))
+	if err != nil {
+		return nil, models.Wrap("select pvz by address", err)
+	}
+	defer rows.Close()
+
+	pvzList := []models.PVZ{}
+	for rows.Next() {
+		var pvz models.PVZ
+		if err := scanPVZ(rows, &pvz); err != nil {
+			return nil, models.Wrap("pvz by address rows scan", err)
+		}
+		pvzList = append(pvzList, pvz)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, models.Wrap("rows err pvz by address", err)
+	}
+	return pvzList, nil
+}
+
+// ImportPVZs creates all the pvz in one transaction or none of them.
+func (r *Repository) ImportPVZs(ctx context.Context, rows []models.PVZImportRow) ([]models.PVZ, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, ErrBeginTransaction
+	}
+	defer tx.Rollback()
+
+	const query = `INSERT INTO pvz (id, create_date, city, address, latitude, longitude) VALUES ($1, $2, $3, $4, $5, $6);`
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, models.Wrap("prepare pvz insert", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC().Round(time.Millisecond)
+	created := make([]models.PVZ, 0, len(rows))
+	for _, row := range rows {
+		pvz := models.PVZ{
+			ID:               uuid.NewString(),
+			RegistrationDate: now,
+			City:             row.City,
+			Address:          row.Address,
+			Latitude:         &row.Latitude,
+			Longitude:        &row.Longitude,
+			Version:          1,
+		}
+		if _, err := stmt.ExecContext(ctx, pvz.ID, pvz.RegistrationDate, pvz.City, pvz.Address, row.Latitude, row.Longitude); err != nil {
+			if isConstraintViolation(err, pqUniqueViolation, pvzAddressIndex) {
+				return nil, fmt.Errorf("line %d: %w", row.Line, ErrPVZExists)
+			}
+			return nil, models.Wrap(fmt.Sprintf("insert pvz of line %d", row.Line), err)
+		}
+		created = append(created, pvz)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, ErrCommitTransaction
+	}
+	return created, nil
+}
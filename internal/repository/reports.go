@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"pvz/internal/models"
+	"strings"
+)
+
+// intakeColumns maps report dimensions to their SQL, in the order they are selected and sorted.
+var intakeColumns = []struct {
+	dimension, expr, name string
+}{
+	{models.GroupByPVZ, "r.pvz_id", "pvz_id"},
+	{models.GroupByCity, "pvz.city", "city"},
+	{models.GroupByProductType, "p.type", "product_type"},
+	{models.GroupByDay, "date_trunc('day', r.create_date AT TIME ZONE 'UTC')", "period"},
+	{models.GroupByWeek, "date_trunc('week', r.create_date AT TIME ZONE 'UTC')", "period"},
+	{models.GroupByMonth, "date_trunc('month', r.create_date AT TIME ZONE 'UTC')", "period"},
+}
+
+// IntakeReport counts products and receptions opened in the range per group. Receptions are
+// grouped first, so a reception's duration is counted once in each group it falls into.
+// Grouped by product type, receptions without products are left out.
+func (r *Repository) IntakeReport(ctx context.Context, f models.IntakeReportFilter) ([]models.IntakeRow, error) {
+	var exprs, names []string
+	for _, col := range intakeColumns {
+		for _, dim := range f.GroupBy {
+			if dim == col.dimension {
+				exprs = append(exprs, col.expr)
+				names = append(names, col.name)
+			}
+		}
+	}
+	productsJoin := "LEFT JOIN"
+	for _, dim := range f.GroupBy {
+		if dim == models.GroupByProductType {
+			productsJoin = "JOIN"
+		}
+	}
+
+	var inner, outer strings.Builder
+	for i := range exprs {
+		fmt.Fprintf(&inner, "%s AS %s, ", exprs[i], names[i])
+		fmt.Fprintf(&outer, "%s, ", names[i])
+	}
+	query := fmt.Sprintf(`SELECT %sCOALESCE(SUM(products), 0), COUNT(*), COUNT(duration), AVG(duration), MAX(duration)
+	FROM (
+		SELECT %sCOUNT(p.id) AS products, EXTRACT(EPOCH FROM r.closed_at - r.create_date) AS duration
+		FROM receptions r
+		JOIN pvz ON pvz.id = r.pvz_id
+		%s products p ON p.reception_id = r.id
+		WHERE r.create_date >= $1 AND r.create_date <= $2
+		GROUP BY %s
+	) per_reception`, outer.String(), inner.String(), productsJoin, strings.Join(append(exprs, "r.id"), ", "))
+	if len(names) > 0 {
+		query += fmt.Sprintf("\n\tGROUP BY %[1]s\n\tORDER BY %[1]s", strings.Join(names, ", "))
+	}
+
+	rows, err := r.DB.QueryContext(ctx, query+";", f.Start, f.End)
+	if err != nil {
+		return nil, models.Wrap("select intake report", err)
+	}
+	defer rows.Close()
+
+	report := []models.IntakeRow{}
+	for rows.Next() {
+		var (
+			row                      models.IntakeRow
+			pvzID, city, productType sql.NullString
+			period                   sql.NullTime
+			avgDur, maxDur           sql.NullFloat64
+		)
+		dest := make([]any, 0, len(names)+5)
+		for _, name := range names {
+			switch name {
+			case "pvz_id":
+				dest = append(dest, &pvzID)
+			case "city":
+				dest = append(dest, &city)
+			case "product_type":
+				dest = append(dest, &productType)
+			case "period":
+				dest = append(dest, &period)
+			}
+		}
+		dest = append(dest, &row.Products, &row.Receptions, &row.ClosedReceptions, &avgDur, &maxDur)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, models.Wrap("intake report rows scan", err)
+		}
+		row.PvzID = pvzID.String
+		row.City = models.City(city.String)
+		row.ProductType = models.ProductType(productType.String)
+		if period.Valid {
+			row.Period = &period.Time
+		}
+		if avgDur.Valid {
+			row.AvgDuration, row.MaxDuration = &avgDur.Float64, &maxDur.Float64
+		}
+		report = append(report, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, models.Wrap("rows err intake report", err)
+	}
+	return report, nil
+}
+
+// ReceptionStats computes reception metrics per pvz over the receptions opened in the range,
+// pvz without such receptions are left out.
+func (r *Repository) ReceptionStats(ctx context.Context, f models.ReceptionStatsFilter) ([]models.ReceptionStats, error) {
+	const query = `SELECT pvz_id, city, COUNT(*), COUNT(duration), SUM(products), AVG(products), AVG(duration),
+		SUM(products) FILTER (WHERE duration IS NOT NULL) / NULLIF(SUM(duration), 0) * 3600,
+		AVG(CASE WHEN deleted_products > 0 THEN 1 ELSE 0 END)
+	FROM (
+		SELECT r.pvz_id, pvz.city, COUNT(p.id) AS products, r.deleted_products,
+			EXTRACT(EPOCH FROM r.closed_at - r.create_date) AS duration
+		FROM receptions r
+		JOIN pvz ON pvz.id = r.pvz_id
+		LEFT JOIN products p ON p.reception_id = r.id
+		WHERE r.create_date >= $1 AND r.create_date <= $2 AND ($3 = '' OR r.pvz_id::text = $3)
+		GROUP BY r.pvz_id, pvz.city, r.id
+	) per_reception
+	GROUP BY pvz_id, city
+	ORDER BY pvz_id;`
+
+	rows, err := r.DB.QueryContext(ctx, query, f.Start, f.End, f.PvzID)
+	if err != nil {
+		return nil, models.Wrap("select reception stats", err)
+	}
+	defer rows.Close()
+
+	stats := []models.ReceptionStats{}
+	for rows.Next() {
+		var (
+			s               models.ReceptionStats
+			avgDur, perHour sql.NullFloat64
+		)
+		err := rows.Scan(&s.PvzID, &s.City, &s.Receptions, &s.ClosedReceptions, &s.Products,
+			&s.ProductsPerReception, &avgDur, &perHour, &s.DeletionShare)
+		if err != nil {
+			return nil, models.Wrap("reception stats rows scan", err)
+		}
+		if avgDur.Valid {
+			s.AvgDuration = &avgDur.Float64
+		}
+		if perHour.Valid {
+			s.ProductsPerHour = &perHour.Float64
+		}
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, models.Wrap("rows err reception stats", err)
+	}
+	return stats, nil
+}
@@ -1,9 +1,9 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
-	"fmt"
 	"pvz/internal/models"
 	"pvz/pkg/utils"
 	"time"
@@ -21,8 +21,33 @@ var (
 	ErrInvalidPassword       = errors.New("invalid password")
 	ErrBeginTransaction      = errors.New("failed to begin transaction")
 	ErrCommitTransaction     = errors.New("failed to commit transaction")
+	ErrPreconditionFailed    = errors.New("resource was modified, precondition failed")
+	ErrNoClosedReception     = errors.New("no closed reception to reopen")
+	ErrUserBlocked           = errors.New("user is blocked")
+	ErrUserExists            = errors.New("user with this email already exists")
 )
 
+const (
+	pqUniqueViolation     = pq.ErrorCode("23505")
+	pqForeignKeyViolation = pq.ErrorCode("23503")
+
+	activeReceptionIndex = "receptions_one_in_progress_idx"
+)
+
+func isConstraintViolation(err error, code pq.ErrorCode, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == code && pqErr.Constraint == constraint
+}
+
+// dummyPasswordHash is a bcrypt hash (default cost) compared against when the user doesn't exist.
+const dummyPasswordHash = "$2a$10$9NvASjgtrh9l2If5OjsynuIdXdS6L4IIFlyA/DOe2oEyCoVrG1sS2"
+
+const pvzColumns = `pvz.id, pvz.create_date, pvz.city, pvz.version, COALESCE(pvz.address, ''), pvz.latitude, pvz.longitude`
+
+func scanPVZ(row rowScanner, pvz *models.PVZ) error {
+	return row.Scan(&pvz.ID, &pvz.RegistrationDate, &pvz.City, &pvz.Version, &pvz.Address, &pvz.Latitude, &pvz.Longitude)
+}
+
 type Repository struct {
 	DB *sql.DB
 }
@@ -31,27 +56,25 @@ func NewRepository(db *sql.DB) *Repository {
 	return &Repository{DB: db}
 }
 
-func (r *Repository) RegisterUser(email, passwordHash string, role models.Role) (models.User, error) {
+func (r *Repository) RegisterUser(ctx context.Context, email, passwordHash string, role models.Role) (models.User, error) {
 	var exists bool
 	const checkQuery = `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1);`
-	err := r.DB.QueryRow(checkQuery, email).Scan(&exists)
+	err := r.DB.QueryRowContext(ctx, checkQuery, email).Scan(&exists)
 	if err != nil {
 		return models.User{}, models.Wrap("failed to check user existence", err)
 	}
 	if exists {
-		return models.User{}, fmt.Errorf("user with email %s already exists", email)
+		return models.User{}, ErrUserExists
 	}
 
 	id := uuid.NewString()
 
-	token, err := utils.GetJWToken(role, id)
-	if err != nil {
-		return models.User{}, models.Wrap("failed to generate token", err)
+	const insertQuery = `INSERT INTO users (id, email, password, role)
+	VALUES ($1, $2, $3, $4);`
+	_, err = r.DB.ExecContext(ctx, insertQuery, id, email, passwordHash, role)
+	if isConstraintViolation(err, pqUniqueViolation, "users_email_key") {
+		return models.User{}, ErrUserExists
 	}
-
-	const insertQuery = `INSERT INTO users (id, email, password, role, token)
-	VALUES ($1, $2, $3, $4, $5);`
-	_, err = r.DB.Exec(insertQuery, id, email, passwordHash, role, token)
 	if err != nil {
 		return models.User{}, models.Wrap("failed to insert user", err)
 	}
@@ -63,54 +86,81 @@ func (r *Repository) RegisterUser(email, passwordHash string, role models.Role)
 	}, nil
 }
 
-func (r *Repository) LoginUser(email, password string) (models.Token, error) {
+// EnsureUser inserts the user if there is no user with the same id. The password is set
+// to a value that is not a bcrypt hash, so nobody can log in to the account with a password.
+func (r *Repository) EnsureUser(ctx context.Context, user models.User) error {
+	const query = `INSERT INTO users (id, email, password, role)
+	VALUES ($1, $2, '!', $3) ON CONFLICT (id) DO NOTHING;`
+	if _, err := r.DB.ExecContext(ctx, query, user.ID, user.Email, user.Role); err != nil {
+		return models.Wrap("failed to insert user", err)
+	}
+	return nil
+}
 
-	const query = `SELECT password, token FROM users WHERE email = $1;`
+// LoginUser checks the credentials and returns the user a token is issued for.
+func (r *Repository) LoginUser(ctx context.Context, email, password string) (models.User, error) {
 
-	var token models.Token
+	const query = `SELECT id, email, role, blocked, password FROM users WHERE email = $1;`
+
+	var user models.User
 	var hash string
-	err := r.DB.QueryRow(query, email).Scan(&hash, &token)
+	err := r.DB.QueryRowContext(ctx, query, email).Scan(&user.ID, &user.Email, &user.Role, &user.Blocked, &hash)
 	if err == sql.ErrNoRows {
-		return "", ErrUserNotFound
+		// spend the same bcrypt time as for an existing user, so response time doesn't reveal the email
+		utils.CheckHashPassword(dummyPasswordHash, password)
+		return models.User{}, ErrUserNotFound
 	}
 	if err != nil {
-		return "", err
+		return models.User{}, err
 	}
 	if !utils.CheckHashPassword(hash, password) {
-		return "", ErrInvalidPassword
+		return models.User{}, ErrInvalidPassword
+	}
+	if user.Blocked {
+		return models.User{}, ErrUserBlocked
 	}
-	return token, nil
+	return user, nil
 }
-func (r *Repository) CreatePVZ(city models.City) (models.PVZ, error) {
+
+func (r *Repository) CreatePVZ(ctx context.Context, city models.City) (models.PVZ, error) {
 	var pvz = models.PVZ{
 		ID:               uuid.NewString(),
 		RegistrationDate: time.Now().UTC().Round(time.Millisecond),
 		City:             city,
+		Version:          1,
 	}
 
 	const query = `INSERT INTO pvz (id, create_date, city) VALUES ($1, $2, $3);`
 
-	_, err := r.DB.Exec(query, pvz.ID, pvz.RegistrationDate, pvz.City)
+	_, err := r.DB.ExecContext(ctx, query, pvz.ID, pvz.RegistrationDate, pvz.City)
 	if err != nil {
 		return models.PVZ{}, models.Wrap("can't create pvz", err)
 	}
 	return pvz, nil
 }
 
-func (r *Repository) CreateReception(pvzID string) (models.Reception, error) {
-	var exists bool
-
-	const checkPvzQuery = `SELECT EXISTS (SELECT 1 FROM pvz WHERE id = $1);`
-	err := r.DB.QueryRow(checkPvzQuery, pvzID).Scan(&exists)
+func (r *Repository) CreateReception(ctx context.Context, pvzID string) (models.Reception, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return models.Reception{}, models.Wrap("failed to check pvz existence", err)
+		return models.Reception{}, ErrBeginTransaction
 	}
-	if !exists {
+	defer tx.Rollback()
+
+	// locking the pvz row serializes concurrent openings for the same pvz,
+	// so the check below can't be passed by two transactions at once
+	const lockPvzQuery = `SELECT id FROM pvz WHERE id = $1 FOR UPDATE;`
+	var lockedID string
+	err = tx.QueryRowContext(ctx, lockPvzQuery, pvzID).Scan(&lockedID)
+	if err == sql.ErrNoRows {
 		return models.Reception{}, ErrPvzNotFound
 	}
+	if err != nil {
+		return models.Reception{}, models.Wrap("failed to lock pvz", err)
+	}
 
+	var exists bool
 	const checkRecQuery = `SELECT EXISTS (SELECT 1 FROM receptions WHERE pvz_id = $1 AND status = $2);`
-	err = r.DB.QueryRow(checkRecQuery, pvzID, models.StatusInProgress).Scan(&exists)
+	err = tx.QueryRowContext(ctx, checkRecQuery, pvzID, models.StatusInProgress).Scan(&exists)
 	if err != nil {
 		return models.Reception{}, models.Wrap("failed to check reception existence", err)
 	}
@@ -123,19 +173,31 @@ func (r *Repository) CreateReception(pvzID string) (models.Reception, error) {
 		DateTime: time.Now().UTC().Round(time.Millisecond),
 		PvzID:    pvzID,
 		Status:   models.StatusInProgress,
+		Version:  1,
 	}
 
 	const insertQuery = `INSERT INTO receptions (id, create_date, pvz_id, status)
 	VALUES ($1, $2, $3, $4);`
-	if _, err := r.DB.Exec(insertQuery, rec.ID, rec.DateTime, rec.PvzID, models.StatusInProgress); err != nil {
+	if _, err := tx.ExecContext(ctx, insertQuery, rec.ID, rec.DateTime, rec.PvzID, models.StatusInProgress); err != nil {
+		// the partial unique index is the last line of defence against a second open reception
+		if isConstraintViolation(err, pqUniqueViolation, activeReceptionIndex) {
+			return models.Reception{}, ErrReceptionInProgress
+		}
 		return models.Reception{}, models.Wrap("failed to insert reception", err)
 	}
+	if err := insertEvent(ctx, tx, models.EventReceptionOpened, pvzID, rec); err != nil {
+		return models.Reception{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Reception{}, ErrCommitTransaction
+	}
 
 	return rec, nil
 }
 
-func (r *Repository) CreateProduct(pvzID string, productType models.ProductType) (models.Product, error) {
-	tx, err := r.DB.Begin()
+func (r *Repository) CreateProduct(ctx context.Context, pvzID string, productType models.ProductType) (models.Product, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return models.Product{}, ErrBeginTransaction
 	}
@@ -146,7 +208,7 @@ func (r *Repository) CreateProduct(pvzID string, productType models.ProductType)
 	LIMIT 1;`
 
 	var receptionID string
-	err = tx.QueryRow(getReceptionIDQuery, pvzID, models.StatusInProgress).Scan(&receptionID)
+	err = tx.QueryRowContext(ctx, getReceptionIDQuery, pvzID, models.StatusInProgress).Scan(&receptionID)
 	if err == sql.ErrNoRows {
 		return models.Product{}, ErrNoActiveReception
 	}
@@ -156,50 +218,100 @@ func (r *Repository) CreateProduct(pvzID string, productType models.ProductType)
 
 	const insertProductQuery = `INSERT INTO products (id, create_date, type, reception_id)
 	VALUES ($1, $2, $3, $4);`
-	productID := uuid.NewString()
-
-	regTime := time.Now().UTC().Round(time.Millisecond)
-	_, err = tx.Exec(insertProductQuery, productID, regTime, productType, receptionID)
+	product := models.Product{
+		ID:          uuid.NewString(),
+		DateTime:    time.Now().UTC().Round(time.Millisecond),
+		Type:        productType,
+		ReceptionID: receptionID,
+	}
+	_, err = tx.ExecContext(ctx, insertProductQuery, product.ID, product.DateTime, product.Type, product.ReceptionID)
 	if err != nil {
 		return models.Product{}, models.Wrap("failed to insert product", err)
 	}
+	if err := insertEvent(ctx, tx, models.EventProductAdded, pvzID, product); err != nil {
+		return models.Product{}, err
+	}
 
 	if err := tx.Commit(); err != nil {
 		return models.Product{}, ErrCommitTransaction
 	}
 
-	return models.Product{
-		ID:          productID,
-		DateTime:    regTime,
-		Type:        productType,
-		ReceptionID: receptionID,
-	}, nil
+	return product, nil
+}
+
+func (r *Repository) UpdatePVZ(ctx context.Context, pvzID string, city models.City, ifMatch models.IfMatch) (models.PVZ, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.PVZ{}, ErrBeginTransaction
+	}
+	defer tx.Rollback()
+
+	const selectQuery = `SELECT ` + pvzColumns + ` FROM pvz WHERE id = $1 FOR UPDATE;`
+	var pvz models.PVZ
+	err = scanPVZ(tx.QueryRowContext(ctx, selectQuery, pvzID), &pvz)
+	if err == sql.ErrNoRows {
+		return models.PVZ{}, ErrPvzNotFound
+	}
+	if err != nil {
+		return models.PVZ{}, models.Wrap("select pvz", err)
+	}
+	if !ifMatch.Allows(pvz.ETag()) {
+		return models.PVZ{}, ErrPreconditionFailed
+	}
+
+	pvz.City = city
+	pvz.Version++
+	const updateQuery = `UPDATE pvz SET city = $1, version = $2 WHERE id = $3;`
+	if _, err := tx.ExecContext(ctx, updateQuery, pvz.City, pvz.Version, pvz.ID); err != nil {
+		return models.PVZ{}, models.Wrap("update pvz", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.PVZ{}, ErrCommitTransaction
+	}
+	return pvz, nil
 }
 
-func (r *Repository) CloseLastReception(pvzID string) (models.Reception, error) {
-	tx, err := r.DB.Begin()
+// CloseLastReception closes the reception in progress on behalf of closedBy, which is empty for API keys.
+func (r *Repository) CloseLastReception(ctx context.Context, closedBy, pvzID string, ifMatch models.IfMatch) (models.Reception, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return models.Reception{}, ErrBeginTransaction
 	}
 	defer tx.Rollback()
 
-	const getRecInfoQuery = `SELECT id, create_date FROM receptions WHERE pvz_id = $1 AND status = $2
+	const getRecInfoQuery = `SELECT id, create_date, version FROM receptions WHERE pvz_id = $1 AND status = $2
 	ORDER BY create_date DESC FOR UPDATE LIMIT 1;`
 
 	var rec models.Reception
-	if err := tx.QueryRow(getRecInfoQuery, pvzID, models.StatusInProgress).Scan(&rec.ID, &rec.DateTime); err != nil {
+	if err := tx.QueryRowContext(ctx, getRecInfoQuery, pvzID, models.StatusInProgress).Scan(&rec.ID, &rec.DateTime, &rec.Version); err != nil {
 		if err == sql.ErrNoRows {
 			return models.Reception{}, ErrNoActiveReception
 		}
 		return models.Reception{}, models.Wrap("select reception", err)
 	}
+	if !ifMatch.Allows(rec.ETag()) {
+		return models.Reception{}, ErrPreconditionFailed
+	}
 	rec.PvzID = pvzID
 	rec.Status = models.StatusClose
-	const updateQuery = `UPDATE receptions SET status = $1 WHERE id = $2;`
-	_, err = tx.Exec(updateQuery, rec.Status, rec.ID)
+	rec.Version++
+	closedAt := time.Now().UTC().Round(time.Millisecond)
+	rec.ClosedAt, rec.ClosedBy = &closedAt, closedBy
+	const updateQuery = `UPDATE receptions SET status = $1, version = $2, closed_at = $3, closed_by = NULLIF($4, '')::uuid
+	WHERE id = $5;`
+	_, err = tx.ExecContext(ctx, updateQuery, rec.Status, rec.Version, closedAt, closedBy, rec.ID)
 	if err != nil {
 		return models.Reception{}, models.Wrap("update reception", err)
 	}
+	products, err := receptionProducts(ctx, tx, rec.ID)
+	if err != nil {
+		return models.Reception{}, err
+	}
+	closed := models.ReceptionWithProducts{Reception: rec, Products: products}
+	if err := insertEvent(ctx, tx, models.EventReceptionClosed, pvzID, closed); err != nil {
+		return models.Reception{}, err
+	}
 
 	if err := tx.Commit(); err != nil {
 		return models.Reception{}, ErrCommitTransaction
@@ -208,10 +320,66 @@ func (r *Repository) CloseLastReception(pvzID string) (models.Reception, error)
 	return rec, nil
 }
 
-func (r *Repository) DeleteLastProduct(pvzID string) error {
-	tx, err := r.DB.Begin()
+func (r *Repository) ReopenLastReception(ctx context.Context, pvzID string, ifMatch models.IfMatch) (models.Reception, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Reception{}, ErrBeginTransaction
+	}
+	defer tx.Rollback()
+
+	// same lock as in CreateReception, opening and reopening can't race each other
+	const lockPvzQuery = `SELECT id FROM pvz WHERE id = $1 FOR UPDATE;`
+	var lockedID string
+	err = tx.QueryRowContext(ctx, lockPvzQuery, pvzID).Scan(&lockedID)
+	if err == sql.ErrNoRows {
+		return models.Reception{}, ErrPvzNotFound
+	}
+	if err != nil {
+		return models.Reception{}, models.Wrap("failed to lock pvz", err)
+	}
+
+	const getRecInfoQuery = `SELECT id, create_date, status, version FROM receptions WHERE pvz_id = $1
+	ORDER BY create_date DESC FOR UPDATE LIMIT 1;`
+	var rec models.Reception
+	err = tx.QueryRowContext(ctx, getRecInfoQuery, pvzID).Scan(&rec.ID, &rec.DateTime, &rec.Status, &rec.Version)
+	if err == sql.ErrNoRows {
+		return models.Reception{}, ErrNoClosedReception
+	}
+	if err != nil {
+		return models.Reception{}, models.Wrap("select reception", err)
+	}
+	if rec.Status == models.StatusInProgress {
+		return models.Reception{}, ErrReceptionInProgress
+	}
+	if !ifMatch.Allows(rec.ETag()) {
+		return models.Reception{}, ErrPreconditionFailed
+	}
+
+	rec.PvzID = pvzID
+	rec.Status = models.StatusInProgress
+	rec.Version++
+	const updateQuery = `UPDATE receptions SET status = $1, version = $2, closed_at = NULL, closed_by = NULL WHERE id = $3;`
+	if _, err := tx.ExecContext(ctx, updateQuery, rec.Status, rec.Version, rec.ID); err != nil {
+		if isConstraintViolation(err, pqUniqueViolation, activeReceptionIndex) {
+			return models.Reception{}, ErrReceptionInProgress
+		}
+		return models.Reception{}, models.Wrap("update reception", err)
+	}
+	if err := insertEvent(ctx, tx, models.EventReceptionReopened, pvzID, rec); err != nil {
+		return models.Reception{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Reception{}, ErrCommitTransaction
+	}
+	return rec, nil
+}
+
+// DeleteLastProduct deletes the newest product of the active reception and returns it.
+func (r *Repository) DeleteLastProduct(ctx context.Context, pvzID string) (models.Product, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return ErrBeginTransaction
+		return models.Product{}, ErrBeginTransaction
 	}
 	defer tx.Rollback()
 
@@ -219,44 +387,92 @@ func (r *Repository) DeleteLastProduct(pvzID string) error {
 	ORDER BY create_date DESC FOR UPDATE LIMIT 1;`
 
 	var receptionID string
-	if err := tx.QueryRow(getReceptionIDQuery, pvzID, models.StatusInProgress).Scan(&receptionID); err != nil {
+	if err := tx.QueryRowContext(ctx, getReceptionIDQuery, pvzID, models.StatusInProgress).Scan(&receptionID); err != nil {
 		if err == sql.ErrNoRows {
-			return ErrNoActiveReception
+			return models.Product{}, ErrNoActiveReception
 		}
-		return models.Wrap("select reception", err)
+		return models.Product{}, models.Wrap("select reception", err)
 	}
 
-	const getProductIDQuery = `SELECT id FROM products WHERE reception_id = $1
+	const getProductQuery = `SELECT id, create_date, type FROM products WHERE reception_id = $1
 	ORDER BY create_date desc FOR UPDATE LIMIT 1;`
 
-	var productID string
-	if err := tx.QueryRow(getProductIDQuery, receptionID).Scan(&productID); err != nil {
+	product := models.Product{ReceptionID: receptionID}
+	if err := tx.QueryRowContext(ctx, getProductQuery, receptionID).Scan(&product.ID, &product.DateTime, &product.Type); err != nil {
 		if err == sql.ErrNoRows {
-			return ErrNoProductsInReception
+			return models.Product{}, ErrNoProductsInReception
 		}
-		return models.Wrap("select product", err)
+		return models.Product{}, models.Wrap("select product", err)
 	}
 
 	const deleteQuery = `DELETE FROM products WHERE id = $1;`
-	if _, err := tx.Exec(deleteQuery, productID); err != nil {
-		return models.Wrap("delete product", err)
+	if _, err := tx.ExecContext(ctx, deleteQuery, product.ID); err != nil {
+		return models.Product{}, models.Wrap("delete product", err)
+	}
+	const countQuery = `UPDATE receptions SET deleted_products = deleted_products + 1 WHERE id = $1;`
+	if _, err := tx.ExecContext(ctx, countQuery, receptionID); err != nil {
+		return models.Product{}, models.Wrap("count deleted product", err)
+	}
+	if err := insertEvent(ctx, tx, models.EventProductDeleted, pvzID, product); err != nil {
+		return models.Product{}, err
 	}
 
 	if err := tx.Commit(); err != nil {
-		return ErrCommitTransaction
+		return models.Product{}, ErrCommitTransaction
 	}
-	return nil
+	return product, nil
 }
 
-func (r *Repository) GetPVZInfo(start, end time.Time, page, limit int) ([]models.PVZInfo, error) {
+// PVZInfoRevisions returns the revisions of the pvz GetPVZInfo would list with the same arguments,
+// without loading their receptions.
+func (r *Repository) PVZInfoRevisions(ctx context.Context, start, end time.Time, by models.ReceptionDate, page, limit int) ([]models.PVZRevision, error) {
+	dateColumn := "create_date"
+	if by == models.ByCloseDate {
+		dateColumn = "closed_at"
+	}
+
+	query := `SELECT p.id, p.version, (SELECT COALESCE(MAX(seq), 0) FROM outbox WHERE pvz_id = p.id)
+	FROM (SELECT DISTINCT pvz.id, pvz.version, pvz.create_date
+		FROM pvz JOIN receptions r ON r.pvz_id = pvz.id
+		WHERE r.` + dateColumn + ` BETWEEN $1 AND $2
+		ORDER BY pvz.create_date
+		LIMIT $3 OFFSET $4) p
+	ORDER BY p.create_date;`
+
+	rows, err := r.DB.QueryContext(ctx, query, start, end, limit, (page-1)*limit)
+	if err != nil {
+		return nil, models.Wrap("select pvz revisions", err)
+	}
+	defer rows.Close()
+
+	revisions := make([]models.PVZRevision, 0, limit)
+	for rows.Next() {
+		var rev models.PVZRevision
+		if err := rows.Scan(&rev.ID, &rev.Version, &rev.EventSeq); err != nil {
+			return nil, models.Wrap("pvz revisions rows scan", err)
+		}
+		revisions = append(revisions, rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, models.Wrap("rows err pvz revisions", err)
+	}
+	return revisions, nil
+}
+
+// GetPVZInfo lists pvz with receptions opened or, by ByCloseDate, closed in the range.
+func (r *Repository) GetPVZInfo(ctx context.Context, start, end time.Time, by models.ReceptionDate, page, limit int) ([]models.PVZInfo, error) {
+	dateColumn := "create_date"
+	if by == models.ByCloseDate {
+		dateColumn = "closed_at"
+	}
 
-	const selectPVZList = `SELECT DISTINCT  pvz.id, pvz.create_date, pvz.city
+	selectPVZList := `SELECT DISTINCT ` + pvzColumns + `
 	FROM pvz JOIN receptions r ON r.pvz_id = pvz.id
-	WHERE r.create_date BETWEEN $1 AND $2
+	WHERE r.` + dateColumn + ` BETWEEN $1 AND $2
 	ORDER BY pvz.create_date
 	LIMIT $3 OFFSET $4;`
 
-	rows, err := r.DB.Query(selectPVZList, start, end, limit, (page-1)*limit)
+	rows, err := r.DB.QueryContext(ctx, selectPVZList, start, end, limit, (page-1)*limit)
 	if err != nil {
 		return nil, models.Wrap("select pvz", err)
 	}
@@ -267,8 +483,7 @@ func (r *Repository) GetPVZInfo(start, end time.Time, page, limit int) ([]models
 
 	for rows.Next() {
 		var pvzInfo models.PVZInfo
-		err := rows.Scan(&pvzInfo.Pvz.ID, &pvzInfo.Pvz.RegistrationDate, &pvzInfo.Pvz.City)
-		if err != nil {
+		if err := scanPVZ(rows, &pvzInfo.Pvz); err != nil {
 			return nil, models.Wrap("pvz rows scan", err)
 		}
 		pvzInfoList = append(pvzInfoList, pvzInfo)
@@ -281,10 +496,11 @@ func (r *Repository) GetPVZInfo(start, end time.Time, page, limit int) ([]models
 		return pvzInfoList, nil
 	}
 
-	const selectRecList = `SELECT id, create_date, status, pvz_id FROM receptions
-	WHERE pvz_id = ANY($1) AND create_date >= $2 AND create_date <= $3
+	selectRecList := `SELECT id, create_date, status, pvz_id, version, closed_at, COALESCE(closed_by::text, '')
+	FROM receptions
+	WHERE pvz_id = ANY($1) AND ` + dateColumn + ` >= $2 AND ` + dateColumn + ` <= $3
 	ORDER BY create_date;`
-	recRows, err := r.DB.Query(selectRecList, pq.Array(pvzIDList), start, end)
+	recRows, err := r.DB.QueryContext(ctx, selectRecList, pq.Array(pvzIDList), start, end)
 	if err != nil {
 		return nil, models.Wrap("select reception", err)
 	}
@@ -299,6 +515,9 @@ func (r *Repository) GetPVZInfo(start, end time.Time, page, limit int) ([]models
 			&recWithProducts.Reception.DateTime,
 			&recWithProducts.Reception.Status,
 			&recWithProducts.Reception.PvzID,
+			&recWithProducts.Reception.Version,
+			&recWithProducts.Reception.ClosedAt,
+			&recWithProducts.Reception.ClosedBy,
 		); err != nil {
 			return nil, models.Wrap("reception rows scan", err)
 		}
@@ -312,7 +531,7 @@ func (r *Repository) GetPVZInfo(start, end time.Time, page, limit int) ([]models
 	const selectProductList = `SELECT id, create_date, type, reception_id
 	FROM products WHERE reception_id = ANY($1)
 	ORDER BY create_date;`
-	prodRows, err := r.DB.Query(selectProductList, pq.Array(recIDList))
+	prodRows, err := r.DB.QueryContext(ctx, selectProductList, pq.Array(recIDList))
 	if err != nil {
 		return nil, models.Wrap("select product", err)
 	}
@@ -1,14 +1,20 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"pvz/internal/models"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -20,6 +26,22 @@ func setup(t *testing.T) (*Repository, sqlmock.Sqlmock) {
 	return NewRepository(db), mock
 }
 
+var insertEventQuery = regexp.QuoteMeta(`INSERT INTO outbox (id, event_type, pvz_id, payload, create_date)
+	VALUES ($1, $2, $3, $4, $5);`)
+
+// payloadHas matches an outbox payload containing the fragment.
+type payloadHas string
+
+func (p payloadHas) Match(v driver.Value) bool {
+	s, ok := v.(string)
+	return ok && strings.Contains(s, string(p))
+}
+
+func expectEvent(mock sqlmock.Sqlmock, eventType, pvzID string) *sqlmock.ExpectedExec {
+	return mock.ExpectExec(insertEventQuery).
+		WithArgs(sqlmock.AnyArg(), eventType, pvzID, sqlmock.AnyArg(), sqlmock.AnyArg())
+}
+
 func TestRegisterUserSuccess(t *testing.T) {
 	repo, mock := setup(t)
 	defer repo.DB.Close()
@@ -27,11 +49,11 @@ func TestRegisterUserSuccess(t *testing.T) {
 	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS(SELECT 1 FROM users WHERE email = $1);`)).
 		WithArgs(email).
 		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
-	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO users (id, email, password, role, token)
-	VALUES ($1, $2, $3, $4, $5);`)).
-		WithArgs(sqlmock.AnyArg(), email, hash, role, sqlmock.AnyArg()).
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO users (id, email, password, role)
+	VALUES ($1, $2, $3, $4);`)).
+		WithArgs(sqlmock.AnyArg(), email, hash, role).
 		WillReturnResult(sqlmock.NewResult(1, 1))
-	u, err := repo.RegisterUser(email, hash, role)
+	u, err := repo.RegisterUser(context.Background(), email, hash, role)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -50,9 +72,9 @@ func TestRegisterUserAlreadyExists(t *testing.T) {
 	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS(SELECT 1 FROM users WHERE email = $1);`)).
 		WithArgs(email).
 		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
-	_, err := repo.RegisterUser(email, "", models.Role("employee"))
-	if err == nil {
-		t.Fatal("expected error")
+	_, err := repo.RegisterUser(context.Background(), email, "", models.Role("employee"))
+	if !errors.Is(err, ErrUserExists) {
+		t.Fatal(err)
 	}
 }
 func TestLoginUserSuccess(t *testing.T) {
@@ -66,21 +88,20 @@ func TestLoginUserSuccess(t *testing.T) {
 		t.Fatalf("bcrypt failed: %v", err)
 	}
 	hash := string(hashBytes)
-	token := "jwt-token"
 
 	mock.ExpectQuery(regexp.QuoteMeta(
-		`SELECT password, token FROM users WHERE email = $1;`)).
+		`SELECT id, email, role, blocked, password FROM users WHERE email = $1;`)).
 		WithArgs(email).
-		WillReturnRows(sqlmock.NewRows([]string{"password", "token"}).
-			AddRow(hash, token),
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "role", "blocked", "password"}).
+			AddRow("u1", email, "employee", false, hash),
 		)
 
-	got, err := repo.LoginUser(email, plain)
+	got, err := repo.LoginUser(context.Background(), email, plain)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
-	if got != models.Token(token) {
-		t.Errorf("token = %q, want %q", got, token)
+	if got.ID != "u1" || got.Role != models.Employee {
+		t.Errorf("user = %+v", got)
 	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
@@ -99,26 +120,43 @@ func TestLoginUserInvalidPassword(t *testing.T) {
 	hash := string(hashBytes)
 
 	mock.ExpectQuery(regexp.QuoteMeta(
-		`SELECT password, token FROM users WHERE email = $1;`)).
+		`SELECT id, email, role, blocked, password FROM users WHERE email = $1;`)).
 		WithArgs(email).
-		WillReturnRows(sqlmock.NewRows([]string{"password", "token"}).
-			AddRow(hash, "irrelevant-token"),
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "role", "blocked", "password"}).
+			AddRow("u1", email, "employee", false, hash),
 		)
 
-	_, err := repo.LoginUser(email, wrongPass)
+	_, err := repo.LoginUser(context.Background(), email, wrongPass)
 	if err != ErrInvalidPassword {
 		t.Fatalf("error = %v, want ErrInvalidPassword", err)
 	}
 }
 
+func TestEnsureUser(t *testing.T) {
+	repo, mock := setup(t)
+	defer repo.DB.Close()
+	user := models.DummyUsers[models.Employee]
+
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO users (id, email, password, role)
+	VALUES ($1, $2, '!', $3) ON CONFLICT (id) DO NOTHING;`)).
+		WithArgs(user.ID, user.Email, user.Role).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := repo.EnsureUser(context.Background(), user); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
 func TestLoginUserNotFound(t *testing.T) {
 	repo, mock := setup(t)
 	defer repo.DB.Close()
 	email := "x"
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT password, token FROM users WHERE email = $1;`)).
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, email, role, blocked, password FROM users WHERE email = $1;`)).
 		WithArgs(email).
 		WillReturnError(sql.ErrNoRows)
-	_, err := repo.LoginUser(email, "")
+	_, err := repo.LoginUser(context.Background(), email, "")
 	if err != ErrUserNotFound {
 		t.Fatalf("want ErrUserNotFound, got %v", err)
 	}
@@ -131,7 +169,7 @@ func TestCreatePVZSuccess(t *testing.T) {
 	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO pvz (id, create_date, city) VALUES ($1, $2, $3);`)).
 		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), city).
 		WillReturnResult(sqlmock.NewResult(1, 1))
-	pvz, err := repo.CreatePVZ(city)
+	pvz, err := repo.CreatePVZ(context.Background(), city)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -144,37 +182,86 @@ func TestCreateReceptionFlows(t *testing.T) {
 	repo, mock := setup(t)
 	defer repo.DB.Close()
 	pvzID := "pvz"
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM pvz WHERE id = $1);`)).
+	lockQuery := regexp.QuoteMeta(`SELECT id FROM pvz WHERE id = $1 FOR UPDATE;`)
+	checkQuery := regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM receptions WHERE pvz_id = $1 AND status = $2);`)
+	insertQuery := regexp.QuoteMeta(`INSERT INTO receptions (id, create_date, pvz_id, status)
+	VALUES ($1, $2, $3, $4);`)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(lockQuery).
 		WithArgs(pvzID).
-		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
-	_, err := repo.CreateReception(pvzID)
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+	_, err := repo.CreateReception(context.Background(), pvzID)
 	if err != ErrPvzNotFound {
 		t.Fatal(err)
 	}
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM pvz WHERE id = $1);`)).
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(lockQuery).
 		WithArgs(pvzID).
-		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM receptions WHERE pvz_id = $1 AND status = $2);`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(pvzID))
+	mock.ExpectQuery(checkQuery).
 		WithArgs(pvzID, models.StatusInProgress).
 		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
-	_, err = repo.CreateReception(pvzID)
+	mock.ExpectRollback()
+	_, err = repo.CreateReception(context.Background(), pvzID)
 	if err != ErrReceptionInProgress {
 		t.Fatal(err)
 	}
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM pvz WHERE id = $1);`)).
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(lockQuery).
 		WithArgs(pvzID).
-		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM receptions WHERE pvz_id = $1 AND status = $2);`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(pvzID))
+	mock.ExpectQuery(checkQuery).
 		WithArgs(pvzID, models.StatusInProgress).
 		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
-	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO receptions (id, create_date, pvz_id, status)
-	VALUES ($1, $2, $3, $4);`)).
+	mock.ExpectExec(insertQuery).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), pvzID, models.StatusInProgress).
+		WillReturnError(&pq.Error{Code: pqUniqueViolation, Constraint: activeReceptionIndex})
+	mock.ExpectRollback()
+	_, err = repo.CreateReception(context.Background(), pvzID)
+	if err != ErrReceptionInProgress {
+		t.Fatal(err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(lockQuery).
+		WithArgs(pvzID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(pvzID))
+	mock.ExpectQuery(checkQuery).
+		WithArgs(pvzID, models.StatusInProgress).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec(insertQuery).
 		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), pvzID, models.StatusInProgress).
 		WillReturnResult(sqlmock.NewResult(1, 1))
-	_, err = repo.CreateReception(pvzID)
+	expectEvent(mock, models.EventReceptionOpened, pvzID).WillReturnError(errors.New("outbox is gone"))
+	mock.ExpectRollback()
+	_, err = repo.CreateReception(context.Background(), pvzID)
+	if err == nil {
+		t.Fatal("reception must not be created without its event")
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(lockQuery).
+		WithArgs(pvzID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(pvzID))
+	mock.ExpectQuery(checkQuery).
+		WithArgs(pvzID, models.StatusInProgress).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec(insertQuery).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), pvzID, models.StatusInProgress).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectEvent(mock, models.EventReceptionOpened, pvzID).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	_, err = repo.CreateReception(context.Background(), pvzID)
 	if err != nil {
 		t.Fatal(err)
 	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
 }
 
 func TestCreateProductSuccessAndNoReception(t *testing.T) {
@@ -188,7 +275,7 @@ func TestCreateProductSuccessAndNoReception(t *testing.T) {
 	LIMIT 1;`)).
 		WithArgs(pvzID, models.StatusInProgress).
 		WillReturnError(sql.ErrNoRows)
-	_, err := repo.CreateProduct(pvzID, productType)
+	_, err := repo.CreateProduct(context.Background(), pvzID, productType)
 	if err != ErrNoActiveReception {
 		t.Fatal(err)
 	}
@@ -202,8 +289,9 @@ func TestCreateProductSuccessAndNoReception(t *testing.T) {
 	VALUES ($1, $2, $3, $4);`)).
 		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), productType, "r1").
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectEvent(mock, models.EventProductAdded, pvzID).WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
-	p, err := repo.CreateProduct(pvzID, productType)
+	p, err := repo.CreateProduct(context.Background(), pvzID, productType)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -216,30 +304,135 @@ func TestCloseLastReceptionSuccessAndEmpty(t *testing.T) {
 	repo, mock := setup(t)
 	defer repo.DB.Close()
 	pvzID := "x"
+	selectQuery := regexp.QuoteMeta(`SELECT id, create_date, version FROM receptions WHERE pvz_id = $1 AND status = $2
+	ORDER BY create_date DESC FOR UPDATE LIMIT 1;`)
 	mock.ExpectBegin()
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, create_date FROM receptions WHERE pvz_id = $1 AND status = $2
-	ORDER BY create_date DESC FOR UPDATE LIMIT 1;`)).
+	mock.ExpectQuery(selectQuery).
 		WithArgs(pvzID, models.StatusInProgress).
 		WillReturnError(sql.ErrNoRows)
-	_, err := repo.CloseLastReception(pvzID)
+	_, err := repo.CloseLastReception(context.Background(), "", pvzID, nil)
 	if err != ErrNoActiveReception {
 		t.Fatal(err)
 	}
 	mock.ExpectBegin()
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, create_date FROM receptions WHERE pvz_id = $1 AND status = $2
-	ORDER BY create_date DESC FOR UPDATE LIMIT 1;`)).
+	mock.ExpectQuery(selectQuery).
 		WithArgs(pvzID, models.StatusInProgress).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "create_date"}).AddRow("r1", time.Now()))
-	mock.ExpectExec(regexp.QuoteMeta(`UPDATE receptions SET status = $1 WHERE id = $2;`)).
-		WithArgs(models.StatusClose, "r1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "create_date", "version"}).AddRow("r1", time.Now(), 1))
+	mock.ExpectRollback()
+	_, err = repo.CloseLastReception(context.Background(), "", pvzID, models.IfMatch{`"r1-0"`})
+	if err != ErrPreconditionFailed {
+		t.Fatal(err)
+	}
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectQuery).
+		WithArgs(pvzID, models.StatusInProgress).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "create_date", "version"}).AddRow("r1", time.Now(), 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE receptions SET status = $1, version = $2, closed_at = $3, closed_by = NULLIF($4, '')::uuid
+	WHERE id = $5;`)).
+		WithArgs(models.StatusClose, 2, sqlmock.AnyArg(), "u1", "r1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, create_date, type FROM products WHERE reception_id = $1 ORDER BY create_date, id;`)).
+		WithArgs("r1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "create_date", "type"}).
+			AddRow("p1", time.Now(), models.Shoes).
+			AddRow("p2", time.Now(), models.Clothes))
+	mock.ExpectExec(insertEventQuery).
+		WithArgs(sqlmock.AnyArg(), models.EventReceptionClosed, pvzID, payloadHas(`"closedBy":"u1"},"products":[{"id":"p1"`), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	rec, err := repo.CloseLastReception(context.Background(), "u1", pvzID, models.IfMatch{`"r1-1"`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Status != models.StatusClose || rec.Version != 2 || rec.ClosedAt == nil || rec.ClosedBy != "u1" {
+		t.Fatalf("got %+v", rec)
+	}
+}
+
+func TestReopenLastReceptionFlows(t *testing.T) {
+	repo, mock := setup(t)
+	defer repo.DB.Close()
+	pvzID := "x"
+	lockQuery := regexp.QuoteMeta(`SELECT id FROM pvz WHERE id = $1 FOR UPDATE;`)
+	selectQuery := regexp.QuoteMeta(`SELECT id, create_date, status, version FROM receptions WHERE pvz_id = $1
+	ORDER BY create_date DESC FOR UPDATE LIMIT 1;`)
+	cols := []string{"id", "create_date", "status", "version"}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(lockQuery).WithArgs(pvzID).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(pvzID))
+	mock.ExpectQuery(selectQuery).WithArgs(pvzID).
+		WillReturnRows(sqlmock.NewRows(cols).AddRow("r1", time.Now(), models.StatusInProgress, 1))
+	mock.ExpectRollback()
+	if _, err := repo.ReopenLastReception(context.Background(), pvzID, nil); err != ErrReceptionInProgress {
+		t.Fatal(err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(lockQuery).WithArgs(pvzID).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(pvzID))
+	mock.ExpectQuery(selectQuery).WithArgs(pvzID).WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+	if _, err := repo.ReopenLastReception(context.Background(), pvzID, nil); err != ErrNoClosedReception {
+		t.Fatal(err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(lockQuery).WithArgs(pvzID).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(pvzID))
+	mock.ExpectQuery(selectQuery).WithArgs(pvzID).
+		WillReturnRows(sqlmock.NewRows(cols).AddRow("r1", time.Now(), models.StatusClose, 2))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE receptions SET status = $1, version = $2, closed_at = NULL, closed_by = NULL WHERE id = $3;`)).
+		WithArgs(models.StatusInProgress, 3, "r1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectEvent(mock, models.EventReceptionReopened, pvzID).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	rec, err := repo.ReopenLastReception(context.Background(), pvzID, models.IfMatch{`"r1-2"`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Status != models.StatusInProgress || rec.Version != 3 {
+		t.Fatalf("got %+v", rec)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUpdatePVZFlows(t *testing.T) {
+	repo, mock := setup(t)
+	defer repo.DB.Close()
+	selectQuery := regexp.QuoteMeta(`SELECT ` + pvzColumns + ` FROM pvz WHERE id = $1 FOR UPDATE;`)
+	cols := []string{"id", "create_date", "city", "version", "address", "latitude", "longitude"}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectQuery).WithArgs("p").WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+	if _, err := repo.UpdatePVZ(context.Background(), "p", models.Kazan, nil); err != ErrPvzNotFound {
+		t.Fatal(err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectQuery).WithArgs("p").
+		WillReturnRows(sqlmock.NewRows(cols).AddRow("p", time.Now(), models.Moscow, 4, "", nil, nil))
+	mock.ExpectRollback()
+	if _, err := repo.UpdatePVZ(context.Background(), "p", models.Kazan, models.IfMatch{`"p-3"`}); err != ErrPreconditionFailed {
+		t.Fatal(err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectQuery).WithArgs("p").
+		WillReturnRows(sqlmock.NewRows(cols).AddRow("p", time.Now(), models.Moscow, 4, "", nil, nil))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE pvz SET city = $1, version = $2 WHERE id = $3;`)).
+		WithArgs(models.Kazan, 5, "p").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
-	rec, err := repo.CloseLastReception(pvzID)
+	pvz, err := repo.UpdatePVZ(context.Background(), "p", models.Kazan, models.IfMatch{`"p-4"`})
 	if err != nil {
 		t.Fatal(err)
 	}
-	if rec.Status != models.StatusClose {
-		t.Fatal("status")
+	if pvz.City != models.Kazan || pvz.ETag() != `"p-5"` {
+		t.Fatalf("got %+v", pvz)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
 	}
 }
 
@@ -252,7 +445,7 @@ func TestDeleteLastProductFlows(t *testing.T) {
 	ORDER BY create_date DESC FOR UPDATE LIMIT 1;`)).
 		WithArgs(pvzID, models.StatusInProgress).
 		WillReturnError(sql.ErrNoRows)
-	if err := repo.DeleteLastProduct(pvzID); err != ErrNoActiveReception {
+	if _, err := repo.DeleteLastProduct(context.Background(), pvzID); err != ErrNoActiveReception {
 		t.Fatal(err)
 	}
 	mock.ExpectBegin()
@@ -260,11 +453,11 @@ func TestDeleteLastProductFlows(t *testing.T) {
 	ORDER BY create_date DESC FOR UPDATE LIMIT 1;`)).
 		WithArgs(pvzID, models.StatusInProgress).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("r"))
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM products WHERE reception_id = $1
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, create_date, type FROM products WHERE reception_id = $1
 	ORDER BY create_date desc FOR UPDATE LIMIT 1;`)).
 		WithArgs("r").
 		WillReturnError(sql.ErrNoRows)
-	if err := repo.DeleteLastProduct(pvzID); err != ErrNoProductsInReception {
+	if _, err := repo.DeleteLastProduct(context.Background(), pvzID); err != ErrNoProductsInReception {
 		t.Fatal(err)
 	}
 	mock.ExpectBegin()
@@ -272,58 +465,840 @@ func TestDeleteLastProductFlows(t *testing.T) {
 	ORDER BY create_date DESC FOR UPDATE LIMIT 1;`)).
 		WithArgs(pvzID, models.StatusInProgress).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("r"))
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM products WHERE reception_id = $1
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, create_date, type FROM products WHERE reception_id = $1
 	ORDER BY create_date desc FOR UPDATE LIMIT 1;`)).
 		WithArgs("r").
-		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("p1"))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "create_date", "type"}).AddRow("p1", time.Now(), models.Shoes))
 	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM products WHERE id = $1;`)).
 		WithArgs("p1").
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE receptions SET deleted_products = deleted_products + 1 WHERE id = $1;`)).
+		WithArgs("r").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectEvent(mock, models.EventProductDeleted, pvzID).WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
-	if err := repo.DeleteLastProduct(pvzID); err != nil {
+	product, err := repo.DeleteLastProduct(context.Background(), pvzID)
+	if err != nil {
 		t.Fatal(err)
 	}
+	if product.ID != "p1" || product.ReceptionID != "r" || product.Type != models.Shoes {
+		t.Errorf("product = %+v", product)
+	}
 }
 
 func TestGetPVZInfoEmptyAndOne(t *testing.T) {
 	repo, mock := setup(t)
 	defer repo.DB.Close()
 	start, end := time.Now(), time.Now()
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT DISTINCT  pvz.id, pvz.create_date, pvz.city
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT DISTINCT pvz.id, pvz.create_date, pvz.city, pvz.version, COALESCE(pvz.address, ''), pvz.latitude, pvz.longitude
 	FROM pvz JOIN receptions r ON r.pvz_id = pvz.id
 	WHERE r.create_date BETWEEN $1 AND $2
 	ORDER BY pvz.create_date
 	LIMIT $3 OFFSET $4;`)).
 		WithArgs(start, end, 10, 0).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "create_date", "city"}))
-	list, err := repo.GetPVZInfo(start, end, 1, 10)
+		WillReturnRows(sqlmock.NewRows([]string{"id", "create_date", "city", "version", "address", "latitude", "longitude"}))
+	list, err := repo.GetPVZInfo(context.Background(), start, end, models.ByOpenDate, 1, 10)
 	if err != nil || len(list) != 0 {
 		t.Fatalf("got %v, %v", list, err)
 	}
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT DISTINCT  pvz.id, pvz.create_date, pvz.city
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT DISTINCT pvz.id, pvz.create_date, pvz.city, pvz.version, COALESCE(pvz.address, ''), pvz.latitude, pvz.longitude
 	FROM pvz JOIN receptions r ON r.pvz_id = pvz.id
 	WHERE r.create_date BETWEEN $1 AND $2
 	ORDER BY pvz.create_date
 	LIMIT $3 OFFSET $4;`)).
 		WithArgs(start, end, 10, 0).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "create_date", "city"}).AddRow("pvz1", start, "Казань"))
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, create_date, status, pvz_id FROM receptions
+		WillReturnRows(sqlmock.NewRows([]string{"id", "create_date", "city", "version", "address", "latitude", "longitude"}).
+			AddRow("pvz1", start, "Казань", 1, "ул. Баумана, 1", 55.79, 49.12))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, create_date, status, pvz_id, version, closed_at, COALESCE(closed_by::text, '')
+	FROM receptions
 	WHERE pvz_id = ANY($1) AND create_date >= $2 AND create_date <= $3
 	ORDER BY create_date;`)).
 		WithArgs(sqlmock.AnyArg(), start, end).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "create_date", "status", "pvz_id"}).
-			AddRow("r1", start, models.StatusInProgress, "pvz1"))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "create_date", "status", "pvz_id", "version", "closed_at", "closed_by"}).
+			AddRow("r1", start, models.StatusInProgress, "pvz1", 1, nil, ""))
 	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, create_date, type, reception_id
 	FROM products WHERE reception_id = ANY($1)
 	ORDER BY create_date;`)).
 		WithArgs(sqlmock.AnyArg()).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "create_date", "type", "reception_id"}).
 			AddRow("p1", start, models.ProductType("электроника"), "r1"))
-	out, err := repo.GetPVZInfo(start, end, 1, 10)
+	out, err := repo.GetPVZInfo(context.Background(), start, end, models.ByOpenDate, 1, 10)
 	if err != nil {
 		t.Fatal(err)
 	}
 	if len(out) != 1 || len(out[0].Receptions) != 1 || len(out[0].Receptions[0].Products) != 1 {
 		t.Fatalf("unexpected %+v", out)
 	}
+	if out[0].Pvz.Address != "ул. Баумана, 1" || out[0].Pvz.Latitude == nil || *out[0].Pvz.Latitude != 55.79 {
+		t.Fatalf("unexpected pvz %+v", out[0].Pvz)
+	}
+}
+
+func TestPVZInfoRevisions(t *testing.T) {
+	repo, mock := setup(t)
+	defer repo.DB.Close()
+	start, end := time.Now().Add(-time.Hour), time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT p.id, p.version, (SELECT COALESCE(MAX(seq), 0) FROM outbox WHERE pvz_id = p.id)
+	FROM (SELECT DISTINCT pvz.id, pvz.version, pvz.create_date
+		FROM pvz JOIN receptions r ON r.pvz_id = pvz.id
+		WHERE r.closed_at BETWEEN $1 AND $2
+		ORDER BY pvz.create_date
+		LIMIT $3 OFFSET $4) p
+	ORDER BY p.create_date;`)).
+		WithArgs(start, end, 10, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version", "seq"}).AddRow("pvz1", 2, 17).AddRow("pvz2", 1, 0))
+	revisions, err := repo.PVZInfoRevisions(context.Background(), start, end, models.ByCloseDate, 2, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.PVZRevision{{ID: "pvz1", Version: 2, EventSeq: 17}, {ID: "pvz2", Version: 1}}
+	if len(revisions) != len(want) || revisions[0] != want[0] || revisions[1] != want[1] {
+		t.Fatalf("got %+v", revisions)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGetPVZInfoByCloseDate(t *testing.T) {
+	repo, mock := setup(t)
+	defer repo.DB.Close()
+	start, end := time.Now().Add(-time.Hour), time.Now()
+	closedAt := end.Add(-time.Minute)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE r.closed_at BETWEEN $1 AND $2`)).
+		WithArgs(start, end, 10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "create_date", "city", "version", "address", "latitude", "longitude"}).
+			AddRow("pvz1", start, "Казань", 1, "", nil, nil))
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE pvz_id = ANY($1) AND closed_at >= $2 AND closed_at <= $3`)).
+		WithArgs(sqlmock.AnyArg(), start, end).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "create_date", "status", "pvz_id", "version", "closed_at", "closed_by"}).
+			AddRow("r1", start.Add(-time.Hour), models.StatusClose, "pvz1", 2, closedAt, "u1"))
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM products WHERE reception_id = ANY($1)`)).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "create_date", "type", "reception_id"}))
+	out, err := repo.GetPVZInfo(context.Background(), start, end, models.ByCloseDate, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := out[0].Receptions[0].Reception
+	if rec.ClosedAt == nil || !rec.ClosedAt.Equal(closedAt) || rec.ClosedBy != "u1" {
+		t.Fatalf("unexpected reception %+v", rec)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReserveIdempotencyKey(t *testing.T) {
+	repo, mock := setup(t)
+	defer repo.DB.Close()
+	insert := regexp.QuoteMeta(`INSERT INTO idempotency_keys (user_id, idempotency_key, request_hash, create_date)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (user_id, idempotency_key) DO UPDATE
+	SET request_hash = EXCLUDED.request_hash, create_date = EXCLUDED.create_date,
+		status_code = NULL, content_type = NULL, response_headers = NULL, response_body = NULL
+	WHERE idempotency_keys.create_date < $5;`)
+	sel := regexp.QuoteMeta(`SELECT request_hash, status_code, content_type, response_headers, response_body
+	FROM idempotency_keys WHERE user_id = $1 AND idempotency_key = $2;`)
+	columns := []string{"request_hash", "status_code", "content_type", "response_headers", "response_body"}
+	expire := time.Now().Add(-time.Hour)
+
+	mock.ExpectExec(insert).WithArgs("u", "k", "h", sqlmock.AnyArg(), expire).WillReturnResult(sqlmock.NewResult(0, 1))
+	_, reserved, err := repo.ReserveIdempotencyKey(context.Background(), "u", "k", "h", expire)
+	if err != nil || !reserved {
+		t.Fatalf("reserved=%v err=%v", reserved, err)
+	}
+
+	mock.ExpectExec(insert).WithArgs("u", "k", "h", sqlmock.AnyArg(), expire).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(sel).WithArgs("u", "k").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("h", 201, "application/json", []byte(`{"ETag":"\"3\""}`), []byte(`{}`)))
+	rec, reserved, err := repo.ReserveIdempotencyKey(context.Background(), "u", "k", "h", expire)
+	if err != nil || reserved {
+		t.Fatalf("reserved=%v err=%v", reserved, err)
+	}
+	if !rec.Completed || rec.StatusCode != 201 || string(rec.Body) != `{}` || rec.Headers["ETag"] != `"3"` {
+		t.Errorf("got %+v", rec)
+	}
+
+	mock.ExpectExec(insert).WithArgs("u", "k", "h", sqlmock.AnyArg(), expire).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(sel).WithArgs("u", "k").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("h", nil, nil, nil, nil))
+	rec, _, err = repo.ReserveIdempotencyKey(context.Background(), "u", "k", "h", expire)
+	if err != nil || rec.Completed {
+		t.Fatalf("rec=%+v err=%v", rec, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSaveIdempotentResponse(t *testing.T) {
+	repo, mock := setup(t)
+	defer repo.DB.Close()
+	query := regexp.QuoteMeta(`UPDATE idempotency_keys SET status_code = $1, content_type = $2, response_headers = $3, response_body = $4
+	WHERE user_id = $5 AND idempotency_key = $6;`)
+
+	mock.ExpectExec(query).
+		WithArgs(201, "application/json", `{"Location":"/pvz/1"}`, []byte(`{}`), "u", "k").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	rec := models.IdempotencyRecord{
+		StatusCode:  201,
+		ContentType: "application/json",
+		Headers:     map[string]string{"Location": "/pvz/1"},
+		Body:        []byte(`{}`),
+	}
+	if err := repo.SaveIdempotentResponse(context.Background(), "u", "k", rec); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPurgeIdempotencyKeys(t *testing.T) {
+	repo, mock := setup(t)
+	defer repo.DB.Close()
+	expire := time.Now().Add(-time.Hour)
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM idempotency_keys WHERE create_date < $1;`)).
+		WithArgs(expire).WillReturnResult(sqlmock.NewResult(0, 3))
+	n, err := repo.PurgeIdempotencyKeys(context.Background(), expire)
+	if err != nil || n != 3 {
+		t.Fatalf("n=%d err=%v", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	repo, mock := setup(t)
+	defer repo.DB.Close()
+	sel := regexp.QuoteMeta(`SELECT password FROM users WHERE id = $1 FOR UPDATE;`)
+	hashBytes, _ := bcrypt.GenerateFromPassword([]byte("Old-pass1"), bcrypt.MinCost)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(sel).WithArgs("u1").WillReturnRows(sqlmock.NewRows([]string{"password"}).AddRow(string(hashBytes)))
+	mock.ExpectRollback()
+	if err := repo.ChangePassword(context.Background(), "u1", "wrong", "new-hash"); err != ErrInvalidPassword {
+		t.Fatalf("want ErrInvalidPassword, got %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(sel).WithArgs("u2").WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+	if err := repo.ChangePassword(context.Background(), "u2", "Old-pass1", "new-hash"); err != ErrUserNotFound {
+		t.Fatalf("want ErrUserNotFound, got %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(sel).WithArgs("u1").WillReturnRows(sqlmock.NewRows([]string{"password"}).AddRow(string(hashBytes)))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET password = $1 WHERE id = $2;`)).
+		WithArgs("new-hash", "u1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE password_resets SET used_at = $1 WHERE user_id = $2 AND used_at IS NULL;`)).
+		WithArgs(sqlmock.AnyArg(), "u1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	if err := repo.ChangePassword(context.Background(), "u1", "Old-pass1", "new-hash"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	repo, mock := setup(t)
+	defer repo.DB.Close()
+	sel := regexp.QuoteMeta(`SELECT user_id FROM password_resets
+	WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $2 FOR UPDATE;`)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(sel).WithArgs("bad", sqlmock.AnyArg()).WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+	if err := repo.ResetPassword(context.Background(), "bad", "new-hash"); err != ErrInvalidResetToken {
+		t.Fatalf("want ErrInvalidResetToken, got %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(sel).WithArgs("th", sqlmock.AnyArg()).WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("u1"))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET password = $1 WHERE id = $2;`)).
+		WithArgs("new-hash", "u1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE password_resets SET used_at = $1 WHERE user_id = $2 AND used_at IS NULL;`)).
+		WithArgs(sqlmock.AnyArg(), "u1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	if err := repo.ResetPassword(context.Background(), "th", "new-hash"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCreatePasswordReset(t *testing.T) {
+	repo, mock := setup(t)
+	defer repo.DB.Close()
+	insert := regexp.QuoteMeta(`INSERT INTO password_resets (token_hash, user_id, expires_at, create_date)
+	SELECT $1, id, $2, $3 FROM users WHERE email = $4 AND password <> '!';`)
+	expires := time.Now().Add(time.Hour)
+
+	mock.ExpectExec(insert).WithArgs("th", expires, sqlmock.AnyArg(), "a@b.com").WillReturnResult(sqlmock.NewResult(0, 1))
+	found, err := repo.CreatePasswordReset(context.Background(), "a@b.com", "th", expires)
+	if err != nil || !found {
+		t.Fatalf("found=%v err=%v", found, err)
+	}
+
+	mock.ExpectExec(insert).WithArgs("th", expires, sqlmock.AnyArg(), "x@b.com").WillReturnResult(sqlmock.NewResult(0, 0))
+	found, err = repo.CreatePasswordReset(context.Background(), "x@b.com", "th", expires)
+	if err != nil || found {
+		t.Fatalf("found=%v err=%v", found, err)
+	}
+}
+
+func TestLoginUserBlocked(t *testing.T) {
+	repo, mock := setup(t)
+	defer repo.DB.Close()
+	hashBytes, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, email, role, blocked, password FROM users WHERE email = $1;`)).
+		WithArgs("a@b.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "role", "blocked", "password"}).AddRow("u1", "a@b.com", "employee", true, string(hashBytes)))
+	if _, err := repo.LoginUser(context.Background(), "a@b.com", "secret"); err != ErrUserBlocked {
+		t.Fatalf("want ErrUserBlocked, got %v", err)
+	}
+}
+
+func TestUpdateUser(t *testing.T) {
+	repo, mock := setup(t)
+	defer repo.DB.Close()
+	sel := regexp.QuoteMeta(`SELECT id, email, role, blocked FROM users WHERE id = $1 FOR UPDATE;`)
+	upd := regexp.QuoteMeta(`UPDATE users SET role = $1, blocked = $2 WHERE id = $3;`)
+	cols := []string{"id", "email", "role", "blocked"}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(sel).WithArgs("u1").WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+	if _, err := repo.UpdateUser(context.Background(), "u1", models.UserUpdate{}); err != ErrUserNotFound {
+		t.Fatalf("want ErrUserNotFound, got %v", err)
+	}
+
+	blocked := true
+	mock.ExpectBegin()
+	mock.ExpectQuery(sel).WithArgs("u1").WillReturnRows(sqlmock.NewRows(cols).AddRow("u1", "a@b.com", "employee", false))
+	mock.ExpectExec(upd).WithArgs(models.Employee, true, "u1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	user, err := repo.UpdateUser(context.Background(), "u1", models.UserUpdate{Blocked: &blocked})
+	if err != nil || !user.Blocked || user.Role != models.Employee {
+		t.Fatalf("user=%+v err=%v", user, err)
+	}
+
+	role := models.Moderator
+	mock.ExpectBegin()
+	mock.ExpectQuery(sel).WithArgs("u1").WillReturnRows(sqlmock.NewRows(cols).AddRow("u1", "a@b.com", "employee", false))
+	mock.ExpectExec(upd).WithArgs(models.Moderator, false, "u1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	user, err = repo.UpdateUser(context.Background(), "u1", models.UserUpdate{Role: &role})
+	if err != nil || user.Role != models.Moderator {
+		t.Fatalf("user=%+v err=%v", user, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDeleteUserAndGetUser(t *testing.T) {
+	repo, mock := setup(t)
+	defer repo.DB.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM users WHERE id = $1;`)).WithArgs("u1").WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := repo.DeleteUser(context.Background(), "u1"); err != ErrUserNotFound {
+		t.Fatalf("want ErrUserNotFound, got %v", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, email, role, blocked FROM users WHERE id = $1;`)).WithArgs("u2").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "role", "blocked"}).AddRow("u2", "c@d.com", "moderator", true))
+	user, err := repo.GetUser(context.Background(), "u2")
+	if err != nil || user.Role != models.Moderator || !user.Blocked {
+		t.Fatalf("user=%+v err=%v", user, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAssignPVZ(t *testing.T) {
+	repo, mock := setup(t)
+	defer repo.DB.Close()
+	insert := regexp.QuoteMeta(`INSERT INTO user_pvz (user_id, pvz_id, create_date) VALUES ($1, $2, $3)
+	ON CONFLICT (user_id, pvz_id) DO NOTHING;`)
+
+	mock.ExpectExec(insert).WithArgs("u1", "p1", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := repo.AssignPVZ(context.Background(), "u1", "p1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mock.ExpectExec(insert).WithArgs("u1", "p2", sqlmock.AnyArg()).
+		WillReturnError(&pq.Error{Code: pqForeignKeyViolation, Constraint: "user_pvz_pvz_id_fkey"})
+	if err := repo.AssignPVZ(context.Background(), "u1", "p2"); err != ErrPvzNotFound {
+		t.Fatalf("want ErrPvzNotFound, got %v", err)
+	}
+
+	mock.ExpectExec(insert).WithArgs("u2", "p1", sqlmock.AnyArg()).
+		WillReturnError(&pq.Error{Code: pqForeignKeyViolation, Constraint: "user_pvz_user_id_fkey"})
+	if err := repo.AssignPVZ(context.Background(), "u2", "p1"); err != ErrUserNotFound {
+		t.Fatalf("want ErrUserNotFound, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestProvisionUser(t *testing.T) {
+	repo, mock := setup(t)
+	defer repo.DB.Close()
+	linked := regexp.QuoteMeta(`SELECT u.id, u.email, u.role, u.blocked, u.password = '!' FROM user_identities i
+	JOIN users u ON u.id = i.user_id
+	WHERE i.issuer = $1 AND i.subject = $2
+	FOR UPDATE OF u;`)
+	byEmail := regexp.QuoteMeta(`SELECT id, email, role, blocked, password = '!' FROM users WHERE email = $1 FOR UPDATE;`)
+	insertUser := regexp.QuoteMeta(`INSERT INTO users (id, email, password, role) VALUES ($1, $2, '!', $3);`)
+	insertIdentity := regexp.QuoteMeta(`INSERT INTO user_identities (issuer, subject, user_id, create_date)
+	VALUES ($1, $2, $3, now());`)
+	cols := []string{"id", "email", "role", "blocked", "provisioned"}
+	ident := models.ExternalIdentity{Issuer: "https://sso", Subject: "s1", Email: "a@b.com", Role: models.Employee}
+
+	// first login creates the user
+	mock.ExpectBegin()
+	mock.ExpectQuery(linked).WithArgs("https://sso", "s1").WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(byEmail).WithArgs("a@b.com").WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec(insertUser).WithArgs(sqlmock.AnyArg(), "a@b.com", models.Employee).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(insertIdentity).WithArgs("https://sso", "s1", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	user, err := repo.ProvisionUser(context.Background(), ident)
+	if err != nil || user.ID == "" || user.Role != models.Employee {
+		t.Fatalf("user=%+v err=%v", user, err)
+	}
+
+	// the role follows the provider
+	ident.Role = models.Moderator
+	mock.ExpectBegin()
+	mock.ExpectQuery(linked).WithArgs("https://sso", "s1").WillReturnRows(sqlmock.NewRows(cols).AddRow("u1", "a@b.com", "employee", false, true))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET role = $1 WHERE id = $2;`)).WithArgs(models.Moderator, "u1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	user, err = repo.ProvisionUser(context.Background(), ident)
+	if err != nil || user.ID != "u1" || user.Role != models.Moderator {
+		t.Fatalf("user=%+v err=%v", user, err)
+	}
+
+	// an existing account is linked only by a verified email
+	mock.ExpectBegin()
+	mock.ExpectQuery(linked).WithArgs("https://sso", "s1").WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(byEmail).WithArgs("a@b.com").WillReturnRows(sqlmock.NewRows(cols).AddRow("u2", "a@b.com", "moderator", false, false))
+	mock.ExpectRollback()
+	if _, err := repo.ProvisionUser(context.Background(), ident); err != ErrIdentityConflict {
+		t.Fatalf("want ErrIdentityConflict, got %v", err)
+	}
+
+	// a linked local account keeps its role
+	ident.EmailVerified = true
+	ident.Role = models.Employee
+	mock.ExpectBegin()
+	mock.ExpectQuery(linked).WithArgs("https://sso", "s1").WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(byEmail).WithArgs("a@b.com").WillReturnRows(sqlmock.NewRows(cols).AddRow("u2", "a@b.com", "moderator", false, false))
+	mock.ExpectExec(insertIdentity).WithArgs("https://sso", "s1", "u2").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	user, err = repo.ProvisionUser(context.Background(), ident)
+	if err != nil || user.ID != "u2" || user.Role != models.Moderator {
+		t.Fatalf("user=%+v err=%v", user, err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(linked).WithArgs("https://sso", "s1").WillReturnRows(sqlmock.NewRows(cols).AddRow("u2", "a@b.com", "moderator", false, false))
+	mock.ExpectCommit()
+	user, err = repo.ProvisionUser(context.Background(), ident)
+	if err != nil || user.Role != models.Moderator {
+		t.Fatalf("user=%+v err=%v", user, err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(linked).WithArgs("https://sso", "s1").WillReturnRows(sqlmock.NewRows(cols).AddRow("u2", "a@b.com", "moderator", true, false))
+	mock.ExpectRollback()
+	if _, err := repo.ProvisionUser(context.Background(), ident); err != ErrUserBlocked {
+		t.Fatalf("want ErrUserBlocked, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAPIKeys(t *testing.T) {
+	repo, mock := setup(t)
+	defer repo.DB.Close()
+	cols := []string{"id", "name", "prefix", "scopes", "created_by", "create_date", "expires_at", "last_used_at"}
+	now := time.Now().UTC()
+
+	key := models.APIKey{ID: "k1", Name: "wms", Prefix: "pvz_abcdef", Scopes: []string{"pvz:read"}, CreatedBy: "u1", CreatedAt: now}
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO api_keys (id, name, prefix, key_hash, scopes, created_by, create_date, expires_at)
+	VALUES ($1, $2, $3, $4, $5, NULLIF($6, '')::uuid, $7, $8);`)).
+		WithArgs("k1", "wms", "pvz_abcdef", "hash", pq.Array(key.Scopes), "u1", now, key.ExpiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := repo.CreateAPIKey(context.Background(), key, "hash"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	authQuery := regexp.QuoteMeta(`UPDATE api_keys k SET last_used_at = now()
+	FROM users u
+	WHERE k.key_hash = $1 AND (k.expires_at IS NULL OR k.expires_at > now())
+		AND u.id = k.created_by AND NOT u.blocked
+	RETURNING k.id, k.name, k.prefix, k.scopes, k.created_by::text, k.create_date, k.expires_at, k.last_used_at, u.role;`)
+	mock.ExpectQuery(authQuery).WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows(append(cols, "role")).
+			AddRow("k1", "wms", "pvz_abcdef", "{pvz:read,product:create}", "u1", now, nil, now, "moderator"))
+	got, role, err := repo.AuthenticateAPIKey(context.Background(), "hash")
+	if err != nil || got.ID != "k1" || len(got.Scopes) != 2 || got.LastUsedAt == nil || got.ExpiresAt != nil || role != models.Moderator {
+		t.Fatalf("key=%+v role=%s err=%v", got, role, err)
+	}
+
+	// expired keys and keys of blocked or deleted users are not found
+	mock.ExpectQuery(authQuery).WithArgs("expired").WillReturnError(sql.ErrNoRows)
+	if _, _, err := repo.AuthenticateAPIKey(context.Background(), "expired"); err != ErrAPIKeyNotFound {
+		t.Fatalf("want ErrAPIKeyNotFound, got %v", err)
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM api_keys WHERE id = $1;`)).WithArgs("k2").WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := repo.DeleteAPIKey(context.Background(), "k2"); err != ErrAPIKeyNotFound {
+		t.Fatalf("want ErrAPIKeyNotFound, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWebhooks(t *testing.T) {
+	repo, mock := setup(t)
+	defer repo.DB.Close()
+	now := time.Now().UTC()
+
+	hook := models.Webhook{ID: "h1", URL: "https://example.com", Events: []string{models.EventProductAdded}, Secret: "s", CreatedBy: "u1", CreatedAt: now}
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO webhooks (id, url, events, secret, created_by, create_date)
+	VALUES ($1, $2, $3, $4, NULLIF($5, '')::uuid, $6);`)).
+		WithArgs("h1", "https://example.com", pq.Array(hook.Events), "s", "u1", now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := repo.CreateWebhook(context.Background(), hook); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, url, events, COALESCE(created_by::text, ''), create_date
+	FROM webhooks ORDER BY create_date, id;`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url", "events", "created_by", "create_date"}).
+			AddRow("h1", "https://example.com", "{product.added,product.deleted}", "", now))
+	hooks, err := repo.ListWebhooks(context.Background())
+	if err != nil || len(hooks) != 1 || len(hooks[0].Events) != 2 || hooks[0].Secret != "" {
+		t.Fatalf("hooks=%+v err=%v", hooks, err)
+	}
+
+	event := models.Event{ID: "e1", Type: models.EventProductAdded, PvzID: "p1", Data: []byte(`{}`)}
+	payload, _ := json.Marshal(event)
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO webhook_deliveries (id, webhook_id, event_id, event_type, payload)
+	SELECT gen_random_uuid(), id, $1, $2, $3 FROM webhooks WHERE $2 = ANY(events)
+	ON CONFLICT (webhook_id, event_id) DO NOTHING;`)).
+		WithArgs("e1", models.EventProductAdded, string(payload)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := repo.EnqueueWebhookDeliveries(context.Background(), event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	deliveryCols := []string{"id", "webhook_id", "event_id", "event_type", "payload", "status", "attempts",
+		"last_status_code", "last_error", "next_attempt_at", "create_date", "delivered_at"}
+	mock.ExpectQuery(regexp.QuoteMeta(`RETURNING `+deliveryColumns+`, w.url, w.secret;`)).
+		WithArgs(20, int64(25000)).
+		WillReturnRows(sqlmock.NewRows(append(deliveryCols, "url", "secret")).
+			AddRow("d1", "h1", "e1", models.EventProductAdded, payload, "pending", 0, nil, nil, now, now, nil, "https://example.com", "s"))
+	jobs, err := repo.ClaimWebhookDeliveries(context.Background(), 20, 25*time.Second)
+	if err != nil || len(jobs) != 1 || jobs[0].URL != "https://example.com" || jobs[0].Secret != "s" || string(jobs[0].Delivery.Payload) != string(payload) {
+		t.Fatalf("jobs=%+v err=%v", jobs, err)
+	}
+
+	code := 200
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE webhook_deliveries SET`)).
+		WithArgs("d1", models.DeliveryDelivered, &code, nil, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := repo.CompleteWebhookDelivery(context.Background(), "d1", models.DeliveryResult{Status: models.DeliveryDelivered, StatusCode: &code}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	existsQuery := regexp.QuoteMeta(`SELECT EXISTS(SELECT 1 FROM webhooks WHERE id = $1);`)
+	mock.ExpectQuery(existsQuery).WithArgs("h2").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	if _, err := repo.ListWebhookDeliveries(context.Background(), "h2", 1, 10); err != ErrWebhookNotFound {
+		t.Fatalf("want ErrWebhookNotFound, got %v", err)
+	}
+	mock.ExpectQuery(existsQuery).WithArgs("h1").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE d.webhook_id = $1
+	ORDER BY d.create_date DESC, d.id
+	LIMIT $2 OFFSET $3;`)).WithArgs("h1", 10, 10).
+		WillReturnRows(sqlmock.NewRows(deliveryCols).
+			AddRow("d1", "h1", "e1", models.EventProductAdded, payload, "delivered", 1, 200, nil, now, now, now))
+	deliveries, err := repo.ListWebhookDeliveries(context.Background(), "h1", 2, 10)
+	if err != nil || len(deliveries) != 1 || *deliveries[0].LastStatusCode != 200 || deliveries[0].DeliveredAt == nil {
+		t.Fatalf("deliveries=%+v err=%v", deliveries, err)
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM webhooks WHERE id = $1;`)).WithArgs("h2").WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := repo.DeleteWebhook(context.Background(), "h2"); err != ErrWebhookNotFound {
+		t.Fatalf("want ErrWebhookNotFound, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestOutbox(t *testing.T) {
+	repo, mock := setup(t)
+	defer repo.DB.Close()
+	now := time.Now().UTC()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`RETURNING seq, id, event_type, pvz_id, payload, create_date;`)).
+		WithArgs(100, int64(30000)).
+		WillReturnRows(sqlmock.NewRows([]string{"seq", "id", "event_type", "pvz_id", "payload", "create_date"}).
+			AddRow(7, "e7", models.EventProductAdded, "p1", []byte(`{"id":"prod-2"}`), now).
+			AddRow(3, "e3", models.EventReceptionOpened, "p1", []byte(`{"id":"r1"}`), now))
+	events, err := repo.ClaimOutbox(context.Background(), models.DestWebhooks, 100, 30*time.Second)
+	if err != nil || len(events) != 2 || events[0].ID != "e3" || events[1].Seq != 7 || string(events[1].Data) != `{"id":"prod-2"}` {
+		t.Fatalf("events=%+v err=%v", events, err)
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE outbox SET sent_at = now(), locked_until = NULL WHERE id = ANY($1);`)).
+		WithArgs(pq.Array([]string{"e3", "e7"})).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	if err := repo.MarkOutboxSent(context.Background(), models.DestWebhooks, []string{"e3", "e7"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the publisher claims and marks its own columns
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE outbox SET publish_locked_until = now() + $2 * interval '1 millisecond'
+	WHERE seq IN (
+		SELECT seq FROM outbox
+		WHERE published_at IS NULL AND (publish_locked_until IS NULL OR publish_locked_until <= now())`)).
+		WithArgs(100, int64(30000)).
+		WillReturnRows(sqlmock.NewRows([]string{"seq", "id", "event_type", "pvz_id", "payload", "create_date"}).
+			AddRow(3, "e3", models.EventReceptionOpened, "p1", []byte(`{"id":"r1"}`), now))
+	if events, err = repo.ClaimOutbox(context.Background(), models.DestPublisher, 100, 30*time.Second); err != nil || len(events) != 1 {
+		t.Fatalf("events=%+v err=%v", events, err)
+	}
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE outbox SET published_at = now(), publish_locked_until = NULL WHERE id = ANY($1);`)).
+		WithArgs(pq.Array([]string{"e3"})).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := repo.MarkOutboxSent(context.Background(), models.DestPublisher, []string{"e3"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.ClaimOutbox(context.Background(), "archive", 100, time.Second); err == nil {
+		t.Error("unknown destination claimed")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPVZEvents(t *testing.T) {
+	repo, mock := setup(t)
+	defer repo.DB.Close()
+	now := time.Now().UTC()
+
+	headQuery := regexp.QuoteMeta(`SELECT (SELECT COALESCE(MAX(seq), 0) FROM outbox WHERE pvz_id = p.id)`)
+	mock.ExpectQuery(headQuery).WithArgs("p1").WillReturnRows(sqlmock.NewRows([]string{"head"}).AddRow(12))
+	mock.ExpectQuery(headQuery).WithArgs("p2").WillReturnError(sql.ErrNoRows)
+	if head, err := repo.PVZEventsHead(context.Background(), "p1"); err != nil || head != 12 {
+		t.Fatalf("head=%d err=%v", head, err)
+	}
+	if _, err := repo.PVZEventsHead(context.Background(), "p2"); !errors.Is(err, ErrPvzNotFound) {
+		t.Fatalf("expected ErrPvzNotFound, got %v", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE pvz_id = $1 AND seq > $2`)).
+		WithArgs("p1", int64(10), 100).
+		WillReturnRows(sqlmock.NewRows([]string{"seq", "id", "event_type", "pvz_id", "payload", "create_date"}).
+			AddRow(11, "e11", models.EventProductAdded, "p1", []byte(`{"id":"prod-1"}`), now).
+			AddRow(12, "e12", models.EventProductDeleted, "p1", []byte(`{"id":"prod-1"}`), now))
+	events, err := repo.ListPVZEvents(context.Background(), "p1", 10, 100)
+	if err != nil || len(events) != 2 || events[0].Seq != 11 || events[1].Type != models.EventProductDeleted {
+		t.Fatalf("events=%+v err=%v", events, err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS(SELECT 1 FROM user_pvz WHERE user_id = $1 AND pvz_id = $2);`)).
+		WithArgs("u1", "p1").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	if ok, err := repo.IsPVZAssigned(context.Background(), "u1", "p1"); err != nil || !ok {
+		t.Fatalf("assigned=%v err=%v", ok, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestIntakeReport(t *testing.T) {
+	repo, mock := setup(t)
+	defer repo.DB.Close()
+	start, end := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	week := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+
+	// dimensions are selected in a fixed order whatever the order of groupBy
+	mock.ExpectQuery(`SELECT city, period, COALESCE\(SUM\(products\), 0\).*`+
+		regexp.QuoteMeta(`GROUP BY pvz.city, date_trunc('week', r.create_date AT TIME ZONE 'UTC'), r.id`)+
+		`.*`+regexp.QuoteMeta(`GROUP BY city, period`)).
+		WithArgs(start, end).
+		WillReturnRows(sqlmock.NewRows([]string{"city", "period", "sum", "count", "count", "avg", "max"}).
+			AddRow(models.Kazan, week, 12, 3, 2, 1800.5, 3600.0).
+			AddRow(models.Moscow, week, 0, 1, 0, nil, nil))
+	report, err := repo.IntakeReport(context.Background(), models.IntakeReportFilter{
+		Start: start, End: end, GroupBy: []string{models.GroupByWeek, models.GroupByCity},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report) != 2 || report[0].City != models.Kazan || !report[0].Period.Equal(week) ||
+		report[0].Products != 12 || report[0].Receptions != 3 || report[0].ClosedReceptions != 2 ||
+		*report[0].AvgDuration != 1800.5 || report[1].AvgDuration != nil || report[1].PvzID != "" {
+		t.Fatalf("report=%+v", report)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`JOIN pvz ON pvz.id = r.pvz_id JOIN products p ON p.reception_id = r.id`)+`.*`+regexp.QuoteMeta(`GROUP BY p.type, r.id`)).
+		WithArgs(start, end).
+		WillReturnRows(sqlmock.NewRows([]string{"product_type", "sum", "count", "count", "avg", "max"}).
+			AddRow(models.Shoes, 5, 2, 2, 60.0, 90.0))
+	report, err = repo.IntakeReport(context.Background(), models.IntakeReportFilter{
+		Start: start, End: end, GroupBy: []string{models.GroupByProductType},
+	})
+	if err != nil || len(report) != 1 || report[0].ProductType != models.Shoes || report[0].Period != nil {
+		t.Fatalf("report=%+v err=%v", report, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReceptionStats(t *testing.T) {
+	repo, mock := setup(t)
+	defer repo.DB.Close()
+	start, end := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`SELECT pvz_id, city, COUNT\(\*\).*`+
+		regexp.QuoteMeta(`WHERE r.create_date >= $1 AND r.create_date <= $2 AND ($3 = '' OR r.pvz_id::text = $3)`)+
+		`.*`+regexp.QuoteMeta(`GROUP BY pvz_id, city ORDER BY pvz_id;`)).
+		WithArgs(start, end, "").
+		WillReturnRows(sqlmock.NewRows([]string{"pvz_id", "city", "count", "count", "sum", "avg", "avg", "rate", "share"}).
+			AddRow("p1", models.Kazan, 4, 3, 20, 5.0, 1800.0, 12.5, 0.25).
+			AddRow("p2", models.Moscow, 1, 0, 2, 2.0, nil, nil, 0.0))
+	stats, err := repo.ReceptionStats(context.Background(), models.ReceptionStatsFilter{Start: start, End: end})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 2 || stats[0].City != models.Kazan || stats[0].Receptions != 4 || stats[0].ClosedReceptions != 3 ||
+		stats[0].Products != 20 || *stats[0].AvgDuration != 1800 || *stats[0].ProductsPerHour != 12.5 ||
+		stats[0].DeletionShare != 0.25 || stats[1].AvgDuration != nil || stats[1].ProductsPerHour != nil {
+		t.Fatalf("stats=%+v", stats)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestExport(t *testing.T) {
+	repo, mock := setup(t)
+	defer repo.DB.Close()
+	start, end := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Now().UTC()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM receptions r JOIN pvz ON pvz.id = r.pvz_id`)).
+		WithArgs(start, end).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "pvz_id", "city", "create_date", "closed_at", "status", "count"}).
+			AddRow("r1", "p1", models.Kazan, now, now, models.StatusClose, 2).
+			AddRow("r2", "p1", models.Kazan, now, nil, models.StatusInProgress, 0))
+	var receptions []models.ExportReception
+	err := repo.ExportReceptions(context.Background(), start, end, func(r models.ExportReception) error {
+		receptions = append(receptions, r)
+		return nil
+	})
+	if err != nil || len(receptions) != 2 || receptions[0].ClosedAt == nil || receptions[0].Products != 2 || receptions[1].ClosedAt != nil {
+		t.Fatalf("receptions=%+v err=%v", receptions, err)
+	}
+
+	// an error of the consumer stops reading
+	stop := errors.New("client gone")
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM products p JOIN receptions r ON r.id = p.reception_id`)).
+		WithArgs(start, end).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "create_date", "type", "reception_id", "pvz_id", "city"}).
+			AddRow("prod-1", now, models.Shoes, "r1", "p1", models.Kazan).
+			AddRow("prod-2", now, models.Clothes, "r1", "p1", models.Kazan))
+	calls := 0
+	err = repo.ExportProducts(context.Background(), start, end, func(p models.ExportProduct) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Fatalf("calls=%d err=%v", calls, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestImportPVZs(t *testing.T) {
+	repo, mock := setup(t)
+	defer repo.DB.Close()
+
+	findQuery := regexp.QuoteMeta(`SELECT ` + pvzColumns + ` FROM pvz WHERE address = ANY($1);`)
+	mock.ExpectQuery(findQuery).
+		WithArgs(pq.Array([]string{"Тверская, 1"})).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "create_date", "city", "version", "address", "latitude", "longitude"}).
+			AddRow("p1", time.Now(), models.Moscow, 1, "Тверская, 1", 55.75, 37.61))
+	found, err := repo.FindPVZsByAddress(context.Background(), []string{"Тверская, 1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].Address != "Тверская, 1" || *found[0].Longitude != 37.61 {
+		t.Fatalf("unexpected pvz: %+v", found)
+	}
+
+	rows := []models.PVZImportRow{
+		{Line: 2, City: models.Moscow, Address: "Тверская, 2", Latitude: 55.76, Longitude: 37.6},
+		{Line: 3, City: models.Kazan, Address: "Баумана, 1", Latitude: 55.79, Longitude: 49.12},
+	}
+	insertQuery := regexp.QuoteMeta(`INSERT INTO pvz (id, create_date, city, address, latitude, longitude) VALUES ($1, $2, $3, $4, $5, $6);`)
+
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare(insertQuery)
+	prep.ExpectExec().
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), models.Moscow, "Тверская, 2", 55.76, 37.6).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	prep.ExpectExec().
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), models.Kazan, "Баумана, 1", 55.79, 49.12).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	created, err := repo.ImportPVZs(context.Background(), rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 2 || created[1].City != models.Kazan || *created[1].Latitude != 55.79 || created[0].Version != 1 {
+		t.Fatalf("unexpected pvz: %+v", created)
+	}
+
+	// a pvz created concurrently rolls the whole import back
+	mock.ExpectBegin()
+	prep = mock.ExpectPrepare(insertQuery)
+	prep.ExpectExec().WillReturnResult(sqlmock.NewResult(1, 1))
+	prep.ExpectExec().WillReturnError(&pq.Error{Code: pqUniqueViolation, Constraint: pvzAddressIndex})
+	mock.ExpectRollback()
+	_, err = repo.ImportPVZs(context.Background(), rows)
+	if !errors.Is(err, ErrPVZExists) || !strings.Contains(err.Error(), "line 3") {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
 }
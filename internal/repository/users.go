@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"pvz/internal/models"
+	"time"
+)
+
+func (r *Repository) ListUsers(ctx context.Context, role models.Role, page, limit int) ([]models.User, error) {
+	const query = `SELECT id, email, role, blocked FROM users
+	WHERE $1 = '' OR role = $1
+	ORDER BY create_date, id
+	LIMIT $2 OFFSET $3;`
+
+	rows, err := r.DB.QueryContext(ctx, query, role, limit, (page-1)*limit)
+	if err != nil {
+		return nil, models.Wrap("select users", err)
+	}
+	defer rows.Close()
+
+	users := make([]models.User, 0, limit)
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.ID, &user.Email, &user.Role, &user.Blocked); err != nil {
+			return nil, models.Wrap("users rows scan", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, models.Wrap("rows err users", err)
+	}
+	return users, nil
+}
+
+func (r *Repository) GetUser(ctx context.Context, userID string) (models.User, error) {
+	const query = `SELECT id, email, role, blocked FROM users WHERE id = $1;`
+
+	var user models.User
+	err := r.DB.QueryRowContext(ctx, query, userID).Scan(&user.ID, &user.Email, &user.Role, &user.Blocked)
+	if err == sql.ErrNoRows {
+		return models.User{}, ErrUserNotFound
+	}
+	if err != nil {
+		return models.User{}, models.Wrap("select user", err)
+	}
+	return user, nil
+}
+
+func (r *Repository) UpdateUser(ctx context.Context, userID string, upd models.UserUpdate) (models.User, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.User{}, ErrBeginTransaction
+	}
+	defer tx.Rollback()
+
+	const selectQuery = `SELECT id, email, role, blocked FROM users WHERE id = $1 FOR UPDATE;`
+	var user models.User
+	err = tx.QueryRowContext(ctx, selectQuery, userID).Scan(&user.ID, &user.Email, &user.Role, &user.Blocked)
+	if err == sql.ErrNoRows {
+		return models.User{}, ErrUserNotFound
+	}
+	if err != nil {
+		return models.User{}, models.Wrap("select user", err)
+	}
+
+	if upd.Blocked != nil {
+		user.Blocked = *upd.Blocked
+	}
+	if upd.Role != nil {
+		user.Role = *upd.Role
+	}
+
+	const updateQuery = `UPDATE users SET role = $1, blocked = $2 WHERE id = $3;`
+	if _, err := tx.ExecContext(ctx, updateQuery, user.Role, user.Blocked, user.ID); err != nil {
+		return models.User{}, models.Wrap("update user", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.User{}, ErrCommitTransaction
+	}
+	return user, nil
+}
+
+func (r *Repository) DeleteUser(ctx context.Context, userID string) error {
+	res, err := r.DB.ExecContext(ctx, `DELETE FROM users WHERE id = $1;`, userID)
+	if err != nil {
+		return models.Wrap("delete user", err)
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return models.Wrap("delete user", err)
+	}
+	if deleted == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *Repository) IsPVZAssigned(ctx context.Context, userID, pvzID string) (bool, error) {
+	const query = `SELECT EXISTS(SELECT 1 FROM user_pvz WHERE user_id = $1 AND pvz_id = $2);`
+	var assigned bool
+	if err := r.DB.QueryRowContext(ctx, query, userID, pvzID).Scan(&assigned); err != nil {
+		return false, models.Wrap("check pvz assignment", err)
+	}
+	return assigned, nil
+}
+
+func (r *Repository) GetUserPVZs(ctx context.Context, userID string) ([]models.PVZ, error) {
+	const query = `SELECT ` + pvzColumns + `
+	FROM user_pvz up JOIN pvz ON pvz.id = up.pvz_id
+	WHERE up.user_id = $1
+	ORDER BY pvz.create_date;`
+
+	rows, err := r.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, models.Wrap("select user pvz", err)
+	}
+	defer rows.Close()
+
+	pvzList := []models.PVZ{}
+	for rows.Next() {
+		var pvz models.PVZ
+		if err := scanPVZ(rows, &pvz); err != nil {
+			return nil, models.Wrap("user pvz rows scan", err)
+		}
+		pvzList = append(pvzList, pvz)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, models.Wrap("rows err user pvz", err)
+	}
+	return pvzList, nil
+}
+
+func (r *Repository) AssignPVZ(ctx context.Context, userID, pvzID string) error {
+	const query = `INSERT INTO user_pvz (user_id, pvz_id, create_date) VALUES ($1, $2, $3)
+	ON CONFLICT (user_id, pvz_id) DO NOTHING;`
+
+	_, err := r.DB.ExecContext(ctx, query, userID, pvzID, time.Now().UTC())
+	switch {
+	case isConstraintViolation(err, pqForeignKeyViolation, "user_pvz_user_id_fkey"):
+		return ErrUserNotFound
+	case isConstraintViolation(err, pqForeignKeyViolation, "user_pvz_pvz_id_fkey"):
+		return ErrPvzNotFound
+	case err != nil:
+		return models.Wrap("assign pvz", err)
+	}
+	return nil
+}
+
+func (r *Repository) UnassignPVZ(ctx context.Context, userID, pvzID string) error {
+	const query = `DELETE FROM user_pvz WHERE user_id = $1 AND pvz_id = $2;`
+	if _, err := r.DB.ExecContext(ctx, query, userID, pvzID); err != nil {
+		return models.Wrap("unassign pvz", err)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"pvz/internal/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+func (r *Repository) CreateWebhook(ctx context.Context, hook models.Webhook) error {
+	const query = `INSERT INTO webhooks (id, url, events, secret, created_by, create_date)
+	VALUES ($1, $2, $3, $4, NULLIF($5, '')::uuid, $6);`
+	_, err := r.DB.ExecContext(ctx, query, hook.ID, hook.URL, pq.Array(hook.Events), hook.Secret, hook.CreatedBy, hook.CreatedAt)
+	if err != nil {
+		return models.Wrap("insert webhook", err)
+	}
+	return nil
+}
+
+// ListWebhooks returns the subscriptions without their secrets.
+func (r *Repository) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	const query = `SELECT id, url, events, COALESCE(created_by::text, ''), create_date
+	FROM webhooks ORDER BY create_date, id;`
+
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, models.Wrap("select webhooks", err)
+	}
+	defer rows.Close()
+
+	hooks := []models.Webhook{}
+	for rows.Next() {
+		var hook models.Webhook
+		if err := rows.Scan(&hook.ID, &hook.URL, pq.Array(&hook.Events), &hook.CreatedBy, &hook.CreatedAt); err != nil {
+			return nil, models.Wrap("webhooks rows scan", err)
+		}
+		hooks = append(hooks, hook)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, models.Wrap("rows err webhooks", err)
+	}
+	return hooks, nil
+}
+
+func (r *Repository) DeleteWebhook(ctx context.Context, webhookID string) error {
+	const query = `DELETE FROM webhooks WHERE id = $1;`
+	res, err := r.DB.ExecContext(ctx, query, webhookID)
+	if err != nil {
+		return models.Wrap("delete webhook", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return models.Wrap("rows affected", err)
+	}
+	if n == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+const deliveryColumns = `d.id, d.webhook_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+	d.last_status_code, d.last_error, d.next_attempt_at, d.create_date, d.delivered_at`
+
+func scanDelivery(row rowScanner, dest ...any) (models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	var payload []byte
+	err := row.Scan(append([]any{&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &payload, &d.Status, &d.Attempts,
+		&d.LastStatusCode, &d.LastError, &d.NextAttemptAt, &d.CreatedAt, &d.DeliveredAt}, dest...)...)
+	d.Payload = payload
+	return d, err
+}
+
+// ListWebhookDeliveries returns the delivery log of the webhook, newest first.
+func (r *Repository) ListWebhookDeliveries(ctx context.Context, webhookID string, page, limit int) ([]models.WebhookDelivery, error) {
+	var exists bool
+	const existsQuery = `SELECT EXISTS(SELECT 1 FROM webhooks WHERE id = $1);`
+	if err := r.DB.QueryRowContext(ctx, existsQuery, webhookID).Scan(&exists); err != nil {
+		return nil, models.Wrap("check webhook", err)
+	}
+	if !exists {
+		return nil, ErrWebhookNotFound
+	}
+
+	const query = `SELECT ` + deliveryColumns + ` FROM webhook_deliveries d
+	WHERE d.webhook_id = $1
+	ORDER BY d.create_date DESC, d.id
+	LIMIT $2 OFFSET $3;`
+
+	rows, err := r.DB.QueryContext(ctx, query, webhookID, limit, (page-1)*limit)
+	if err != nil {
+		return nil, models.Wrap("select deliveries", err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]models.WebhookDelivery, 0, limit)
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, models.Wrap("deliveries rows scan", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, models.Wrap("rows err deliveries", err)
+	}
+	return deliveries, nil
+}
+
+// EnqueueWebhookDeliveries adds a pending delivery of the event for every subscribed webhook.
+// Enqueueing the same event again is a no-op.
+func (r *Repository) EnqueueWebhookDeliveries(ctx context.Context, event models.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return models.Wrap("marshal event", err)
+	}
+	// jsonb does not accept a bytea parameter, so the payload is sent as text
+	const query = `INSERT INTO webhook_deliveries (id, webhook_id, event_id, event_type, payload)
+	SELECT gen_random_uuid(), id, $1, $2, $3 FROM webhooks WHERE $2 = ANY(events)
+	ON CONFLICT (webhook_id, event_id) DO NOTHING;`
+	if _, err := r.DB.ExecContext(ctx, query, event.ID, event.Type, string(payload)); err != nil {
+		return models.Wrap("insert deliveries", err)
+	}
+	return nil
+}
+
+// ClaimWebhookDeliveries takes up to limit due deliveries and moves their next attempt
+// lease into the future, so other workers skip them while they are being sent.
+func (r *Repository) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookJob, error) {
+	const query = `UPDATE webhook_deliveries d SET next_attempt_at = now() + $2 * interval '1 millisecond'
+	FROM webhooks w
+	WHERE w.id = d.webhook_id AND d.id IN (
+		SELECT id FROM webhook_deliveries
+		WHERE status = 'pending' AND next_attempt_at <= now()
+		ORDER BY next_attempt_at
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING ` + deliveryColumns + `, w.url, w.secret;`
+
+	rows, err := r.DB.QueryContext(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, models.Wrap("claim deliveries", err)
+	}
+	defer rows.Close()
+
+	var jobs []models.WebhookJob
+	for rows.Next() {
+		var job models.WebhookJob
+		job.Delivery, err = scanDelivery(rows, &job.URL, &job.Secret)
+		if err != nil {
+			return nil, models.Wrap("claimed deliveries rows scan", err)
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, models.Wrap("rows err claimed deliveries", err)
+	}
+	return jobs, nil
+}
+
+// CompleteWebhookDelivery records the outcome of an attempt.
+func (r *Repository) CompleteWebhookDelivery(ctx context.Context, deliveryID string, res models.DeliveryResult) error {
+	const query = `UPDATE webhook_deliveries SET
+		status = $2,
+		attempts = attempts + 1,
+		last_status_code = $3,
+		last_error = $4,
+		next_attempt_at = COALESCE($5, next_attempt_at),
+		delivered_at = CASE WHEN $2 = 'delivered' THEN now() END
+	WHERE id = $1;`
+	if _, err := r.DB.ExecContext(ctx, query, deliveryID, res.Status, res.StatusCode, res.Error, res.NextAttemptAt); err != nil {
+		return models.Wrap("update delivery", err)
+	}
+	return nil
+}
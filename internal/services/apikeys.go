@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"pvz/internal/auth"
+	"pvz/internal/models"
+	"pvz/internal/tracing"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// APIKeyPrefix starts every API key, so leaked keys are easy to find in code and logs.
+const APIKeyPrefix = "pvz_"
+
+var (
+	ErrInvalidScopes = errors.New("scopes must be a non-empty list of known permissions")
+	ErrKeyExpired    = errors.New("expiration time must be in the future")
+	ErrScopeNotHeld  = errors.New("scopes must not exceed the caller's permissions")
+	ErrKeyRevoked    = errors.New("api key creator no longer holds its scopes")
+)
+
+// CreateAPIKey issues a key with the scopes, each of them must be held by the caller.
+// The key itself is only returned here. A key created by a key belongs to the same user.
+func (s *Service) CreateAPIKey(ctx context.Context, p *auth.Principal, name string, scopes []string, expiresAt *time.Time) (_ models.NewAPIKey, err error) {
+	ctx, span := tracer.Start(ctx, "Service.CreateAPIKey", trace.WithAttributes(attribute.StringSlice("apikey.scopes", scopes)))
+	defer tracing.End(span, &err)
+
+	if len(scopes) == 0 {
+		return models.NewAPIKey{}, ErrInvalidScopes
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(models.Permissions, scope) {
+			return models.NewAPIKey{}, ErrInvalidScopes
+		}
+		if !p.Can(s.Roles, scope) {
+			return models.NewAPIKey{}, ErrScopeNotHeld
+		}
+	}
+	now := time.Now().UTC()
+	if expiresAt != nil && !expiresAt.After(now) {
+		return models.NewAPIKey{}, ErrKeyExpired
+	}
+
+	secret, err := newSecretToken()
+	if err != nil {
+		return models.NewAPIKey{}, models.Wrap("generate api key", err)
+	}
+	raw := APIKeyPrefix + secret
+	createdBy := p.UserID
+	if p.APIKeyID != "" {
+		createdBy = p.KeyCreator
+	}
+	key := models.APIKey{
+		ID:        uuid.NewString(),
+		Name:      name,
+		Prefix:    raw[:len(APIKeyPrefix)+6],
+		Scopes:    slices.Compact(slices.Sorted(slices.Values(scopes))),
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.Repo.CreateAPIKey(ctx, key, hashToken(raw)); err != nil {
+		return models.NewAPIKey{}, err
+	}
+	return models.NewAPIKey{APIKey: key, Key: raw}, nil
+}
+
+func (s *Service) ListAPIKeys(ctx context.Context) (_ []models.APIKey, err error) {
+	ctx, span := tracer.Start(ctx, "Service.ListAPIKeys")
+	defer tracing.End(span, &err)
+
+	return s.Repo.ListAPIKeys(ctx)
+}
+
+func (s *Service) DeleteAPIKey(ctx context.Context, keyID string) (err error) {
+	ctx, span := tracer.Start(ctx, "Service.DeleteAPIKey", trace.WithAttributes(attribute.String("apikey.id", keyID)))
+	defer tracing.End(span, &err)
+
+	return s.Repo.DeleteAPIKey(ctx, keyID)
+}
+
+// AuthenticateAPIKey returns the unexpired key matching raw. The key acts on behalf of its
+// creator, it stops working once the creator's role loses one of the scopes.
+func (s *Service) AuthenticateAPIKey(ctx context.Context, raw string) (_ models.APIKey, err error) {
+	ctx, span := tracer.Start(ctx, "Service.AuthenticateAPIKey")
+	defer tracing.End(span, &err)
+
+	key, creatorRole, err := s.Repo.AuthenticateAPIKey(ctx, hashToken(raw))
+	if err != nil {
+		return models.APIKey{}, err
+	}
+	for _, scope := range key.Scopes {
+		if !s.Roles.Has(creatorRole, scope) {
+			return models.APIKey{}, ErrKeyRevoked
+		}
+	}
+	return key, nil
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"pvz/internal/auth"
+	"pvz/internal/models"
+)
+
+func TestServiceCreateAPIKey(t *testing.T) {
+	repo, svc := newSvc()
+	moderator := &auth.Principal{UserID: "m1", Role: models.Moderator}
+
+	_, err := svc.CreateAPIKey(context.Background(), moderator, "wms", nil, nil)
+	require.ErrorIs(t, err, ErrInvalidScopes)
+	_, err = svc.CreateAPIKey(context.Background(), moderator, "wms", []string{"pvz:everything"}, nil)
+	require.ErrorIs(t, err, ErrInvalidScopes)
+	past := time.Now().Add(-time.Hour)
+	_, err = svc.CreateAPIKey(context.Background(), moderator, "wms", []string{models.PermPVZRead}, &past)
+	require.ErrorIs(t, err, ErrKeyExpired)
+
+	// a caller can't hand out more than it holds, whether a role or a key
+	_, err = svc.CreateAPIKey(context.Background(), moderator, "wms", []string{models.PermProductCreate}, nil)
+	require.ErrorIs(t, err, ErrScopeNotHeld)
+	manager := &auth.Principal{APIKeyID: "k0", Scopes: []string{models.PermUserManage}}
+	_, err = svc.CreateAPIKey(context.Background(), manager, "wms", []string{models.PermPVZRead}, nil)
+	require.ErrorIs(t, err, ErrScopeNotHeld)
+
+	var storedHash string
+	repo.EXPECT().CreateAPIKey(mock.Anything, mock.AnythingOfType("models.APIKey"), mock.AnythingOfType("string")).
+		Run(func(_ context.Context, _ models.APIKey, keyHash string) { storedHash = keyHash }).
+		Return(nil).Once()
+	key, err := svc.CreateAPIKey(context.Background(), moderator, "wms",
+		[]string{models.PermPVZCreate, models.PermPVZRead, models.PermPVZRead}, nil)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(key.Key, APIKeyPrefix))
+	require.True(t, strings.HasPrefix(key.Key, key.Prefix))
+	require.Equal(t, []string{models.PermPVZCreate, models.PermPVZRead}, key.Scopes)
+	require.Equal(t, "m1", key.CreatedBy)
+	require.Equal(t, hashToken(key.Key), storedHash)
+	require.NotEqual(t, key.Key, storedHash)
+
+	repo.EXPECT().AuthenticateAPIKey(mock.Anything, storedHash).Return(key.APIKey, models.Moderator, nil).Once()
+	got, err := svc.AuthenticateAPIKey(context.Background(), key.Key)
+	require.NoError(t, err)
+	require.Equal(t, key.ID, got.ID)
+
+	// the creator was demoted, pvz:create is no longer theirs to delegate
+	repo.EXPECT().AuthenticateAPIKey(mock.Anything, storedHash).Return(key.APIKey, models.Employee, nil).Once()
+	_, err = svc.AuthenticateAPIKey(context.Background(), key.Key)
+	require.ErrorIs(t, err, ErrKeyRevoked)
+
+	// a key issued by a key belongs to the user behind it
+	repo.EXPECT().CreateAPIKey(mock.Anything, mock.MatchedBy(func(k models.APIKey) bool { return k.CreatedBy == "m1" }),
+		mock.AnythingOfType("string")).Return(nil).Once()
+	delegate := &auth.Principal{APIKeyID: key.ID, Scopes: key.Scopes, KeyCreator: "m1"}
+	_, err = svc.CreateAPIKey(context.Background(), delegate, "wms-read", []string{models.PermPVZRead}, nil)
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"pvz/internal/auth"
+	"pvz/internal/models"
+	"pvz/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	ErrPVZAccessDenied = errors.New("pvz is not assigned to the caller")
+	ErrAccessRevoked   = errors.New("caller is blocked or its role has changed")
+)
+
+// OpenPVZEvents checks that the caller may watch the pvz: with pvz:read_all any pvz,
+// otherwise only an assigned one. It returns the seq of the last stored event, live
+// events follow it.
+func (s *Service) OpenPVZEvents(ctx context.Context, p *auth.Principal, pvzID string) (_ int64, err error) {
+	ctx, span := tracer.Start(ctx, "Service.OpenPVZEvents", trace.WithAttributes(attribute.String("pvz.id", pvzID)))
+	defer tracing.End(span, &err)
+
+	head, err := s.Repo.PVZEventsHead(ctx, pvzID)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.checkPVZAccess(ctx, p, pvzID); err != nil {
+		return 0, err
+	}
+	return head, nil
+}
+
+// CheckPVZEvents repeats the check of an open stream. A user must also still exist,
+// not be blocked and keep the role it had when the stream was opened.
+func (s *Service) CheckPVZEvents(ctx context.Context, p *auth.Principal, pvzID string) (err error) {
+	ctx, span := tracer.Start(ctx, "Service.CheckPVZEvents", trace.WithAttributes(attribute.String("pvz.id", pvzID)))
+	defer tracing.End(span, &err)
+
+	if p.APIKeyID == "" {
+		user, err := s.Repo.GetUser(ctx, p.UserID)
+		if err != nil {
+			return err
+		}
+		if user.Blocked || user.Role != p.Role {
+			return ErrAccessRevoked
+		}
+	}
+	return s.checkPVZAccess(ctx, p, pvzID)
+}
+
+func (s *Service) checkPVZAccess(ctx context.Context, p *auth.Principal, pvzID string) error {
+	if p.Can(s.Roles, models.PermPVZReadAll) {
+		return nil
+	}
+	if p.UserID == "" {
+		return ErrPVZAccessDenied
+	}
+	assigned, err := s.Repo.IsPVZAssigned(ctx, p.UserID, pvzID)
+	if err != nil {
+		return err
+	}
+	if !assigned {
+		return ErrPVZAccessDenied
+	}
+	return nil
+}
+
+// PVZEvents returns up to limit events of the pvz after the seq.
+func (s *Service) PVZEvents(ctx context.Context, pvzID string, afterSeq int64, limit int) (_ []models.Event, err error) {
+	ctx, span := tracer.Start(ctx, "Service.PVZEvents", trace.WithAttributes(
+		attribute.String("pvz.id", pvzID),
+		attribute.Int64("events.after_seq", afterSeq),
+	))
+	defer tracing.End(span, &err)
+
+	return s.Repo.ListPVZEvents(ctx, pvzID, afterSeq, limit)
+}
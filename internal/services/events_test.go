@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"pvz/internal/auth"
+	"pvz/internal/models"
+	"pvz/internal/repository"
+)
+
+func TestServiceOpenPVZEvents(t *testing.T) {
+	repo, svc := newSvc()
+	repo.EXPECT().PVZEventsHead(mock.Anything, "p1").Return(7, nil)
+
+	head, err := svc.OpenPVZEvents(context.Background(), &auth.Principal{UserID: "m1", Role: models.Moderator}, "p1")
+	require.NoError(t, err)
+	require.Equal(t, int64(7), head)
+
+	repo.EXPECT().IsPVZAssigned(mock.Anything, "e1", "p1").Return(true, nil).Once()
+	head, err = svc.OpenPVZEvents(context.Background(), &auth.Principal{UserID: "e1", Role: models.Employee}, "p1")
+	require.NoError(t, err)
+	require.Equal(t, int64(7), head)
+
+	repo.EXPECT().IsPVZAssigned(mock.Anything, "e2", "p1").Return(false, nil).Once()
+	_, err = svc.OpenPVZEvents(context.Background(), &auth.Principal{UserID: "e2", Role: models.Employee}, "p1")
+	require.ErrorIs(t, err, ErrPVZAccessDenied)
+
+	// an api key is not assigned to any pvz
+	_, err = svc.OpenPVZEvents(context.Background(), &auth.Principal{APIKeyID: "k1", Scopes: []string{models.PermPVZRead}}, "p1")
+	require.ErrorIs(t, err, ErrPVZAccessDenied)
+	_, err = svc.OpenPVZEvents(context.Background(), &auth.Principal{APIKeyID: "k1", Scopes: []string{models.PermPVZReadAll}}, "p1")
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestServiceCheckPVZEvents(t *testing.T) {
+	repo, svc := newSvc()
+	employee := &auth.Principal{UserID: "e1", Role: models.Employee}
+
+	repo.EXPECT().GetUser(mock.Anything, "e1").Return(models.User{ID: "e1", Role: models.Employee}, nil).Twice()
+	repo.EXPECT().IsPVZAssigned(mock.Anything, "e1", "p1").Return(true, nil).Once()
+	require.NoError(t, svc.CheckPVZEvents(context.Background(), employee, "p1"))
+	repo.EXPECT().IsPVZAssigned(mock.Anything, "e1", "p1").Return(false, nil).Once()
+	require.ErrorIs(t, svc.CheckPVZEvents(context.Background(), employee, "p1"), ErrPVZAccessDenied)
+
+	repo.EXPECT().GetUser(mock.Anything, "e1").Return(models.User{ID: "e1", Role: models.Employee, Blocked: true}, nil).Once()
+	require.ErrorIs(t, svc.CheckPVZEvents(context.Background(), employee, "p1"), ErrAccessRevoked)
+	repo.EXPECT().GetUser(mock.Anything, "e1").Return(models.User{ID: "e1", Role: models.Moderator}, nil).Once()
+	require.ErrorIs(t, svc.CheckPVZEvents(context.Background(), employee, "p1"), ErrAccessRevoked)
+	repo.EXPECT().GetUser(mock.Anything, "e1").Return(models.User{}, repository.ErrUserNotFound).Once()
+	require.ErrorIs(t, svc.CheckPVZEvents(context.Background(), employee, "p1"), repository.ErrUserNotFound)
+
+	// an api key has no user to look up
+	require.NoError(t, svc.CheckPVZEvents(context.Background(), &auth.Principal{APIKeyID: "k1", Scopes: []string{models.PermPVZReadAll}}, "p1"))
+	repo.AssertExpectations(t)
+}
+
+func TestServiceOpenPVZEventsNotFound(t *testing.T) {
+	repo, svc := newSvc()
+	repo.EXPECT().PVZEventsHead(mock.Anything, "p1").Return(0, repository.ErrPvzNotFound).Once()
+
+	_, err := svc.OpenPVZEvents(context.Background(), &auth.Principal{UserID: "m1", Role: models.Moderator}, "p1")
+	require.ErrorIs(t, err, repository.ErrPvzNotFound)
+	repo.AssertExpectations(t)
+}
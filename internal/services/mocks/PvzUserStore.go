@@ -3,6 +3,7 @@
 package mocks
 
 import (
+	context "context"
 	models "pvz/internal/models"
 
 	mock "github.com/stretchr/testify/mock"
@@ -23,9 +24,170 @@ func (_m *PvzUserStore) EXPECT() *PvzUserStore_Expecter {
 	return &PvzUserStore_Expecter{mock: &_m.Mock}
 }
 
-// CloseLastReception provides a mock function with given fields: pvzID
-func (_m *PvzUserStore) CloseLastReception(pvzID string) (models.Reception, error) {
-	ret := _m.Called(pvzID)
+// AssignPVZ provides a mock function with given fields: ctx, userID, pvzID
+func (_m *PvzUserStore) AssignPVZ(ctx context.Context, userID string, pvzID string) error {
+	ret := _m.Called(ctx, userID, pvzID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AssignPVZ")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, userID, pvzID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PvzUserStore_AssignPVZ_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AssignPVZ'
+type PvzUserStore_AssignPVZ_Call struct {
+	*mock.Call
+}
+
+// AssignPVZ is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - pvzID string
+func (_e *PvzUserStore_Expecter) AssignPVZ(ctx interface{}, userID interface{}, pvzID interface{}) *PvzUserStore_AssignPVZ_Call {
+	return &PvzUserStore_AssignPVZ_Call{Call: _e.mock.On("AssignPVZ", ctx, userID, pvzID)}
+}
+
+func (_c *PvzUserStore_AssignPVZ_Call) Run(run func(ctx context.Context, userID string, pvzID string)) *PvzUserStore_AssignPVZ_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_AssignPVZ_Call) Return(_a0 error) *PvzUserStore_AssignPVZ_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *PvzUserStore_AssignPVZ_Call) RunAndReturn(run func(context.Context, string, string) error) *PvzUserStore_AssignPVZ_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AuthenticateAPIKey provides a mock function with given fields: ctx, keyHash
+func (_m *PvzUserStore) AuthenticateAPIKey(ctx context.Context, keyHash string) (models.APIKey, models.Role, error) {
+	ret := _m.Called(ctx, keyHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AuthenticateAPIKey")
+	}
+
+	var r0 models.APIKey
+	var r1 models.Role
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.APIKey, models.Role, error)); ok {
+		return rf(ctx, keyHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.APIKey); ok {
+		r0 = rf(ctx, keyHash)
+	} else {
+		r0 = ret.Get(0).(models.APIKey)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) models.Role); ok {
+		r1 = rf(ctx, keyHash)
+	} else {
+		r1 = ret.Get(1).(models.Role)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = rf(ctx, keyHash)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// PvzUserStore_AuthenticateAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AuthenticateAPIKey'
+type PvzUserStore_AuthenticateAPIKey_Call struct {
+	*mock.Call
+}
+
+// AuthenticateAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - keyHash string
+func (_e *PvzUserStore_Expecter) AuthenticateAPIKey(ctx interface{}, keyHash interface{}) *PvzUserStore_AuthenticateAPIKey_Call {
+	return &PvzUserStore_AuthenticateAPIKey_Call{Call: _e.mock.On("AuthenticateAPIKey", ctx, keyHash)}
+}
+
+func (_c *PvzUserStore_AuthenticateAPIKey_Call) Run(run func(ctx context.Context, keyHash string)) *PvzUserStore_AuthenticateAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_AuthenticateAPIKey_Call) Return(_a0 models.APIKey, _a1 models.Role, _a2 error) *PvzUserStore_AuthenticateAPIKey_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *PvzUserStore_AuthenticateAPIKey_Call) RunAndReturn(run func(context.Context, string) (models.APIKey, models.Role, error)) *PvzUserStore_AuthenticateAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ChangePassword provides a mock function with given fields: ctx, userID, oldPassword, newPasswordHash
+func (_m *PvzUserStore) ChangePassword(ctx context.Context, userID string, oldPassword string, newPasswordHash string) error {
+	ret := _m.Called(ctx, userID, oldPassword, newPasswordHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ChangePassword")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, userID, oldPassword, newPasswordHash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PvzUserStore_ChangePassword_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ChangePassword'
+type PvzUserStore_ChangePassword_Call struct {
+	*mock.Call
+}
+
+// ChangePassword is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - oldPassword string
+//   - newPasswordHash string
+func (_e *PvzUserStore_Expecter) ChangePassword(ctx interface{}, userID interface{}, oldPassword interface{}, newPasswordHash interface{}) *PvzUserStore_ChangePassword_Call {
+	return &PvzUserStore_ChangePassword_Call{Call: _e.mock.On("ChangePassword", ctx, userID, oldPassword, newPasswordHash)}
+}
+
+func (_c *PvzUserStore_ChangePassword_Call) Run(run func(ctx context.Context, userID string, oldPassword string, newPasswordHash string)) *PvzUserStore_ChangePassword_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_ChangePassword_Call) Return(_a0 error) *PvzUserStore_ChangePassword_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *PvzUserStore_ChangePassword_Call) RunAndReturn(run func(context.Context, string, string, string) error) *PvzUserStore_ChangePassword_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CloseLastReception provides a mock function with given fields: ctx, closedBy, pvzID, ifMatch
+func (_m *PvzUserStore) CloseLastReception(ctx context.Context, closedBy string, pvzID string, ifMatch models.IfMatch) (models.Reception, error) {
+	ret := _m.Called(ctx, closedBy, pvzID, ifMatch)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CloseLastReception")
@@ -33,17 +195,17 @@ func (_m *PvzUserStore) CloseLastReception(pvzID string) (models.Reception, erro
 
 	var r0 models.Reception
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (models.Reception, error)); ok {
-		return rf(pvzID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, models.IfMatch) (models.Reception, error)); ok {
+		return rf(ctx, closedBy, pvzID, ifMatch)
 	}
-	if rf, ok := ret.Get(0).(func(string) models.Reception); ok {
-		r0 = rf(pvzID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, models.IfMatch) models.Reception); ok {
+		r0 = rf(ctx, closedBy, pvzID, ifMatch)
 	} else {
 		r0 = ret.Get(0).(models.Reception)
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(pvzID)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, models.IfMatch) error); ok {
+		r1 = rf(ctx, closedBy, pvzID, ifMatch)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -57,14 +219,17 @@ type PvzUserStore_CloseLastReception_Call struct {
 }
 
 // CloseLastReception is a helper method to define mock.On call
+//   - ctx context.Context
+//   - closedBy string
 //   - pvzID string
-func (_e *PvzUserStore_Expecter) CloseLastReception(pvzID interface{}) *PvzUserStore_CloseLastReception_Call {
-	return &PvzUserStore_CloseLastReception_Call{Call: _e.mock.On("CloseLastReception", pvzID)}
+//   - ifMatch models.IfMatch
+func (_e *PvzUserStore_Expecter) CloseLastReception(ctx interface{}, closedBy interface{}, pvzID interface{}, ifMatch interface{}) *PvzUserStore_CloseLastReception_Call {
+	return &PvzUserStore_CloseLastReception_Call{Call: _e.mock.On("CloseLastReception", ctx, closedBy, pvzID, ifMatch)}
 }
 
-func (_c *PvzUserStore_CloseLastReception_Call) Run(run func(pvzID string)) *PvzUserStore_CloseLastReception_Call {
+func (_c *PvzUserStore_CloseLastReception_Call) Run(run func(ctx context.Context, closedBy string, pvzID string, ifMatch models.IfMatch)) *PvzUserStore_CloseLastReception_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(models.IfMatch))
 	})
 	return _c
 }
@@ -74,14 +239,62 @@ func (_c *PvzUserStore_CloseLastReception_Call) Return(_a0 models.Reception, _a1
 	return _c
 }
 
-func (_c *PvzUserStore_CloseLastReception_Call) RunAndReturn(run func(string) (models.Reception, error)) *PvzUserStore_CloseLastReception_Call {
+func (_c *PvzUserStore_CloseLastReception_Call) RunAndReturn(run func(context.Context, string, string, models.IfMatch) (models.Reception, error)) *PvzUserStore_CloseLastReception_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateAPIKey provides a mock function with given fields: ctx, key, keyHash
+func (_m *PvzUserStore) CreateAPIKey(ctx context.Context, key models.APIKey, keyHash string) error {
+	ret := _m.Called(ctx, key, keyHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAPIKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.APIKey, string) error); ok {
+		r0 = rf(ctx, key, keyHash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PvzUserStore_CreateAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateAPIKey'
+type PvzUserStore_CreateAPIKey_Call struct {
+	*mock.Call
+}
+
+// CreateAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key models.APIKey
+//   - keyHash string
+func (_e *PvzUserStore_Expecter) CreateAPIKey(ctx interface{}, key interface{}, keyHash interface{}) *PvzUserStore_CreateAPIKey_Call {
+	return &PvzUserStore_CreateAPIKey_Call{Call: _e.mock.On("CreateAPIKey", ctx, key, keyHash)}
+}
+
+func (_c *PvzUserStore_CreateAPIKey_Call) Run(run func(ctx context.Context, key models.APIKey, keyHash string)) *PvzUserStore_CreateAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.APIKey), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_CreateAPIKey_Call) Return(_a0 error) *PvzUserStore_CreateAPIKey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *PvzUserStore_CreateAPIKey_Call) RunAndReturn(run func(context.Context, models.APIKey, string) error) *PvzUserStore_CreateAPIKey_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// CreatePVZ provides a mock function with given fields: city
-func (_m *PvzUserStore) CreatePVZ(city models.City) (models.PVZ, error) {
-	ret := _m.Called(city)
+// CreatePVZ provides a mock function with given fields: ctx, city
+func (_m *PvzUserStore) CreatePVZ(ctx context.Context, city models.City) (models.PVZ, error) {
+	ret := _m.Called(ctx, city)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreatePVZ")
@@ -89,17 +302,17 @@ func (_m *PvzUserStore) CreatePVZ(city models.City) (models.PVZ, error) {
 
 	var r0 models.PVZ
 	var r1 error
-	if rf, ok := ret.Get(0).(func(models.City) (models.PVZ, error)); ok {
-		return rf(city)
+	if rf, ok := ret.Get(0).(func(context.Context, models.City) (models.PVZ, error)); ok {
+		return rf(ctx, city)
 	}
-	if rf, ok := ret.Get(0).(func(models.City) models.PVZ); ok {
-		r0 = rf(city)
+	if rf, ok := ret.Get(0).(func(context.Context, models.City) models.PVZ); ok {
+		r0 = rf(ctx, city)
 	} else {
 		r0 = ret.Get(0).(models.PVZ)
 	}
 
-	if rf, ok := ret.Get(1).(func(models.City) error); ok {
-		r1 = rf(city)
+	if rf, ok := ret.Get(1).(func(context.Context, models.City) error); ok {
+		r1 = rf(ctx, city)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -113,14 +326,15 @@ type PvzUserStore_CreatePVZ_Call struct {
 }
 
 // CreatePVZ is a helper method to define mock.On call
+//   - ctx context.Context
 //   - city models.City
-func (_e *PvzUserStore_Expecter) CreatePVZ(city interface{}) *PvzUserStore_CreatePVZ_Call {
-	return &PvzUserStore_CreatePVZ_Call{Call: _e.mock.On("CreatePVZ", city)}
+func (_e *PvzUserStore_Expecter) CreatePVZ(ctx interface{}, city interface{}) *PvzUserStore_CreatePVZ_Call {
+	return &PvzUserStore_CreatePVZ_Call{Call: _e.mock.On("CreatePVZ", ctx, city)}
 }
 
-func (_c *PvzUserStore_CreatePVZ_Call) Run(run func(city models.City)) *PvzUserStore_CreatePVZ_Call {
+func (_c *PvzUserStore_CreatePVZ_Call) Run(run func(ctx context.Context, city models.City)) *PvzUserStore_CreatePVZ_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(models.City))
+		run(args[0].(context.Context), args[1].(models.City))
 	})
 	return _c
 }
@@ -130,14 +344,73 @@ func (_c *PvzUserStore_CreatePVZ_Call) Return(_a0 models.PVZ, _a1 error) *PvzUse
 	return _c
 }
 
-func (_c *PvzUserStore_CreatePVZ_Call) RunAndReturn(run func(models.City) (models.PVZ, error)) *PvzUserStore_CreatePVZ_Call {
+func (_c *PvzUserStore_CreatePVZ_Call) RunAndReturn(run func(context.Context, models.City) (models.PVZ, error)) *PvzUserStore_CreatePVZ_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreatePasswordReset provides a mock function with given fields: ctx, email, tokenHash, expiresAt
+func (_m *PvzUserStore) CreatePasswordReset(ctx context.Context, email string, tokenHash string, expiresAt time.Time) (bool, error) {
+	ret := _m.Called(ctx, email, tokenHash, expiresAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreatePasswordReset")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time) (bool, error)); ok {
+		return rf(ctx, email, tokenHash, expiresAt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time) bool); ok {
+		r0 = rf(ctx, email, tokenHash, expiresAt)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, time.Time) error); ok {
+		r1 = rf(ctx, email, tokenHash, expiresAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserStore_CreatePasswordReset_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreatePasswordReset'
+type PvzUserStore_CreatePasswordReset_Call struct {
+	*mock.Call
+}
+
+// CreatePasswordReset is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+//   - tokenHash string
+//   - expiresAt time.Time
+func (_e *PvzUserStore_Expecter) CreatePasswordReset(ctx interface{}, email interface{}, tokenHash interface{}, expiresAt interface{}) *PvzUserStore_CreatePasswordReset_Call {
+	return &PvzUserStore_CreatePasswordReset_Call{Call: _e.mock.On("CreatePasswordReset", ctx, email, tokenHash, expiresAt)}
+}
+
+func (_c *PvzUserStore_CreatePasswordReset_Call) Run(run func(ctx context.Context, email string, tokenHash string, expiresAt time.Time)) *PvzUserStore_CreatePasswordReset_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(time.Time))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_CreatePasswordReset_Call) Return(_a0 bool, _a1 error) *PvzUserStore_CreatePasswordReset_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserStore_CreatePasswordReset_Call) RunAndReturn(run func(context.Context, string, string, time.Time) (bool, error)) *PvzUserStore_CreatePasswordReset_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// CreateProduct provides a mock function with given fields: pvzID, prType
-func (_m *PvzUserStore) CreateProduct(pvzID string, prType models.ProductType) (models.Product, error) {
-	ret := _m.Called(pvzID, prType)
+// CreateProduct provides a mock function with given fields: ctx, pvzID, prType
+func (_m *PvzUserStore) CreateProduct(ctx context.Context, pvzID string, prType models.ProductType) (models.Product, error) {
+	ret := _m.Called(ctx, pvzID, prType)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateProduct")
@@ -145,17 +418,17 @@ func (_m *PvzUserStore) CreateProduct(pvzID string, prType models.ProductType) (
 
 	var r0 models.Product
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, models.ProductType) (models.Product, error)); ok {
-		return rf(pvzID, prType)
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.ProductType) (models.Product, error)); ok {
+		return rf(ctx, pvzID, prType)
 	}
-	if rf, ok := ret.Get(0).(func(string, models.ProductType) models.Product); ok {
-		r0 = rf(pvzID, prType)
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.ProductType) models.Product); ok {
+		r0 = rf(ctx, pvzID, prType)
 	} else {
 		r0 = ret.Get(0).(models.Product)
 	}
 
-	if rf, ok := ret.Get(1).(func(string, models.ProductType) error); ok {
-		r1 = rf(pvzID, prType)
+	if rf, ok := ret.Get(1).(func(context.Context, string, models.ProductType) error); ok {
+		r1 = rf(ctx, pvzID, prType)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -169,15 +442,16 @@ type PvzUserStore_CreateProduct_Call struct {
 }
 
 // CreateProduct is a helper method to define mock.On call
+//   - ctx context.Context
 //   - pvzID string
 //   - prType models.ProductType
-func (_e *PvzUserStore_Expecter) CreateProduct(pvzID interface{}, prType interface{}) *PvzUserStore_CreateProduct_Call {
-	return &PvzUserStore_CreateProduct_Call{Call: _e.mock.On("CreateProduct", pvzID, prType)}
+func (_e *PvzUserStore_Expecter) CreateProduct(ctx interface{}, pvzID interface{}, prType interface{}) *PvzUserStore_CreateProduct_Call {
+	return &PvzUserStore_CreateProduct_Call{Call: _e.mock.On("CreateProduct", ctx, pvzID, prType)}
 }
 
-func (_c *PvzUserStore_CreateProduct_Call) Run(run func(pvzID string, prType models.ProductType)) *PvzUserStore_CreateProduct_Call {
+func (_c *PvzUserStore_CreateProduct_Call) Run(run func(ctx context.Context, pvzID string, prType models.ProductType)) *PvzUserStore_CreateProduct_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(models.ProductType))
+		run(args[0].(context.Context), args[1].(string), args[2].(models.ProductType))
 	})
 	return _c
 }
@@ -187,14 +461,14 @@ func (_c *PvzUserStore_CreateProduct_Call) Return(_a0 models.Product, _a1 error)
 	return _c
 }
 
-func (_c *PvzUserStore_CreateProduct_Call) RunAndReturn(run func(string, models.ProductType) (models.Product, error)) *PvzUserStore_CreateProduct_Call {
+func (_c *PvzUserStore_CreateProduct_Call) RunAndReturn(run func(context.Context, string, models.ProductType) (models.Product, error)) *PvzUserStore_CreateProduct_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// CreateReception provides a mock function with given fields: pvzID
-func (_m *PvzUserStore) CreateReception(pvzID string) (models.Reception, error) {
-	ret := _m.Called(pvzID)
+// CreateReception provides a mock function with given fields: ctx, pvzID
+func (_m *PvzUserStore) CreateReception(ctx context.Context, pvzID string) (models.Reception, error) {
+	ret := _m.Called(ctx, pvzID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateReception")
@@ -202,17 +476,17 @@ func (_m *PvzUserStore) CreateReception(pvzID string) (models.Reception, error)
 
 	var r0 models.Reception
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (models.Reception, error)); ok {
-		return rf(pvzID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.Reception, error)); ok {
+		return rf(ctx, pvzID)
 	}
-	if rf, ok := ret.Get(0).(func(string) models.Reception); ok {
-		r0 = rf(pvzID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.Reception); ok {
+		r0 = rf(ctx, pvzID)
 	} else {
 		r0 = ret.Get(0).(models.Reception)
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(pvzID)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, pvzID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -226,14 +500,15 @@ type PvzUserStore_CreateReception_Call struct {
 }
 
 // CreateReception is a helper method to define mock.On call
+//   - ctx context.Context
 //   - pvzID string
-func (_e *PvzUserStore_Expecter) CreateReception(pvzID interface{}) *PvzUserStore_CreateReception_Call {
-	return &PvzUserStore_CreateReception_Call{Call: _e.mock.On("CreateReception", pvzID)}
+func (_e *PvzUserStore_Expecter) CreateReception(ctx interface{}, pvzID interface{}) *PvzUserStore_CreateReception_Call {
+	return &PvzUserStore_CreateReception_Call{Call: _e.mock.On("CreateReception", ctx, pvzID)}
 }
 
-func (_c *PvzUserStore_CreateReception_Call) Run(run func(pvzID string)) *PvzUserStore_CreateReception_Call {
+func (_c *PvzUserStore_CreateReception_Call) Run(run func(ctx context.Context, pvzID string)) *PvzUserStore_CreateReception_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
@@ -243,22 +518,22 @@ func (_c *PvzUserStore_CreateReception_Call) Return(_a0 models.Reception, _a1 er
 	return _c
 }
 
-func (_c *PvzUserStore_CreateReception_Call) RunAndReturn(run func(string) (models.Reception, error)) *PvzUserStore_CreateReception_Call {
+func (_c *PvzUserStore_CreateReception_Call) RunAndReturn(run func(context.Context, string) (models.Reception, error)) *PvzUserStore_CreateReception_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DeleteLastProduct provides a mock function with given fields: pvzID
-func (_m *PvzUserStore) DeleteLastProduct(pvzID string) error {
-	ret := _m.Called(pvzID)
+// CreateWebhook provides a mock function with given fields: ctx, hook
+func (_m *PvzUserStore) CreateWebhook(ctx context.Context, hook models.Webhook) error {
+	ret := _m.Called(ctx, hook)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DeleteLastProduct")
+		panic("no return value specified for CreateWebhook")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(pvzID)
+	if rf, ok := ret.Get(0).(func(context.Context, models.Webhook) error); ok {
+		r0 = rf(ctx, hook)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -266,116 +541,103 @@ func (_m *PvzUserStore) DeleteLastProduct(pvzID string) error {
 	return r0
 }
 
-// PvzUserStore_DeleteLastProduct_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteLastProduct'
-type PvzUserStore_DeleteLastProduct_Call struct {
+// PvzUserStore_CreateWebhook_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateWebhook'
+type PvzUserStore_CreateWebhook_Call struct {
 	*mock.Call
 }
 
-// DeleteLastProduct is a helper method to define mock.On call
-//   - pvzID string
-func (_e *PvzUserStore_Expecter) DeleteLastProduct(pvzID interface{}) *PvzUserStore_DeleteLastProduct_Call {
-	return &PvzUserStore_DeleteLastProduct_Call{Call: _e.mock.On("DeleteLastProduct", pvzID)}
+// CreateWebhook is a helper method to define mock.On call
+//   - ctx context.Context
+//   - hook models.Webhook
+func (_e *PvzUserStore_Expecter) CreateWebhook(ctx interface{}, hook interface{}) *PvzUserStore_CreateWebhook_Call {
+	return &PvzUserStore_CreateWebhook_Call{Call: _e.mock.On("CreateWebhook", ctx, hook)}
 }
 
-func (_c *PvzUserStore_DeleteLastProduct_Call) Run(run func(pvzID string)) *PvzUserStore_DeleteLastProduct_Call {
+func (_c *PvzUserStore_CreateWebhook_Call) Run(run func(ctx context.Context, hook models.Webhook)) *PvzUserStore_CreateWebhook_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(models.Webhook))
 	})
 	return _c
 }
 
-func (_c *PvzUserStore_DeleteLastProduct_Call) Return(_a0 error) *PvzUserStore_DeleteLastProduct_Call {
+func (_c *PvzUserStore_CreateWebhook_Call) Return(_a0 error) *PvzUserStore_CreateWebhook_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *PvzUserStore_DeleteLastProduct_Call) RunAndReturn(run func(string) error) *PvzUserStore_DeleteLastProduct_Call {
+func (_c *PvzUserStore_CreateWebhook_Call) RunAndReturn(run func(context.Context, models.Webhook) error) *PvzUserStore_CreateWebhook_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetPVZInfo provides a mock function with given fields: start, end, page, limit
-func (_m *PvzUserStore) GetPVZInfo(start time.Time, end time.Time, page int, limit int) ([]models.PVZInfo, error) {
-	ret := _m.Called(start, end, page, limit)
+// DeleteAPIKey provides a mock function with given fields: ctx, keyID
+func (_m *PvzUserStore) DeleteAPIKey(ctx context.Context, keyID string) error {
+	ret := _m.Called(ctx, keyID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetPVZInfo")
-	}
-
-	var r0 []models.PVZInfo
-	var r1 error
-	if rf, ok := ret.Get(0).(func(time.Time, time.Time, int, int) ([]models.PVZInfo, error)); ok {
-		return rf(start, end, page, limit)
-	}
-	if rf, ok := ret.Get(0).(func(time.Time, time.Time, int, int) []models.PVZInfo); ok {
-		r0 = rf(start, end, page, limit)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]models.PVZInfo)
-		}
+		panic("no return value specified for DeleteAPIKey")
 	}
 
-	if rf, ok := ret.Get(1).(func(time.Time, time.Time, int, int) error); ok {
-		r1 = rf(start, end, page, limit)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, keyID)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// PvzUserStore_GetPVZInfo_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPVZInfo'
-type PvzUserStore_GetPVZInfo_Call struct {
+// PvzUserStore_DeleteAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteAPIKey'
+type PvzUserStore_DeleteAPIKey_Call struct {
 	*mock.Call
 }
 
-// GetPVZInfo is a helper method to define mock.On call
-//   - start time.Time
-//   - end time.Time
-//   - page int
-//   - limit int
-func (_e *PvzUserStore_Expecter) GetPVZInfo(start interface{}, end interface{}, page interface{}, limit interface{}) *PvzUserStore_GetPVZInfo_Call {
-	return &PvzUserStore_GetPVZInfo_Call{Call: _e.mock.On("GetPVZInfo", start, end, page, limit)}
+// DeleteAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - keyID string
+func (_e *PvzUserStore_Expecter) DeleteAPIKey(ctx interface{}, keyID interface{}) *PvzUserStore_DeleteAPIKey_Call {
+	return &PvzUserStore_DeleteAPIKey_Call{Call: _e.mock.On("DeleteAPIKey", ctx, keyID)}
 }
 
-func (_c *PvzUserStore_GetPVZInfo_Call) Run(run func(start time.Time, end time.Time, page int, limit int)) *PvzUserStore_GetPVZInfo_Call {
+func (_c *PvzUserStore_DeleteAPIKey_Call) Run(run func(ctx context.Context, keyID string)) *PvzUserStore_DeleteAPIKey_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(time.Time), args[1].(time.Time), args[2].(int), args[3].(int))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *PvzUserStore_GetPVZInfo_Call) Return(_a0 []models.PVZInfo, _a1 error) *PvzUserStore_GetPVZInfo_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *PvzUserStore_DeleteAPIKey_Call) Return(_a0 error) *PvzUserStore_DeleteAPIKey_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *PvzUserStore_GetPVZInfo_Call) RunAndReturn(run func(time.Time, time.Time, int, int) ([]models.PVZInfo, error)) *PvzUserStore_GetPVZInfo_Call {
+func (_c *PvzUserStore_DeleteAPIKey_Call) RunAndReturn(run func(context.Context, string) error) *PvzUserStore_DeleteAPIKey_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// LoginUser provides a mock function with given fields: email, password
-func (_m *PvzUserStore) LoginUser(email string, password string) (models.Token, error) {
-	ret := _m.Called(email, password)
+// DeleteLastProduct provides a mock function with given fields: ctx, pvzID
+func (_m *PvzUserStore) DeleteLastProduct(ctx context.Context, pvzID string) (models.Product, error) {
+	ret := _m.Called(ctx, pvzID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for LoginUser")
+		panic("no return value specified for DeleteLastProduct")
 	}
 
-	var r0 models.Token
+	var r0 models.Product
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string) (models.Token, error)); ok {
-		return rf(email, password)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.Product, error)); ok {
+		return rf(ctx, pvzID)
 	}
-	if rf, ok := ret.Get(0).(func(string, string) models.Token); ok {
-		r0 = rf(email, password)
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.Product); ok {
+		r0 = rf(ctx, pvzID)
 	} else {
-		r0 = ret.Get(0).(models.Token)
+		r0 = ret.Get(0).(models.Product)
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(email, password)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, pvzID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -383,89 +645,1607 @@ func (_m *PvzUserStore) LoginUser(email string, password string) (models.Token,
 	return r0, r1
 }
 
-// PvzUserStore_LoginUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LoginUser'
-type PvzUserStore_LoginUser_Call struct {
+// PvzUserStore_DeleteLastProduct_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteLastProduct'
+type PvzUserStore_DeleteLastProduct_Call struct {
 	*mock.Call
 }
 
-// LoginUser is a helper method to define mock.On call
-//   - email string
-//   - password string
-func (_e *PvzUserStore_Expecter) LoginUser(email interface{}, password interface{}) *PvzUserStore_LoginUser_Call {
-	return &PvzUserStore_LoginUser_Call{Call: _e.mock.On("LoginUser", email, password)}
+// DeleteLastProduct is a helper method to define mock.On call
+//   - ctx context.Context
+//   - pvzID string
+func (_e *PvzUserStore_Expecter) DeleteLastProduct(ctx interface{}, pvzID interface{}) *PvzUserStore_DeleteLastProduct_Call {
+	return &PvzUserStore_DeleteLastProduct_Call{Call: _e.mock.On("DeleteLastProduct", ctx, pvzID)}
 }
 
-func (_c *PvzUserStore_LoginUser_Call) Run(run func(email string, password string)) *PvzUserStore_LoginUser_Call {
+func (_c *PvzUserStore_DeleteLastProduct_Call) Run(run func(ctx context.Context, pvzID string)) *PvzUserStore_DeleteLastProduct_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *PvzUserStore_LoginUser_Call) Return(_a0 models.Token, _a1 error) *PvzUserStore_LoginUser_Call {
+func (_c *PvzUserStore_DeleteLastProduct_Call) Return(_a0 models.Product, _a1 error) *PvzUserStore_DeleteLastProduct_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *PvzUserStore_LoginUser_Call) RunAndReturn(run func(string, string) (models.Token, error)) *PvzUserStore_LoginUser_Call {
+func (_c *PvzUserStore_DeleteLastProduct_Call) RunAndReturn(run func(context.Context, string) (models.Product, error)) *PvzUserStore_DeleteLastProduct_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// RegisterUser provides a mock function with given fields: email, password, role
-func (_m *PvzUserStore) RegisterUser(email string, password string, role models.Role) (models.User, error) {
-	ret := _m.Called(email, password, role)
+// DeleteUser provides a mock function with given fields: ctx, userID
+func (_m *PvzUserStore) DeleteUser(ctx context.Context, userID string) error {
+	ret := _m.Called(ctx, userID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for RegisterUser")
+		panic("no return value specified for DeleteUser")
 	}
 
-	var r0 models.User
-	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string, models.Role) (models.User, error)); ok {
-		return rf(email, password, role)
-	}
-	if rf, ok := ret.Get(0).(func(string, string, models.Role) models.User); ok {
-		r0 = rf(email, password, role)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, userID)
 	} else {
-		r0 = ret.Get(0).(models.User)
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PvzUserStore_DeleteUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteUser'
+type PvzUserStore_DeleteUser_Call struct {
+	*mock.Call
+}
+
+// DeleteUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *PvzUserStore_Expecter) DeleteUser(ctx interface{}, userID interface{}) *PvzUserStore_DeleteUser_Call {
+	return &PvzUserStore_DeleteUser_Call{Call: _e.mock.On("DeleteUser", ctx, userID)}
+}
+
+func (_c *PvzUserStore_DeleteUser_Call) Run(run func(ctx context.Context, userID string)) *PvzUserStore_DeleteUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_DeleteUser_Call) Return(_a0 error) *PvzUserStore_DeleteUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *PvzUserStore_DeleteUser_Call) RunAndReturn(run func(context.Context, string) error) *PvzUserStore_DeleteUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteWebhook provides a mock function with given fields: ctx, webhookID
+func (_m *PvzUserStore) DeleteWebhook(ctx context.Context, webhookID string) error {
+	ret := _m.Called(ctx, webhookID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteWebhook")
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string, models.Role) error); ok {
-		r1 = rf(email, password, role)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, webhookID)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// PvzUserStore_RegisterUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RegisterUser'
-type PvzUserStore_RegisterUser_Call struct {
+// PvzUserStore_DeleteWebhook_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteWebhook'
+type PvzUserStore_DeleteWebhook_Call struct {
 	*mock.Call
 }
 
-// RegisterUser is a helper method to define mock.On call
-//   - email string
-//   - password string
-//   - role models.Role
-func (_e *PvzUserStore_Expecter) RegisterUser(email interface{}, password interface{}, role interface{}) *PvzUserStore_RegisterUser_Call {
-	return &PvzUserStore_RegisterUser_Call{Call: _e.mock.On("RegisterUser", email, password, role)}
+// DeleteWebhook is a helper method to define mock.On call
+//   - ctx context.Context
+//   - webhookID string
+func (_e *PvzUserStore_Expecter) DeleteWebhook(ctx interface{}, webhookID interface{}) *PvzUserStore_DeleteWebhook_Call {
+	return &PvzUserStore_DeleteWebhook_Call{Call: _e.mock.On("DeleteWebhook", ctx, webhookID)}
 }
 
-func (_c *PvzUserStore_RegisterUser_Call) Run(run func(email string, password string, role models.Role)) *PvzUserStore_RegisterUser_Call {
+func (_c *PvzUserStore_DeleteWebhook_Call) Run(run func(ctx context.Context, webhookID string)) *PvzUserStore_DeleteWebhook_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(string), args[2].(models.Role))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *PvzUserStore_RegisterUser_Call) Return(_a0 models.User, _a1 error) *PvzUserStore_RegisterUser_Call {
+func (_c *PvzUserStore_DeleteWebhook_Call) Return(_a0 error) *PvzUserStore_DeleteWebhook_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *PvzUserStore_DeleteWebhook_Call) RunAndReturn(run func(context.Context, string) error) *PvzUserStore_DeleteWebhook_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// EnsureUser provides a mock function with given fields: ctx, user
+func (_m *PvzUserStore) EnsureUser(ctx context.Context, user models.User) error {
+	ret := _m.Called(ctx, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EnsureUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.User) error); ok {
+		r0 = rf(ctx, user)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PvzUserStore_EnsureUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EnsureUser'
+type PvzUserStore_EnsureUser_Call struct {
+	*mock.Call
+}
+
+// EnsureUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - user models.User
+func (_e *PvzUserStore_Expecter) EnsureUser(ctx interface{}, user interface{}) *PvzUserStore_EnsureUser_Call {
+	return &PvzUserStore_EnsureUser_Call{Call: _e.mock.On("EnsureUser", ctx, user)}
+}
+
+func (_c *PvzUserStore_EnsureUser_Call) Run(run func(ctx context.Context, user models.User)) *PvzUserStore_EnsureUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.User))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_EnsureUser_Call) Return(_a0 error) *PvzUserStore_EnsureUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *PvzUserStore_EnsureUser_Call) RunAndReturn(run func(context.Context, models.User) error) *PvzUserStore_EnsureUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ExportProducts provides a mock function with given fields: ctx, start, end, fn
+func (_m *PvzUserStore) ExportProducts(ctx context.Context, start time.Time, end time.Time, fn func(models.ExportProduct) error) error {
+	ret := _m.Called(ctx, start, end, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportProducts")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time, func(models.ExportProduct) error) error); ok {
+		r0 = rf(ctx, start, end, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PvzUserStore_ExportProducts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExportProducts'
+type PvzUserStore_ExportProducts_Call struct {
+	*mock.Call
+}
+
+// ExportProducts is a helper method to define mock.On call
+//   - ctx context.Context
+//   - start time.Time
+//   - end time.Time
+//   - fn func(models.ExportProduct) error
+func (_e *PvzUserStore_Expecter) ExportProducts(ctx interface{}, start interface{}, end interface{}, fn interface{}) *PvzUserStore_ExportProducts_Call {
+	return &PvzUserStore_ExportProducts_Call{Call: _e.mock.On("ExportProducts", ctx, start, end, fn)}
+}
+
+func (_c *PvzUserStore_ExportProducts_Call) Run(run func(ctx context.Context, start time.Time, end time.Time, fn func(models.ExportProduct) error)) *PvzUserStore_ExportProducts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Time), args[2].(time.Time), args[3].(func(models.ExportProduct) error))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_ExportProducts_Call) Return(_a0 error) *PvzUserStore_ExportProducts_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *PvzUserStore_ExportProducts_Call) RunAndReturn(run func(context.Context, time.Time, time.Time, func(models.ExportProduct) error) error) *PvzUserStore_ExportProducts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ExportReceptions provides a mock function with given fields: ctx, start, end, fn
+func (_m *PvzUserStore) ExportReceptions(ctx context.Context, start time.Time, end time.Time, fn func(models.ExportReception) error) error {
+	ret := _m.Called(ctx, start, end, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportReceptions")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time, func(models.ExportReception) error) error); ok {
+		r0 = rf(ctx, start, end, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PvzUserStore_ExportReceptions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExportReceptions'
+type PvzUserStore_ExportReceptions_Call struct {
+	*mock.Call
+}
+
+// ExportReceptions is a helper method to define mock.On call
+//   - ctx context.Context
+//   - start time.Time
+//   - end time.Time
+//   - fn func(models.ExportReception) error
+func (_e *PvzUserStore_Expecter) ExportReceptions(ctx interface{}, start interface{}, end interface{}, fn interface{}) *PvzUserStore_ExportReceptions_Call {
+	return &PvzUserStore_ExportReceptions_Call{Call: _e.mock.On("ExportReceptions", ctx, start, end, fn)}
+}
+
+func (_c *PvzUserStore_ExportReceptions_Call) Run(run func(ctx context.Context, start time.Time, end time.Time, fn func(models.ExportReception) error)) *PvzUserStore_ExportReceptions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Time), args[2].(time.Time), args[3].(func(models.ExportReception) error))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_ExportReceptions_Call) Return(_a0 error) *PvzUserStore_ExportReceptions_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *PvzUserStore_ExportReceptions_Call) RunAndReturn(run func(context.Context, time.Time, time.Time, func(models.ExportReception) error) error) *PvzUserStore_ExportReceptions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindPVZsByAddress provides a mock function with given fields: ctx, addresses
+func (_m *PvzUserStore) FindPVZsByAddress(ctx context.Context, addresses []string) ([]models.PVZ, error) {
+	ret := _m.Called(ctx, addresses)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindPVZsByAddress")
+	}
+
+	var r0 []models.PVZ
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]models.PVZ, error)); ok {
+		return rf(ctx, addresses)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []models.PVZ); ok {
+		r0 = rf(ctx, addresses)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.PVZ)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, addresses)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserStore_FindPVZsByAddress_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindPVZsByAddress'
+type PvzUserStore_FindPVZsByAddress_Call struct {
+	*mock.Call
+}
+
+// FindPVZsByAddress is a helper method to define mock.On call
+//   - ctx context.Context
+//   - addresses []string
+func (_e *PvzUserStore_Expecter) FindPVZsByAddress(ctx interface{}, addresses interface{}) *PvzUserStore_FindPVZsByAddress_Call {
+	return &PvzUserStore_FindPVZsByAddress_Call{Call: _e.mock.On("FindPVZsByAddress", ctx, addresses)}
+}
+
+func (_c *PvzUserStore_FindPVZsByAddress_Call) Run(run func(ctx context.Context, addresses []string)) *PvzUserStore_FindPVZsByAddress_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]string))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_FindPVZsByAddress_Call) Return(_a0 []models.PVZ, _a1 error) *PvzUserStore_FindPVZsByAddress_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserStore_FindPVZsByAddress_Call) RunAndReturn(run func(context.Context, []string) ([]models.PVZ, error)) *PvzUserStore_FindPVZsByAddress_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetPVZInfo provides a mock function with given fields: ctx, start, end, by, page, limit
+func (_m *PvzUserStore) GetPVZInfo(ctx context.Context, start time.Time, end time.Time, by models.ReceptionDate, page int, limit int) ([]models.PVZInfo, error) {
+	ret := _m.Called(ctx, start, end, by, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPVZInfo")
+	}
+
+	var r0 []models.PVZInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time, models.ReceptionDate, int, int) ([]models.PVZInfo, error)); ok {
+		return rf(ctx, start, end, by, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time, models.ReceptionDate, int, int) []models.PVZInfo); ok {
+		r0 = rf(ctx, start, end, by, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.PVZInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, time.Time, models.ReceptionDate, int, int) error); ok {
+		r1 = rf(ctx, start, end, by, page, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserStore_GetPVZInfo_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPVZInfo'
+type PvzUserStore_GetPVZInfo_Call struct {
+	*mock.Call
+}
+
+// GetPVZInfo is a helper method to define mock.On call
+//   - ctx context.Context
+//   - start time.Time
+//   - end time.Time
+//   - by models.ReceptionDate
+//   - page int
+//   - limit int
+func (_e *PvzUserStore_Expecter) GetPVZInfo(ctx interface{}, start interface{}, end interface{}, by interface{}, page interface{}, limit interface{}) *PvzUserStore_GetPVZInfo_Call {
+	return &PvzUserStore_GetPVZInfo_Call{Call: _e.mock.On("GetPVZInfo", ctx, start, end, by, page, limit)}
+}
+
+func (_c *PvzUserStore_GetPVZInfo_Call) Run(run func(ctx context.Context, start time.Time, end time.Time, by models.ReceptionDate, page int, limit int)) *PvzUserStore_GetPVZInfo_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Time), args[2].(time.Time), args[3].(models.ReceptionDate), args[4].(int), args[5].(int))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_GetPVZInfo_Call) Return(_a0 []models.PVZInfo, _a1 error) *PvzUserStore_GetPVZInfo_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserStore_GetPVZInfo_Call) RunAndReturn(run func(context.Context, time.Time, time.Time, models.ReceptionDate, int, int) ([]models.PVZInfo, error)) *PvzUserStore_GetPVZInfo_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUser provides a mock function with given fields: ctx, userID
+func (_m *PvzUserStore) GetUser(ctx context.Context, userID string) (models.User, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUser")
+	}
+
+	var r0 models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.User, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.User); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(models.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserStore_GetUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUser'
+type PvzUserStore_GetUser_Call struct {
+	*mock.Call
+}
+
+// GetUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *PvzUserStore_Expecter) GetUser(ctx interface{}, userID interface{}) *PvzUserStore_GetUser_Call {
+	return &PvzUserStore_GetUser_Call{Call: _e.mock.On("GetUser", ctx, userID)}
+}
+
+func (_c *PvzUserStore_GetUser_Call) Run(run func(ctx context.Context, userID string)) *PvzUserStore_GetUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_GetUser_Call) Return(_a0 models.User, _a1 error) *PvzUserStore_GetUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserStore_GetUser_Call) RunAndReturn(run func(context.Context, string) (models.User, error)) *PvzUserStore_GetUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUserPVZs provides a mock function with given fields: ctx, userID
+func (_m *PvzUserStore) GetUserPVZs(ctx context.Context, userID string) ([]models.PVZ, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserPVZs")
+	}
+
+	var r0 []models.PVZ
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]models.PVZ, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []models.PVZ); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.PVZ)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserStore_GetUserPVZs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserPVZs'
+type PvzUserStore_GetUserPVZs_Call struct {
+	*mock.Call
+}
+
+// GetUserPVZs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *PvzUserStore_Expecter) GetUserPVZs(ctx interface{}, userID interface{}) *PvzUserStore_GetUserPVZs_Call {
+	return &PvzUserStore_GetUserPVZs_Call{Call: _e.mock.On("GetUserPVZs", ctx, userID)}
+}
+
+func (_c *PvzUserStore_GetUserPVZs_Call) Run(run func(ctx context.Context, userID string)) *PvzUserStore_GetUserPVZs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_GetUserPVZs_Call) Return(_a0 []models.PVZ, _a1 error) *PvzUserStore_GetUserPVZs_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserStore_GetUserPVZs_Call) RunAndReturn(run func(context.Context, string) ([]models.PVZ, error)) *PvzUserStore_GetUserPVZs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ImportPVZs provides a mock function with given fields: ctx, rows
+func (_m *PvzUserStore) ImportPVZs(ctx context.Context, rows []models.PVZImportRow) ([]models.PVZ, error) {
+	ret := _m.Called(ctx, rows)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportPVZs")
+	}
+
+	var r0 []models.PVZ
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []models.PVZImportRow) ([]models.PVZ, error)); ok {
+		return rf(ctx, rows)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []models.PVZImportRow) []models.PVZ); ok {
+		r0 = rf(ctx, rows)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.PVZ)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []models.PVZImportRow) error); ok {
+		r1 = rf(ctx, rows)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserStore_ImportPVZs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ImportPVZs'
+type PvzUserStore_ImportPVZs_Call struct {
+	*mock.Call
+}
+
+// ImportPVZs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - rows []models.PVZImportRow
+func (_e *PvzUserStore_Expecter) ImportPVZs(ctx interface{}, rows interface{}) *PvzUserStore_ImportPVZs_Call {
+	return &PvzUserStore_ImportPVZs_Call{Call: _e.mock.On("ImportPVZs", ctx, rows)}
+}
+
+func (_c *PvzUserStore_ImportPVZs_Call) Run(run func(ctx context.Context, rows []models.PVZImportRow)) *PvzUserStore_ImportPVZs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]models.PVZImportRow))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_ImportPVZs_Call) Return(_a0 []models.PVZ, _a1 error) *PvzUserStore_ImportPVZs_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserStore_ImportPVZs_Call) RunAndReturn(run func(context.Context, []models.PVZImportRow) ([]models.PVZ, error)) *PvzUserStore_ImportPVZs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IntakeReport provides a mock function with given fields: ctx, f
+func (_m *PvzUserStore) IntakeReport(ctx context.Context, f models.IntakeReportFilter) ([]models.IntakeRow, error) {
+	ret := _m.Called(ctx, f)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IntakeReport")
+	}
+
+	var r0 []models.IntakeRow
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.IntakeReportFilter) ([]models.IntakeRow, error)); ok {
+		return rf(ctx, f)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.IntakeReportFilter) []models.IntakeRow); ok {
+		r0 = rf(ctx, f)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.IntakeRow)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.IntakeReportFilter) error); ok {
+		r1 = rf(ctx, f)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserStore_IntakeReport_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IntakeReport'
+type PvzUserStore_IntakeReport_Call struct {
+	*mock.Call
+}
+
+// IntakeReport is a helper method to define mock.On call
+//   - ctx context.Context
+//   - f models.IntakeReportFilter
+func (_e *PvzUserStore_Expecter) IntakeReport(ctx interface{}, f interface{}) *PvzUserStore_IntakeReport_Call {
+	return &PvzUserStore_IntakeReport_Call{Call: _e.mock.On("IntakeReport", ctx, f)}
+}
+
+func (_c *PvzUserStore_IntakeReport_Call) Run(run func(ctx context.Context, f models.IntakeReportFilter)) *PvzUserStore_IntakeReport_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.IntakeReportFilter))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_IntakeReport_Call) Return(_a0 []models.IntakeRow, _a1 error) *PvzUserStore_IntakeReport_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserStore_IntakeReport_Call) RunAndReturn(run func(context.Context, models.IntakeReportFilter) ([]models.IntakeRow, error)) *PvzUserStore_IntakeReport_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IsPVZAssigned provides a mock function with given fields: ctx, userID, pvzID
+func (_m *PvzUserStore) IsPVZAssigned(ctx context.Context, userID string, pvzID string) (bool, error) {
+	ret := _m.Called(ctx, userID, pvzID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsPVZAssigned")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (bool, error)); ok {
+		return rf(ctx, userID, pvzID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) bool); ok {
+		r0 = rf(ctx, userID, pvzID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userID, pvzID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserStore_IsPVZAssigned_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IsPVZAssigned'
+type PvzUserStore_IsPVZAssigned_Call struct {
+	*mock.Call
+}
+
+// IsPVZAssigned is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - pvzID string
+func (_e *PvzUserStore_Expecter) IsPVZAssigned(ctx interface{}, userID interface{}, pvzID interface{}) *PvzUserStore_IsPVZAssigned_Call {
+	return &PvzUserStore_IsPVZAssigned_Call{Call: _e.mock.On("IsPVZAssigned", ctx, userID, pvzID)}
+}
+
+func (_c *PvzUserStore_IsPVZAssigned_Call) Run(run func(ctx context.Context, userID string, pvzID string)) *PvzUserStore_IsPVZAssigned_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_IsPVZAssigned_Call) Return(_a0 bool, _a1 error) *PvzUserStore_IsPVZAssigned_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserStore_IsPVZAssigned_Call) RunAndReturn(run func(context.Context, string, string) (bool, error)) *PvzUserStore_IsPVZAssigned_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListAPIKeys provides a mock function with given fields: ctx
+func (_m *PvzUserStore) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAPIKeys")
+	}
+
+	var r0 []models.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]models.APIKey, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []models.APIKey); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserStore_ListAPIKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListAPIKeys'
+type PvzUserStore_ListAPIKeys_Call struct {
+	*mock.Call
+}
+
+// ListAPIKeys is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *PvzUserStore_Expecter) ListAPIKeys(ctx interface{}) *PvzUserStore_ListAPIKeys_Call {
+	return &PvzUserStore_ListAPIKeys_Call{Call: _e.mock.On("ListAPIKeys", ctx)}
+}
+
+func (_c *PvzUserStore_ListAPIKeys_Call) Run(run func(ctx context.Context)) *PvzUserStore_ListAPIKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_ListAPIKeys_Call) Return(_a0 []models.APIKey, _a1 error) *PvzUserStore_ListAPIKeys_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserStore_ListAPIKeys_Call) RunAndReturn(run func(context.Context) ([]models.APIKey, error)) *PvzUserStore_ListAPIKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListPVZEvents provides a mock function with given fields: ctx, pvzID, afterSeq, limit
+func (_m *PvzUserStore) ListPVZEvents(ctx context.Context, pvzID string, afterSeq int64, limit int) ([]models.Event, error) {
+	ret := _m.Called(ctx, pvzID, afterSeq, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListPVZEvents")
+	}
+
+	var r0 []models.Event
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, int) ([]models.Event, error)); ok {
+		return rf(ctx, pvzID, afterSeq, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, int) []models.Event); ok {
+		r0 = rf(ctx, pvzID, afterSeq, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Event)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64, int) error); ok {
+		r1 = rf(ctx, pvzID, afterSeq, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserStore_ListPVZEvents_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListPVZEvents'
+type PvzUserStore_ListPVZEvents_Call struct {
+	*mock.Call
+}
+
+// ListPVZEvents is a helper method to define mock.On call
+//   - ctx context.Context
+//   - pvzID string
+//   - afterSeq int64
+//   - limit int
+func (_e *PvzUserStore_Expecter) ListPVZEvents(ctx interface{}, pvzID interface{}, afterSeq interface{}, limit interface{}) *PvzUserStore_ListPVZEvents_Call {
+	return &PvzUserStore_ListPVZEvents_Call{Call: _e.mock.On("ListPVZEvents", ctx, pvzID, afterSeq, limit)}
+}
+
+func (_c *PvzUserStore_ListPVZEvents_Call) Run(run func(ctx context.Context, pvzID string, afterSeq int64, limit int)) *PvzUserStore_ListPVZEvents_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int64), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_ListPVZEvents_Call) Return(_a0 []models.Event, _a1 error) *PvzUserStore_ListPVZEvents_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserStore_ListPVZEvents_Call) RunAndReturn(run func(context.Context, string, int64, int) ([]models.Event, error)) *PvzUserStore_ListPVZEvents_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListUsers provides a mock function with given fields: ctx, role, page, limit
+func (_m *PvzUserStore) ListUsers(ctx context.Context, role models.Role, page int, limit int) ([]models.User, error) {
+	ret := _m.Called(ctx, role, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListUsers")
+	}
+
+	var r0 []models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.Role, int, int) ([]models.User, error)); ok {
+		return rf(ctx, role, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.Role, int, int) []models.User); ok {
+		r0 = rf(ctx, role, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.Role, int, int) error); ok {
+		r1 = rf(ctx, role, page, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserStore_ListUsers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListUsers'
+type PvzUserStore_ListUsers_Call struct {
+	*mock.Call
+}
+
+// ListUsers is a helper method to define mock.On call
+//   - ctx context.Context
+//   - role models.Role
+//   - page int
+//   - limit int
+func (_e *PvzUserStore_Expecter) ListUsers(ctx interface{}, role interface{}, page interface{}, limit interface{}) *PvzUserStore_ListUsers_Call {
+	return &PvzUserStore_ListUsers_Call{Call: _e.mock.On("ListUsers", ctx, role, page, limit)}
+}
+
+func (_c *PvzUserStore_ListUsers_Call) Run(run func(ctx context.Context, role models.Role, page int, limit int)) *PvzUserStore_ListUsers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.Role), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_ListUsers_Call) Return(_a0 []models.User, _a1 error) *PvzUserStore_ListUsers_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserStore_ListUsers_Call) RunAndReturn(run func(context.Context, models.Role, int, int) ([]models.User, error)) *PvzUserStore_ListUsers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListWebhookDeliveries provides a mock function with given fields: ctx, webhookID, page, limit
+func (_m *PvzUserStore) ListWebhookDeliveries(ctx context.Context, webhookID string, page int, limit int) ([]models.WebhookDelivery, error) {
+	ret := _m.Called(ctx, webhookID, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListWebhookDeliveries")
+	}
+
+	var r0 []models.WebhookDelivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) ([]models.WebhookDelivery, error)); ok {
+		return rf(ctx, webhookID, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) []models.WebhookDelivery); ok {
+		r0 = rf(ctx, webhookID, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.WebhookDelivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, int) error); ok {
+		r1 = rf(ctx, webhookID, page, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserStore_ListWebhookDeliveries_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListWebhookDeliveries'
+type PvzUserStore_ListWebhookDeliveries_Call struct {
+	*mock.Call
+}
+
+// ListWebhookDeliveries is a helper method to define mock.On call
+//   - ctx context.Context
+//   - webhookID string
+//   - page int
+//   - limit int
+func (_e *PvzUserStore_Expecter) ListWebhookDeliveries(ctx interface{}, webhookID interface{}, page interface{}, limit interface{}) *PvzUserStore_ListWebhookDeliveries_Call {
+	return &PvzUserStore_ListWebhookDeliveries_Call{Call: _e.mock.On("ListWebhookDeliveries", ctx, webhookID, page, limit)}
+}
+
+func (_c *PvzUserStore_ListWebhookDeliveries_Call) Run(run func(ctx context.Context, webhookID string, page int, limit int)) *PvzUserStore_ListWebhookDeliveries_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_ListWebhookDeliveries_Call) Return(_a0 []models.WebhookDelivery, _a1 error) *PvzUserStore_ListWebhookDeliveries_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserStore_ListWebhookDeliveries_Call) RunAndReturn(run func(context.Context, string, int, int) ([]models.WebhookDelivery, error)) *PvzUserStore_ListWebhookDeliveries_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListWebhooks provides a mock function with given fields: ctx
+func (_m *PvzUserStore) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListWebhooks")
+	}
+
+	var r0 []models.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]models.Webhook, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []models.Webhook); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Webhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserStore_ListWebhooks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListWebhooks'
+type PvzUserStore_ListWebhooks_Call struct {
+	*mock.Call
+}
+
+// ListWebhooks is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *PvzUserStore_Expecter) ListWebhooks(ctx interface{}) *PvzUserStore_ListWebhooks_Call {
+	return &PvzUserStore_ListWebhooks_Call{Call: _e.mock.On("ListWebhooks", ctx)}
+}
+
+func (_c *PvzUserStore_ListWebhooks_Call) Run(run func(ctx context.Context)) *PvzUserStore_ListWebhooks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_ListWebhooks_Call) Return(_a0 []models.Webhook, _a1 error) *PvzUserStore_ListWebhooks_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserStore_ListWebhooks_Call) RunAndReturn(run func(context.Context) ([]models.Webhook, error)) *PvzUserStore_ListWebhooks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LoginUser provides a mock function with given fields: ctx, email, password
+func (_m *PvzUserStore) LoginUser(ctx context.Context, email string, password string) (models.User, error) {
+	ret := _m.Called(ctx, email, password)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LoginUser")
+	}
+
+	var r0 models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (models.User, error)); ok {
+		return rf(ctx, email, password)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) models.User); ok {
+		r0 = rf(ctx, email, password)
+	} else {
+		r0 = ret.Get(0).(models.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, email, password)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserStore_LoginUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LoginUser'
+type PvzUserStore_LoginUser_Call struct {
+	*mock.Call
+}
+
+// LoginUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+//   - password string
+func (_e *PvzUserStore_Expecter) LoginUser(ctx interface{}, email interface{}, password interface{}) *PvzUserStore_LoginUser_Call {
+	return &PvzUserStore_LoginUser_Call{Call: _e.mock.On("LoginUser", ctx, email, password)}
+}
+
+func (_c *PvzUserStore_LoginUser_Call) Run(run func(ctx context.Context, email string, password string)) *PvzUserStore_LoginUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_LoginUser_Call) Return(_a0 models.User, _a1 error) *PvzUserStore_LoginUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserStore_LoginUser_Call) RunAndReturn(run func(context.Context, string, string) (models.User, error)) *PvzUserStore_LoginUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PVZEventsHead provides a mock function with given fields: ctx, pvzID
+func (_m *PvzUserStore) PVZEventsHead(ctx context.Context, pvzID string) (int64, error) {
+	ret := _m.Called(ctx, pvzID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PVZEventsHead")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int64, error)); ok {
+		return rf(ctx, pvzID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int64); ok {
+		r0 = rf(ctx, pvzID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, pvzID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserStore_PVZEventsHead_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PVZEventsHead'
+type PvzUserStore_PVZEventsHead_Call struct {
+	*mock.Call
+}
+
+// PVZEventsHead is a helper method to define mock.On call
+//   - ctx context.Context
+//   - pvzID string
+func (_e *PvzUserStore_Expecter) PVZEventsHead(ctx interface{}, pvzID interface{}) *PvzUserStore_PVZEventsHead_Call {
+	return &PvzUserStore_PVZEventsHead_Call{Call: _e.mock.On("PVZEventsHead", ctx, pvzID)}
+}
+
+func (_c *PvzUserStore_PVZEventsHead_Call) Run(run func(ctx context.Context, pvzID string)) *PvzUserStore_PVZEventsHead_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_PVZEventsHead_Call) Return(_a0 int64, _a1 error) *PvzUserStore_PVZEventsHead_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserStore_PVZEventsHead_Call) RunAndReturn(run func(context.Context, string) (int64, error)) *PvzUserStore_PVZEventsHead_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PVZInfoRevisions provides a mock function with given fields: ctx, start, end, by, page, limit
+func (_m *PvzUserStore) PVZInfoRevisions(ctx context.Context, start time.Time, end time.Time, by models.ReceptionDate, page int, limit int) ([]models.PVZRevision, error) {
+	ret := _m.Called(ctx, start, end, by, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PVZInfoRevisions")
+	}
+
+	var r0 []models.PVZRevision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time, models.ReceptionDate, int, int) ([]models.PVZRevision, error)); ok {
+		return rf(ctx, start, end, by, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time, models.ReceptionDate, int, int) []models.PVZRevision); ok {
+		r0 = rf(ctx, start, end, by, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.PVZRevision)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, time.Time, models.ReceptionDate, int, int) error); ok {
+		r1 = rf(ctx, start, end, by, page, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserStore_PVZInfoRevisions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PVZInfoRevisions'
+type PvzUserStore_PVZInfoRevisions_Call struct {
+	*mock.Call
+}
+
+// PVZInfoRevisions is a helper method to define mock.On call
+//   - ctx context.Context
+//   - start time.Time
+//   - end time.Time
+//   - by models.ReceptionDate
+//   - page int
+//   - limit int
+func (_e *PvzUserStore_Expecter) PVZInfoRevisions(ctx interface{}, start interface{}, end interface{}, by interface{}, page interface{}, limit interface{}) *PvzUserStore_PVZInfoRevisions_Call {
+	return &PvzUserStore_PVZInfoRevisions_Call{Call: _e.mock.On("PVZInfoRevisions", ctx, start, end, by, page, limit)}
+}
+
+func (_c *PvzUserStore_PVZInfoRevisions_Call) Run(run func(ctx context.Context, start time.Time, end time.Time, by models.ReceptionDate, page int, limit int)) *PvzUserStore_PVZInfoRevisions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Time), args[2].(time.Time), args[3].(models.ReceptionDate), args[4].(int), args[5].(int))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_PVZInfoRevisions_Call) Return(_a0 []models.PVZRevision, _a1 error) *PvzUserStore_PVZInfoRevisions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserStore_PVZInfoRevisions_Call) RunAndReturn(run func(context.Context, time.Time, time.Time, models.ReceptionDate, int, int) ([]models.PVZRevision, error)) *PvzUserStore_PVZInfoRevisions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ProvisionUser provides a mock function with given fields: ctx, ident
+func (_m *PvzUserStore) ProvisionUser(ctx context.Context, ident models.ExternalIdentity) (models.User, error) {
+	ret := _m.Called(ctx, ident)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ProvisionUser")
+	}
+
+	var r0 models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.ExternalIdentity) (models.User, error)); ok {
+		return rf(ctx, ident)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.ExternalIdentity) models.User); ok {
+		r0 = rf(ctx, ident)
+	} else {
+		r0 = ret.Get(0).(models.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.ExternalIdentity) error); ok {
+		r1 = rf(ctx, ident)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserStore_ProvisionUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ProvisionUser'
+type PvzUserStore_ProvisionUser_Call struct {
+	*mock.Call
+}
+
+// ProvisionUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ident models.ExternalIdentity
+func (_e *PvzUserStore_Expecter) ProvisionUser(ctx interface{}, ident interface{}) *PvzUserStore_ProvisionUser_Call {
+	return &PvzUserStore_ProvisionUser_Call{Call: _e.mock.On("ProvisionUser", ctx, ident)}
+}
+
+func (_c *PvzUserStore_ProvisionUser_Call) Run(run func(ctx context.Context, ident models.ExternalIdentity)) *PvzUserStore_ProvisionUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.ExternalIdentity))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_ProvisionUser_Call) Return(_a0 models.User, _a1 error) *PvzUserStore_ProvisionUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserStore_ProvisionUser_Call) RunAndReturn(run func(context.Context, models.ExternalIdentity) (models.User, error)) *PvzUserStore_ProvisionUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReceptionStats provides a mock function with given fields: ctx, f
+func (_m *PvzUserStore) ReceptionStats(ctx context.Context, f models.ReceptionStatsFilter) ([]models.ReceptionStats, error) {
+	ret := _m.Called(ctx, f)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReceptionStats")
+	}
+
+	var r0 []models.ReceptionStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.ReceptionStatsFilter) ([]models.ReceptionStats, error)); ok {
+		return rf(ctx, f)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.ReceptionStatsFilter) []models.ReceptionStats); ok {
+		r0 = rf(ctx, f)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.ReceptionStats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.ReceptionStatsFilter) error); ok {
+		r1 = rf(ctx, f)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserStore_ReceptionStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReceptionStats'
+type PvzUserStore_ReceptionStats_Call struct {
+	*mock.Call
+}
+
+// ReceptionStats is a helper method to define mock.On call
+//   - ctx context.Context
+//   - f models.ReceptionStatsFilter
+func (_e *PvzUserStore_Expecter) ReceptionStats(ctx interface{}, f interface{}) *PvzUserStore_ReceptionStats_Call {
+	return &PvzUserStore_ReceptionStats_Call{Call: _e.mock.On("ReceptionStats", ctx, f)}
+}
+
+func (_c *PvzUserStore_ReceptionStats_Call) Run(run func(ctx context.Context, f models.ReceptionStatsFilter)) *PvzUserStore_ReceptionStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.ReceptionStatsFilter))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_ReceptionStats_Call) Return(_a0 []models.ReceptionStats, _a1 error) *PvzUserStore_ReceptionStats_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserStore_ReceptionStats_Call) RunAndReturn(run func(context.Context, models.ReceptionStatsFilter) ([]models.ReceptionStats, error)) *PvzUserStore_ReceptionStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RegisterUser provides a mock function with given fields: ctx, email, password, role
+func (_m *PvzUserStore) RegisterUser(ctx context.Context, email string, password string, role models.Role) (models.User, error) {
+	ret := _m.Called(ctx, email, password, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RegisterUser")
+	}
+
+	var r0 models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, models.Role) (models.User, error)); ok {
+		return rf(ctx, email, password, role)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, models.Role) models.User); ok {
+		r0 = rf(ctx, email, password, role)
+	} else {
+		r0 = ret.Get(0).(models.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, models.Role) error); ok {
+		r1 = rf(ctx, email, password, role)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserStore_RegisterUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RegisterUser'
+type PvzUserStore_RegisterUser_Call struct {
+	*mock.Call
+}
+
+// RegisterUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+//   - password string
+//   - role models.Role
+func (_e *PvzUserStore_Expecter) RegisterUser(ctx interface{}, email interface{}, password interface{}, role interface{}) *PvzUserStore_RegisterUser_Call {
+	return &PvzUserStore_RegisterUser_Call{Call: _e.mock.On("RegisterUser", ctx, email, password, role)}
+}
+
+func (_c *PvzUserStore_RegisterUser_Call) Run(run func(ctx context.Context, email string, password string, role models.Role)) *PvzUserStore_RegisterUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(models.Role))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_RegisterUser_Call) Return(_a0 models.User, _a1 error) *PvzUserStore_RegisterUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserStore_RegisterUser_Call) RunAndReturn(run func(context.Context, string, string, models.Role) (models.User, error)) *PvzUserStore_RegisterUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReopenLastReception provides a mock function with given fields: ctx, pvzID, ifMatch
+func (_m *PvzUserStore) ReopenLastReception(ctx context.Context, pvzID string, ifMatch models.IfMatch) (models.Reception, error) {
+	ret := _m.Called(ctx, pvzID, ifMatch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReopenLastReception")
+	}
+
+	var r0 models.Reception
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.IfMatch) (models.Reception, error)); ok {
+		return rf(ctx, pvzID, ifMatch)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.IfMatch) models.Reception); ok {
+		r0 = rf(ctx, pvzID, ifMatch)
+	} else {
+		r0 = ret.Get(0).(models.Reception)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, models.IfMatch) error); ok {
+		r1 = rf(ctx, pvzID, ifMatch)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserStore_ReopenLastReception_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReopenLastReception'
+type PvzUserStore_ReopenLastReception_Call struct {
+	*mock.Call
+}
+
+// ReopenLastReception is a helper method to define mock.On call
+//   - ctx context.Context
+//   - pvzID string
+//   - ifMatch models.IfMatch
+func (_e *PvzUserStore_Expecter) ReopenLastReception(ctx interface{}, pvzID interface{}, ifMatch interface{}) *PvzUserStore_ReopenLastReception_Call {
+	return &PvzUserStore_ReopenLastReception_Call{Call: _e.mock.On("ReopenLastReception", ctx, pvzID, ifMatch)}
+}
+
+func (_c *PvzUserStore_ReopenLastReception_Call) Run(run func(ctx context.Context, pvzID string, ifMatch models.IfMatch)) *PvzUserStore_ReopenLastReception_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(models.IfMatch))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_ReopenLastReception_Call) Return(_a0 models.Reception, _a1 error) *PvzUserStore_ReopenLastReception_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserStore_ReopenLastReception_Call) RunAndReturn(run func(context.Context, string, models.IfMatch) (models.Reception, error)) *PvzUserStore_ReopenLastReception_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResetPassword provides a mock function with given fields: ctx, tokenHash, newPasswordHash
+func (_m *PvzUserStore) ResetPassword(ctx context.Context, tokenHash string, newPasswordHash string) error {
+	ret := _m.Called(ctx, tokenHash, newPasswordHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResetPassword")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, tokenHash, newPasswordHash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PvzUserStore_ResetPassword_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResetPassword'
+type PvzUserStore_ResetPassword_Call struct {
+	*mock.Call
+}
+
+// ResetPassword is a helper method to define mock.On call
+//   - ctx context.Context
+//   - tokenHash string
+//   - newPasswordHash string
+func (_e *PvzUserStore_Expecter) ResetPassword(ctx interface{}, tokenHash interface{}, newPasswordHash interface{}) *PvzUserStore_ResetPassword_Call {
+	return &PvzUserStore_ResetPassword_Call{Call: _e.mock.On("ResetPassword", ctx, tokenHash, newPasswordHash)}
+}
+
+func (_c *PvzUserStore_ResetPassword_Call) Run(run func(ctx context.Context, tokenHash string, newPasswordHash string)) *PvzUserStore_ResetPassword_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_ResetPassword_Call) Return(_a0 error) *PvzUserStore_ResetPassword_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *PvzUserStore_ResetPassword_Call) RunAndReturn(run func(context.Context, string, string) error) *PvzUserStore_ResetPassword_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UnassignPVZ provides a mock function with given fields: ctx, userID, pvzID
+func (_m *PvzUserStore) UnassignPVZ(ctx context.Context, userID string, pvzID string) error {
+	ret := _m.Called(ctx, userID, pvzID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UnassignPVZ")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, userID, pvzID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PvzUserStore_UnassignPVZ_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UnassignPVZ'
+type PvzUserStore_UnassignPVZ_Call struct {
+	*mock.Call
+}
+
+// UnassignPVZ is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - pvzID string
+func (_e *PvzUserStore_Expecter) UnassignPVZ(ctx interface{}, userID interface{}, pvzID interface{}) *PvzUserStore_UnassignPVZ_Call {
+	return &PvzUserStore_UnassignPVZ_Call{Call: _e.mock.On("UnassignPVZ", ctx, userID, pvzID)}
+}
+
+func (_c *PvzUserStore_UnassignPVZ_Call) Run(run func(ctx context.Context, userID string, pvzID string)) *PvzUserStore_UnassignPVZ_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_UnassignPVZ_Call) Return(_a0 error) *PvzUserStore_UnassignPVZ_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *PvzUserStore_UnassignPVZ_Call) RunAndReturn(run func(context.Context, string, string) error) *PvzUserStore_UnassignPVZ_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdatePVZ provides a mock function with given fields: ctx, pvzID, city, ifMatch
+func (_m *PvzUserStore) UpdatePVZ(ctx context.Context, pvzID string, city models.City, ifMatch models.IfMatch) (models.PVZ, error) {
+	ret := _m.Called(ctx, pvzID, city, ifMatch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePVZ")
+	}
+
+	var r0 models.PVZ
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.City, models.IfMatch) (models.PVZ, error)); ok {
+		return rf(ctx, pvzID, city, ifMatch)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.City, models.IfMatch) models.PVZ); ok {
+		r0 = rf(ctx, pvzID, city, ifMatch)
+	} else {
+		r0 = ret.Get(0).(models.PVZ)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, models.City, models.IfMatch) error); ok {
+		r1 = rf(ctx, pvzID, city, ifMatch)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserStore_UpdatePVZ_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdatePVZ'
+type PvzUserStore_UpdatePVZ_Call struct {
+	*mock.Call
+}
+
+// UpdatePVZ is a helper method to define mock.On call
+//   - ctx context.Context
+//   - pvzID string
+//   - city models.City
+//   - ifMatch models.IfMatch
+func (_e *PvzUserStore_Expecter) UpdatePVZ(ctx interface{}, pvzID interface{}, city interface{}, ifMatch interface{}) *PvzUserStore_UpdatePVZ_Call {
+	return &PvzUserStore_UpdatePVZ_Call{Call: _e.mock.On("UpdatePVZ", ctx, pvzID, city, ifMatch)}
+}
+
+func (_c *PvzUserStore_UpdatePVZ_Call) Run(run func(ctx context.Context, pvzID string, city models.City, ifMatch models.IfMatch)) *PvzUserStore_UpdatePVZ_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(models.City), args[3].(models.IfMatch))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_UpdatePVZ_Call) Return(_a0 models.PVZ, _a1 error) *PvzUserStore_UpdatePVZ_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PvzUserStore_UpdatePVZ_Call) RunAndReturn(run func(context.Context, string, models.City, models.IfMatch) (models.PVZ, error)) *PvzUserStore_UpdatePVZ_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateUser provides a mock function with given fields: ctx, userID, upd
+func (_m *PvzUserStore) UpdateUser(ctx context.Context, userID string, upd models.UserUpdate) (models.User, error) {
+	ret := _m.Called(ctx, userID, upd)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateUser")
+	}
+
+	var r0 models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.UserUpdate) (models.User, error)); ok {
+		return rf(ctx, userID, upd)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.UserUpdate) models.User); ok {
+		r0 = rf(ctx, userID, upd)
+	} else {
+		r0 = ret.Get(0).(models.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, models.UserUpdate) error); ok {
+		r1 = rf(ctx, userID, upd)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PvzUserStore_UpdateUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateUser'
+type PvzUserStore_UpdateUser_Call struct {
+	*mock.Call
+}
+
+// UpdateUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - upd models.UserUpdate
+func (_e *PvzUserStore_Expecter) UpdateUser(ctx interface{}, userID interface{}, upd interface{}) *PvzUserStore_UpdateUser_Call {
+	return &PvzUserStore_UpdateUser_Call{Call: _e.mock.On("UpdateUser", ctx, userID, upd)}
+}
+
+func (_c *PvzUserStore_UpdateUser_Call) Run(run func(ctx context.Context, userID string, upd models.UserUpdate)) *PvzUserStore_UpdateUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(models.UserUpdate))
+	})
+	return _c
+}
+
+func (_c *PvzUserStore_UpdateUser_Call) Return(_a0 models.User, _a1 error) *PvzUserStore_UpdateUser_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *PvzUserStore_RegisterUser_Call) RunAndReturn(run func(string, string, models.Role) (models.User, error)) *PvzUserStore_RegisterUser_Call {
+func (_c *PvzUserStore_UpdateUser_Call) RunAndReturn(run func(context.Context, string, models.UserUpdate) (models.User, error)) *PvzUserStore_UpdateUser_Call {
 	_c.Call.Return(run)
 	return _c
 }
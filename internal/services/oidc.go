@@ -0,0 +1,40 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"pvz/internal/models"
+	"pvz/internal/tracing"
+	"pvz/pkg/utils"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var ErrOIDCDisabled = errors.New("oidc login is not configured")
+
+// IdentityVerifier checks a token of an external identity provider.
+type IdentityVerifier interface {
+	Verify(ctx context.Context, rawToken string) (models.ExternalIdentity, error)
+}
+
+// OIDCLogin exchanges a token of the identity provider for a token of the service,
+// provisioning the user on first login.
+func (s *Service) OIDCLogin(ctx context.Context, rawToken string) (_ models.Token, err error) {
+	ctx, span := tracer.Start(ctx, "Service.OIDCLogin")
+	defer tracing.End(span, &err)
+
+	if s.Identities == nil {
+		return "", ErrOIDCDisabled
+	}
+	ident, err := s.Identities.Verify(ctx, rawToken)
+	if err != nil {
+		return "", err
+	}
+	span.SetAttributes(attribute.String("user.role", string(ident.Role)))
+
+	user, err := s.Repo.ProvisionUser(ctx, ident)
+	if err != nil {
+		return "", err
+	}
+	return utils.GetJWToken(s.Tokens, user.Role, user.ID)
+}
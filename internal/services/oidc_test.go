@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"pvz/internal/models"
+	"pvz/pkg/utils"
+)
+
+type fakeVerifier struct {
+	ident models.ExternalIdentity
+	err   error
+}
+
+func (f fakeVerifier) Verify(context.Context, string) (models.ExternalIdentity, error) {
+	return f.ident, f.err
+}
+
+func TestServiceOIDCLogin(t *testing.T) {
+	repo, svc := newSvc()
+
+	_, err := svc.OIDCLogin(context.Background(), "raw")
+	require.ErrorIs(t, err, ErrOIDCDisabled)
+
+	ident := models.ExternalIdentity{Issuer: "https://sso", Subject: "s1", Email: "a@b.com", Role: models.Moderator}
+	svc.Identities = fakeVerifier{ident: ident}
+	repo.EXPECT().ProvisionUser(mock.Anything, ident).
+		Return(models.User{ID: "u1", Email: "a@b.com", Role: models.Moderator}, nil).Once()
+
+	token, err := svc.OIDCLogin(context.Background(), "raw")
+	require.NoError(t, err)
+	claims := new(utils.Claims)
+	_, _, err = jwt.NewParser().ParseUnverified(string(token), claims)
+	require.NoError(t, err)
+	require.Equal(t, "u1", claims.Subject)
+	require.Equal(t, models.Moderator, claims.Role)
+
+	verifyErr := errors.New("bad token")
+	svc.Identities = fakeVerifier{err: verifyErr}
+	_, err = svc.OIDCLogin(context.Background(), "raw")
+	require.ErrorIs(t, err, verifyErr)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"pvz/internal/models"
+	"pvz/internal/password"
+	"pvz/internal/tracing"
+	"pvz/pkg/utils"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var ErrPasswordResetDisabled = errors.New("password reset is not available")
+
+func (s *Service) ChangePassword(ctx context.Context, userID, oldPassword, newPassword string) (err error) {
+	ctx, span := tracer.Start(ctx, "Service.ChangePassword", trace.WithAttributes(attribute.String("user.id", userID)))
+	defer tracing.End(span, &err)
+
+	if oldPassword == newPassword {
+		return fmt.Errorf("%w: new password must differ from the current one", password.ErrWeakPassword)
+	}
+	if err := s.Passwords.Check(newPassword); err != nil {
+		return err
+	}
+	hash, err := utils.GenerateHashPassword(newPassword)
+	if err != nil {
+		return models.Wrap("generate hash", err)
+	}
+	return s.Repo.ChangePassword(ctx, userID, oldPassword, hash)
+}
+
+// RequestPasswordReset sends a one-time reset token to the user. An unknown email
+// is not reported, so the endpoint can't be used to find registered accounts.
+// Without a Notifier there is no way to deliver the token and reset is disabled.
+func (s *Service) RequestPasswordReset(ctx context.Context, email string) (err error) {
+	ctx, span := tracer.Start(ctx, "Service.RequestPasswordReset")
+	defer tracing.End(span, &err)
+
+	if s.Notifier == nil {
+		return ErrPasswordResetDisabled
+	}
+	token, err := newSecretToken()
+	if err != nil {
+		return models.Wrap("generate reset token", err)
+	}
+	expiresAt := time.Now().UTC().Add(s.ResetTTL)
+
+	found, err := s.Repo.CreatePasswordReset(ctx, email, hashToken(token), expiresAt)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return nil
+	}
+	if err := s.Notifier.PasswordReset(ctx, email, token, expiresAt); err != nil {
+		return models.Wrap("send reset token", err)
+	}
+	return nil
+}
+
+func (s *Service) ResetPassword(ctx context.Context, token, newPassword string) (err error) {
+	ctx, span := tracer.Start(ctx, "Service.ResetPassword")
+	defer tracing.End(span, &err)
+
+	if err := s.Passwords.Check(newPassword); err != nil {
+		return err
+	}
+	hash, err := utils.GenerateHashPassword(newPassword)
+	if err != nil {
+		return models.Wrap("generate hash", err)
+	}
+	return s.Repo.ResetPassword(ctx, hashToken(token), hash)
+}
+
+func newSecretToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// only the hash of reset tokens and API keys is stored, a database leak doesn't expose usable secrets
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"pvz/internal/password"
+)
+
+type fakeNotifier struct {
+	email string
+	token string
+}
+
+func (n *fakeNotifier) PasswordReset(_ context.Context, email, token string, _ time.Time) error {
+	n.email, n.token = email, token
+	return nil
+}
+
+func TestServiceRegisterUserWeakPassword(t *testing.T) {
+	repo, svc := newSvc()
+
+	_, err := svc.RegisterUser(context.Background(), "user@example.com", "1", "employee")
+	require.ErrorIs(t, err, password.ErrWeakPassword)
+	repo.AssertNotCalled(t, "RegisterUser")
+}
+
+func TestServiceChangePassword(t *testing.T) {
+	repo, svc := newSvc()
+
+	err := svc.ChangePassword(context.Background(), "u1", "Reception42", "Reception42")
+	require.ErrorIs(t, err, password.ErrWeakPassword)
+
+	err = svc.ChangePassword(context.Background(), "u1", "Reception42", "qwerty")
+	require.ErrorIs(t, err, password.ErrWeakPassword)
+
+	repo.EXPECT().
+		ChangePassword(mock.Anything, "u1", "Reception42", mock.AnythingOfType("string")).
+		Return(nil).Once()
+	require.NoError(t, svc.ChangePassword(context.Background(), "u1", "Reception42", "Delivery-Point7"))
+	repo.AssertExpectations(t)
+}
+
+func TestServiceRequestPasswordReset(t *testing.T) {
+	repo, svc := newSvc()
+	// without a notifier the token can't be delivered, nothing is stored
+	require.ErrorIs(t, svc.RequestPasswordReset(context.Background(), "a@b.com"), ErrPasswordResetDisabled)
+
+	n := &fakeNotifier{}
+	svc.Notifier = n
+
+	var storedHash string
+	repo.EXPECT().
+		CreatePasswordReset(mock.Anything, "a@b.com", mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).
+		Run(func(_ context.Context, _ string, tokenHash string, _ time.Time) { storedHash = tokenHash }).
+		Return(true, nil).Once()
+	require.NoError(t, svc.RequestPasswordReset(context.Background(), "a@b.com"))
+	require.Equal(t, "a@b.com", n.email)
+	require.NotEmpty(t, n.token)
+	require.NotEqual(t, n.token, storedHash)
+	require.Equal(t, hashToken(n.token), storedHash)
+
+	n.token = ""
+	repo.EXPECT().
+		CreatePasswordReset(mock.Anything, "x@b.com", mock.Anything, mock.Anything).
+		Return(false, nil).Once()
+	require.NoError(t, svc.RequestPasswordReset(context.Background(), "x@b.com"))
+	require.Empty(t, n.token)
+
+	repo.EXPECT().
+		CreatePasswordReset(mock.Anything, "y@b.com", mock.Anything, mock.Anything).
+		Return(false, errors.New("db fail")).Once()
+	require.Error(t, svc.RequestPasswordReset(context.Background(), "y@b.com"))
+	repo.AssertExpectations(t)
+}
+
+func TestServiceResetPassword(t *testing.T) {
+	repo, svc := newSvc()
+
+	require.ErrorIs(t, svc.ResetPassword(context.Background(), "tok", "short"), password.ErrWeakPassword)
+
+	repo.EXPECT().
+		ResetPassword(mock.Anything, hashToken("tok"), mock.AnythingOfType("string")).
+		Return(nil).Once()
+	require.NoError(t, svc.ResetPassword(context.Background(), "tok", "Delivery-Point7"))
+	repo.AssertExpectations(t)
+}
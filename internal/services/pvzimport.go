@@ -0,0 +1,76 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"pvz/internal/models"
+	"pvz/internal/tracing"
+	"slices"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var ErrNoImportRows = errors.New("import file has no rows")
+
+// ImportPVZs checks parsed rows for duplicates in the file and in the database and creates them
+// all at once. Nothing is created on a dry run or when any row, including rowErrs, is invalid.
+func (s *Service) ImportPVZs(ctx context.Context, rows []models.PVZImportRow, rowErrs []models.PVZImportError, dryRun bool) (_ models.PVZImportReport, err error) {
+	ctx, span := tracer.Start(ctx, "Service.ImportPVZs", trace.WithAttributes(
+		attribute.Int("import.rows", len(rows)+len(rowErrs)),
+		attribute.Bool("import.dry_run", dryRun),
+	))
+	defer tracing.End(span, &err)
+
+	if len(rows)+len(rowErrs) == 0 {
+		return models.PVZImportReport{}, ErrNoImportRows
+	}
+	report := models.PVZImportReport{
+		DryRun:  dryRun,
+		Rows:    len(rows) + len(rowErrs),
+		Errors:  slices.Clone(rowErrs),
+		Created: []models.PVZ{},
+	}
+	if report.Errors == nil {
+		report.Errors = []models.PVZImportError{}
+	}
+
+	type key struct {
+		city    models.City
+		address string
+	}
+	addresses := make([]string, 0, len(rows))
+	for _, row := range rows {
+		addresses = append(addresses, row.Address)
+	}
+	existing, err := s.Repo.FindPVZsByAddress(ctx, addresses)
+	if err != nil {
+		return models.PVZImportReport{}, err
+	}
+	seen := make(map[key]int, len(rows)+len(existing))
+	for _, pvz := range existing {
+		seen[key{pvz.City, pvz.Address}] = 0
+	}
+	for _, row := range rows {
+		line, dup := seen[key{row.City, row.Address}]
+		switch {
+		case dup && line == 0:
+			report.Errors = append(report.Errors, models.PVZImportError{Line: row.Line, Error: "pvz with this city and address already exists"})
+		case dup:
+			report.Errors = append(report.Errors, models.PVZImportError{Line: row.Line, Error: fmt.Sprintf("duplicates line %d", line)})
+		default:
+			seen[key{row.City, row.Address}] = row.Line
+		}
+	}
+	slices.SortStableFunc(report.Errors, func(a, b models.PVZImportError) int { return cmp.Compare(a.Line, b.Line) })
+
+	if len(report.Errors) > 0 || dryRun {
+		return report, nil
+	}
+	if report.Created, err = s.Repo.ImportPVZs(ctx, rows); err != nil {
+		return models.PVZImportReport{}, err
+	}
+	return report, nil
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"pvz/internal/models"
+)
+
+func TestServiceImportPVZs(t *testing.T) {
+	repo, svc := newSvc()
+	rows := []models.PVZImportRow{
+		{Line: 2, City: models.Moscow, Address: "Тверская, 1", Latitude: 55.75, Longitude: 37.61},
+		{Line: 3, City: models.Kazan, Address: "Баумана, 1", Latitude: 55.79, Longitude: 49.12},
+	}
+	addresses := []string{"Тверская, 1", "Баумана, 1"}
+
+	// the same address in another city isn't a duplicate
+	repo.EXPECT().FindPVZsByAddress(mock.Anything, addresses).
+		Return([]models.PVZ{{ID: "p0", City: models.SaintP, Address: "Тверская, 1"}}, nil).Twice()
+	report, err := svc.ImportPVZs(context.Background(), rows, nil, true)
+	require.NoError(t, err)
+	require.Equal(t, models.PVZImportReport{DryRun: true, Rows: 2, Errors: []models.PVZImportError{}, Created: []models.PVZ{}}, report)
+
+	created := []models.PVZ{{ID: "p1", City: models.Moscow}, {ID: "p2", City: models.Kazan}}
+	repo.EXPECT().ImportPVZs(mock.Anything, rows).Return(created, nil).Once()
+	report, err = svc.ImportPVZs(context.Background(), rows, nil, false)
+	require.NoError(t, err)
+	require.Equal(t, created, report.Created)
+	require.Empty(t, report.Errors)
+	repo.AssertExpectations(t)
+}
+
+func TestServiceImportPVZsErrors(t *testing.T) {
+	repo, svc := newSvc()
+	rows := []models.PVZImportRow{
+		{Line: 2, City: models.Moscow, Address: "Тверская, 1"},
+		{Line: 4, City: models.Moscow, Address: "Тверская, 1"},
+		{Line: 5, City: models.Kazan, Address: "Баумана, 1"},
+	}
+	rowErrs := []models.PVZImportError{{Line: 3, Error: "City: Омск does not validate as city"}}
+
+	repo.EXPECT().FindPVZsByAddress(mock.Anything, mock.Anything).
+		Return([]models.PVZ{{ID: "p0", City: models.Kazan, Address: "Баумана, 1"}}, nil).Once()
+	report, err := svc.ImportPVZs(context.Background(), rows, rowErrs, false)
+	require.NoError(t, err)
+	require.Equal(t, 4, report.Rows)
+	require.Equal(t, []models.PVZImportError{
+		rowErrs[0],
+		{Line: 4, Error: "duplicates line 2"},
+		{Line: 5, Error: "pvz with this city and address already exists"},
+	}, report.Errors)
+	require.Empty(t, report.Created)
+
+	_, err = svc.ImportPVZs(context.Background(), nil, nil, false)
+	require.ErrorIs(t, err, ErrNoImportRows)
+
+	dbErr := errors.New("db is down")
+	repo.EXPECT().FindPVZsByAddress(mock.Anything, mock.Anything).Return(nil, dbErr).Once()
+	_, err = svc.ImportPVZs(context.Background(), rows[:1], nil, false)
+	require.ErrorIs(t, err, dbErr)
+	repo.AssertExpectations(t)
+}
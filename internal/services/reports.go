@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"pvz/internal/models"
+	"pvz/internal/tracing"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	ErrInvalidDate    = errors.New("date must be in RFC3339 format")
+	ErrInvalidRange   = errors.New("startDate must not be after endDate")
+	ErrInvalidGroupBy = errors.New("groupBy must be a list of pvz, city, productType and at most one of day, week, month")
+)
+
+// parseDateRange parses optional RFC3339 bounds, the range is open to the past and ends now by default.
+func parseDateRange(start, end string) (time.Time, time.Time, error) {
+	startDate, endDate := time.Time{}, time.Now().UTC()
+	var err error
+	if start != "" {
+		if startDate, err = time.Parse(time.RFC3339, start); err != nil {
+			return time.Time{}, time.Time{}, models.Wrap("invalid startDate", ErrInvalidDate)
+		}
+	}
+	if end != "" {
+		if endDate, err = time.Parse(time.RFC3339, end); err != nil {
+			return time.Time{}, time.Time{}, models.Wrap("invalid endDate", ErrInvalidDate)
+		}
+	}
+	return startDate, endDate, nil
+}
+
+// IntakeReport aggregates products and receptions opened in the range by the groupBy dimensions,
+// without any it returns a single row of totals.
+func (s *Service) IntakeReport(ctx context.Context, start, end string, groupBy []string) (_ models.IntakeReport, err error) {
+	ctx, span := tracer.Start(ctx, "Service.IntakeReport", trace.WithAttributes(attribute.StringSlice("report.group_by", groupBy)))
+	defer tracing.End(span, &err)
+
+	startDate, endDate, err := parseDateRange(start, end)
+	if err != nil {
+		return models.IntakeReport{}, err
+	}
+	if startDate.After(endDate) {
+		return models.IntakeReport{}, ErrInvalidRange
+	}
+	periods := 0
+	for i, dim := range groupBy {
+		if !slices.Contains(models.IntakeDimensions, dim) || slices.Contains(groupBy[:i], dim) {
+			return models.IntakeReport{}, ErrInvalidGroupBy
+		}
+		if slices.Contains(models.IntakePeriods, dim) {
+			periods++
+		}
+	}
+	if periods > 1 {
+		return models.IntakeReport{}, ErrInvalidGroupBy
+	}
+	if groupBy == nil {
+		groupBy = []string{}
+	}
+
+	rows, err := s.Repo.IntakeReport(ctx, models.IntakeReportFilter{Start: startDate, End: endDate, GroupBy: groupBy})
+	if err != nil {
+		return models.IntakeReport{}, err
+	}
+	return models.IntakeReport{StartDate: startDate, EndDate: endDate, GroupBy: groupBy, Rows: rows}, nil
+}
+
+// ReceptionStats computes reception metrics per pvz over receptions opened in the range,
+// of all pvz when pvzID is empty.
+func (s *Service) ReceptionStats(ctx context.Context, start, end, pvzID string) (_ models.ReceptionStatsReport, err error) {
+	ctx, span := tracer.Start(ctx, "Service.ReceptionStats", trace.WithAttributes(attribute.String("pvz.id", pvzID)))
+	defer tracing.End(span, &err)
+
+	startDate, endDate, err := parseDateRange(start, end)
+	if err != nil {
+		return models.ReceptionStatsReport{}, err
+	}
+	if startDate.After(endDate) {
+		return models.ReceptionStatsReport{}, ErrInvalidRange
+	}
+
+	rows, err := s.Repo.ReceptionStats(ctx, models.ReceptionStatsFilter{Start: startDate, End: endDate, PvzID: pvzID})
+	if err != nil {
+		return models.ReceptionStatsReport{}, err
+	}
+	return models.ReceptionStatsReport{StartDate: startDate, EndDate: endDate, Rows: rows}, nil
+}
+
+// ExportReceptions passes receptions opened in the range to fn one by one, the range is as in GetPVZInfo.
+func (s *Service) ExportReceptions(ctx context.Context, start, end string, fn func(models.ExportReception) error) (err error) {
+	ctx, span := tracer.Start(ctx, "Service.ExportReceptions")
+	defer tracing.End(span, &err)
+
+	startDate, endDate, err := parseDateRange(start, end)
+	if err != nil {
+		return err
+	}
+	return s.Repo.ExportReceptions(ctx, startDate, endDate, fn)
+}
+
+// ExportProducts passes products of receptions opened in the range to fn one by one.
+func (s *Service) ExportProducts(ctx context.Context, start, end string, fn func(models.ExportProduct) error) (err error) {
+	ctx, span := tracer.Start(ctx, "Service.ExportProducts")
+	defer tracing.End(span, &err)
+
+	startDate, endDate, err := parseDateRange(start, end)
+	if err != nil {
+		return err
+	}
+	return s.Repo.ExportProducts(ctx, startDate, endDate, fn)
+}
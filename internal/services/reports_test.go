@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"pvz/internal/models"
+)
+
+func TestServiceIntakeReport(t *testing.T) {
+	repo, svc := newSvc()
+	start, end := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	filter := models.IntakeReportFilter{Start: start, End: end, GroupBy: []string{models.GroupByCity, models.GroupByMonth}}
+	repo.EXPECT().IntakeReport(mock.Anything, filter).Return([]models.IntakeRow{{City: models.Kazan, Products: 3}}, nil).Once()
+	report, err := svc.IntakeReport(context.Background(), "2025-01-01T00:00:00Z", "2025-02-01T00:00:00Z", filter.GroupBy)
+	require.NoError(t, err)
+	require.Equal(t, start, report.StartDate)
+	require.Len(t, report.Rows, 1)
+
+	repo.EXPECT().IntakeReport(mock.Anything, mock.MatchedBy(func(f models.IntakeReportFilter) bool {
+		return f.Start.IsZero() && len(f.GroupBy) == 0
+	})).Return([]models.IntakeRow{{Products: 10}}, nil).Once()
+	report, err = svc.IntakeReport(context.Background(), "", "", nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{}, report.GroupBy)
+	repo.AssertExpectations(t)
+}
+
+func TestServiceIntakeReportErrors(t *testing.T) {
+	_, svc := newSvc()
+
+	for _, groupBy := range [][]string{
+		{"year"},
+		{models.GroupByCity, models.GroupByCity},
+		{models.GroupByDay, models.GroupByWeek},
+	} {
+		_, err := svc.IntakeReport(context.Background(), "", "", groupBy)
+		require.ErrorIs(t, err, ErrInvalidGroupBy, groupBy)
+	}
+	_, err := svc.IntakeReport(context.Background(), "yesterday", "", nil)
+	require.ErrorIs(t, err, ErrInvalidDate)
+	_, err = svc.IntakeReport(context.Background(), "2025-02-01T00:00:00Z", "2025-01-01T00:00:00Z", nil)
+	require.ErrorIs(t, err, ErrInvalidRange)
+}
+
+func TestServiceReceptionStats(t *testing.T) {
+	repo, svc := newSvc()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	repo.EXPECT().ReceptionStats(mock.Anything, mock.MatchedBy(func(f models.ReceptionStatsFilter) bool {
+		return f.Start.Equal(start) && f.PvzID == "p1" && !f.End.Before(start)
+	})).Return([]models.ReceptionStats{{PvzID: "p1", Receptions: 2}}, nil).Once()
+	report, err := svc.ReceptionStats(context.Background(), "2025-01-01T00:00:00Z", "", "p1")
+	require.NoError(t, err)
+	require.Equal(t, start, report.StartDate)
+	require.Len(t, report.Rows, 1)
+
+	_, err = svc.ReceptionStats(context.Background(), "2025-02-01T00:00:00Z", "2025-01-01T00:00:00Z", "")
+	require.ErrorIs(t, err, ErrInvalidRange)
+	_, err = svc.ReceptionStats(context.Background(), "", "soon", "")
+	require.ErrorIs(t, err, ErrInvalidDate)
+	repo.AssertExpectations(t)
+}
+
+func TestServiceExport(t *testing.T) {
+	repo, svc := newSvc()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	repo.EXPECT().ExportReceptions(mock.Anything, start, mock.AnythingOfType("time.Time"), mock.Anything).
+		RunAndReturn(func(_ context.Context, _, _ time.Time, fn func(models.ExportReception) error) error {
+			return fn(models.ExportReception{City: models.Kazan})
+		}).Once()
+	var got []models.ExportReception
+	err := svc.ExportReceptions(context.Background(), "2025-01-01T00:00:00Z", "", func(r models.ExportReception) error {
+		got = append(got, r)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+
+	err = svc.ExportProducts(context.Background(), "", "tomorrow", func(models.ExportProduct) error { return nil })
+	require.ErrorIs(t, err, ErrInvalidDate)
+	repo.AssertExpectations(t)
+}
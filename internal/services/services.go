@@ -1,46 +1,120 @@
 package services
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"pvz/internal/auth"
 	"pvz/internal/models"
+	"pvz/internal/notify"
+	"pvz/internal/password"
+	"pvz/internal/tracing"
 	"pvz/pkg/utils"
 	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("pvz/internal/services")
+
 //go:generate mockery --name=PvzUserStore --dir=. --output=./mocks --outpkg=mocks --with-expecter
 type PvzUserStore interface {
 	PvzStore
 	UserStore
+	WebhookStore
+	ReportStore
 }
 
 type PvzStore interface {
-	CreatePVZ(city models.City) (models.PVZ, error)
-	CreateReception(pvzID string) (models.Reception, error)
-	CreateProduct(pvzID string, prType models.ProductType) (models.Product, error)
-	CloseLastReception(pvzID string) (models.Reception, error)
-	DeleteLastProduct(pvzID string) error
-	GetPVZInfo(start, end time.Time, page, limit int) ([]models.PVZInfo, error)
+	CreatePVZ(ctx context.Context, city models.City) (models.PVZ, error)
+	FindPVZsByAddress(ctx context.Context, addresses []string) ([]models.PVZ, error)
+	ImportPVZs(ctx context.Context, rows []models.PVZImportRow) ([]models.PVZ, error)
+	CreateReception(ctx context.Context, pvzID string) (models.Reception, error)
+	CreateProduct(ctx context.Context, pvzID string, prType models.ProductType) (models.Product, error)
+	UpdatePVZ(ctx context.Context, pvzID string, city models.City, ifMatch models.IfMatch) (models.PVZ, error)
+	CloseLastReception(ctx context.Context, closedBy, pvzID string, ifMatch models.IfMatch) (models.Reception, error)
+	ReopenLastReception(ctx context.Context, pvzID string, ifMatch models.IfMatch) (models.Reception, error)
+	DeleteLastProduct(ctx context.Context, pvzID string) (models.Product, error)
+	GetPVZInfo(ctx context.Context, start, end time.Time, by models.ReceptionDate, page, limit int) ([]models.PVZInfo, error)
+	PVZInfoRevisions(ctx context.Context, start, end time.Time, by models.ReceptionDate, page, limit int) ([]models.PVZRevision, error)
+	PVZEventsHead(ctx context.Context, pvzID string) (int64, error)
+	ListPVZEvents(ctx context.Context, pvzID string, afterSeq int64, limit int) ([]models.Event, error)
 }
 type UserStore interface {
-	RegisterUser(email, password string, role models.Role) (models.User, error)
-	LoginUser(email, password string) (models.Token, error)
+	RegisterUser(ctx context.Context, email, password string, role models.Role) (models.User, error)
+	LoginUser(ctx context.Context, email, password string) (models.User, error)
+	EnsureUser(ctx context.Context, user models.User) error
+	ProvisionUser(ctx context.Context, ident models.ExternalIdentity) (models.User, error)
+	ListUsers(ctx context.Context, role models.Role, page, limit int) ([]models.User, error)
+	GetUser(ctx context.Context, userID string) (models.User, error)
+	UpdateUser(ctx context.Context, userID string, upd models.UserUpdate) (models.User, error)
+	DeleteUser(ctx context.Context, userID string) error
+	GetUserPVZs(ctx context.Context, userID string) ([]models.PVZ, error)
+	IsPVZAssigned(ctx context.Context, userID, pvzID string) (bool, error)
+	AssignPVZ(ctx context.Context, userID, pvzID string) error
+	UnassignPVZ(ctx context.Context, userID, pvzID string) error
+	ChangePassword(ctx context.Context, userID, oldPassword, newPasswordHash string) error
+	CreatePasswordReset(ctx context.Context, email, tokenHash string, expiresAt time.Time) (bool, error)
+	ResetPassword(ctx context.Context, tokenHash, newPasswordHash string) error
+	CreateAPIKey(ctx context.Context, key models.APIKey, keyHash string) error
+	ListAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	DeleteAPIKey(ctx context.Context, keyID string) error
+	AuthenticateAPIKey(ctx context.Context, keyHash string) (models.APIKey, models.Role, error)
+}
+
+type WebhookStore interface {
+	CreateWebhook(ctx context.Context, hook models.Webhook) error
+	ListWebhooks(ctx context.Context) ([]models.Webhook, error)
+	DeleteWebhook(ctx context.Context, webhookID string) error
+	ListWebhookDeliveries(ctx context.Context, webhookID string, page, limit int) ([]models.WebhookDelivery, error)
+}
+
+type ReportStore interface {
+	IntakeReport(ctx context.Context, f models.IntakeReportFilter) ([]models.IntakeRow, error)
+	ReceptionStats(ctx context.Context, f models.ReceptionStatsFilter) ([]models.ReceptionStats, error)
+	ExportReceptions(ctx context.Context, start, end time.Time, fn func(models.ExportReception) error) error
+	ExportProducts(ctx context.Context, start, end time.Time, fn func(models.ExportProduct) error) error
 }
 
 type Service struct {
-	Repo PvzUserStore
+	Repo      PvzUserStore
+	Passwords password.Policy
+	Notifier  notify.Notifier
+	ResetTTL  time.Duration
+	Tokens    utils.TokenOptions
+	Roles     *auth.Roles
+	// Identities verifies external provider tokens, nil disables OIDC login
+	Identities IdentityVerifier
+	// AllowPrivateWebhooks accepts webhook urls on localhost and private addresses
+	AllowPrivateWebhooks bool
 }
 
 func NewService(repo PvzUserStore) *Service {
-	return &Service{Repo: repo}
+	return &Service{
+		Repo:      repo,
+		Passwords: password.DefaultPolicy(),
+		ResetTTL:  30 * time.Minute,
+		Tokens:    utils.TokenOptions{Issuer: "pvz", Audience: "pvz", TTL: 24 * time.Hour},
+		Roles:     auth.DefaultRoles(),
+	}
 }
 
-func (s *Service) RegisterUser(email, password string, role models.Role) (models.User, error) {
+func (s *Service) RegisterUser(ctx context.Context, email, pass string, role models.Role) (_ models.User, err error) {
+	ctx, span := tracer.Start(ctx, "Service.RegisterUser", trace.WithAttributes(attribute.String("user.role", string(role))))
+	defer tracing.End(span, &err)
 
-	hash, err := utils.GenerateHashPassword(password)
+	if err := s.Passwords.Check(pass); err != nil {
+		return models.User{}, err
+	}
+	hash, err := utils.GenerateHashPassword(pass)
 	if err != nil {
 		return models.User{}, models.Wrap("generate hash", err)
 	}
 
-	user, err := s.Repo.RegisterUser(email, hash, role)
+	user, err := s.Repo.RegisterUser(ctx, email, hash, role)
 	if err != nil {
 		return models.User{}, models.Wrap("failed to register user", err)
 
@@ -48,53 +122,147 @@ func (s *Service) RegisterUser(email, password string, role models.Role) (models
 	return user, nil
 }
 
-func (s *Service) LoginUser(email, password string) (models.Token, error) {
-	return s.Repo.LoginUser(email, password)
+// DummyLogin returns a token of the seeded dummy user with the role, creating the
+// user if needed, so user-scoped features work with dummy tokens.
+func (s *Service) DummyLogin(ctx context.Context, role models.Role) (_ models.Token, err error) {
+	ctx, span := tracer.Start(ctx, "Service.DummyLogin", trace.WithAttributes(attribute.String("user.role", string(role))))
+	defer tracing.End(span, &err)
+
+	user, ok := models.DummyUsers[role]
+	if !ok {
+		return "", fmt.Errorf("no dummy user for role %q", role)
+	}
+	if err := s.Repo.EnsureUser(ctx, user); err != nil {
+		return "", models.Wrap("failed to seed dummy user", err)
+	}
+	return utils.GetJWToken(s.Tokens, user.Role, user.ID)
+}
+
+func (s *Service) LoginUser(ctx context.Context, email, password string) (_ models.Token, err error) {
+	ctx, span := tracer.Start(ctx, "Service.LoginUser")
+	defer tracing.End(span, &err)
+
+	user, err := s.Repo.LoginUser(ctx, email, password)
+	if err != nil {
+		return "", err
+	}
+	return utils.GetJWToken(s.Tokens, user.Role, user.ID)
+}
+
+func (s *Service) CreatePVZ(ctx context.Context, city models.City) (_ models.PVZ, err error) {
+	ctx, span := tracer.Start(ctx, "Service.CreatePVZ", trace.WithAttributes(attribute.String("pvz.city", string(city))))
+	defer tracing.End(span, &err)
+
+	return s.Repo.CreatePVZ(ctx, city)
+}
+
+func (s *Service) CreateReception(ctx context.Context, pvzID string) (_ models.Reception, err error) {
+	ctx, span := tracer.Start(ctx, "Service.CreateReception", trace.WithAttributes(attribute.String("pvz.id", pvzID)))
+	defer tracing.End(span, &err)
+
+	return s.Repo.CreateReception(ctx, pvzID)
+}
+
+func (s *Service) CreateProduct(ctx context.Context, pvzID string, prType models.ProductType) (_ models.Product, err error) {
+	ctx, span := tracer.Start(ctx, "Service.CreateProduct", trace.WithAttributes(
+		attribute.String("pvz.id", pvzID),
+		attribute.String("product.type", string(prType)),
+	))
+	defer tracing.End(span, &err)
+
+	return s.Repo.CreateProduct(ctx, pvzID, prType)
 }
 
-func (s *Service) CreatePVZ(city models.City) (models.PVZ, error) {
-	return s.Repo.CreatePVZ(city)
+func (s *Service) UpdatePVZ(ctx context.Context, pvzID string, city models.City, ifMatch models.IfMatch) (_ models.PVZ, err error) {
+	ctx, span := tracer.Start(ctx, "Service.UpdatePVZ", trace.WithAttributes(attribute.String("pvz.id", pvzID)))
+	defer tracing.End(span, &err)
+
+	return s.Repo.UpdatePVZ(ctx, pvzID, city, ifMatch)
 }
 
-func (s *Service) CreateReception(pvzID string) (models.Reception, error) {
-	return s.Repo.CreateReception(pvzID)
+// CloseLastReception closes the reception in progress, actorID is recorded as closedBy and is empty for API keys.
+func (s *Service) CloseLastReception(ctx context.Context, actorID, pvzID string, ifMatch models.IfMatch) (_ models.Reception, err error) {
+	ctx, span := tracer.Start(ctx, "Service.CloseLastReception", trace.WithAttributes(attribute.String("pvz.id", pvzID)))
+	defer tracing.End(span, &err)
+
+	return s.Repo.CloseLastReception(ctx, actorID, pvzID, ifMatch)
 }
-func (s *Service) CreateProduct(pvzID string, prType models.ProductType) (models.Product, error) {
-	return s.Repo.CreateProduct(pvzID, prType)
+
+func (s *Service) ReopenLastReception(ctx context.Context, pvzID string, ifMatch models.IfMatch) (_ models.Reception, err error) {
+	ctx, span := tracer.Start(ctx, "Service.ReopenLastReception", trace.WithAttributes(attribute.String("pvz.id", pvzID)))
+	defer tracing.End(span, &err)
+
+	return s.Repo.ReopenLastReception(ctx, pvzID, ifMatch)
 }
 
-func (s *Service) CloseLastReception(pvzID string) (models.Reception, error) {
-	return s.Repo.CloseLastReception(pvzID)
+func (s *Service) DeleteLastProduct(ctx context.Context, pvzID string) (err error) {
+	ctx, span := tracer.Start(ctx, "Service.DeleteLastProduct", trace.WithAttributes(attribute.String("pvz.id", pvzID)))
+	defer tracing.End(span, &err)
+
+	_, err = s.Repo.DeleteLastProduct(ctx, pvzID)
+	return err
+}
+
+// GetPVZInfo lists pvz with their receptions opened in the range, or closed in it by ByCloseDate.
+func (s *Service) GetPVZInfo(ctx context.Context, start, end string, by models.ReceptionDate, page, limit int) (_ []models.PVZInfo, err error) {
+	ctx, span := tracer.Start(ctx, "Service.GetPVZInfo", trace.WithAttributes(
+		attribute.String("date_by", string(by)),
+		attribute.Int("page", page),
+		attribute.Int("limit", limit),
+	))
+	defer tracing.End(span, &err)
+
+	q, err := newPVZInfoQuery(start, end, by, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	return s.Repo.GetPVZInfo(ctx, q.start, q.end, q.by, q.page, q.limit)
 }
 
-func (s *Service) DeleteLastProduct(pvzID string) error {
-	return s.Repo.DeleteLastProduct(pvzID)
+// PVZInfoETag returns the entity tag of the GetPVZInfo page. It changes whenever a pvz of the
+// page, its receptions or products change, or the page lists other pvz.
+func (s *Service) PVZInfoETag(ctx context.Context, start, end string, by models.ReceptionDate, page, limit int) (_ string, err error) {
+	ctx, span := tracer.Start(ctx, "Service.PVZInfoETag", trace.WithAttributes(
+		attribute.String("date_by", string(by)),
+		attribute.Int("page", page),
+		attribute.Int("limit", limit),
+	))
+	defer tracing.End(span, &err)
+
+	q, err := newPVZInfoQuery(start, end, by, page, limit)
+	if err != nil {
+		return "", err
+	}
+	revisions, err := s.Repo.PVZInfoRevisions(ctx, q.start, q.end, q.by, q.page, q.limit)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	for _, rev := range revisions {
+		fmt.Fprintf(h, "%s/%d/%d\n", rev.ID, rev.Version, rev.EventSeq)
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`, nil
 }
 
-func (s *Service) GetPVZInfo(start, end string, page, limit int) ([]models.PVZInfo, error) {
+type pvzInfoQuery struct {
+	start, end  time.Time
+	by          models.ReceptionDate
+	page, limit int
+}
 
+func newPVZInfoQuery(start, end string, by models.ReceptionDate, page, limit int) (pvzInfoQuery, error) {
 	if page == 0 {
 		page = 1
 	}
 	if limit == 0 {
 		limit = 10
 	}
-	startDate := time.Time{}
-	endDate := time.Now().UTC()
-	var err error
-
-	if start != "" {
-		startDate, err = time.Parse(time.RFC3339, start)
-		if err != nil {
-			return nil, models.Wrap("invalid startDate", err)
-		}
+	if by == "" {
+		by = models.ByOpenDate
 	}
-	if end != "" {
-		endDate, err = time.Parse(time.RFC3339, end)
-		if err != nil {
-			return nil, models.Wrap("invalid endDate", err)
-		}
+	startDate, endDate, err := parseDateRange(start, end)
+	if err != nil {
+		return pvzInfoQuery{}, err
 	}
-
-	return s.Repo.GetPVZInfo(startDate, endDate, page, limit)
+	return pvzInfoQuery{start: startDate, end: endDate, by: by, page: page, limit: limit}, nil
 }
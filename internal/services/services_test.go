@@ -1,30 +1,36 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"pvz/internal/auth"
 	"pvz/internal/models"
 	"pvz/internal/services/mocks"
+	"pvz/pkg/utils"
 )
 
 func newSvc() (*mocks.PvzUserStore, *Service) {
 	repo := new(mocks.PvzUserStore)
-	return repo, NewService(repo)
+	svc := NewService(repo)
+	svc.Tokens.Signer = auth.NewHMACKeySet("test", []byte("secret"))
+	return repo, svc
 }
 
 func TestServiceRegisterUserErrors(t *testing.T) {
 	repo, svc := newSvc()
 
 	repo.EXPECT().
-		RegisterUser("user@example.com", mock.AnythingOfType("string"), models.Employee).
+		RegisterUser(mock.Anything, "user@example.com", mock.AnythingOfType("string"), models.Employee).
 		Return(models.User{}, errors.New("db fail")).Once()
 
-	_, err := svc.RegisterUser("user@example.com", "password123", models.Employee)
+	_, err := svc.RegisterUser(context.Background(), "user@example.com", "Reception42", models.Employee)
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "failed to register user")
 	repo.AssertExpectations(t)
@@ -35,23 +41,40 @@ func TestServiceRegisterUserSuccess(t *testing.T) {
 
 	want := models.User{ID: "u1", Email: "user@example.com", Role: models.Employee}
 	repo.EXPECT().
-		RegisterUser("user@example.com", mock.AnythingOfType("string"), models.Employee).
+		RegisterUser(mock.Anything, "user@example.com", mock.AnythingOfType("string"), models.Employee).
 		Return(want, nil).Once()
 
-	got, err := svc.RegisterUser("user@example.com", "password123", models.Employee)
+	got, err := svc.RegisterUser(context.Background(), "user@example.com", "Reception42", models.Employee)
 	require.NoError(t, err)
 	require.Equal(t, want, got)
 	repo.AssertExpectations(t)
 }
 
+func TestServiceDummyLogin(t *testing.T) {
+	repo, svc := newSvc()
+
+	repo.EXPECT().EnsureUser(mock.Anything, models.DummyUsers[models.Moderator]).Return(nil).Once()
+	token, err := svc.DummyLogin(context.Background(), models.Moderator)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+
+	repo.EXPECT().EnsureUser(mock.Anything, models.DummyUsers[models.Employee]).Return(errors.New("db fail")).Once()
+	_, err = svc.DummyLogin(context.Background(), models.Employee)
+	require.Error(t, err)
+
+	_, err = svc.DummyLogin(context.Background(), models.Role("admin"))
+	require.Error(t, err)
+	repo.AssertExpectations(t)
+}
+
 func TestServiceLoginUserErrors(t *testing.T) {
 	repo, svc := newSvc()
 
 	repo.EXPECT().
-		LoginUser("user@example.com", "password123").
-		Return(models.Token(""), errors.New("db fail")).Once()
+		LoginUser(mock.Anything, "user@example.com", "Reception42").
+		Return(models.User{}, errors.New("db fail")).Once()
 
-	_, err := svc.LoginUser("user@example.com", "password123")
+	_, err := svc.LoginUser(context.Background(), "user@example.com", "Reception42")
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "db fail")
 	repo.AssertExpectations(t)
@@ -61,12 +84,21 @@ func TestServiceLoginUserSuccess(t *testing.T) {
 	repo, svc := newSvc()
 
 	repo.EXPECT().
-		LoginUser("user@example.com", "password123").
-		Return(models.Token("tok-123"), nil).Once()
+		LoginUser(mock.Anything, "user@example.com", "Reception42").
+		Return(models.User{ID: "u1", Email: "user@example.com", Role: models.Employee}, nil).Once()
+
+	got, err := svc.LoginUser(context.Background(), "user@example.com", "Reception42")
+	require.NoError(t, err)
 
-	got, err := svc.LoginUser("user@example.com", "password123")
+	claims := new(utils.Claims)
+	_, _, err = jwt.NewParser().ParseUnverified(string(got), claims)
 	require.NoError(t, err)
-	require.Equal(t, models.Token("tok-123"), got)
+	require.Equal(t, "u1", claims.Subject)
+	require.Equal(t, models.Employee, claims.Role)
+	require.Equal(t, "pvz", claims.Issuer)
+	require.Equal(t, jwt.ClaimStrings{"pvz"}, claims.Audience)
+	require.NotNil(t, claims.IssuedAt)
+	require.WithinDuration(t, time.Now().Add(24*time.Hour), claims.ExpiresAt.Time, time.Minute)
 	repo.AssertExpectations(t)
 }
 
@@ -74,10 +106,10 @@ func TestServiceCreatePVZErrors(t *testing.T) {
 	repo, svc := newSvc()
 
 	repo.EXPECT().
-		CreatePVZ(models.Moscow).
+		CreatePVZ(mock.Anything, models.Moscow).
 		Return(models.PVZ{}, errors.New("db fail")).Once()
 
-	_, err := svc.CreatePVZ(models.Moscow)
+	_, err := svc.CreatePVZ(context.Background(), models.Moscow)
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "db fail")
 	repo.AssertExpectations(t)
@@ -88,10 +120,10 @@ func TestServiceCreatePVZSuccess(t *testing.T) {
 
 	want := models.PVZ{ID: "uuid-123", RegistrationDate: time.Now().UTC(), City: models.Moscow}
 	repo.EXPECT().
-		CreatePVZ(models.Moscow).
+		CreatePVZ(mock.Anything, models.Moscow).
 		Return(want, nil).Once()
 
-	got, err := svc.CreatePVZ(models.Moscow)
+	got, err := svc.CreatePVZ(context.Background(), models.Moscow)
 	require.NoError(t, err)
 	require.Equal(t, want.ID, got.ID)
 	require.Equal(t, want.City, got.City)
@@ -102,10 +134,10 @@ func TestServiceCreateReceptionErrors(t *testing.T) {
 	repo, svc := newSvc()
 
 	repo.EXPECT().
-		CreateReception("uuid-123").
+		CreateReception(mock.Anything, "uuid-123").
 		Return(models.Reception{}, errors.New("db fail")).Once()
 
-	_, err := svc.CreateReception("uuid-123")
+	_, err := svc.CreateReception(context.Background(), "uuid-123")
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "db fail")
 	repo.AssertExpectations(t)
@@ -116,10 +148,10 @@ func TestServiceCreateReceptionSuccess(t *testing.T) {
 
 	want := models.Reception{ID: "rec-1", PvzID: "uuid-123"}
 	repo.EXPECT().
-		CreateReception("uuid-123").
+		CreateReception(mock.Anything, "uuid-123").
 		Return(want, nil).Once()
 
-	got, err := svc.CreateReception("uuid-123")
+	got, err := svc.CreateReception(context.Background(), "uuid-123")
 	require.NoError(t, err)
 	require.Equal(t, want, got)
 	repo.AssertExpectations(t)
@@ -129,10 +161,10 @@ func TestServiceCreateProductErrors(t *testing.T) {
 	repo, svc := newSvc()
 
 	repo.EXPECT().
-		CreateProduct("uuid-123", models.Electronic).
+		CreateProduct(mock.Anything, "uuid-123", models.Electronic).
 		Return(models.Product{}, errors.New("db fail")).Once()
 
-	_, err := svc.CreateProduct("uuid-123", models.Electronic)
+	_, err := svc.CreateProduct(context.Background(), "uuid-123", models.Electronic)
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "db fail")
 	repo.AssertExpectations(t)
@@ -143,10 +175,10 @@ func TestServiceCreateProductSuccess(t *testing.T) {
 
 	want := models.Product{ID: "prod-1", ReceptionID: "r1", Type: models.Electronic}
 	repo.EXPECT().
-		CreateProduct("uuid-123", models.Electronic).
+		CreateProduct(mock.Anything, "uuid-123", models.Electronic).
 		Return(want, nil).Once()
 
-	got, err := svc.CreateProduct("uuid-123", models.Electronic)
+	got, err := svc.CreateProduct(context.Background(), "uuid-123", models.Electronic)
 	require.NoError(t, err)
 	require.Equal(t, want, got)
 	repo.AssertExpectations(t)
@@ -156,10 +188,10 @@ func TestServiceCloseLastReceptionErrors(t *testing.T) {
 	repo, svc := newSvc()
 
 	repo.EXPECT().
-		CloseLastReception("uuid-123").
+		CloseLastReception(mock.Anything, "u1", "uuid-123", models.IfMatch(nil)).
 		Return(models.Reception{}, errors.New("db fail")).Once()
 
-	_, err := svc.CloseLastReception("uuid-123")
+	_, err := svc.CloseLastReception(context.Background(), "u1", "uuid-123", nil)
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "db fail")
 	repo.AssertExpectations(t)
@@ -170,10 +202,10 @@ func TestServiceCloseLastReceptionSuccess(t *testing.T) {
 
 	want := models.Reception{ID: "r2", Status: models.StatusClose}
 	repo.EXPECT().
-		CloseLastReception("uuid-123").
+		CloseLastReception(mock.Anything, "u1", "uuid-123", models.IfMatch(nil)).
 		Return(want, nil).Once()
 
-	got, err := svc.CloseLastReception("uuid-123")
+	got, err := svc.CloseLastReception(context.Background(), "u1", "uuid-123", nil)
 	require.NoError(t, err)
 	require.Equal(t, want, got)
 	repo.AssertExpectations(t)
@@ -183,10 +215,10 @@ func TestServiceDeleteLastProductErrors(t *testing.T) {
 	repo, svc := newSvc()
 
 	repo.EXPECT().
-		DeleteLastProduct("uuid-123").
-		Return(errors.New("db fail")).Once()
+		DeleteLastProduct(mock.Anything, "uuid-123").
+		Return(models.Product{}, errors.New("db fail")).Once()
 
-	err := svc.DeleteLastProduct("uuid-123")
+	err := svc.DeleteLastProduct(context.Background(), "uuid-123")
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "db fail")
 	repo.AssertExpectations(t)
@@ -196,10 +228,10 @@ func TestServiceDeleteLastProductSuccess(t *testing.T) {
 	repo, svc := newSvc()
 
 	repo.EXPECT().
-		DeleteLastProduct("uuid-123").
-		Return(nil).Once()
+		DeleteLastProduct(mock.Anything, "uuid-123").
+		Return(models.Product{ID: "prod-1", ReceptionID: "r1", Type: models.Electronic}, nil).Once()
 
-	err := svc.DeleteLastProduct("uuid-123")
+	err := svc.DeleteLastProduct(context.Background(), "uuid-123")
 	require.NoError(t, err)
 	repo.AssertExpectations(t)
 }
@@ -207,12 +239,12 @@ func TestServiceDeleteLastProductSuccess(t *testing.T) {
 func TestServiceGetPVZInfoErrors(t *testing.T) {
 	_, svc := newSvc()
 
-	_, err := svc.GetPVZInfo("bad-date", "", 0, 0)
+	_, err := svc.GetPVZInfo(context.Background(), "bad-date", "", "", 0, 0)
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "invalid startDate")
 
 	valid := time.Now().UTC().Format(time.RFC3339)
-	_, err = svc.GetPVZInfo(valid, "bad-date", 0, 0)
+	_, err = svc.GetPVZInfo(context.Background(), valid, "bad-date", "", 0, 0)
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "invalid endDate")
 }
@@ -230,11 +262,77 @@ func TestServiceGetPVZInfoSuccess(t *testing.T) {
 
 	want := []models.PVZInfo{{Pvz: models.PVZ{ID: "1"}}}
 	repo.EXPECT().
-		GetPVZInfo(startTime, endTime, page, limit).
+		GetPVZInfo(mock.Anything, startTime, endTime, models.ByOpenDate, page, limit).
 		Return(want, nil).Once()
 
-	got, err := svc.GetPVZInfo(startStr, endStr, page, limit)
+	got, err := svc.GetPVZInfo(context.Background(), startStr, endStr, "", page, limit)
 	require.NoError(t, err)
 	require.Equal(t, want, got)
+
+	repo.EXPECT().
+		GetPVZInfo(mock.Anything, startTime, endTime, models.ByCloseDate, 1, 10).
+		Return(want, nil).Once()
+	_, err = svc.GetPVZInfo(context.Background(), startStr, endStr, models.ByCloseDate, 0, 0)
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestServicePVZInfoETag(t *testing.T) {
+	repo, svc := newSvc()
+
+	startStr := "2025-01-01T00:00:00Z"
+	endStr := "2025-01-02T00:00:00Z"
+	startTime, _ := time.Parse(time.RFC3339, startStr)
+	endTime, _ := time.Parse(time.RFC3339, endStr)
+	revisions := []models.PVZRevision{{ID: "1", Version: 1, EventSeq: 7}}
+
+	etag := func() string {
+		t.Helper()
+		repo.EXPECT().
+			PVZInfoRevisions(mock.Anything, startTime, endTime, models.ByOpenDate, 1, 10).
+			Return(revisions, nil).Once()
+		tag, err := svc.PVZInfoETag(context.Background(), startStr, endStr, "", 0, 0)
+		require.NoError(t, err)
+		return tag
+	}
+	first := etag()
+	require.Equal(t, first, etag())
+
+	// a new event of a listed pvz changes the tag, so does another pvz on the page
+	revisions = []models.PVZRevision{{ID: "1", Version: 1, EventSeq: 8}}
+	second := etag()
+	require.NotEqual(t, first, second)
+	revisions = append(revisions, models.PVZRevision{ID: "2", Version: 1})
+	require.NotEqual(t, second, etag())
+
+	_, err := svc.PVZInfoETag(context.Background(), "bad-date", "", "", 0, 0)
+	require.ErrorContains(t, err, "invalid startDate")
+	repo.AssertExpectations(t)
+}
+
+func TestServiceUpdatePVZ(t *testing.T) {
+	repo, svc := newSvc()
+
+	want := models.PVZ{ID: "uuid-123", City: models.Kazan, Version: 2}
+	repo.EXPECT().
+		UpdatePVZ(mock.Anything, "uuid-123", models.Kazan, models.IfMatch{`"uuid-123-1"`}).
+		Return(want, nil).Once()
+
+	got, err := svc.UpdatePVZ(context.Background(), "uuid-123", models.Kazan, models.IfMatch{`"uuid-123-1"`})
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+	repo.AssertExpectations(t)
+}
+
+func TestServiceReopenLastReceptionErrors(t *testing.T) {
+	repo, svc := newSvc()
+
+	repo.EXPECT().
+		ReopenLastReception(mock.Anything, "uuid-123", models.IfMatch(nil)).
+		Return(models.Reception{}, errors.New("db fail")).Once()
+
+	_, err := svc.ReopenLastReception(context.Background(), "uuid-123", nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "db fail")
 	repo.AssertExpectations(t)
 }
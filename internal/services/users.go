@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"pvz/internal/models"
+	"pvz/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var ErrSelfModification = errors.New("moderator can't delete, block or demote themselves")
+
+func (s *Service) ListUsers(ctx context.Context, role models.Role, page, limit int) (_ []models.User, err error) {
+	ctx, span := tracer.Start(ctx, "Service.ListUsers", trace.WithAttributes(
+		attribute.Int("page", page),
+		attribute.Int("limit", limit),
+	))
+	defer tracing.End(span, &err)
+
+	if page == 0 {
+		page = 1
+	}
+	if limit == 0 {
+		limit = 10
+	}
+	return s.Repo.ListUsers(ctx, role, page, limit)
+}
+
+func (s *Service) Me(ctx context.Context, userID string) (_ models.Me, err error) {
+	ctx, span := tracer.Start(ctx, "Service.Me", trace.WithAttributes(attribute.String("user.id", userID)))
+	defer tracing.End(span, &err)
+
+	user, err := s.Repo.GetUser(ctx, userID)
+	if err != nil {
+		return models.Me{}, err
+	}
+	pvzList, err := s.Repo.GetUserPVZs(ctx, userID)
+	if err != nil {
+		return models.Me{}, err
+	}
+	return models.Me{
+		User:        user,
+		PVZs:        pvzList,
+		Permissions: s.Roles.Permissions(user.Role),
+	}, nil
+}
+
+func (s *Service) GetUser(ctx context.Context, userID string) (_ models.User, err error) {
+	ctx, span := tracer.Start(ctx, "Service.GetUser", trace.WithAttributes(attribute.String("user.id", userID)))
+	defer tracing.End(span, &err)
+
+	return s.Repo.GetUser(ctx, userID)
+}
+
+// UpdateUser changes the role or the blocked flag. actorID is the moderator making the change,
+// they can't block themselves or take a role without user management.
+func (s *Service) UpdateUser(ctx context.Context, actorID, userID string, upd models.UserUpdate) (_ models.User, err error) {
+	ctx, span := tracer.Start(ctx, "Service.UpdateUser", trace.WithAttributes(attribute.String("user.id", userID)))
+	defer tracing.End(span, &err)
+
+	if actorID == userID && ((upd.Blocked != nil && *upd.Blocked) || (upd.Role != nil && !s.Roles.Has(*upd.Role, models.PermUserManage))) {
+		return models.User{}, ErrSelfModification
+	}
+	return s.Repo.UpdateUser(ctx, userID, upd)
+}
+
+func (s *Service) DeleteUser(ctx context.Context, actorID, userID string) (err error) {
+	ctx, span := tracer.Start(ctx, "Service.DeleteUser", trace.WithAttributes(attribute.String("user.id", userID)))
+	defer tracing.End(span, &err)
+
+	if actorID == userID {
+		return ErrSelfModification
+	}
+	return s.Repo.DeleteUser(ctx, userID)
+}
+
+func (s *Service) AssignPVZ(ctx context.Context, userID, pvzID string) (err error) {
+	ctx, span := tracer.Start(ctx, "Service.AssignPVZ", trace.WithAttributes(
+		attribute.String("user.id", userID),
+		attribute.String("pvz.id", pvzID),
+	))
+	defer tracing.End(span, &err)
+
+	return s.Repo.AssignPVZ(ctx, userID, pvzID)
+}
+
+func (s *Service) UnassignPVZ(ctx context.Context, userID, pvzID string) (err error) {
+	ctx, span := tracer.Start(ctx, "Service.UnassignPVZ", trace.WithAttributes(
+		attribute.String("user.id", userID),
+		attribute.String("pvz.id", pvzID),
+	))
+	defer tracing.End(span, &err)
+
+	return s.Repo.UnassignPVZ(ctx, userID, pvzID)
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"pvz/internal/auth"
+	"pvz/internal/models"
+)
+
+func TestServiceListUsersDefaults(t *testing.T) {
+	repo, svc := newSvc()
+
+	repo.EXPECT().ListUsers(mock.Anything, models.Role(""), 1, 10).Return([]models.User{}, nil).Once()
+	_, err := svc.ListUsers(context.Background(), "", 0, 0)
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestServiceUpdateUserSelf(t *testing.T) {
+	repo, svc := newSvc()
+	blocked, unblocked := true, false
+	employee, moderator := models.Employee, models.Moderator
+
+	_, err := svc.UpdateUser(context.Background(), "m1", "m1", models.UserUpdate{Blocked: &blocked})
+	require.ErrorIs(t, err, ErrSelfModification)
+	_, err = svc.UpdateUser(context.Background(), "m1", "m1", models.UserUpdate{Role: &employee})
+	require.ErrorIs(t, err, ErrSelfModification)
+
+	upd := models.UserUpdate{Role: &moderator, Blocked: &unblocked}
+	repo.EXPECT().UpdateUser(mock.Anything, "m1", upd).Return(models.User{ID: "m1"}, nil).Once()
+	_, err = svc.UpdateUser(context.Background(), "m1", "m1", upd)
+	require.NoError(t, err)
+
+	upd = models.UserUpdate{Blocked: &blocked}
+	repo.EXPECT().UpdateUser(mock.Anything, "u2", upd).Return(models.User{ID: "u2", Blocked: true}, nil).Once()
+	got, err := svc.UpdateUser(context.Background(), "m1", "u2", upd)
+	require.NoError(t, err)
+	require.True(t, got.Blocked)
+	repo.AssertExpectations(t)
+}
+
+func TestServiceUpdateUserSelfCustomRole(t *testing.T) {
+	repo, svc := newSvc()
+	roles, err := auth.NewRoles(map[models.Role][]string{
+		models.Moderator: {models.PermUserManage},
+		"admin":          {models.PermUserManage, models.PermPVZCreate},
+		"auditor":        {models.PermPVZRead},
+	})
+	require.NoError(t, err)
+	svc.Roles = roles
+	admin, auditor := models.Role("admin"), models.Role("auditor")
+
+	_, err = svc.UpdateUser(context.Background(), "m1", "m1", models.UserUpdate{Role: &auditor})
+	require.ErrorIs(t, err, ErrSelfModification)
+
+	upd := models.UserUpdate{Role: &admin}
+	repo.EXPECT().UpdateUser(mock.Anything, "m1", upd).Return(models.User{ID: "m1", Role: admin}, nil).Once()
+	_, err = svc.UpdateUser(context.Background(), "m1", "m1", upd)
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestServiceDeleteUser(t *testing.T) {
+	repo, svc := newSvc()
+
+	require.ErrorIs(t, svc.DeleteUser(context.Background(), "m1", "m1"), ErrSelfModification)
+
+	repo.EXPECT().DeleteUser(mock.Anything, "u2").Return(nil).Once()
+	require.NoError(t, svc.DeleteUser(context.Background(), "m1", "u2"))
+	repo.AssertExpectations(t)
+}
+
+func TestServiceMe(t *testing.T) {
+	repo, svc := newSvc()
+
+	user := models.User{ID: "u1", Email: "a@b.com", Role: models.Employee}
+	pvzList := []models.PVZ{{ID: "p1", City: models.Kazan}}
+	repo.EXPECT().GetUser(mock.Anything, "u1").Return(user, nil).Once()
+	repo.EXPECT().GetUserPVZs(mock.Anything, "u1").Return(pvzList, nil).Once()
+
+	me, err := svc.Me(context.Background(), "u1")
+	require.NoError(t, err)
+	require.Equal(t, user, me.User)
+	require.Equal(t, pvzList, me.PVZs)
+	require.Contains(t, me.Permissions, models.PermReceptionOpen)
+	require.NotContains(t, me.Permissions, models.PermPVZCreate)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"pvz/internal/models"
+	"pvz/internal/tracing"
+	"pvz/internal/webhooks"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	ErrInvalidWebhookURL = errors.New("url must be an absolute http or https url of a public host")
+	ErrInvalidEvents     = errors.New("events must be a non-empty list of known event types")
+)
+
+// CreateWebhook subscribes the url to the event types. A secret is generated
+// when empty, it is only returned here.
+func (s *Service) CreateWebhook(ctx context.Context, actorID, rawURL string, events []string, secret string) (_ models.Webhook, err error) {
+	ctx, span := tracer.Start(ctx, "Service.CreateWebhook", trace.WithAttributes(attribute.StringSlice("webhook.events", events)))
+	defer tracing.End(span, &err)
+
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return models.Webhook{}, ErrInvalidWebhookURL
+	}
+	if !s.AllowPrivateWebhooks && !webhooks.PublicHost(u.Hostname()) {
+		return models.Webhook{}, ErrInvalidWebhookURL
+	}
+	if len(events) == 0 {
+		return models.Webhook{}, ErrInvalidEvents
+	}
+	for _, event := range events {
+		if !slices.Contains(models.EventTypes, event) {
+			return models.Webhook{}, ErrInvalidEvents
+		}
+	}
+	if secret == "" {
+		if secret, err = newSecretToken(); err != nil {
+			return models.Webhook{}, models.Wrap("generate webhook secret", err)
+		}
+	}
+
+	hook := models.Webhook{
+		ID:        uuid.NewString(),
+		URL:       rawURL,
+		Events:    slices.Compact(slices.Sorted(slices.Values(events))),
+		Secret:    secret,
+		CreatedBy: actorID,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.Repo.CreateWebhook(ctx, hook); err != nil {
+		return models.Webhook{}, err
+	}
+	return hook, nil
+}
+
+func (s *Service) ListWebhooks(ctx context.Context) (_ []models.Webhook, err error) {
+	ctx, span := tracer.Start(ctx, "Service.ListWebhooks")
+	defer tracing.End(span, &err)
+
+	return s.Repo.ListWebhooks(ctx)
+}
+
+func (s *Service) DeleteWebhook(ctx context.Context, webhookID string) (err error) {
+	ctx, span := tracer.Start(ctx, "Service.DeleteWebhook", trace.WithAttributes(attribute.String("webhook.id", webhookID)))
+	defer tracing.End(span, &err)
+
+	return s.Repo.DeleteWebhook(ctx, webhookID)
+}
+
+func (s *Service) ListWebhookDeliveries(ctx context.Context, webhookID string, page, limit int) (_ []models.WebhookDelivery, err error) {
+	ctx, span := tracer.Start(ctx, "Service.ListWebhookDeliveries", trace.WithAttributes(
+		attribute.String("webhook.id", webhookID),
+		attribute.Int("page", page),
+		attribute.Int("limit", limit),
+	))
+	defer tracing.End(span, &err)
+
+	if page == 0 {
+		page = 1
+	}
+	if limit == 0 {
+		limit = 10
+	}
+	return s.Repo.ListWebhookDeliveries(ctx, webhookID, page, limit)
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"pvz/internal/models"
+)
+
+func TestServiceCreateWebhook(t *testing.T) {
+	repo, svc := newSvc()
+
+	for _, u := range []string{"", "ftp://example.com/hook", "/hook", "http://",
+		"http://localhost:8080/hook", "http://127.0.0.1/hook", "http://10.0.0.5/hook", "http://169.254.169.254/latest", "http://[::1]/hook"} {
+		_, err := svc.CreateWebhook(context.Background(), "m1", u, []string{models.EventProductAdded}, "")
+		require.ErrorIs(t, err, ErrInvalidWebhookURL, u)
+	}
+	_, err := svc.CreateWebhook(context.Background(), "m1", "https://example.com/hook", nil, "")
+	require.ErrorIs(t, err, ErrInvalidEvents)
+	_, err = svc.CreateWebhook(context.Background(), "m1", "https://example.com/hook", []string{"pvz.burned"}, "")
+	require.ErrorIs(t, err, ErrInvalidEvents)
+
+	repo.EXPECT().CreateWebhook(mock.Anything, mock.AnythingOfType("models.Webhook")).Return(nil).Twice()
+	hook, err := svc.CreateWebhook(context.Background(), "m1", "https://example.com/hook",
+		[]string{models.EventProductDeleted, models.EventProductAdded, models.EventProductAdded}, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{models.EventProductAdded, models.EventProductDeleted}, hook.Events)
+	require.NotEmpty(t, hook.Secret)
+	require.Equal(t, "m1", hook.CreatedBy)
+
+	hook, err = svc.CreateWebhook(context.Background(), "m1", "http://wms.local/hook", []string{models.EventReceptionClosed}, "given")
+	require.NoError(t, err)
+	require.Equal(t, "given", hook.Secret)
+
+	svc.AllowPrivateWebhooks = true
+	repo.EXPECT().CreateWebhook(mock.Anything, mock.AnythingOfType("models.Webhook")).Return(nil).Once()
+	_, err = svc.CreateWebhook(context.Background(), "m1", "http://localhost:8080/hook", []string{models.EventReceptionClosed}, "")
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestServiceListWebhookDeliveriesDefaults(t *testing.T) {
+	repo, svc := newSvc()
+
+	repo.EXPECT().ListWebhookDeliveries(mock.Anything, "h1", 1, 10).Return([]models.WebhookDelivery{}, nil).Once()
+	_, err := svc.ListWebhookDeliveries(context.Background(), "h1", 0, 0)
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
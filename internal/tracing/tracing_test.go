@@ -0,0 +1,56 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"pvz/internal/config"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestStdoutExporterFallback(t *testing.T) {
+	var buf bytes.Buffer
+	exporter, err := newExporter(context.Background(), config.TracingCfg{}, &buf)
+	require.NoError(t, err)
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	_, span := provider.Tracer("test").Start(context.Background(), "Service.GetPVZInfo")
+	span.End()
+	require.NoError(t, provider.Shutdown(context.Background()))
+
+	require.Contains(t, buf.String(), "Service.GetPVZInfo")
+}
+
+func TestEndRecordsError(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := provider.Tracer("test")
+
+	func() (err error) {
+		_, span := tracer.Start(context.Background(), "ok")
+		defer End(span, &err)
+		return nil
+	}()
+	func() (err error) {
+		_, span := tracer.Start(context.Background(), "failed")
+		defer End(span, &err)
+		return errors.New("db fail")
+	}()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	require.Equal(t, codes.Unset, spans[0].Status().Code)
+	require.Equal(t, codes.Error, spans[1].Status().Code)
+	require.Equal(t, "db fail", spans[1].Status().Description)
+}
+
+func TestInitDisabled(t *testing.T) {
+	shutdown, err := Init(context.Background(), config.TracingCfg{Enabled: false})
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+}
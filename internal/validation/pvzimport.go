@@ -0,0 +1,99 @@
+package validation
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"pvz/internal/models"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidCSV = errors.New("invalid csv")
+
+// ImportPVZRow is a row of a pvz import file, coordinates are kept as text for the tags.
+type ImportPVZRow struct {
+	City      models.City `valid:"required,city"`
+	Address   string      `valid:"required,stringlength(1|500)"`
+	Latitude  string      `valid:"required,latitude"`
+	Longitude string      `valid:"required,longitude"`
+}
+
+var pvzImportColumns = []string{"city", "address", "latitude", "longitude"}
+
+// ParsePVZCSV reads a pvz import file with a header row, the columns may come in any order.
+// Invalid rows are reported with their line, an error is returned only for an unreadable file.
+func ParsePVZCSV(r io.Reader, validate func(any) error) ([]models.PVZImportRow, []models.PVZImportError, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("%w: empty file", ErrInvalidCSV)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidCSV, err)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range pvzImportColumns {
+		if _, ok := index[name]; !ok {
+			return nil, nil, fmt.Errorf("%w: no %s column", ErrInvalidCSV, name)
+		}
+	}
+
+	rows := []models.PVZImportRow{}
+	rowErrs := []models.PVZImportError{}
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			rowErrs = append(rowErrs, models.PVZImportError{Line: parseErr.StartLine, Error: parseErr.Err.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %w", ErrInvalidCSV, err)
+		}
+		line, _ := cr.FieldPos(0)
+		if len(record) != len(header) {
+			rowErrs = append(rowErrs, models.PVZImportError{
+				Line:  line,
+				Error: fmt.Sprintf("expected %d fields, got %d", len(header), len(record)),
+			})
+			continue
+		}
+
+		field := func(name string) string { return strings.TrimSpace(record[index[name]]) }
+		req := ImportPVZRow{
+			City:      models.City(field("city")),
+			Address:   field("address"),
+			Latitude:  field("latitude"),
+			Longitude: field("longitude"),
+		}
+		if err := validate(&req); err != nil {
+			rowErrs = append(rowErrs, models.PVZImportError{Line: line, Error: err.Error()})
+			continue
+		}
+		// the tags have checked the format already
+		lat, _ := strconv.ParseFloat(req.Latitude, 64)
+		lon, _ := strconv.ParseFloat(req.Longitude, 64)
+		rows = append(rows, models.PVZImportRow{
+			Line:      line,
+			City:      req.City,
+			Address:   req.Address,
+			Latitude:  lat,
+			Longitude: lon,
+		})
+	}
+	return rows, rowErrs, nil
+}
@@ -1,6 +1,9 @@
 package validation
 
-import "pvz/internal/models"
+import (
+	"pvz/internal/models"
+	"time"
+)
 
 type RoleForDummyLogin struct {
 	Role models.Role `json:"role" valid:"required,role"`
@@ -17,10 +20,61 @@ type LoginRequest struct {
 	Password string `json:"password" valid:"required"`
 }
 
+type OIDCLoginRequest struct {
+	Token string `json:"token" valid:"required"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"oldPassword" valid:"required"`
+	NewPassword string `json:"newPassword" valid:"required"`
+}
+
+type PasswordResetRequest struct {
+	Email string `json:"email" valid:"required,email"`
+}
+
+type PasswordResetConfirmRequest struct {
+	Token       string `json:"token" valid:"required"`
+	NewPassword string `json:"newPassword" valid:"required"`
+}
+
+type ListUsersQuery struct {
+	Role  string `query:"role" valid:"optional,role"`
+	Page  int    `query:"page" valid:"optional,range(1|10000000)"`
+	Limit int    `query:"limit" valid:"optional,range(1|100)"`
+}
+
+type UpdateUserRequest struct {
+	Role    *models.Role `json:"role" valid:"optional,role"`
+	Blocked *bool        `json:"blocked" valid:"optional"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" valid:"required,stringlength(1|100)"`
+	Scopes    []string   `json:"scopes" valid:"required"`
+	ExpiresAt *time.Time `json:"expiresAt" valid:"optional"`
+}
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" valid:"required,stringlength(1|2048)"`
+	Events []string `json:"events" valid:"required"`
+	// Secret signs deliveries, generated when empty
+	Secret string `json:"secret" valid:"optional,stringlength(16|256)"`
+}
+
+type ListDeliveriesQuery struct {
+	Page  int `query:"page" valid:"optional,range(1|10000000)"`
+	Limit int `query:"limit" valid:"optional,range(1|100)"`
+}
+
 type CreatePVZRequest struct {
 	City models.City `json:"city" valid:"required,city"`
 }
 
+type UpdatePVZRequest struct {
+	City models.City `json:"city" valid:"required,city"`
+}
+
 type AddProductRequest struct {
 	Type  models.ProductType `json:"type" valid:"required,productType"`
 	PvzID string             `json:"pvzId" valid:"required,uuid"`
@@ -33,7 +87,30 @@ type CreateReceptionRequest struct {
 type GetPVZQuery struct {
 	StartDate string `query:"startDate" valid:"optional,datetime"`
 	EndDate   string `query:"endDate" valid:"optional,datetime"`
+	// DateBy is the reception time the range applies to, open by default
+	DateBy string `query:"dateBy" valid:"optional,in(open|close)"`
 
 	Page  int `query:"page" valid:"optional,range(1|10000000)"`
 	Limit int `query:"limit" valid:"optional,range(1|30)"`
 }
+
+type IntakeReportQuery struct {
+	StartDate string `query:"startDate" valid:"optional,datetime"`
+	EndDate   string `query:"endDate" valid:"optional,datetime"`
+	// GroupBy is a comma separated list of pvz, city, productType, day, week, month
+	GroupBy string `query:"groupBy" valid:"optional,stringlength(1|100)"`
+}
+
+type ReceptionStatsQuery struct {
+	StartDate string `query:"startDate" valid:"optional,datetime"`
+	EndDate   string `query:"endDate" valid:"optional,datetime"`
+	PvzID     string `query:"pvzId" valid:"optional,uuid"`
+}
+
+type ExportQuery struct {
+	StartDate string `query:"startDate" valid:"optional,datetime"`
+	EndDate   string `query:"endDate" valid:"optional,datetime"`
+	Format    string `query:"format" valid:"optional,in(csv|xlsx)"`
+	// Lang of the column headers, Accept-Language is used when empty
+	Lang string `query:"lang" valid:"optional,in(ru|en)"`
+}
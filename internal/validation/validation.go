@@ -9,8 +9,12 @@ import (
 
 type Govalidator struct{}
 
-func NewValidator() *Govalidator {
-	initValidation()
+// NewValidator accepts the given roles in "role" fields, employee and moderator by default.
+func NewValidator(roles ...models.Role) *Govalidator {
+	if len(roles) == 0 {
+		roles = []models.Role{models.Employee, models.Moderator}
+	}
+	initValidation(roles)
 	return &Govalidator{}
 }
 
@@ -25,9 +29,9 @@ func (v *Govalidator) Validate(i any) error {
 	return nil
 }
 
-func initValidation() {
+func initValidation(roles []models.Role) {
 	govalidator.TagMap["role"] = func(str string) bool {
-		return check(models.Role(str), models.Employee, models.Moderator)
+		return check(models.Role(str), roles...)
 	}
 	govalidator.TagMap["city"] = func(str string) bool {
 		return check(models.City(str), models.Moscow, models.SaintP, models.Kazan)
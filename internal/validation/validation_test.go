@@ -1,6 +1,7 @@
 package validation
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -31,6 +32,18 @@ func TestValidateRole(t *testing.T) {
 	}
 }
 
+func TestValidateCustomRoles(t *testing.T) {
+	v := NewValidator(models.Employee, "auditor")
+	defer NewValidator()
+
+	obj := struct {
+		Role models.Role `valid:"role"`
+	}{"auditor"}
+	require.NoError(t, v.Validate(&obj))
+	obj.Role = models.Moderator
+	require.Error(t, v.Validate(&obj))
+}
+
 func TestValidateCity(t *testing.T) {
 	v := NewValidator()
 	cases := []struct {
@@ -98,3 +111,32 @@ func TestValidateDateTime(t *testing.T) {
 		})
 	}
 }
+
+func TestParsePVZCSV(t *testing.T) {
+	v := NewValidator()
+	file := "\ufeffAddress,city,longitude,latitude\n" +
+		"\"Тверская, 1\",Москва,37.61,55.75\n" +
+		"\n" +
+		"Невский, 2,Санкт-Петербург,30.31,59.93\n" +
+		"Баумана 3,Казань,49.12,95\n" +
+		"Ленина 4,Новосибирск,82.92,55.03\n" +
+		" Баумана 5 , Казань ,49.13,55.79\n"
+	rows, rowErrs, err := ParsePVZCSV(strings.NewReader(file), v.Validate)
+	require.NoError(t, err)
+	require.Equal(t, []models.PVZImportRow{
+		{Line: 2, City: models.Moscow, Address: "Тверская, 1", Latitude: 55.75, Longitude: 37.61},
+		{Line: 7, City: models.Kazan, Address: "Баумана 5", Latitude: 55.79, Longitude: 49.13},
+	}, rows)
+	require.Len(t, rowErrs, 3)
+	require.Equal(t, 4, rowErrs[0].Line)
+	require.Contains(t, rowErrs[0].Error, "expected 4 fields, got 5")
+	require.Equal(t, 5, rowErrs[1].Line)
+	require.Contains(t, rowErrs[1].Error, "latitude")
+	require.Equal(t, 6, rowErrs[2].Line)
+	require.Contains(t, rowErrs[2].Error, "city")
+
+	for _, file := range []string{"", "city,address,latitude\nМосква,a,1"} {
+		_, _, err = ParsePVZCSV(strings.NewReader(file), v.Validate)
+		require.ErrorIs(t, err, ErrInvalidCSV)
+	}
+}
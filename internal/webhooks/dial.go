@@ -0,0 +1,67 @@
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var ErrPrivateAddress = errors.New("webhook target is not a public address")
+
+// PublicIP reports whether the address is routable on the internet: loopback,
+// private, link-local, unspecified and multicast addresses are not.
+func PublicIP(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsValid() && ip.IsGlobalUnicast() && !ip.IsPrivate() && !cgnat.Contains(ip)
+}
+
+// cgnat is the shared address space (RFC 6598), it is internal to the provider.
+var cgnat = netip.MustParsePrefix("100.64.0.0/10")
+
+// PublicHost rejects localhost and IP literals that aren't public. Other names
+// are only checked when they are dialed, they may resolve anywhere.
+func PublicHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if ip, err := netip.ParseAddr(strings.Trim(host, "[]")); err == nil {
+		return PublicIP(ip)
+	}
+	return true
+}
+
+// NewClient returns the delivery client. Unless private targets are allowed it
+// refuses to connect to addresses that aren't public, checked after resolution so
+// a name pointing inside the network is caught too, and it never follows redirects.
+func NewClient(cfg Config) *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, KeepAlive: 30 * time.Second}
+	if !cfg.AllowPrivate {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			addr, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: %s", ErrPrivateAddress, address)
+			}
+			if !PublicIP(addr.Addr()) {
+				return fmt.Errorf("%w: %s", ErrPrivateAddress, addr.Addr())
+			}
+			return nil
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would be dialed instead of the target and bypass the check
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
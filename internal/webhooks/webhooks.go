@@ -0,0 +1,238 @@
+// Package webhooks delivers domain events to subscribed URLs.
+//
+// The Dispatcher stores one pending delivery per matching subscription, the Worker
+// sends them in the background and retries failures with exponential backoff.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"pvz/internal/logger"
+	"pvz/internal/models"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	HeaderEvent     = "X-PVZ-Event"
+	HeaderDelivery  = "X-PVZ-Delivery"
+	HeaderTimestamp = "X-PVZ-Timestamp"
+	HeaderSignature = "X-PVZ-Signature"
+)
+
+// Store keeps the delivery queue.
+type Store interface {
+	EnqueueWebhookDeliveries(ctx context.Context, event models.Event) error
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookJob, error)
+	CompleteWebhookDelivery(ctx context.Context, deliveryID string, res models.DeliveryResult) error
+}
+
+// Dispatcher queues events for delivery, the outbox relay publishes to it.
+type Dispatcher struct {
+	Store Store
+}
+
+func NewDispatcher(store Store) *Dispatcher {
+	return &Dispatcher{Store: store}
+}
+
+func (d *Dispatcher) Publish(ctx context.Context, event models.Event) error {
+	return d.Store.EnqueueWebhookDeliveries(ctx, event)
+}
+
+// Sign returns the X-PVZ-Signature value: hex HMAC-SHA256 of "timestamp.body".
+// Receivers recompute it with the webhook secret and compare in constant time.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type Config struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	Timeout      time.Duration
+	// AllowPrivate lets deliveries reach loopback and private addresses, for local setups
+	AllowPrivate bool
+}
+
+func DefaultConfig() Config {
+	return Config{
+		PollInterval: time.Second,
+		BatchSize:    20,
+		MaxAttempts:  8,
+		BaseBackoff:  10 * time.Second,
+		MaxBackoff:   time.Hour,
+		Timeout:      10 * time.Second,
+	}
+}
+
+// Lease is how long claimed deliveries are hidden from other workers. Deliveries to one
+// endpoint are sent one after another, so a batch takes up to BatchSize timeouts when
+// they all go to the same slow endpoint; the lease covers that and a poll on top.
+func (c Config) Lease() time.Duration {
+	return time.Duration(c.BatchSize+1)*c.Timeout + c.PollInterval
+}
+
+// Backoff is the delay after the given failed attempt: base * 2^(attempt-1), capped at max.
+func (c Config) Backoff(attempt int) time.Duration {
+	delay := c.BaseBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= c.MaxBackoff {
+			return c.MaxBackoff
+		}
+	}
+	return min(delay, c.MaxBackoff)
+}
+
+type Worker struct {
+	Store  Store
+	Client *http.Client
+	Config Config
+	now    func() time.Time
+}
+
+func NewWorker(store Store, cfg Config) *Worker {
+	return &Worker{
+		Store:  store,
+		Client: NewClient(cfg),
+		Config: cfg,
+		now:    time.Now,
+	}
+}
+
+// Run sends due deliveries every poll interval until ctx is canceled.
+func (w *Worker) Run(ctx context.Context) {
+	log := logger.FromContext(ctx)
+	ticker := time.NewTicker(w.Config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// drain the queue before waiting for the next tick
+		for {
+			n, err := w.RunOnce(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Error("webhook deliveries", "error", err)
+			}
+			if err != nil || n < w.Config.BatchSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce sends one batch of due deliveries and returns how many were claimed.
+// Endpoints are sent to concurrently, each gets one request at a time in claim
+// order, so a slow receiver only delays its own deliveries.
+func (w *Worker) RunOnce(ctx context.Context) (int, error) {
+	jobs, err := w.Store.ClaimWebhookDeliveries(ctx, w.Config.BatchSize, w.Config.Lease())
+	if err != nil {
+		return 0, err
+	}
+
+	var endpoints []string
+	byEndpoint := make(map[string][]models.WebhookJob)
+	for _, job := range jobs {
+		key := endpoint(job.URL)
+		if _, ok := byEndpoint[key]; !ok {
+			endpoints = append(endpoints, key)
+		}
+		byEndpoint[key] = append(byEndpoint[key], job)
+	}
+
+	errs := make([]error, len(endpoints))
+	var wg sync.WaitGroup
+	for i, key := range endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, job := range byEndpoint[key] {
+				res := w.deliver(ctx, job)
+				if err := w.Store.CompleteWebhookDelivery(ctx, job.Delivery.ID, res); err != nil {
+					errs[i] = err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return len(jobs), errors.Join(errs...)
+}
+
+// endpoint is the scheme and host deliveries are limited by, the url itself when it
+// doesn't parse (the request fails then anyway).
+func endpoint(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return u.Scheme + "://" + strings.ToLower(u.Host)
+}
+
+func (w *Worker) deliver(ctx context.Context, job models.WebhookJob) models.DeliveryResult {
+	status, err := w.send(ctx, job)
+	if err == nil {
+		return models.DeliveryResult{Status: models.DeliveryDelivered, StatusCode: &status}
+	}
+
+	logger.FromContext(ctx).Warn("webhook delivery failed",
+		"delivery_id", job.Delivery.ID, "webhook_id", job.Delivery.WebhookID, "error", err)
+	msg := err.Error()
+	res := models.DeliveryResult{Status: models.DeliveryFailed, Error: &msg}
+	if status != 0 {
+		res.StatusCode = &status
+	}
+	if attempt := job.Delivery.Attempts + 1; attempt < w.Config.MaxAttempts {
+		next := w.now().Add(w.Config.Backoff(attempt))
+		res.Status = models.DeliveryPending
+		res.NextAttemptAt = &next
+	}
+	return res
+}
+
+func (w *Worker) send(ctx context.Context, job models.WebhookJob) (int, error) {
+	ts := w.now().Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.URL, bytes.NewReader(job.Delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "pvz-webhooks")
+	req.Header.Set(HeaderEvent, job.Delivery.EventType)
+	req.Header.Set(HeaderDelivery, job.Delivery.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderSignature, Sign(job.Secret, ts, job.Delivery.Payload))
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,361 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"pvz/internal/models"
+)
+
+// memStore is a queue where every pending delivery is due.
+type memStore struct {
+	mu         sync.Mutex
+	hooks      []models.Webhook
+	deliveries []models.WebhookDelivery
+	lease      time.Duration
+}
+
+func (s *memStore) EnqueueWebhookDeliveries(_ context.Context, event models.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	for _, hook := range s.hooks {
+		for _, t := range hook.Events {
+			if t == event.Type {
+				s.deliveries = append(s.deliveries, models.WebhookDelivery{
+					ID: strconv.Itoa(len(s.deliveries) + 1), WebhookID: hook.ID, EventID: event.ID,
+					EventType: event.Type, Payload: payload, Status: models.DeliveryPending,
+				})
+			}
+		}
+	}
+	return nil
+}
+
+func (s *memStore) ClaimWebhookDeliveries(_ context.Context, limit int, lease time.Duration) ([]models.WebhookJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lease = lease
+	var jobs []models.WebhookJob
+	for _, d := range s.deliveries {
+		if d.Status != models.DeliveryPending || len(jobs) == limit {
+			continue
+		}
+		for _, hook := range s.hooks {
+			if hook.ID == d.WebhookID {
+				jobs = append(jobs, models.WebhookJob{Delivery: d, URL: hook.URL, Secret: hook.Secret})
+			}
+		}
+	}
+	return jobs, nil
+}
+
+func (s *memStore) CompleteWebhookDelivery(_ context.Context, id string, res models.DeliveryResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.deliveries {
+		d := &s.deliveries[i]
+		if d.ID == id {
+			d.Status, d.LastStatusCode, d.LastError = res.Status, res.StatusCode, res.Error
+			d.Attempts++
+			if res.NextAttemptAt != nil {
+				d.NextAttemptAt = *res.NextAttemptAt
+			}
+		}
+	}
+	return nil
+}
+
+func (s *memStore) delivery(t *testing.T, id string) models.WebhookDelivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range s.deliveries {
+		if d.ID == id {
+			return d
+		}
+	}
+	t.Fatalf("delivery %s not found", id)
+	return models.WebhookDelivery{}
+}
+
+type received struct {
+	header http.Header
+	body   []byte
+}
+
+func newReceiver(t *testing.T, statuses ...int) (*httptest.Server, chan received) {
+	got := make(chan received, 10)
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got <- received{header: r.Header.Clone(), body: body}
+		mu.Lock()
+		status := http.StatusOK
+		if len(statuses) > 0 {
+			status, statuses = statuses[0], statuses[1:]
+		}
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, got
+}
+
+func newTestWorker(store Store) *Worker {
+	cfg := DefaultConfig()
+	cfg.MaxAttempts = 3
+	cfg.AllowPrivate = true
+	w := NewWorker(store, cfg)
+	w.now = func() time.Time { return time.Unix(1700000000, 0) }
+	return w
+}
+
+func publish(t *testing.T, store Store, eventType string) models.Event {
+	event, err := models.NewEvent(eventType, "pvz-1", models.Product{ID: "prod-1", Type: models.Electronic})
+	require.NoError(t, err)
+	require.NoError(t, NewDispatcher(store).Publish(context.Background(), event))
+	return event
+}
+
+func TestWorkerDeliversSignedEvent(t *testing.T) {
+	srv, got := newReceiver(t)
+	store := &memStore{hooks: []models.Webhook{
+		{ID: "h1", URL: srv.URL, Events: []string{models.EventProductAdded}, Secret: "s3cret"},
+		{ID: "h2", URL: srv.URL, Events: []string{models.EventReceptionClosed}, Secret: "other"},
+	}}
+	event := publish(t, store, models.EventProductAdded)
+	require.Len(t, store.deliveries, 1)
+
+	n, err := newTestWorker(store).RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	req := <-got
+	require.Equal(t, models.EventProductAdded, req.header.Get(HeaderEvent))
+	require.Equal(t, "1", req.header.Get(HeaderDelivery))
+	require.Equal(t, "1700000000", req.header.Get(HeaderTimestamp))
+	require.Equal(t, Sign("s3cret", 1700000000, req.body), req.header.Get(HeaderSignature))
+
+	var sent models.Event
+	require.NoError(t, json.Unmarshal(req.body, &sent))
+	require.Equal(t, event.ID, sent.ID)
+	require.JSONEq(t, `{"id":"prod-1","dateTime":"0001-01-01T00:00:00Z","type":"электроника","receptionId":""}`, string(sent.Data))
+
+	d := store.delivery(t, "1")
+	require.Equal(t, models.DeliveryDelivered, d.Status)
+	require.Equal(t, 1, d.Attempts)
+	require.Equal(t, http.StatusOK, *d.LastStatusCode)
+
+	n, err = newTestWorker(store).RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
+
+func TestWorkerRetriesWithBackoff(t *testing.T) {
+	srv, got := newReceiver(t, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable)
+	store := &memStore{hooks: []models.Webhook{
+		{ID: "h1", URL: srv.URL, Events: []string{models.EventProductDeleted}, Secret: "s"},
+	}}
+	publish(t, store, models.EventProductDeleted)
+	w := newTestWorker(store)
+
+	_, err := w.RunOnce(context.Background())
+	require.NoError(t, err)
+	<-got
+	d := store.delivery(t, "1")
+	require.Equal(t, models.DeliveryPending, d.Status)
+	require.Equal(t, http.StatusInternalServerError, *d.LastStatusCode)
+	require.Equal(t, "unexpected status 500", *d.LastError)
+	require.Equal(t, w.now().Add(10*time.Second), d.NextAttemptAt)
+
+	_, err = w.RunOnce(context.Background())
+	require.NoError(t, err)
+	<-got
+	d = store.delivery(t, "1")
+	require.Equal(t, models.DeliveryPending, d.Status)
+	require.Equal(t, w.now().Add(20*time.Second), d.NextAttemptAt)
+
+	_, err = w.RunOnce(context.Background())
+	require.NoError(t, err)
+	<-got
+	d = store.delivery(t, "1")
+	require.Equal(t, models.DeliveryFailed, d.Status)
+	require.Equal(t, 3, d.Attempts)
+	require.Equal(t, http.StatusServiceUnavailable, *d.LastStatusCode)
+}
+
+func TestWorkerUnreachableReceiver(t *testing.T) {
+	srv, _ := newReceiver(t)
+	url := srv.URL
+	srv.Close()
+
+	store := &memStore{hooks: []models.Webhook{
+		{ID: "h1", URL: url, Events: []string{models.EventReceptionOpened}, Secret: "s"},
+	}}
+	publish(t, store, models.EventReceptionOpened)
+
+	_, err := newTestWorker(store).RunOnce(context.Background())
+	require.NoError(t, err)
+	d := store.delivery(t, "1")
+	require.Equal(t, models.DeliveryPending, d.Status)
+	require.Nil(t, d.LastStatusCode)
+	require.NotEmpty(t, *d.LastError)
+}
+
+func TestWorkerSlowEndpointDoesNotBlockOthers(t *testing.T) {
+	release := make(chan struct{})
+	var slowCalls sync.WaitGroup
+	slowCalls.Add(1)
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(HeaderDelivery) == "1" {
+			slowCalls.Done()
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(slow.Close)
+	fast, got := newReceiver(t)
+	store := &memStore{hooks: []models.Webhook{
+		{ID: "h1", URL: slow.URL, Events: []string{models.EventProductAdded}, Secret: "s"},
+		{ID: "h2", URL: fast.URL, Events: []string{models.EventProductAdded}, Secret: "s"},
+	}}
+	publish(t, store, models.EventProductAdded)
+	publish(t, store, models.EventProductAdded)
+
+	w := newTestWorker(store)
+	done := make(chan error, 1)
+	go func() {
+		_, err := w.RunOnce(context.Background())
+		done <- err
+	}()
+	slowCalls.Wait()
+	// both deliveries to the fast endpoint go out while the slow one hangs
+	for range 2 {
+		select {
+		case <-got:
+		case <-time.After(5 * time.Second):
+			t.Fatal("fast endpoint waited for the slow one")
+		}
+	}
+	require.Equal(t, models.DeliveryPending, store.delivery(t, "3").Status)
+	close(release)
+	require.NoError(t, <-done)
+
+	for _, id := range []string{"1", "2", "3", "4"} {
+		require.Equal(t, models.DeliveryDelivered, store.delivery(t, id).Status, id)
+	}
+	require.Equal(t, w.Config.Lease(), store.lease)
+	require.GreaterOrEqual(t, w.Config.Lease(), time.Duration(w.Config.BatchSize)*w.Config.Timeout)
+}
+
+func TestWorkerRefusesPrivateTarget(t *testing.T) {
+	srv, got := newReceiver(t)
+	store := &memStore{hooks: []models.Webhook{
+		{ID: "h1", URL: srv.URL, Events: []string{models.EventReceptionOpened}, Secret: "s"},
+	}}
+	publish(t, store, models.EventReceptionOpened)
+
+	w := newTestWorker(store)
+	w.Client = NewClient(DefaultConfig())
+	_, err := w.RunOnce(context.Background())
+	require.NoError(t, err)
+	d := store.delivery(t, "1")
+	require.Equal(t, models.DeliveryPending, d.Status)
+	require.Contains(t, *d.LastError, ErrPrivateAddress.Error())
+	require.Empty(t, got)
+}
+
+func TestWorkerDoesNotFollowRedirects(t *testing.T) {
+	target, got := newReceiver(t)
+	redirect := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusFound))
+	t.Cleanup(redirect.Close)
+	store := &memStore{hooks: []models.Webhook{
+		{ID: "h1", URL: redirect.URL, Events: []string{models.EventReceptionOpened}, Secret: "s"},
+	}}
+	publish(t, store, models.EventReceptionOpened)
+
+	_, err := newTestWorker(store).RunOnce(context.Background())
+	require.NoError(t, err)
+	d := store.delivery(t, "1")
+	require.Equal(t, models.DeliveryPending, d.Status)
+	require.Equal(t, http.StatusFound, *d.LastStatusCode)
+	require.Empty(t, got)
+}
+
+func TestPublicHost(t *testing.T) {
+	for host, public := range map[string]bool{
+		"example.com":     true,
+		"93.184.216.34":   true,
+		"2606:4700::1111": true,
+		"localhost":       false,
+		"api.localhost.":  false,
+		"127.0.0.1":       false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"100.64.0.1":      false,
+		"0.0.0.0":         false,
+		"::1":             false,
+		"[::1]":           false,
+		"fe80::1":         false,
+		"fd00::1":         false,
+		"::ffff:10.0.0.1": false,
+		"224.0.0.1":       false,
+	} {
+		require.Equal(t, public, PublicHost(host), host)
+	}
+}
+
+func TestWorkerRunStopsOnCancel(t *testing.T) {
+	srv, got := newReceiver(t)
+	store := &memStore{hooks: []models.Webhook{
+		{ID: "h1", URL: srv.URL, Events: []string{models.EventReceptionClosed}, Secret: "s"},
+	}}
+	publish(t, store, models.EventReceptionClosed)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		newTestWorker(store).Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-got:
+	case <-time.After(5 * time.Second):
+		t.Fatal("delivery was not sent")
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker did not stop")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	cfg := Config{BaseBackoff: time.Second, MaxBackoff: 10 * time.Second}
+	require.Equal(t, time.Second, cfg.Backoff(1))
+	require.Equal(t, 2*time.Second, cfg.Backoff(2))
+	require.Equal(t, 8*time.Second, cfg.Backoff(4))
+	require.Equal(t, 10*time.Second, cfg.Backoff(5))
+	require.Equal(t, 10*time.Second, cfg.Backoff(40))
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '1700000000.{}' | openssl dgst -sha256 -hmac secret
+	require.Equal(t, "sha256=b8569b78799ff9e3cbff0fc2d63a33a2b57f3282abd07c37ae5e8e7d79a5f163", Sign("secret", 1700000000, []byte("{}")))
+}
@@ -0,0 +1,673 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: events.proto
+
+// Domain events published to the message broker, one message per event.
+// The subject is <subject_prefix>.<type>, for example pvz.events.reception.closed.
+
+package eventspb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ReceptionStatus int32
+
+const (
+	ReceptionStatus_RECEPTION_STATUS_UNSPECIFIED ReceptionStatus = 0
+	ReceptionStatus_RECEPTION_STATUS_IN_PROGRESS ReceptionStatus = 1
+	ReceptionStatus_RECEPTION_STATUS_CLOSED      ReceptionStatus = 2
+)
+
+// Enum value maps for ReceptionStatus.
+var (
+	ReceptionStatus_name = map[int32]string{
+		0: "RECEPTION_STATUS_UNSPECIFIED",
+		1: "RECEPTION_STATUS_IN_PROGRESS",
+		2: "RECEPTION_STATUS_CLOSED",
+	}
+	ReceptionStatus_value = map[string]int32{
+		"RECEPTION_STATUS_UNSPECIFIED": 0,
+		"RECEPTION_STATUS_IN_PROGRESS": 1,
+		"RECEPTION_STATUS_CLOSED":      2,
+	}
+)
+
+func (x ReceptionStatus) Enum() *ReceptionStatus {
+	p := new(ReceptionStatus)
+	*p = x
+	return p
+}
+
+func (x ReceptionStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ReceptionStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_events_proto_enumTypes[0].Descriptor()
+}
+
+func (ReceptionStatus) Type() protoreflect.EnumType {
+	return &file_events_proto_enumTypes[0]
+}
+
+func (x ReceptionStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ReceptionStatus.Descriptor instead.
+func (ReceptionStatus) EnumDescriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{0}
+}
+
+type Product struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	DateTime      *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=date_time,json=dateTime,proto3" json:"date_time,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	ReceptionId   string                 `protobuf:"bytes,4,opt,name=reception_id,json=receptionId,proto3" json:"reception_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Product) Reset() {
+	*x = Product{}
+	mi := &file_events_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Product) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Product) ProtoMessage() {}
+
+func (x *Product) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Product.ProtoReflect.Descriptor instead.
+func (*Product) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Product) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Product) GetDateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DateTime
+	}
+	return nil
+}
+
+func (x *Product) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Product) GetReceptionId() string {
+	if x != nil {
+		return x.ReceptionId
+	}
+	return ""
+}
+
+type Reception struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	DateTime      *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=date_time,json=dateTime,proto3" json:"date_time,omitempty"`
+	PvzId         string                 `protobuf:"bytes,3,opt,name=pvz_id,json=pvzId,proto3" json:"pvz_id,omitempty"`
+	Status        ReceptionStatus        `protobuf:"varint,4,opt,name=status,proto3,enum=pvz.events.v1.ReceptionStatus" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Reception) Reset() {
+	*x = Reception{}
+	mi := &file_events_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Reception) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reception) ProtoMessage() {}
+
+func (x *Reception) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reception.ProtoReflect.Descriptor instead.
+func (*Reception) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Reception) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Reception) GetDateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DateTime
+	}
+	return nil
+}
+
+func (x *Reception) GetPvzId() string {
+	if x != nil {
+		return x.PvzId
+	}
+	return ""
+}
+
+func (x *Reception) GetStatus() ReceptionStatus {
+	if x != nil {
+		return x.Status
+	}
+	return ReceptionStatus_RECEPTION_STATUS_UNSPECIFIED
+}
+
+type ReceptionOpened struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reception     *Reception             `protobuf:"bytes,1,opt,name=reception,proto3" json:"reception,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReceptionOpened) Reset() {
+	*x = ReceptionOpened{}
+	mi := &file_events_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReceptionOpened) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReceptionOpened) ProtoMessage() {}
+
+func (x *ReceptionOpened) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReceptionOpened.ProtoReflect.Descriptor instead.
+func (*ReceptionOpened) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ReceptionOpened) GetReception() *Reception {
+	if x != nil {
+		return x.Reception
+	}
+	return nil
+}
+
+type ReceptionClosed struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Reception *Reception             `protobuf:"bytes,1,opt,name=reception,proto3" json:"reception,omitempty"`
+	// every product accepted in the reception
+	Products      []*Product `protobuf:"bytes,2,rep,name=products,proto3" json:"products,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReceptionClosed) Reset() {
+	*x = ReceptionClosed{}
+	mi := &file_events_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReceptionClosed) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReceptionClosed) ProtoMessage() {}
+
+func (x *ReceptionClosed) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReceptionClosed.ProtoReflect.Descriptor instead.
+func (*ReceptionClosed) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ReceptionClosed) GetReception() *Reception {
+	if x != nil {
+		return x.Reception
+	}
+	return nil
+}
+
+func (x *ReceptionClosed) GetProducts() []*Product {
+	if x != nil {
+		return x.Products
+	}
+	return nil
+}
+
+type ProductAdded struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductAdded) Reset() {
+	*x = ProductAdded{}
+	mi := &file_events_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductAdded) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductAdded) ProtoMessage() {}
+
+func (x *ProductAdded) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductAdded.ProtoReflect.Descriptor instead.
+func (*ProductAdded) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ProductAdded) GetProduct() *Product {
+	if x != nil {
+		return x.Product
+	}
+	return nil
+}
+
+type ProductDeleted struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductDeleted) Reset() {
+	*x = ProductDeleted{}
+	mi := &file_events_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductDeleted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductDeleted) ProtoMessage() {}
+
+func (x *ProductDeleted) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductDeleted.ProtoReflect.Descriptor instead.
+func (*ProductDeleted) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ProductDeleted) GetProduct() *Product {
+	if x != nil {
+		return x.Product
+	}
+	return nil
+}
+
+type Event struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// id is unique per event, consumers deduplicate by it
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// type is reception.opened, reception.closed, reception.reopened, product.added or product.deleted
+	Type       string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	OccurredAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	PvzId      string                 `protobuf:"bytes,4,opt,name=pvz_id,json=pvzId,proto3" json:"pvz_id,omitempty"`
+	// seq orders the events of the service
+	Seq int64 `protobuf:"varint,5,opt,name=seq,proto3" json:"seq,omitempty"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*Event_ReceptionOpened
+	//	*Event_ReceptionClosed
+	//	*Event_ProductAdded
+	//	*Event_ProductDeleted
+	Payload       isEvent_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_events_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Event) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Event) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+func (x *Event) GetPvzId() string {
+	if x != nil {
+		return x.PvzId
+	}
+	return ""
+}
+
+func (x *Event) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *Event) GetPayload() isEvent_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *Event) GetReceptionOpened() *ReceptionOpened {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_ReceptionOpened); ok {
+			return x.ReceptionOpened
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetReceptionClosed() *ReceptionClosed {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_ReceptionClosed); ok {
+			return x.ReceptionClosed
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetProductAdded() *ProductAdded {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_ProductAdded); ok {
+			return x.ProductAdded
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetProductDeleted() *ProductDeleted {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_ProductDeleted); ok {
+			return x.ProductDeleted
+		}
+	}
+	return nil
+}
+
+type isEvent_Payload interface {
+	isEvent_Payload()
+}
+
+type Event_ReceptionOpened struct {
+	// also set for reception.reopened
+	ReceptionOpened *ReceptionOpened `protobuf:"bytes,10,opt,name=reception_opened,json=receptionOpened,proto3,oneof"`
+}
+
+type Event_ReceptionClosed struct {
+	ReceptionClosed *ReceptionClosed `protobuf:"bytes,11,opt,name=reception_closed,json=receptionClosed,proto3,oneof"`
+}
+
+type Event_ProductAdded struct {
+	ProductAdded *ProductAdded `protobuf:"bytes,12,opt,name=product_added,json=productAdded,proto3,oneof"`
+}
+
+type Event_ProductDeleted struct {
+	ProductDeleted *ProductDeleted `protobuf:"bytes,13,opt,name=product_deleted,json=productDeleted,proto3,oneof"`
+}
+
+func (*Event_ReceptionOpened) isEvent_Payload() {}
+
+func (*Event_ReceptionClosed) isEvent_Payload() {}
+
+func (*Event_ProductAdded) isEvent_Payload() {}
+
+func (*Event_ProductDeleted) isEvent_Payload() {}
+
+var File_events_proto protoreflect.FileDescriptor
+
+const file_events_proto_rawDesc = "" +
+	"\n" +
+	"\fevents.proto\x12\rpvz.events.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x89\x01\n" +
+	"\aProduct\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x127\n" +
+	"\tdate_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\bdateTime\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12!\n" +
+	"\freception_id\x18\x04 \x01(\tR\vreceptionId\"\xa3\x01\n" +
+	"\tReception\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x127\n" +
+	"\tdate_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\bdateTime\x12\x15\n" +
+	"\x06pvz_id\x18\x03 \x01(\tR\x05pvzId\x126\n" +
+	"\x06status\x18\x04 \x01(\x0e2\x1e.pvz.events.v1.ReceptionStatusR\x06status\"I\n" +
+	"\x0fReceptionOpened\x126\n" +
+	"\treception\x18\x01 \x01(\v2\x18.pvz.events.v1.ReceptionR\treception\"}\n" +
+	"\x0fReceptionClosed\x126\n" +
+	"\treception\x18\x01 \x01(\v2\x18.pvz.events.v1.ReceptionR\treception\x122\n" +
+	"\bproducts\x18\x02 \x03(\v2\x16.pvz.events.v1.ProductR\bproducts\"@\n" +
+	"\fProductAdded\x120\n" +
+	"\aproduct\x18\x01 \x01(\v2\x16.pvz.events.v1.ProductR\aproduct\"B\n" +
+	"\x0eProductDeleted\x120\n" +
+	"\aproduct\x18\x01 \x01(\v2\x16.pvz.events.v1.ProductR\aproduct\"\xc4\x03\n" +
+	"\x05Event\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12;\n" +
+	"\voccurred_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt\x12\x15\n" +
+	"\x06pvz_id\x18\x04 \x01(\tR\x05pvzId\x12\x10\n" +
+	"\x03seq\x18\x05 \x01(\x03R\x03seq\x12K\n" +
+	"\x10reception_opened\x18\n" +
+	" \x01(\v2\x1e.pvz.events.v1.ReceptionOpenedH\x00R\x0freceptionOpened\x12K\n" +
+	"\x10reception_closed\x18\v \x01(\v2\x1e.pvz.events.v1.ReceptionClosedH\x00R\x0freceptionClosed\x12B\n" +
+	"\rproduct_added\x18\f \x01(\v2\x1b.pvz.events.v1.ProductAddedH\x00R\fproductAdded\x12H\n" +
+	"\x0fproduct_deleted\x18\r \x01(\v2\x1d.pvz.events.v1.ProductDeletedH\x00R\x0eproductDeletedB\t\n" +
+	"\apayload*r\n" +
+	"\x0fReceptionStatus\x12 \n" +
+	"\x1cRECEPTION_STATUS_UNSPECIFIED\x10\x00\x12 \n" +
+	"\x1cRECEPTION_STATUS_IN_PROGRESS\x10\x01\x12\x1b\n" +
+	"\x17RECEPTION_STATUS_CLOSED\x10\x02B\x1bZ\x19pvz/pkg/eventspb;eventspbb\x06proto3"
+
+var (
+	file_events_proto_rawDescOnce sync.Once
+	file_events_proto_rawDescData []byte
+)
+
+func file_events_proto_rawDescGZIP() []byte {
+	file_events_proto_rawDescOnce.Do(func() {
+		file_events_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_events_proto_rawDesc), len(file_events_proto_rawDesc)))
+	})
+	return file_events_proto_rawDescData
+}
+
+var file_events_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_events_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_events_proto_goTypes = []any{
+	(ReceptionStatus)(0),          // 0: pvz.events.v1.ReceptionStatus
+	(*Product)(nil),               // 1: pvz.events.v1.Product
+	(*Reception)(nil),             // 2: pvz.events.v1.Reception
+	(*ReceptionOpened)(nil),       // 3: pvz.events.v1.ReceptionOpened
+	(*ReceptionClosed)(nil),       // 4: pvz.events.v1.ReceptionClosed
+	(*ProductAdded)(nil),          // 5: pvz.events.v1.ProductAdded
+	(*ProductDeleted)(nil),        // 6: pvz.events.v1.ProductDeleted
+	(*Event)(nil),                 // 7: pvz.events.v1.Event
+	(*timestamppb.Timestamp)(nil), // 8: google.protobuf.Timestamp
+}
+var file_events_proto_depIdxs = []int32{
+	8,  // 0: pvz.events.v1.Product.date_time:type_name -> google.protobuf.Timestamp
+	8,  // 1: pvz.events.v1.Reception.date_time:type_name -> google.protobuf.Timestamp
+	0,  // 2: pvz.events.v1.Reception.status:type_name -> pvz.events.v1.ReceptionStatus
+	2,  // 3: pvz.events.v1.ReceptionOpened.reception:type_name -> pvz.events.v1.Reception
+	2,  // 4: pvz.events.v1.ReceptionClosed.reception:type_name -> pvz.events.v1.Reception
+	1,  // 5: pvz.events.v1.ReceptionClosed.products:type_name -> pvz.events.v1.Product
+	1,  // 6: pvz.events.v1.ProductAdded.product:type_name -> pvz.events.v1.Product
+	1,  // 7: pvz.events.v1.ProductDeleted.product:type_name -> pvz.events.v1.Product
+	8,  // 8: pvz.events.v1.Event.occurred_at:type_name -> google.protobuf.Timestamp
+	3,  // 9: pvz.events.v1.Event.reception_opened:type_name -> pvz.events.v1.ReceptionOpened
+	4,  // 10: pvz.events.v1.Event.reception_closed:type_name -> pvz.events.v1.ReceptionClosed
+	5,  // 11: pvz.events.v1.Event.product_added:type_name -> pvz.events.v1.ProductAdded
+	6,  // 12: pvz.events.v1.Event.product_deleted:type_name -> pvz.events.v1.ProductDeleted
+	13, // [13:13] is the sub-list for method output_type
+	13, // [13:13] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_events_proto_init() }
+func file_events_proto_init() {
+	if File_events_proto != nil {
+		return
+	}
+	file_events_proto_msgTypes[6].OneofWrappers = []any{
+		(*Event_ReceptionOpened)(nil),
+		(*Event_ReceptionClosed)(nil),
+		(*Event_ProductAdded)(nil),
+		(*Event_ProductDeleted)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_events_proto_rawDesc), len(file_events_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_events_proto_goTypes,
+		DependencyIndexes: file_events_proto_depIdxs,
+		EnumInfos:         file_events_proto_enumTypes,
+		MessageInfos:      file_events_proto_msgTypes,
+	}.Build()
+	File_events_proto = out.File
+	file_events_proto_goTypes = nil
+	file_events_proto_depIdxs = nil
+}
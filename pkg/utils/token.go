@@ -1,22 +1,49 @@
 package utils
 
 import (
-	"os"
+	"errors"
 	"pvz/internal/models"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
-func GetJWToken(role models.Role, userID string) (models.Token, error) {
+// Claims are the claims of tokens issued by the service, the user id is the subject.
+type Claims struct {
+	Role models.Role `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// Signer signs the claims and sets the key id header.
+type Signer interface {
+	Sign(claims jwt.Claims) (string, error)
+}
 
-	claims := jwt.MapClaims{
-		"role":   role,
-		"userID": userID,
+type TokenOptions struct {
+	Signer   Signer
+	Issuer   string
+	Audience string
+	TTL      time.Duration
+}
+
+func GetJWToken(opts TokenOptions, role models.Role, userID string) (models.Token, error) {
+	now := time.Now()
+	claims := Claims{
+		Role: role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID,
+			Issuer:    opts.Issuer,
+			Audience:  jwt.ClaimStrings{opts.Audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(opts.TTL)),
+		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(os.Getenv("SECRET_KEY")))
+	if opts.Signer == nil {
+		return "", errors.New("no token signer configured")
+	}
+	tokenString, err := opts.Signer.Sign(claims)
 	if err != nil {
 		return "", err
 	}
@@ -1 +1 @@
-TRUNCATE TABLE products, receptions, pvz, users RESTART IDENTITY CASCADE;
\ No newline at end of file
+TRUNCATE TABLE products, receptions, pvz, users, idempotency_keys, password_resets, user_pvz, user_identities, api_keys, webhooks, webhook_deliveries, outbox RESTART IDENTITY CASCADE;
\ No newline at end of file
@@ -1,4 +0,0 @@
-DROP TABLE IF EXISTS products;
-DROP TABLE IF EXISTS receptions;
-DROP TABLE IF EXISTS pvz;
-DROP TABLE IF EXISTS users;
\ No newline at end of file
@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"pvz/internal/models"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrentCreateReception(t *testing.T) {
+	cleanupDB(t)
+
+	client := &http.Client{}
+
+	modToken := login(t, "moderator")
+	pvzBody, _ := json.Marshal(map[string]string{"city": "Казань"})
+	req, _ := http.NewRequest("POST", baseURL+"/pvz", bytes.NewReader(pvzBody))
+	req.Header.Set("Authorization", "Bearer "+modToken)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var pvz models.PVZ
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&pvz))
+
+	empToken := login(t, "employee")
+	recvBody, _ := json.Marshal(map[string]string{"pvzId": pvz.ID})
+
+	const workers = 20
+	var (
+		wg      sync.WaitGroup
+		start   = make(chan struct{})
+		codes   = make(chan int, workers)
+		reqErrs = make(chan error, workers)
+	)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest("POST", baseURL+"/receptions", bytes.NewReader(recvBody))
+			req.Header.Set("Authorization", "Bearer "+empToken)
+			req.Header.Set("Content-Type", "application/json")
+			<-start
+			resp, err := client.Do(req)
+			if err != nil {
+				reqErrs <- err
+				return
+			}
+			resp.Body.Close()
+			codes <- resp.StatusCode
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(codes)
+	close(reqErrs)
+
+	for err := range reqErrs {
+		require.NoError(t, err)
+	}
+	created := 0
+	for code := range codes {
+		if code == http.StatusCreated {
+			created++
+			continue
+		}
+		require.Equal(t, http.StatusBadRequest, code)
+	}
+	require.Equal(t, 1, created)
+
+	db := openDB(t)
+	defer db.Close()
+	var open int
+	err = db.QueryRow(`SELECT count(*) FROM receptions WHERE pvz_id = $1 AND status = $2;`,
+		pvz.ID, models.StatusInProgress).Scan(&open)
+	require.NoError(t, err)
+	require.Equal(t, 1, open)
+}
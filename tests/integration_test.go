@@ -17,7 +17,7 @@ import (
 
 const baseURL = "http://localhost:8081"
 
-func cleanupDB(t *testing.T) {
+func openDB(t *testing.T) *sql.DB {
 	t.Helper()
 
 	cfg := config.Config{
@@ -37,9 +37,16 @@ func cleanupDB(t *testing.T) {
 	dsn := cfg.DB.GetDsn()
 	db, err := sql.Open("postgres", dsn)
 	require.NoError(t, err)
+	return db
+}
+
+func cleanupDB(t *testing.T) {
+	t.Helper()
+
+	db := openDB(t)
 	defer db.Close()
 
-	_, err = db.Exec(`TRUNCATE TABLE products, receptions, pvz, users RESTART IDENTITY CASCADE;`)
+	_, err := db.Exec(`TRUNCATE TABLE products, receptions, pvz, users, idempotency_keys, password_resets, user_pvz, user_identities, api_keys, webhooks, webhook_deliveries, outbox RESTART IDENTITY CASCADE;`)
 	require.NoError(t, err)
 }
 